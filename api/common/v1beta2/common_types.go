@@ -2,9 +2,13 @@ package v1beta2
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // KubernetesConfig will be the JSON struct for Basic Redis Config
@@ -22,6 +26,18 @@ type KubernetesConfig struct {
 	MinReadySeconds                      *int32                                                  `json:"minReadySeconds,omitempty"`
 }
 
+// ManagedFailoverUpdateStrategyType is the update strategy of a RedisCluster or a RedisReplication
+// whose pods are restarted by the operator one at a time, each master failed over to one of its
+// replicas before its pod is deleted. The statefulsets restarted by the operator are given the
+// OnDelete strategy.
+const ManagedFailoverUpdateStrategyType appsv1.StatefulSetUpdateStrategyType = "ManagedFailover"
+
+// UsesManagedFailover reports whether the pods are restarted by the operator with the
+// ManagedFailover update strategy
+func (in *KubernetesConfig) UsesManagedFailover() bool {
+	return in.UpdateStrategy.Type == ManagedFailoverUpdateStrategyType
+}
+
 func (in *KubernetesConfig) GetServiceType() string {
 	if in.Service == nil {
 		return "ClusterIP"
@@ -99,6 +115,34 @@ func (in *KubernetesConfig) ShouldIncludeBusPortForAdditional() bool {
 	return *in.Service.Additional.IncludeBusPort
 }
 
+// ShouldCreateReplicaService returns whether the service of the replicas should be created,
+// byDefault when service.replicas is not set
+func (in *KubernetesConfig) ShouldCreateReplicaService(byDefault bool) bool {
+	if in.Service == nil || in.Service.Replicas == nil {
+		return byDefault
+	}
+	if in.Service.Replicas.Enabled == nil {
+		return true
+	}
+	return *in.Service.Replicas.Enabled
+}
+
+// GetReplicaServiceType returns the type of the service of the replicas, ClusterIP by default
+func (in *KubernetesConfig) GetReplicaServiceType() string {
+	if in.Service == nil || in.Service.Replicas == nil || in.Service.Replicas.Type == "" {
+		return "ClusterIP"
+	}
+	return in.Service.Replicas.Type
+}
+
+// GetReplicaServiceAnnotations returns the additional annotations of the service of the replicas
+func (in *KubernetesConfig) GetReplicaServiceAnnotations() map[string]string {
+	if in.Service == nil || in.Service.Replicas == nil {
+		return nil
+	}
+	return in.Service.Replicas.AdditionalAnnotations
+}
+
 // ServiceConfig define the type of service to be created and its annotations
 // +k8s:deepcopy-gen=true
 type ServiceConfig struct {
@@ -112,6 +156,10 @@ type ServiceConfig struct {
 	Headless *Service `json:"headless,omitempty"`
 	// Additional config for which suffix is -additional service
 	Additional *Service `json:"additional,omitempty"`
+	// Replicas config for which suffix is -replica service, it selects the pods labelled
+	// redis-role=slave. A RedisReplication creates it unless it is disabled, a RedisCluster only
+	// when it is set, selecting its follower pods.
+	Replicas *Service `json:"replicas,omitempty"`
 }
 
 // Service is the struct to define the service type and its annotations
@@ -135,6 +183,60 @@ type ExistingPasswordSecret struct {
 	Key  *string `json:"key,omitempty"`
 }
 
+// DefaultPasswordRotationGracePeriod is how long the previous password keeps working when no
+// grace period is configured
+const DefaultPasswordRotationGracePeriod = 5 * time.Minute
+
+// PasswordRotation configures how a change of the ExistingPasswordSecret is rolled out
+// +k8s:deepcopy-gen=true
+type PasswordRotation struct {
+	// GracePeriod is how long the previous password keeps being accepted once the new password
+	// works on every node, clients have to switch to the new password within it
+	// +kubebuilder:default:="5m"
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// GetGracePeriod returns the configured grace period or the default one
+func (in *PasswordRotation) GetGracePeriod() time.Duration {
+	if in == nil || in.GracePeriod == nil {
+		return DefaultPasswordRotationGracePeriod
+	}
+	return in.GracePeriod.Duration
+}
+
+// PasswordRotationPhase is a step of the rollout of a changed password
+type PasswordRotationPhase string
+
+const (
+	// PasswordRotationAddingPassword adds the new password next to the previous one on every node
+	PasswordRotationAddingPassword PasswordRotationPhase = "AddingPassword"
+	// PasswordRotationGracePeriod waits for clients to switch to the new password
+	PasswordRotationGracePeriod PasswordRotationPhase = "GracePeriod"
+	// PasswordRotationRestartingPods restarts, one at a time, the pods whose containers were
+	// started with the previous password, their probes and exporter would fail without it
+	PasswordRotationRestartingPods PasswordRotationPhase = "RestartingPods"
+	// PasswordRotationRemovingPassword removes the previous password from every node
+	PasswordRotationRemovingPassword PasswordRotationPhase = "RemovingPassword"
+	// PasswordRotationCompleted reports the previous password is no longer accepted
+	PasswordRotationCompleted PasswordRotationPhase = "Completed"
+)
+
+// PasswordRotationStatus is the progress of the last rotation of the ExistingPasswordSecret
+// +k8s:deepcopy-gen=true
+type PasswordRotationStatus struct {
+	Phase   PasswordRotationPhase `json:"phase,omitempty"`
+	Message string                `json:"message,omitempty"`
+	// StartTime is when the change of the password secret was detected
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// GracePeriodEnd is when the previous password may stop being accepted
+	GracePeriodEnd *metav1.Time `json:"gracePeriodEnd,omitempty"`
+	// PendingPods are the pods still running containers started with the previous password
+	PendingPods []string `json:"pendingPods,omitempty"`
+	// CompletionTime is when the previous password was removed from every node
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
 // RedisExporter interface will have the information for redis exporter related stuff
 // +k8s:deepcopy-gen=true
 type RedisExporter struct {
@@ -183,8 +285,187 @@ type TLSConfig struct {
 	KeyFile     string `json:"key,omitempty"`
 	// Reference to secret which contains the certificates
 	Secret corev1.SecretVolumeSource `json:"secret"`
+	// IssuerRef makes the operator request the certificate of the secret from cert-manager, for
+	// the headless service name of every pod and the services of the instance
+	// +optional
+	IssuerRef *CertificateIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// CertificateIssuerRef is the cert-manager issuer certificates are requested from
+// +k8s:deepcopy-gen=true
+type CertificateIssuerRef struct {
+	Name string `json:"name"`
+	// +kubebuilder:default:=Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:default:=cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// TLSRotationStatus is the progress of the rollout of the last change of the TLS secret, pods
+// load the certificate at start and are restarted one at a time to pick it up
+// +k8s:deepcopy-gen=true
+type TLSRotationStatus struct {
+	// SecretHash identifies the content of the TLS secret the pods are rolled out to
+	SecretHash string `json:"secretHash,omitempty"`
+	// StartTime is when the change of the TLS secret was detected
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// PendingPods are the pods still running containers started with the previous certificate
+	PendingPods []string `json:"pendingPods,omitempty"`
+	// CompletionTime is when every pod was restarted with the certificate
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DefaultMinReplicasMaxLag is the min-replicas-max-lag of redis, in seconds
+const DefaultMinReplicasMaxLag = 10
+
+// Durability keeps a master from accepting writes unless enough of its replicas acknowledged the
+// replication stream recently, it sets min-replicas-to-write and min-replicas-max-lag
+// +k8s:deepcopy-gen=true
+type Durability struct {
+	// MinReplicasToWrite is the number of replicas a master needs to accept writes
+	// +kubebuilder:validation:Minimum=1
+	MinReplicasToWrite int32 `json:"minReplicasToWrite"`
+	// MinReplicasMaxLag is the number of seconds since its last acknowledgement a replica still
+	// counts for MinReplicasToWrite
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicasMaxLag *int32 `json:"minReplicasMaxLag,omitempty"`
+}
+
+// GetMinReplicasMaxLag returns the configured lag or the default one
+func (in *Durability) GetMinReplicasMaxLag() int32 {
+	if in == nil || in.MinReplicasMaxLag == nil {
+		return DefaultMinReplicasMaxLag
+	}
+	return *in.MinReplicasMaxLag
 }
 
+// Validate checks that a master has replicas enough to accept writes and that the dynamic config
+// leaves the durability to the operator
+func (in *Durability) Validate(replicas int32, dynamicConfig []string) error {
+	if in == nil {
+		return nil
+	}
+	if in.MinReplicasToWrite > replicas {
+		return fmt.Errorf("minReplicasToWrite %d exceeds the %d replicas of a master", in.MinReplicasToWrite, replicas)
+	}
+	for _, config := range dynamicConfig {
+		switch key := strings.ToLower(strings.Fields(config + " ")[0]); key {
+		case "min-replicas-to-write", "min-replicas-max-lag", "min-slaves-to-write", "min-slaves-max-lag":
+			return fmt.Errorf("the dynamic config cannot set %s, it is managed through the durability", key)
+		}
+	}
+	return nil
+}
+
+// DurabilityStatus is the durability applied to the pods
+// +k8s:deepcopy-gen=true
+type DurabilityStatus struct {
+	// MinReplicasToWrite is the min-replicas-to-write of the pods, 0 while relaxed
+	MinReplicasToWrite int32 `json:"minReplicasToWrite"`
+	// MinReplicasMaxLag is the min-replicas-max-lag of the pods
+	// +optional
+	MinReplicasMaxLag int32 `json:"minReplicasMaxLag,omitempty"`
+	// Relaxed is set while replicas are knowingly unavailable, writes do not wait for them
+	// +optional
+	Relaxed bool `json:"relaxed,omitempty"`
+	// Reason is the planned scale-down or rolling restart the durability is relaxed for
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// Engine is the server run by the pods, Redis or its Valkey fork
+// +kubebuilder:validation:Enum=redis;valkey
+type Engine string
+
+const (
+	EngineRedis  Engine = "redis"
+	EngineValkey Engine = "valkey"
+)
+
+// Server returns the binary starting the server, an empty engine is Redis
+func (e Engine) Server() string {
+	if e == EngineValkey {
+		return "valkey-server"
+	}
+	return "redis-server"
+}
+
+// Sentinel returns the binary starting a sentinel
+func (e Engine) Sentinel() string {
+	if e == EngineValkey {
+		return "valkey-sentinel"
+	}
+	return "redis-sentinel"
+}
+
+// CLI returns the command line client of the engine
+func (e Engine) CLI() string {
+	if e == EngineValkey {
+		return "valkey-cli"
+	}
+	return "redis-cli"
+}
+
+// MajorVersion returns the major version the pods are configured for, like v7, from an engine
+// version like 7.2.4, or nil when it is unknown. Valkey forked Redis 7.2 and has every feature
+// of Redis 7, it is never configured below v7.
+func (e Engine) MajorVersion(version string) *string {
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(version, "v"), ".", 2)[0])
+	if err != nil {
+		if e != EngineValkey {
+			return nil
+		}
+		major = 7
+	}
+	if e == EngineValkey && major < 7 {
+		major = 7
+	}
+	majorVersion := "v" + strconv.Itoa(major)
+	return &majorVersion
+}
+
+// Condition types reported by Redis, RedisCluster, RedisReplication and RedisSentinel
+const (
+	// ConditionReady is True when every pod serves clients in the desired topology
+	ConditionReady = "Ready"
+	// ConditionProgressing is True while pods are created, updated or restarted
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is True when the topology is broken and needs repair
+	ConditionDegraded = "Degraded"
+	// ConditionScalingInProgress is True while nodes are added or removed
+	ConditionScalingInProgress = "ScalingInProgress"
+	// ConditionFailoverInProgress is True while the master role moves to another pod
+	ConditionFailoverInProgress = "FailoverInProgress"
+	// ConditionQuorumReachable is True when the sentinels can authorize a failover of every
+	// master they monitor
+	ConditionQuorumReachable = "QuorumReachable"
+)
+
+// Reasons of the conditions
+const (
+	// ConditionReasonAsExpected is the reason of every condition in its healthy state
+	ConditionReasonAsExpected          = "AsExpected"
+	ConditionReasonPodsNotReady        = "PodsNotReady"
+	ConditionReasonInitializing        = "Initializing"
+	ConditionReasonBootstrapping       = "Bootstrapping"
+	ConditionReasonPasswordRotation    = "PasswordRotation"
+	ConditionReasonTLSRotation         = "TLSRotation"
+	ConditionReasonUnhealthyNodes      = "UnhealthyNodes"
+	ConditionReasonNoMaster            = "NoMaster"
+	ConditionReasonBrokenReplication   = "BrokenReplication"
+	ConditionReasonNoPromotableReplica = "NoPromotableReplica"
+	ConditionReasonNoQuorum            = "NoQuorum"
+	ConditionReasonReplicationNotReady = "ReplicationNotReady"
+	ConditionReasonScalingUp           = "ScalingUp"
+	ConditionReasonScalingDown         = "ScalingDown"
+	ConditionReasonFailover            = "Failover"
+	ConditionReasonRollingRestart      = "RollingRestart"
+)
+
 // Sidecar for each Redis pods
 // +k8s:deepcopy-gen=true
 type Sidecar struct {
@@ -86,6 +86,22 @@ func TestKubernetesConfig_ShouldCreateAdditionalService(t *testing.T) {
 	}
 }
 
+func TestKubernetesConfig_ShouldCreateReplicaService(t *testing.T) {
+	config := &KubernetesConfig{}
+	assert.True(t, config.ShouldCreateReplicaService(true))
+	assert.False(t, config.ShouldCreateReplicaService(false))
+	assert.Equal(t, "ClusterIP", config.GetReplicaServiceType())
+	assert.Nil(t, config.GetReplicaServiceAnnotations())
+
+	config.Service = &ServiceConfig{Replicas: &Service{Type: "LoadBalancer", AdditionalAnnotations: map[string]string{"a": "b"}}}
+	assert.True(t, config.ShouldCreateReplicaService(false))
+	assert.Equal(t, "LoadBalancer", config.GetReplicaServiceType())
+	assert.Equal(t, map[string]string{"a": "b"}, config.GetReplicaServiceAnnotations())
+
+	config.Service.Replicas.Enabled = ptr.To(false)
+	assert.False(t, config.ShouldCreateReplicaService(true))
+}
+
 func TestACLConfig_PersistentVolumeClaim(t *testing.T) {
 	tests := []struct {
 		name                     string
@@ -232,3 +248,20 @@ func TestACLConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestEngine(t *testing.T) {
+	var redis Engine
+	assert.Equal(t, "redis-server", redis.Server())
+	assert.Equal(t, "redis-sentinel", redis.Sentinel())
+	assert.Equal(t, "redis-cli", redis.CLI())
+	assert.Equal(t, "valkey-server", EngineValkey.Server())
+	assert.Equal(t, "valkey-sentinel", EngineValkey.Sentinel())
+	assert.Equal(t, "valkey-cli", EngineValkey.CLI())
+
+	assert.Nil(t, redis.MajorVersion(""))
+	assert.Equal(t, ptr.To("v6"), EngineRedis.MajorVersion("6.2.14"))
+	assert.Equal(t, ptr.To("v8"), EngineRedis.MajorVersion("v8"))
+	// Valkey has the features of Redis 7 whatever its version
+	assert.Equal(t, ptr.To("v7"), EngineValkey.MajorVersion(""))
+	assert.Equal(t, ptr.To("v8"), EngineValkey.MajorVersion("8.1"))
+}
@@ -47,6 +47,19 @@ type RedisSpec struct {
 	TerminationGracePeriodSeconds *int64                     `json:"terminationGracePeriodSeconds,omitempty" protobuf:"varint,4,opt,name=terminationGracePeriodSeconds"`
 	EnvVars                       *[]corev1.EnvVar           `json:"env,omitempty"`
 	HostPort                      *int                       `json:"hostPort,omitempty"`
+	// PasswordRotation configures how a change of the password secret is rolled out
+	// +optional
+	PasswordRotation *common.PasswordRotation `json:"passwordRotation,omitempty"`
+	// Engine is the server run by the pods, redis or valkey. Changing it from redis to valkey
+	// rolls the pods out one at a time with the Valkey binaries, Valkey loads the data files of
+	// Redis up to 7.2.
+	// +optional
+	Engine common.Engine `json:"engine,omitempty"`
+	// EngineVersion is the version of the engine shipped by the image, like 8.0, the pods are
+	// configured with the features of its major version
+	// +optional
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+(\.[0-9]+){0,2}$`
+	EngineVersion string `json:"engineVersion,omitempty"`
 }
 
 func (cr *RedisSpec) GetRedisDynamicConfig() []string {
@@ -57,11 +70,31 @@ func (cr *RedisSpec) GetRedisDynamicConfig() []string {
 }
 
 // RedisStatus defines the observed state of Redis
-type RedisStatus struct{}
+type RedisStatus struct {
+	// PasswordRotation is the progress of the last change of the password secret
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
+	// TLSRotation is the progress of the rollout of the last change of the TLS secret
+	// +optional
+	TLSRotation *common.TLSRotationStatus `json:"tlsRotation,omitempty"`
+	// ObservedGeneration is the generation of the spec the conditions were last computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Progressing, Degraded, ScalingInProgress and FailoverInProgress
+	// conditions
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Redis is the Schema for the redis API
 type Redis struct {
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta2
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -80,6 +81,14 @@ func (r *Redis) validate(_ *Redis) (admission.Warnings, error) {
 		}
 	}
 
+	if r.Spec.KubernetesConfig.UsesManagedFailover() {
+		errors = append(errors, field.NotSupported(
+			field.NewPath("spec").Child("kubernetesConfig", "updateStrategy", "type"),
+			r.Spec.KubernetesConfig.UpdateStrategy.Type,
+			[]string{string(appsv1.RollingUpdateStatefulSetStrategyType), string(appsv1.OnDeleteStatefulSetStrategyType)},
+		))
+	}
+
 	if len(errors) == 0 {
 		return nil, nil
 	}
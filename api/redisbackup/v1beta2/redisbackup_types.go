@@ -0,0 +1,178 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisBackupSpec defines the desired state of RedisBackup
+type RedisBackupSpec struct {
+	// Target is the Redis deployment, in the same namespace, whose data is backed up.
+	Target BackupTarget `json:"target"`
+	// Storage is the backend the RDB snapshots are streamed into.
+	Storage BackupStorage `json:"storage"`
+	// DeletionPolicy controls whether the stored snapshots are removed along with the backup.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy BackupDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+type BackupDeletionPolicy string
+
+const (
+	// BackupDeletionPolicyRetain keeps the snapshots in the storage when the backup is deleted
+	BackupDeletionPolicyRetain BackupDeletionPolicy = "Retain"
+	// BackupDeletionPolicyDelete removes the snapshots from the storage when the backup is deleted
+	BackupDeletionPolicyDelete BackupDeletionPolicy = "Delete"
+)
+
+// BackupTarget references the Redis deployment a backup is taken from
+type BackupTarget struct {
+	// +kubebuilder:validation:Enum=RedisCluster;RedisReplication;Redis
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+const (
+	BackupTargetRedisCluster     = "RedisCluster"
+	BackupTargetRedisReplication = "RedisReplication"
+	BackupTargetRedis            = "Redis"
+)
+
+// BackupStorage is the pluggable storage backend of a backup, exactly one backend must be set
+type BackupStorage struct {
+	// PersistentVolumeClaim stores the snapshots on an existing claim. The claim is mounted
+	// by a short-lived writer pod for the duration of the backup.
+	PersistentVolumeClaim *PVCBackupStorage `json:"persistentVolumeClaim,omitempty"`
+	// Filesystem stores the snapshots on a path of the operator's own filesystem, typically a
+	// volume mounted into the operator deployment.
+	Filesystem *FilesystemBackupStorage `json:"filesystem,omitempty"`
+}
+
+// PVCBackupStorage stores snapshots on a PersistentVolumeClaim
+type PVCBackupStorage struct {
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+	// Path is the directory inside the claim the snapshots are written to, defaults to the claim root
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// FilesystemBackupStorage stores snapshots on the operator's filesystem
+type FilesystemBackupStorage struct {
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+// RedisBackupStatus defines the observed state of RedisBackup
+type RedisBackupStatus struct {
+	State          RedisBackupState `json:"state,omitempty"`
+	Reason         string           `json:"reason,omitempty"`
+	StartTime      *metav1.Time     `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time     `json:"completionTime,omitempty"`
+	// Progress is the number of finished shards out of the total, e.g. "2/3"
+	Progress string `json:"progress,omitempty"`
+	// Size is the total size in bytes of the stored snapshots
+	Size   int64              `json:"size,omitempty"`
+	Shards []RedisBackupShard `json:"shards,omitempty"`
+}
+
+// RedisBackupShard is the result of backing up a single redis node
+type RedisBackupShard struct {
+	// PodName is the pod the snapshot was taken on
+	PodName string `json:"podName"`
+	// NodeID is the cluster node ID of the shard master, only set for RedisCluster targets
+	NodeID string `json:"nodeID,omitempty"`
+	// Slots are the hash slot ranges owned by the shard, only set for RedisCluster targets
+	Slots []string              `json:"slots,omitempty"`
+	State RedisBackupShardState `json:"state,omitempty"`
+	// SaveRequestTime is the redis server time BGSAVE was requested at
+	SaveRequestTime *metav1.Time `json:"saveRequestTime,omitempty"`
+	// SavedBeforeRequest is the RDB save state the pod reported right before BGSAVE was requested,
+	// the requested save is the one that changes it
+	SavedBeforeRequest *RedisBackupSaveState `json:"savedBeforeRequest,omitempty"`
+	// Location is the path of the snapshot relative to the storage root
+	Location string `json:"location,omitempty"`
+	// Size is the size in bytes of the stored snapshot
+	Size    int64  `json:"size,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RedisBackupSaveState is the RDB save state reported by INFO persistence
+type RedisBackupSaveState struct {
+	// Saves is the number of RDB saves started since the server started, unset before redis 7.0
+	Saves *int64 `json:"saves,omitempty"`
+	// LastSaveTime is the time the last successful save ended at
+	LastSaveTime *metav1.Time `json:"lastSaveTime,omitempty"`
+	// LastStatus is the result of the last save, ok or err
+	LastStatus string `json:"lastStatus,omitempty"`
+}
+
+type RedisBackupState string
+
+// Status Field of the Redis Backup
+const (
+	RedisBackupPending   RedisBackupState = "Pending"
+	RedisBackupRunning   RedisBackupState = "Running"
+	RedisBackupCompleted RedisBackupState = "Completed"
+	RedisBackupFailed    RedisBackupState = "Failed"
+)
+
+type RedisBackupShardState string
+
+// Status Field of a single shard of the Redis Backup
+const (
+	RedisBackupShardPending   RedisBackupShardState = "Pending"
+	RedisBackupShardSaving    RedisBackupShardState = "Saving"
+	RedisBackupShardCompleted RedisBackupShardState = "Completed"
+	RedisBackupShardFailed    RedisBackupShardState = "Failed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The Redis deployment being backed up"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="The current state of the backup"
+// +kubebuilder:printcolumn:name="Progress",type="string",JSONPath=".status.progress",description="Finished shards out of the total"
+// +kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".status.size",description="Total size in bytes of the stored snapshots"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Backup"
+
+// RedisBackup is the Schema for the redisbackups API
+type RedisBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupSpec   `json:"spec"`
+	Status RedisBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisBackupList contains a list of RedisBackup
+type RedisBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackup `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisBackup{}, &RedisBackupList{})
+}
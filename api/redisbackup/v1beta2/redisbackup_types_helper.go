@@ -0,0 +1,38 @@
+package v1beta2
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Validate checks that exactly one storage backend is specified
+func (s *BackupStorage) Validate() error {
+	if s.PersistentVolumeClaim == nil && s.Filesystem == nil {
+		return errors.New("one of 'persistentVolumeClaim' or 'filesystem' must be specified in backup storage")
+	}
+	if s.PersistentVolumeClaim != nil && s.Filesystem != nil {
+		return errors.New("only one of 'persistentVolumeClaim' or 'filesystem' can be specified in backup storage")
+	}
+	return nil
+}
+
+// IsFinished reports whether the backup reached a terminal state
+func (cr *RedisBackup) IsFinished() bool {
+	return cr.Status.State == RedisBackupCompleted || cr.Status.State == RedisBackupFailed
+}
+
+// ShardLocation returns the path, relative to the storage root, the snapshot of the given pod is stored at
+func (cr *RedisBackup) ShardLocation(podName string) string {
+	return path.Join(cr.Namespace, cr.Name, fmt.Sprintf("%s.rdb", podName))
+}
+
+// WriterPodName returns the name of the pod that mounts the backup claim while snapshots are written
+func (cr *RedisBackup) WriterPodName() string {
+	return cr.Name + "-backup-writer"
+}
+
+// IsFinished reports whether the restore reached a terminal state
+func (cr *RedisRestore) IsFinished() bool {
+	return cr.Status.State == RedisRestoreCompleted || cr.Status.State == RedisRestoreFailed
+}
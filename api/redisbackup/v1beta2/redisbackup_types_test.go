@@ -0,0 +1,59 @@
+package v1beta2_test
+
+import (
+	"testing"
+
+	v1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBackupStorage_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage v1beta2.BackupStorage
+		wantErr bool
+	}{
+		{
+			name:    "no backend",
+			storage: v1beta2.BackupStorage{},
+			wantErr: true,
+		},
+		{
+			name: "both backends",
+			storage: v1beta2.BackupStorage{
+				PersistentVolumeClaim: &v1beta2.PVCBackupStorage{ClaimName: "backups"},
+				Filesystem:            &v1beta2.FilesystemBackupStorage{Path: "/backups"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "pvc backend",
+			storage: v1beta2.BackupStorage{
+				PersistentVolumeClaim: &v1beta2.PVCBackupStorage{ClaimName: "backups"},
+			},
+		},
+		{
+			name: "filesystem backend",
+			storage: v1beta2.BackupStorage{
+				Filesystem: &v1beta2.FilesystemBackupStorage{Path: "/backups"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.storage.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRedisBackup_ShardLocation(t *testing.T) {
+	backup := &v1beta2.RedisBackup{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "redis"}}
+	assert.Equal(t, "redis/nightly/redis-cluster-leader-0.rdb", backup.ShardLocation("redis-cluster-leader-0"))
+}
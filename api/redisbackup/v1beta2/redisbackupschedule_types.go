@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisBackupScheduleSpec defines the desired state of RedisBackupSchedule
+type RedisBackupScheduleSpec struct {
+	// Schedule is a cron expression in UTC, e.g. "0 2 * * *", at which backups are taken.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Suspend stops new backups from being taken, existing ones are still pruned.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Target is the Redis deployment, in the same namespace, whose data is backed up.
+	Target BackupTarget `json:"target"`
+	// Storage is the backend the RDB snapshots are streamed into.
+	Storage BackupStorage `json:"storage"`
+	// Retention selects the completed backups to keep, all backups are kept when it is not set.
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention selects the completed backups of a schedule to keep. A backup is kept when
+// any of the rules selects it, the most recent completed backup is always kept.
+type BackupRetention struct {
+	// KeepLast keeps the given number of most recent backups
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// KeepDaily keeps the most recent backup of each of the given number of most recent days
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+	// KeepWeekly keeps the most recent backup of each of the given number of most recent weeks
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+}
+
+// RedisBackupScheduleStatus defines the observed state of RedisBackupSchedule
+type RedisBackupScheduleStatus struct {
+	// AccountedBackups are the names of the finished backups counted in the status, the ones that
+	// no longer exist are dropped
+	// +listType=set
+	// +optional
+	AccountedBackups []string `json:"accountedBackups,omitempty"`
+	// LastScheduleTime is the most recent schedule tick that was processed
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulBackupTime is the completion time of the last backup that succeeded
+	LastSuccessfulBackupTime *metav1.Time `json:"lastSuccessfulBackupTime,omitempty"`
+	// LastBackup is the name of the most recently created backup
+	LastBackup string `json:"lastBackup,omitempty"`
+	// ActiveBackup is the name of the backup that is currently running
+	ActiveBackup string `json:"activeBackup,omitempty"`
+	// ConsecutiveFailures is the number of backups that failed since the last successful one
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// FailedBackups is the total number of backups of this schedule that failed
+	FailedBackups int32 `json:"failedBackups,omitempty"`
+	// SuccessfulBackups is the total number of backups of this schedule that succeeded
+	SuccessfulBackups int32  `json:"successfulBackups,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="The cron expression backups are taken at"
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The Redis deployment being backed up"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",description="Whether taking backups is suspended"
+// +kubebuilder:printcolumn:name="Last Success",type="date",JSONPath=".status.lastSuccessfulBackupTime",description="Completion time of the last successful backup"
+// +kubebuilder:printcolumn:name="Failures",type="integer",JSONPath=".status.consecutiveFailures",description="Backups failed since the last successful one"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Backup Schedule"
+
+// RedisBackupSchedule is the Schema for the redisbackupschedules API
+type RedisBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupScheduleSpec   `json:"spec"`
+	Status RedisBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisBackupScheduleList contains a list of RedisBackupSchedule
+type RedisBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackupSchedule `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisBackupSchedule{}, &RedisBackupScheduleList{})
+}
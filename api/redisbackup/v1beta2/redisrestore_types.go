@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisRestoreSpec defines the desired state of RedisRestore
+type RedisRestoreSpec struct {
+	// Target is the Redis deployment, in the same namespace, that is seeded from the backup.
+	// The target must not have been deployed yet, its pods load the snapshots before redis starts.
+	Target RestoreTarget `json:"target"`
+	// BackupName is the name of the RedisBackup, in the same namespace, the data is restored from.
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName"`
+}
+
+// RestoreTarget references the Redis deployment a restore seeds
+type RestoreTarget struct {
+	// +kubebuilder:validation:Enum=RedisCluster;RedisReplication
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RedisRestoreStatus defines the observed state of RedisRestore
+type RedisRestoreStatus struct {
+	State  RedisRestoreState `json:"state,omitempty"`
+	Reason string            `json:"reason,omitempty"`
+	// Source is the claim the snapshots of the backup are read from
+	Source         *PVCBackupStorage   `json:"source,omitempty"`
+	Shards         []RedisRestoreShard `json:"shards,omitempty"`
+	StartTime      *metav1.Time        `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time        `json:"completionTime,omitempty"`
+}
+
+// RedisRestoreShard maps a snapshot of the backup onto a pod of the target
+type RedisRestoreShard struct {
+	// PodName is the target pod the snapshot is loaded into
+	PodName string `json:"podName"`
+	// Location is the path of the snapshot relative to the storage root
+	Location string `json:"location"`
+	// Slots are the hash slot ranges the pod owns once restored, only set for RedisCluster targets
+	Slots []string `json:"slots,omitempty"`
+}
+
+type RedisRestoreState string
+
+// Status Field of the Redis Restore
+const (
+	RedisRestorePending   RedisRestoreState = "Pending"
+	RedisRestoreRestoring RedisRestoreState = "Restoring"
+	RedisRestoreCompleted RedisRestoreState = "Completed"
+	RedisRestoreFailed    RedisRestoreState = "Failed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName",description="The backup being restored"
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The Redis deployment being seeded"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="The current state of the restore"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Restore"
+
+// RedisRestore is the Schema for the redisrestores API
+type RedisRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisRestoreSpec   `json:"spec"`
+	Status RedisRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisRestoreList contains a list of RedisRestore
+type RedisRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisRestore `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisRestore{}, &RedisRestoreList{})
+}
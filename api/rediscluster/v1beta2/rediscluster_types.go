@@ -17,6 +17,11 @@ limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -57,6 +62,150 @@ type RedisClusterSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy *string `json:"podManagementPolicy,omitempty"`
+	// ReplicasPerShard is the number of followers replicating every leader. It sizes the follower
+	// statefulset to the number of leaders times it, in place of redisFollower.replicas. Followers
+	// are attached round-robin across the masters, away from the node and the zone of their master
+	// when the pods are spread over several.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ReplicasPerShard *int32 `json:"replicasPerShard,omitempty"`
+	// SlotDistribution sets how the hash slots are spread over the shards, evenly by default
+	// +optional
+	SlotDistribution *SlotDistribution `json:"slotDistribution,omitempty"`
+	// ScalingPolicy sets how a change of the number of leaders or followers is carried out
+	// +optional
+	ScalingPolicy *ScalingPolicy `json:"scalingPolicy,omitempty"`
+	// OrphanedNodes sets how the nodes left behind in the cluster by deleted pods or lost
+	// node-conf volumes are forgotten
+	// +optional
+	OrphanedNodes *OrphanedNodes `json:"orphanedNodes,omitempty"`
+	// ReadinessGate adds the rediscluster.opstreelabs.in/node-ready readiness gate to the pods. The
+	// operator only sets it once the node joined the cluster with the role of its pod, the state of
+	// the cluster is ok and, for a replica, the link to its master is up, so that the services only
+	// route to such pods. It requires the Parallel pod management policy.
+	// +optional
+	ReadinessGate bool `json:"readinessGate,omitempty"`
+	// ExternalAccess exposes every pod through a service of its own whose address the node
+	// announces, so that clients outside of the Kubernetes network follow the MOVED redirects
+	// +optional
+	ExternalAccess *ExternalAccess `json:"externalAccess,omitempty"`
+	// Durability keeps the masters from accepting writes unless enough of their replicas are in
+	// sync. It is relaxed while a planned scale-down or rolling restart makes replicas unavailable.
+	// +optional
+	Durability *common.Durability `json:"durability,omitempty"`
+	// PasswordRotation configures how a change of the password secret is rolled out
+	// +optional
+	PasswordRotation *common.PasswordRotation `json:"passwordRotation,omitempty"`
+	// Engine is the server run by the pods, redis or valkey. Changing it from redis to valkey
+	// rolls the pods out one at a time with the Valkey binaries, Valkey loads the data files of
+	// Redis up to 7.2.
+	// +optional
+	Engine common.Engine `json:"engine,omitempty"`
+	// EngineVersion is the version of the engine shipped by the image, like 8.0, the pods are
+	// configured with the features of its major version
+	// +optional
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+(\.[0-9]+){0,2}$`
+	EngineVersion string `json:"engineVersion,omitempty"`
+}
+
+// SlotDistributionStrategy is how the hash slots are spread over the shards
+// +kubebuilder:validation:Enum=even;weighted;pinned
+type SlotDistributionStrategy string
+
+const (
+	// SlotDistributionEven gives every shard the same number of slots
+	SlotDistributionEven SlotDistributionStrategy = "even"
+	// SlotDistributionWeighted gives every shard a number of slots in proportion to its weight
+	SlotDistributionWeighted SlotDistributionStrategy = "weighted"
+	// SlotDistributionPinned moves the slots of pins onto their shard, the other slots stay where
+	// they are and are only moved off the shards removed by a scale down
+	SlotDistributionPinned SlotDistributionStrategy = "pinned"
+)
+
+// SlotDistribution sets how the hash slots are spread over the shards
+type SlotDistribution struct {
+	// Strategy is even, weighted or pinned
+	// +kubebuilder:default:=even
+	// +optional
+	Strategy SlotDistributionStrategy `json:"strategy,omitempty"`
+	// Weights are the weights of the shards for the weighted strategy, a shard left out weighs 1
+	// +optional
+	// +listType=map
+	// +listMapKey=shard
+	Weights []ShardWeight `json:"weights,omitempty"`
+	// Pins are the slots the pinned strategy moves onto a shard
+	// +optional
+	// +listType=map
+	// +listMapKey=shard
+	Pins []ShardPin `json:"pins,omitempty"`
+}
+
+// ShardWeight is the weight of a shard for the weighted slot distribution
+type ShardWeight struct {
+	// Shard is the index of the shard, the one of its leader pod
+	// +kubebuilder:validation:Minimum=0
+	Shard int32 `json:"shard"`
+	// Weight is the share of the slots served by the shard relative to the others, 0 moves all
+	// its slots away
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight"`
+}
+
+// ShardPin is the slots pinned to a shard for the pinned slot distribution
+type ShardPin struct {
+	// Shard is the index of the shard, the one of its leader pod
+	// +kubebuilder:validation:Minimum=0
+	Shard int32 `json:"shard"`
+	// Slots are the slots served by the shard, single slots like 42 or ranges like 0-5460
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Pattern=`^[0-9]+(-[0-9]+)?$`
+	Slots []string `json:"slots"`
+}
+
+// ScalingPolicy sets how a change of the number of leaders or followers is carried out
+type ScalingPolicy struct {
+	// DryRun holds a change of the number of leaders or followers of a running cluster: the steps
+	// the scaling would take are recorded in status.pendingPlan and only run once the cluster is
+	// annotated with rediscluster.opstreelabs.in/apply-plan set to the generation of the plan
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// NodeReadyCondition is the readiness gate of the pods set by the operator once their node is
+// serving the cluster
+const NodeReadyCondition corev1.PodConditionType = "rediscluster.opstreelabs.in/node-ready"
+
+// DefaultOrphanedNodesGracePeriod is how long an orphaned node has not answered before it is
+// forgotten when no grace period is set
+const DefaultOrphanedNodesGracePeriod = 5 * time.Minute
+
+// OrphanedNodes sets how the nodes no pod of the cluster runs anymore are forgotten. A node is
+// orphaned when its ID is not the one of any pod, it is marked fail or noaddr and it serves no
+// slots.
+type OrphanedNodes struct {
+	// Forget issues CLUSTER FORGET for the orphaned nodes on every node of the cluster
+	// +kubebuilder:default:=true
+	// +optional
+	Forget *bool `json:"forget,omitempty"`
+	// GracePeriod is how long an orphaned node has not answered before it is forgotten, 5m by
+	// default
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// ExternalAccess sets the services the pods are exposed through
+type ExternalAccess struct {
+	// Type is the type of the services of the pods. A node behind a LoadBalancer service announces
+	// the address of its load balancer, the pods wait for it to be assigned. A node behind a
+	// NodePort service announces the IP of its Kubernetes node.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +kubebuilder:default:=LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations are added to the services of the pods, like the ones configuring the load
+	// balancers
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // Node-conf needs to be added only in redis cluster
@@ -71,12 +220,112 @@ func (cr *RedisClusterSpec) GetReplicaCounts(t string) int32 {
 	replica := cr.ClusterSize
 	if t == "leader" && cr.RedisLeader.Replicas != nil {
 		replica = cr.RedisLeader.Replicas
+	} else if t == "follower" && cr.ReplicasPerShard != nil {
+		return cr.GetReplicaCounts("leader") * *cr.ReplicasPerShard
 	} else if t == "follower" && cr.RedisFollower.Replicas != nil {
 		replica = cr.RedisFollower.Replicas
 	}
 	return *replica
 }
 
+// GetSlotDistributionStrategy returns the strategy the slots are spread with, even when unset
+func (cr *RedisClusterSpec) GetSlotDistributionStrategy() SlotDistributionStrategy {
+	if cr.SlotDistribution == nil || cr.SlotDistribution.Strategy == "" {
+		return SlotDistributionEven
+	}
+	return cr.SlotDistribution.Strategy
+}
+
+// ScalingDryRun reports whether a scaling is planned and held until it is confirmed
+func (cr *RedisClusterSpec) ScalingDryRun() bool {
+	return cr.ScalingPolicy != nil && cr.ScalingPolicy.DryRun
+}
+
+// GetExternalServiceType returns the type of the services of the pods whose address the nodes
+// announce, empty when the pods have none. The NodePort service type of the kubernetesConfig
+// exposes the pods as well.
+func (cr *RedisClusterSpec) GetExternalServiceType() corev1.ServiceType {
+	if cr.ExternalAccess != nil {
+		if cr.ExternalAccess.Type == "" {
+			return corev1.ServiceTypeLoadBalancer
+		}
+		return cr.ExternalAccess.Type
+	}
+	if cr.KubernetesConfig.GetServiceType() == "NodePort" {
+		return corev1.ServiceTypeNodePort
+	}
+	return ""
+}
+
+// GetOrphanedNodesGracePeriod returns how long an orphaned node has not answered before it is
+// forgotten, false when the orphaned nodes are kept
+func (cr *RedisClusterSpec) GetOrphanedNodesGracePeriod() (time.Duration, bool) {
+	if cr.OrphanedNodes == nil {
+		return DefaultOrphanedNodesGracePeriod, true
+	}
+	if cr.OrphanedNodes.Forget != nil && !*cr.OrphanedNodes.Forget {
+		return 0, false
+	}
+	if cr.OrphanedNodes.GracePeriod == nil {
+		return DefaultOrphanedNodesGracePeriod, true
+	}
+	return cr.OrphanedNodes.GracePeriod.Duration, true
+}
+
+// GetShardWeight returns the weight of the shard for the weighted slot distribution, 1 when it
+// is not declared
+func (cr *RedisClusterSpec) GetShardWeight(shard int32) int32 {
+	if cr.SlotDistribution != nil {
+		for _, weight := range cr.SlotDistribution.Weights {
+			if weight.Shard == shard {
+				return weight.Weight
+			}
+		}
+	}
+	return 1
+}
+
+// GetPinnedSlots returns the shard every slot of the pins is pinned to, by slot. Slots that
+// cannot be parsed are left out, the webhook rejects them.
+func (cr *RedisClusterSpec) GetPinnedSlots() map[int]int32 {
+	if cr.SlotDistribution == nil || len(cr.SlotDistribution.Pins) == 0 {
+		return nil
+	}
+	pinned := map[int]int32{}
+	for _, pin := range cr.SlotDistribution.Pins {
+		for _, slots := range pin.Slots {
+			start, end, err := ParseSlotRange(slots)
+			if err != nil {
+				continue
+			}
+			for slot := start; slot <= end; slot++ {
+				pinned[slot] = pin.Shard
+			}
+		}
+	}
+	return pinned
+}
+
+// ParseSlotRange returns the first and the last slot of a single slot like 42 or a range like
+// 0-5460
+func ParseSlotRange(slots string) (int, int, error) {
+	first, last, isRange := strings.Cut(slots, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid slot %q", first)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil {
+			return 0, 0, fmt.Errorf("invalid slot %q", last)
+		}
+	}
+	if start < 0 || end >= 16384 || start > end {
+		return 0, 0, fmt.Errorf("invalid slot range %s, the slots go from 0 to 16383", slots)
+	}
+	return start, end, nil
+}
+
 // GetRedisLeaderResources returns the resources for the redis leader, if not set, it will return the default resources
 func (cr *RedisClusterSpec) GetRedisLeaderResources() *corev1.ResourceRequirements {
 	if cr.RedisLeader.Resources != nil {
@@ -106,6 +355,26 @@ func (cr *RedisClusterSpec) GetRedisFollowerResources() *corev1.ResourceRequirem
 	return cr.KubernetesConfig.Resources
 }
 
+// GetMajorVersion returns the major version the pods are configured for, from the engine when
+// its version is known and from the cluster version otherwise
+func (cr *RedisClusterSpec) GetMajorVersion() *string {
+	if version := cr.Engine.MajorVersion(cr.EngineVersion); version != nil {
+		return version
+	}
+	return cr.ClusterVersion
+}
+
+// AnnouncesHostnames reports whether the nodes announce their hostname and support
+// CLUSTER ADDSLOTSRANGE, both added in Redis 7
+func (cr *RedisClusterSpec) AnnouncesHostnames() bool {
+	version := cr.GetMajorVersion()
+	if version == nil {
+		return false
+	}
+	major, err := strconv.Atoi(strings.TrimPrefix(*version, "v"))
+	return err == nil && major >= 7
+}
+
 // RedisLeader interface will have the redis leader configuration
 type RedisLeader struct {
 	common.RedisLeader            `json:",inline"`
@@ -131,8 +400,152 @@ type RedisClusterStatus struct {
 	ReadyLeaderReplicas int32 `json:"readyLeaderReplicas,omitempty"`
 	// +kubebuilder:default=0
 	ReadyFollowerReplicas int32 `json:"readyFollowerReplicas,omitempty"`
+	// PasswordRotation is the progress of the last change of the password secret
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
+	// TLSRotation is the progress of the rollout of the last change of the TLS secret
+	// +optional
+	TLSRotation *common.TLSRotationStatus `json:"tlsRotation,omitempty"`
+	// ObservedGeneration is the generation of the spec the conditions were last computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Progressing, Degraded, ScalingInProgress and FailoverInProgress
+	// conditions
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Nodes is the topology of the cluster as reported by CLUSTER NODES
+	// +optional
+	Nodes []RedisClusterNode `json:"nodes,omitempty"`
+	// SlotsAssigned is the number of hash slots served by the masters of the cluster
+	// +optional
+	SlotsAssigned int32 `json:"slotsAssigned,omitempty"`
+	// Resharding is the progress of the slots moved off the shards removed by the last scale down
+	// +optional
+	Resharding *ReshardingStatus `json:"resharding,omitempty"`
+	// PendingPlan is the scaling held by the dry run of the scaling policy until it is confirmed
+	// +optional
+	PendingPlan *ScalingPlan `json:"pendingPlan,omitempty"`
+	// Durability is the durability applied to the pods
+	// +optional
+	Durability *common.DurabilityStatus `json:"durability,omitempty"`
 }
 
+// ScalingPlan is the steps a change of the number of leaders or followers takes, computed from
+// the topology of the cluster when the change was held
+type ScalingPlan struct {
+	// Generation is the generation of the spec the plan was computed for, the value of the
+	// rediscluster.opstreelabs.in/apply-plan annotation confirming it
+	Generation int64 `json:"generation"`
+	// CurrentLeaders is the number of leaders the plan starts from
+	CurrentLeaders int32 `json:"currentLeaders"`
+	// DesiredLeaders is the number of leaders the plan ends with
+	DesiredLeaders int32 `json:"desiredLeaders"`
+	// CurrentFollowers is the number of followers the plan starts from
+	CurrentFollowers int32 `json:"currentFollowers"`
+	// DesiredFollowers is the number of followers the plan ends with
+	DesiredFollowers int32 `json:"desiredFollowers"`
+	// SlotsToMove is the number of hash slots the steps move, the ones of a rebalance estimated
+	// +optional
+	SlotsToMove int32 `json:"slotsToMove,omitempty"`
+	// Steps are the steps of the scaling, in the order they are taken
+	// +optional
+	Steps []ScalingPlanStep `json:"steps,omitempty"`
+	// ComputedTime is when the plan was computed
+	// +optional
+	ComputedTime *metav1.Time `json:"computedTime,omitempty"`
+}
+
+// ScalingPlanAction is an action taken by a step of a scaling
+// +kubebuilder:validation:Enum=Failover;RemoveNode;MoveSlots;Rebalance;DeletePod;CreatePod;AddNode
+type ScalingPlanAction string
+
+const (
+	// ScalingPlanFailover makes the leader pod the master of its shard with CLUSTER FAILOVER
+	ScalingPlanFailover ScalingPlanAction = "Failover"
+	// ScalingPlanRemoveNode removes the node of the pod from the cluster
+	ScalingPlanRemoveNode ScalingPlanAction = "RemoveNode"
+	// ScalingPlanMoveSlots moves the slots of the pod to the target pod
+	ScalingPlanMoveSlots ScalingPlanAction = "MoveSlots"
+	// ScalingPlanRebalance spreads the slots over the masters according to the slot distribution
+	ScalingPlanRebalance ScalingPlanAction = "Rebalance"
+	// ScalingPlanDeletePod deletes the pod by scaling its statefulset down
+	ScalingPlanDeletePod ScalingPlanAction = "DeletePod"
+	// ScalingPlanCreatePod creates the pod by scaling its statefulset up
+	ScalingPlanCreatePod ScalingPlanAction = "CreatePod"
+	// ScalingPlanAddNode adds the node of the pod to the cluster
+	ScalingPlanAddNode ScalingPlanAction = "AddNode"
+)
+
+// ScalingPlanStep is a step of a scaling
+type ScalingPlanStep struct {
+	// Action is what the step does
+	Action ScalingPlanAction `json:"action"`
+	// Pod is the pod the step acts on, the one the slots are moved off for MoveSlots
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// TargetPod is the pod the slots are moved to
+	// +optional
+	TargetPod string `json:"targetPod,omitempty"`
+	// Slots is the number of slots the step moves, an estimate for a rebalance
+	// +optional
+	Slots int32 `json:"slots,omitempty"`
+}
+
+// ReshardingStatus is the progress of the move of the slots of a shard removed by a scale down.
+// A reconcile interrupted while it is in progress resumes from it.
+type ReshardingStatus struct {
+	// SourcePod is the leader of the shard the slots are moved off
+	SourcePod string `json:"sourcePod"`
+	// TargetPod is the leader the slots are moved to
+	TargetPod string `json:"targetPod"`
+	// SlotsTotal is the number of slots the source served when the move started
+	SlotsTotal int32 `json:"slotsTotal"`
+	// SlotsMoved is the number of slots moved to the target
+	SlotsMoved int32 `json:"slotsMoved"`
+	// CurrentSlot is the slot whose keys were being migrated when the move stopped
+	// +optional
+	CurrentSlot *int32 `json:"currentSlot,omitempty"`
+	// StartTime is when the move of the slots of the source started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the slots of the last shard removed by the scale down were moved
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RedisClusterNode is a node of the cluster as reported by CLUSTER NODES
+type RedisClusterNode struct {
+	// PodName is the pod running the node, empty when the node does not announce its hostname
+	// +optional
+	PodName string `json:"podName,omitempty"`
+	// NodeID is the cluster node ID
+	NodeID string `json:"nodeID"`
+	// Role is master or replica
+	Role string `json:"role"`
+	// MasterID is the node ID of the master of a replica
+	// +optional
+	MasterID string `json:"masterID,omitempty"`
+	// Slots are the hash slot ranges served by a master, like 0-5460
+	// +optional
+	Slots []string `json:"slots,omitempty"`
+	// LinkState is the state of the cluster bus link to the node, connected or disconnected
+	// +optional
+	LinkState string `json:"linkState,omitempty"`
+	// FailureFlags are the fail, fail?, handshake and noaddr flags set on the node
+	// +optional
+	FailureFlags []string `json:"failureFlags,omitempty"`
+}
+
+// Roles of a RedisClusterNode
+const (
+	RedisClusterNodeMaster  string = "master"
+	RedisClusterNodeReplica string = "replica"
+)
+
 type RedisClusterState string
 
 const (
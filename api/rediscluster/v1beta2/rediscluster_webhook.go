@@ -17,6 +17,9 @@ limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -84,6 +87,81 @@ func (r *RedisCluster) validate(_ *RedisCluster) (admission.Warnings, error) {
 		))
 	}
 
+	if r.Spec.ReplicasPerShard != nil && r.Spec.RedisFollower.Replicas != nil {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("redisFollower", "replicas"),
+			"the number of followers is set by spec.replicasPerShard",
+		))
+	}
+
+	if distribution := r.Spec.SlotDistribution; distribution != nil && len(distribution.Weights) > 0 {
+		weightsPath := field.NewPath("spec").Child("slotDistribution", "weights")
+		if r.Spec.GetSlotDistributionStrategy() != SlotDistributionWeighted {
+			errors = append(errors, field.Forbidden(weightsPath, "weights are only used by the weighted strategy"))
+		} else {
+			var total int32
+			for shard := int32(0); shard < r.Spec.GetReplicaCounts("leader"); shard++ {
+				total += r.Spec.GetShardWeight(shard)
+			}
+			if total == 0 {
+				errors = append(errors, field.Invalid(weightsPath, distribution.Weights, "at least one shard must have a positive weight"))
+			}
+		}
+	}
+
+	if distribution := r.Spec.SlotDistribution; distribution != nil && len(distribution.Pins) > 0 {
+		pinsPath := field.NewPath("spec").Child("slotDistribution", "pins")
+		if r.Spec.GetSlotDistributionStrategy() != SlotDistributionPinned {
+			errors = append(errors, field.Forbidden(pinsPath, "pins are only used by the pinned strategy"))
+		} else {
+			pinned := map[int]int32{}
+			for i, pin := range distribution.Pins {
+				for j, slots := range pin.Slots {
+					slotsPath := pinsPath.Index(i).Child("slots").Index(j)
+					start, end, err := ParseSlotRange(slots)
+					if err != nil {
+						errors = append(errors, field.Invalid(slotsPath, slots, err.Error()))
+						continue
+					}
+					for slot := start; slot <= end; slot++ {
+						if shard, found := pinned[slot]; found && shard != pin.Shard {
+							errors = append(errors, field.Invalid(slotsPath, slots, fmt.Sprintf("slot %d is pinned to the shards %d and %d", slot, shard, pin.Shard)))
+							break
+						}
+						pinned[slot] = pin.Shard
+					}
+				}
+			}
+		}
+	}
+
+	// with OrderedReady the next pod waits for a gate set once the whole cluster is up
+	if r.Spec.ReadinessGate && (r.Spec.PodManagementPolicy == nil || *r.Spec.PodManagementPolicy != string(appsv1.ParallelPodManagement)) {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("readinessGate"),
+			"the readiness gate requires spec.podManagementPolicy Parallel",
+		))
+	}
+
+	// the pods of the NodePort service type announce the ports of their own NodePort services
+	if r.Spec.ExternalAccess != nil && r.Spec.KubernetesConfig.GetServiceType() == "NodePort" {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("externalAccess"),
+			"the external access cannot be combined with the NodePort service type",
+		))
+	}
+
+	if r.Spec.Durability != nil {
+		replicasPerShard := r.Spec.GetReplicaCounts("follower") / r.Spec.GetReplicaCounts("leader")
+		if err := r.Spec.Durability.Validate(replicasPerShard, r.Spec.GetRedisDynamicConfig()); err != nil {
+			errors = append(errors, field.Invalid(
+				field.NewPath("spec").Child("durability"),
+				r.Spec.Durability,
+				err.Error(),
+			))
+		}
+	}
+
 	// Validate ACL configuration
 	if r.Spec.ACL != nil {
 		if err := r.Spec.ACL.Validate(); err != nil {
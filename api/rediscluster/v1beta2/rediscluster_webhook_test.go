@@ -52,6 +52,172 @@ func TestRedisClusterWebhook(t *testing.T) {
 			},
 			Check: webhook.ValidationWebhookFailed("only one of 'secret' or 'persistentVolumeClaim' can be specified"),
 		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-replicasPerShard-and-follower-replicas",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ReplicasPerShard = ptr.To(int32(2))
+				cluster.Spec.RedisFollower.Replicas = ptr.To(int32(6))
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("the number of followers is set by spec.replicasPerShard"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-zero-slot-weights",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionWeighted,
+					Weights:  []v1beta2.ShardWeight{{Shard: 0, Weight: 0}, {Shard: 1, Weight: 0}, {Shard: 2, Weight: 0}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("at least one shard must have a positive weight"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-weights-without-weighted-strategy",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionPinned,
+					Weights:  []v1beta2.ShardWeight{{Shard: 0, Weight: 2}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("weights are only used by the weighted strategy"),
+		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-pinned-slots",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionPinned,
+					Pins:     []v1beta2.ShardPin{{Shard: 0, Slots: []string{"0-99", "200"}}, {Shard: 2, Slots: []string{"100-199"}}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-pins-without-pinned-strategy",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionWeighted,
+					Pins:     []v1beta2.ShardPin{{Shard: 0, Slots: []string{"0-99"}}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("pins are only used by the pinned strategy"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-pinned-slot-out-of-range",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionPinned,
+					Pins:     []v1beta2.ShardPin{{Shard: 0, Slots: []string{"16000-16384"}}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("invalid slot range 16000-16384, the slots go from 0 to 16383"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-slot-pinned-twice",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotDistribution = &v1beta2.SlotDistribution{
+					Strategy: v1beta2.SlotDistributionPinned,
+					Pins:     []v1beta2.ShardPin{{Shard: 0, Slots: []string{"0-99"}}, {Shard: 1, Slots: []string{"50-149"}}},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("slot 50 is pinned to the shards 0 and 1"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-readiness-gate-ordered-ready",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ReadinessGate = true
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("the readiness gate requires spec.podManagementPolicy Parallel"),
+		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-readiness-gate-parallel",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ReadinessGate = true
+				cluster.Spec.PodManagementPolicy = ptr.To("Parallel")
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-external-access-nodeport-service",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.KubernetesConfig.Service = &common.ServiceConfig{ServiceType: "NodePort"}
+				cluster.Spec.ExternalAccess = &v1beta2.ExternalAccess{Type: corev1.ServiceTypeLoadBalancer}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("the external access cannot be combined with the NodePort service type"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-durability-exceeds-replicas-per-shard",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ReplicasPerShard = ptr.To(int32(1))
+				cluster.Spec.Durability = &common.Durability{MinReplicasToWrite: 2}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("minReplicasToWrite 2 exceeds the 1 replicas of a master"),
+		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-durability",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.Durability = &common.Durability{MinReplicasToWrite: 1}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
 	}
 
 	gvk := metav1.GroupVersionKind{
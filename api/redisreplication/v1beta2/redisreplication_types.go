@@ -1,6 +1,8 @@
 package v1beta2
 
 import (
+	"slices"
+
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +42,32 @@ type RedisReplicationSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy *string `json:"podManagementPolicy,omitempty"`
+	// PasswordRotation configures how a change of the password secret is rolled out
+	// +optional
+	PasswordRotation *common.PasswordRotation `json:"passwordRotation,omitempty"`
+	// PreferredMaster is the pod the master is switched over to whenever it runs as a ready
+	// replica, like <name>-0. The redisreplication.opstreelabs.in/switchover-to annotation requests
+	// a single switchover instead.
+	// +optional
+	PreferredMaster string `json:"preferredMaster,omitempty"`
+	// ReplicaPriority sets the replica-priority of the pods, the sentinels and the operator
+	// promote the replica with the lowest priority first and never promote one with priority 0
+	// +optional
+	ReplicaPriority []ReplicaPriorityRule `json:"replicaPriority,omitempty"`
+	// Durability keeps the master from accepting writes unless enough replicas are in sync. It is
+	// relaxed while a planned scale-down or rolling restart makes replicas unavailable.
+	// +optional
+	Durability *common.Durability `json:"durability,omitempty"`
+	// Engine is the server run by the pods, redis or valkey. Changing it from redis to valkey
+	// rolls the pods out one at a time with the Valkey binaries, Valkey loads the data files of
+	// Redis up to 7.2.
+	// +optional
+	Engine common.Engine `json:"engine,omitempty"`
+	// EngineVersion is the version of the engine shipped by the image, like 8.0, the pods are
+	// configured with the features of its major version
+	// +optional
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+(\.[0-9]+){0,2}$`
+	EngineVersion string `json:"engineVersion,omitempty"`
 }
 
 type Sentinel struct {
@@ -69,6 +97,37 @@ func (cr *RedisReplicationSpec) GetRedisDynamicConfig() []string {
 	return []string{}
 }
 
+// DefaultReplicaPriority is the replica-priority of redis, the priority of the pods no rule selects
+const DefaultReplicaPriority = 100
+
+// ReplicaPriorityRule sets the replica-priority of the pods it selects by ordinal, by the labels of
+// their node or by both. The first rule selecting a pod applies.
+type ReplicaPriorityRule struct {
+	// Ordinals are the ordinals of the pods, like 2 for <name>-2
+	// +optional
+	Ordinals []int32 `json:"ordinals,omitempty"`
+	// NodeSelector selects the pods running on nodes with all of these labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Priority is the replica-priority of the pods, 0 never promotes them
+	// +kubebuilder:validation:Minimum=0
+	Priority int32 `json:"priority"`
+}
+
+// Matches reports whether the rule selects the pod with the ordinal running on a node with the
+// labels
+func (in *ReplicaPriorityRule) Matches(ordinal int32, nodeLabels map[string]string) bool {
+	if len(in.Ordinals) > 0 && !slices.Contains(in.Ordinals, ordinal) {
+		return false
+	}
+	for key, value := range in.NodeSelector {
+		if label, ok := nodeLabels[key]; !ok || label != value {
+			return false
+		}
+	}
+	return true
+}
+
 // ConnectionInfo provides connection details for clients to connect to Redis
 type ConnectionInfo struct {
 	// Host is the service FQDN
@@ -86,12 +145,63 @@ type RedisReplicationStatus struct {
 	// ConnectionInfo provides connection details for clients to connect to Redis
 	// +optional
 	ConnectionInfo *ConnectionInfo `json:"connectionInfo,omitempty"`
+	// PasswordRotation is the progress of the last change of the password secret
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
+	// TLSRotation is the progress of the rollout of the last change of the TLS secret
+	// +optional
+	TLSRotation *common.TLSRotationStatus `json:"tlsRotation,omitempty"`
+	// ObservedGeneration is the generation of the spec the conditions were last computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Progressing, Degraded, ScalingInProgress and FailoverInProgress
+	// conditions
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// Switchover is the result of the last planned switchover of the master
+	// +optional
+	Switchover *SwitchoverStatus `json:"switchover,omitempty"`
+	// Durability is the durability applied to the pods
+	// +optional
+	Durability *common.DurabilityStatus `json:"durability,omitempty"`
+	// ReplicaPriorities is the replica-priority applied to every pod by spec.replicaPriority
+	// +optional
+	ReplicaPriorities map[string]int32 `json:"replicaPriorities,omitempty"`
+}
+
+// SwitchoverStatus is the result of a planned switchover of the master
+type SwitchoverStatus struct {
+	// From is the master the switchover started from
+	// +optional
+	From string `json:"from,omitempty"`
+	// To is the pod the master was switched over to
+	To    string          `json:"to"`
+	Phase SwitchoverPhase `json:"phase"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
+// SwitchoverPhase is the outcome of a planned switchover
+type SwitchoverPhase string
+
+const (
+	SwitchoverCompleted SwitchoverPhase = "Completed"
+	SwitchoverFailed    SwitchoverPhase = "Failed"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Master",type="string",JSONPath=".status.masterNode"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Redis is the Schema for the redis API
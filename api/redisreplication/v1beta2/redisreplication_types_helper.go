@@ -1,6 +1,10 @@
 package v1beta2
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 func (cr *RedisReplication) EnableSentinel() bool {
 	return cr != nil && cr.Spec.Sentinel != nil && cr.Spec.Sentinel.Size > 0
@@ -14,6 +18,40 @@ func (cr *RedisReplication) RedisStatefulSet() string {
 	return cr.Name
 }
 
+// IsRedisPod reports whether name is a pod of the redis statefulset of the replication
+func (cr *RedisReplication) IsRedisPod(name string) bool {
+	ordinal, ok := strings.CutPrefix(name, cr.RedisStatefulSet()+"-")
+	if !ok {
+		return false
+	}
+	i, err := strconv.Atoi(ordinal)
+	if err != nil || i < 0 || strconv.Itoa(i) != ordinal {
+		return false
+	}
+	return cr.Spec.Size == nil || int32(i) < *cr.Spec.Size
+}
+
+// ReplicaPriority returns the replica-priority of the pod with the ordinal running on a node with
+// the labels, set by the first rule selecting it
+func (cr *RedisReplication) ReplicaPriority(ordinal int32, nodeLabels map[string]string) int32 {
+	for _, rule := range cr.Spec.ReplicaPriority {
+		if rule.Matches(ordinal, nodeLabels) {
+			return rule.Priority
+		}
+	}
+	return DefaultReplicaPriority
+}
+
+// ReplicaPriorityNeedsNodeLabels reports whether a rule selects pods by the labels of their node
+func (cr *RedisReplication) ReplicaPriorityNeedsNodeLabels() bool {
+	for _, rule := range cr.Spec.ReplicaPriority {
+		if len(rule.NodeSelector) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (cr *RedisReplication) SentinelHLService() string {
 	return cr.Name + "-s-hl"
 }
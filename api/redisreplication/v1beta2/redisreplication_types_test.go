@@ -50,3 +50,17 @@ func TestRedisReplicationSpec_GetRedisDynamicConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisReplication_ReplicaPriority(t *testing.T) {
+	cr := &v1beta2.RedisReplication{Spec: v1beta2.RedisReplicationSpec{ReplicaPriority: []v1beta2.ReplicaPriorityRule{
+		{Ordinals: []int32{1}, NodeSelector: map[string]string{"zone": "dr"}, Priority: 0},
+		{NodeSelector: map[string]string{"zone": "dr"}, Priority: 50},
+		{Ordinals: []int32{0, 2}, Priority: 10},
+	}}}
+
+	assert.Equal(t, int32(0), cr.ReplicaPriority(1, map[string]string{"zone": "dr"}))
+	assert.Equal(t, int32(50), cr.ReplicaPriority(2, map[string]string{"zone": "dr"}))
+	assert.Equal(t, int32(10), cr.ReplicaPriority(2, map[string]string{"zone": "primary"}))
+	assert.Equal(t, int32(v1beta2.DefaultReplicaPriority), cr.ReplicaPriority(1, nil))
+	assert.True(t, cr.ReplicaPriorityNeedsNodeLabels())
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -80,6 +84,60 @@ func (r *RedisReplication) validate(_ *RedisReplication) (admission.Warnings, er
 		}
 	}
 
+	if r.Spec.PreferredMaster != "" && !r.IsRedisPod(r.Spec.PreferredMaster) {
+		errors = append(errors, field.Invalid(
+			field.NewPath("spec").Child("preferredMaster"),
+			r.Spec.PreferredMaster,
+			"must be a pod of the replication",
+		))
+	}
+
+	for i, rule := range r.Spec.ReplicaPriority {
+		rulePath := field.NewPath("spec").Child("replicaPriority").Index(i)
+		if len(rule.Ordinals) == 0 && len(rule.NodeSelector) == 0 {
+			errors = append(errors, field.Required(rulePath, "a rule selects pods by ordinals, nodeSelector or both"))
+		}
+		for j, ordinal := range rule.Ordinals {
+			if !r.IsRedisPod(r.RedisStatefulSet() + "-" + strconv.Itoa(int(ordinal))) {
+				errors = append(errors, field.Invalid(rulePath.Child("ordinals").Index(j), ordinal, "must be the ordinal of a pod of the replication"))
+			}
+		}
+	}
+	if r.Spec.PreferredMaster != "" && len(r.Spec.ReplicaPriority) > 0 && r.IsRedisPod(r.Spec.PreferredMaster) && !r.ReplicaPriorityNeedsNodeLabels() {
+		ordinal, _ := strconv.Atoi(strings.TrimPrefix(r.Spec.PreferredMaster, r.RedisStatefulSet()+"-"))
+		if r.ReplicaPriority(int32(ordinal), nil) == 0 {
+			errors = append(errors, field.Invalid(
+				field.NewPath("spec").Child("preferredMaster"),
+				r.Spec.PreferredMaster,
+				"must not be a pod whose replica priority is 0",
+			))
+		}
+	}
+	// the operator manages the replica-priority of the pods
+	if len(r.Spec.ReplicaPriority) > 0 {
+		for _, config := range r.Spec.GetRedisDynamicConfig() {
+			switch key := strings.ToLower(strings.Fields(config + " ")[0]); key {
+			case "replica-priority", "slave-priority":
+				errors = append(errors, field.Invalid(
+					field.NewPath("spec").Child("redisConfig", "dynamicConfig"),
+					config,
+					fmt.Sprintf("the dynamic config cannot set %s, it is managed through spec.replicaPriority", key),
+				))
+			}
+		}
+	}
+
+	// every pod but the master is a replica
+	if r.Spec.Durability != nil && r.Spec.Size != nil {
+		if err := r.Spec.Durability.Validate(*r.Spec.Size-1, r.Spec.GetRedisDynamicConfig()); err != nil {
+			errors = append(errors, field.Invalid(
+				field.NewPath("spec").Child("durability"),
+				r.Spec.Durability,
+				err.Error(),
+			))
+		}
+	}
+
 	if len(errors) == 0 {
 		return nil, nil
 	}
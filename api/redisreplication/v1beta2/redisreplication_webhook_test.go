@@ -29,6 +29,131 @@ func TestRedisReplicationWebhook(t *testing.T) {
 			},
 			Check: webhook.ValidationWebhookFailed("only one of 'secret' or 'persistentVolumeClaim' can be specified"),
 		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-preferred-master-outside-replication",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.PreferredMaster = replication.Name + "-3"
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("must be a pod of the replication"),
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-durability-exceeds-replicas",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(2))
+				replication.Spec.Durability = &common.Durability{MinReplicasToWrite: 2}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("minReplicasToWrite 2 exceeds the 1 replicas of a master"),
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-durability-in-dynamic-config",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.Durability = &common.Durability{MinReplicasToWrite: 1}
+				replication.Spec.RedisConfig = &common.RedisConfig{DynamicConfig: []string{"min-replicas-to-write 2"}}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("the dynamic config cannot set min-replicas-to-write, it is managed through the durability"),
+		},
+		{
+			Name:      "success-create-v1beta2-redisreplication-durability",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.Durability = &common.Durability{MinReplicasToWrite: 2}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-replica-priority-ordinal-outside-replication",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.ReplicaPriority = []v1beta2.ReplicaPriorityRule{{Ordinals: []int32{3}, Priority: 0}}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("must be the ordinal of a pod of the replication"),
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-replica-priority-rule-without-selector",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.ReplicaPriority = []v1beta2.ReplicaPriorityRule{{Priority: 10}}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("a rule selects pods by ordinals, nodeSelector or both"),
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-preferred-master-never-promoted",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.PreferredMaster = replication.Name + "-2"
+				replication.Spec.ReplicaPriority = []v1beta2.ReplicaPriorityRule{{Ordinals: []int32{2}, Priority: 0}}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("must not be a pod whose replica priority is 0"),
+		},
+		{
+			Name:      "failed-create-v1beta2-redisreplication-replica-priority-in-dynamic-config",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.ReplicaPriority = []v1beta2.ReplicaPriorityRule{{Ordinals: []int32{2}, Priority: 0}}
+				replication.Spec.RedisConfig = &common.RedisConfig{DynamicConfig: []string{"replica-priority 50"}}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookFailed("the dynamic config cannot set replica-priority, it is managed through spec.replicaPriority"),
+		},
+		{
+			Name:      "success-create-v1beta2-redisreplication-replica-priority",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.Size = ptr.To(int32(3))
+				replication.Spec.ReplicaPriority = []v1beta2.ReplicaPriorityRule{
+					{NodeSelector: map[string]string{"topology.kubernetes.io/zone": "dr"}, Priority: 0},
+					{Ordinals: []int32{0}, Priority: 10},
+				}
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "success-create-v1beta2-redisreplication-managed-failover-update-strategy",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				replication := mkRedisReplication(uid)
+				replication.Spec.KubernetesConfig.UpdateStrategy.Type = common.ManagedFailoverUpdateStrategyType
+				return marshal(t, replication)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
 	}
 
 	gvk := metav1.GroupVersionKind{
@@ -40,6 +40,15 @@ type RedisSentinelSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy *string `json:"podManagementPolicy,omitempty"`
+	// Engine is the sentinel run by the pods, redis or valkey, like the engine of the monitored
+	// replication. Changing it rolls the pods out one at a time.
+	// +optional
+	Engine common.Engine `json:"engine,omitempty"`
+	// EngineVersion is the version of the engine shipped by the image, like 8.0, the pods are
+	// configured with the features of its major version
+	// +optional
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+(\.[0-9]+){0,2}$`
+	EngineVersion string `json:"engineVersion,omitempty"`
 }
 
 func (cr *RedisSentinelSpec) GetSentinelCounts(t string) int32 {
@@ -49,13 +58,129 @@ func (cr *RedisSentinelSpec) GetSentinelCounts(t string) int32 {
 
 type RedisSentinelConfig struct {
 	common.RedisSentinelConfig `json:",inline"`
+	// Masters are further replications monitored by the sentinels, each one as a master group of
+	// its own, so that one sentinel tier serves several replications
+	// +optional
+	// +listType=map
+	// +listMapKey=masterGroupName
+	Masters []SentinelMaster `json:"masters,omitempty"`
+}
+
+// SentinelMaster is a replication monitored by the sentinels. The quorum and the timings left
+// empty are the ones of the redisSentinelConfig.
+type SentinelMaster struct {
+	// MasterGroupName is the name the sentinels know the master of the replication by
+	MasterGroupName string `json:"masterGroupName"`
+	// RedisReplicationName is the name of the monitored RedisReplication
+	RedisReplicationName string `json:"redisReplicationName"`
+	// RedisReplicationPassword is the password of the monitored replication
+	// +optional
+	RedisReplicationPassword *corev1.EnvVarSource `json:"redisReplicationPassword,omitempty"`
+	// +kubebuilder:default:="6379"
+	// +optional
+	RedisPort string `json:"redisPort,omitempty"`
+	// +optional
+	Quorum string `json:"quorum,omitempty"`
+	// +optional
+	ParallelSyncs string `json:"parallelSyncs,omitempty"`
+	// +optional
+	FailoverTimeout string `json:"failoverTimeout,omitempty"`
+	// +optional
+	DownAfterMilliseconds string `json:"downAfterMilliseconds,omitempty"`
 }
 
-type RedisSentinelStatus struct{}
+// GetMasters returns every master group monitored by the sentinels, the one of the
+// redisSentinelConfig first, with the quorum and the timings they inherit
+func (cr *RedisSentinelSpec) GetMasters() []SentinelMaster {
+	config := cr.RedisSentinelConfig
+	if config == nil {
+		return nil
+	}
+	masters := []SentinelMaster{{
+		MasterGroupName:          config.MasterGroupName,
+		RedisReplicationName:     config.RedisReplicationName,
+		RedisReplicationPassword: config.RedisReplicationPassword,
+		RedisPort:                config.RedisPort,
+		Quorum:                   config.Quorum,
+		ParallelSyncs:            config.ParallelSyncs,
+		FailoverTimeout:          config.FailoverTimeout,
+		DownAfterMilliseconds:    config.DownAfterMilliseconds,
+	}}
+	for _, master := range config.Masters {
+		if master.RedisPort == "" {
+			master.RedisPort = "6379"
+		}
+		if master.Quorum == "" {
+			master.Quorum = config.Quorum
+		}
+		if master.ParallelSyncs == "" {
+			master.ParallelSyncs = config.ParallelSyncs
+		}
+		if master.FailoverTimeout == "" {
+			master.FailoverTimeout = config.FailoverTimeout
+		}
+		if master.DownAfterMilliseconds == "" {
+			master.DownAfterMilliseconds = config.DownAfterMilliseconds
+		}
+		masters = append(masters, master)
+	}
+	return masters
+}
+
+// RedisSentinelStatus defines the observed state of RedisSentinel
+type RedisSentinelStatus struct {
+	// TLSRotation is the progress of the rollout of the last change of the TLS secret
+	// +optional
+	TLSRotation *common.TLSRotationStatus `json:"tlsRotation,omitempty"`
+	// ObservedGeneration is the generation of the spec the conditions were last computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Masters are the master groups monitored by the sentinels
+	// +optional
+	Masters []SentinelMasterStatus `json:"masters,omitempty"`
+	// Conditions are the Ready, Progressing, Degraded, ScalingInProgress and FailoverInProgress
+	// conditions, and the QuorumReachable condition of the monitored masters
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// SentinelMasterStatus is a master group as seen by the sentinels
+type SentinelMasterStatus struct {
+	// Name is the name of the master group
+	Name string `json:"name"`
+	// Address is the address of the master most sentinels agree on
+	// +optional
+	Address string `json:"address,omitempty"`
+	// Status is the status of the master reported by INFO sentinel, like ok or odown
+	// +optional
+	Status string `json:"status,omitempty"`
+	// Sentinels is the number of sentinels agreeing on the address of the master
+	// +optional
+	Sentinels int32 `json:"sentinels,omitempty"`
+	// KnownSentinels is the number of sentinels known to those agreeing, themselves included
+	// +optional
+	KnownSentinels int32 `json:"knownSentinels,omitempty"`
+	// Replicas is the number of replicas of the master known to the sentinels
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// QuorumReachable is whether the sentinels can authorize a failover of the master
+	// +optional
+	QuorumReachable bool `json:"quorumReachable,omitempty"`
+	// CKQuorum is the reply of SENTINEL CKQUORUM
+	// +optional
+	CKQuorum string `json:"ckquorum,omitempty"`
+}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 //+kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Quorum",type="string",JSONPath=".status.conditions[?(@.type=='QuorumReachable')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Redis is the Schema for the redis API
 type RedisSentinel struct {
@@ -0,0 +1,52 @@
+package v1beta2_test
+
+import (
+	"testing"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	v1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisSentinelSpec_GetMasters(t *testing.T) {
+	assert.Nil(t, (&v1beta2.RedisSentinelSpec{}).GetMasters())
+
+	spec := v1beta2.RedisSentinelSpec{
+		RedisSentinelConfig: &v1beta2.RedisSentinelConfig{
+			RedisSentinelConfig: common.RedisSentinelConfig{
+				SentinelConfig: common.SentinelConfig{
+					Quorum:                "2",
+					ParallelSyncs:         "1",
+					FailoverTimeout:       "10000",
+					DownAfterMilliseconds: "5000",
+				},
+				RedisPort:            "6379",
+				MasterGroupName:      "myMaster",
+				RedisReplicationName: "redis-replication",
+			},
+			Masters: []v1beta2.SentinelMaster{
+				{MasterGroupName: "orders", RedisReplicationName: "orders", Quorum: "3"},
+			},
+		},
+	}
+	assert.Equal(t, []v1beta2.SentinelMaster{
+		{
+			MasterGroupName:       "myMaster",
+			RedisReplicationName:  "redis-replication",
+			RedisPort:             "6379",
+			Quorum:                "2",
+			ParallelSyncs:         "1",
+			FailoverTimeout:       "10000",
+			DownAfterMilliseconds: "5000",
+		},
+		{
+			MasterGroupName:       "orders",
+			RedisReplicationName:  "orders",
+			RedisPort:             "6379",
+			Quorum:                "3",
+			ParallelSyncs:         "1",
+			FailoverTimeout:       "10000",
+			DownAfterMilliseconds: "5000",
+		},
+	}, spec.GetMasters())
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta2
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -84,6 +85,32 @@ func (r *RedisSentinel) validate(_ *RedisSentinel) (admission.Warnings, error) {
 		))
 	}
 
+	if r.Spec.KubernetesConfig.UsesManagedFailover() {
+		errors = append(errors, field.NotSupported(
+			field.NewPath("spec").Child("kubernetesConfig", "updateStrategy", "type"),
+			r.Spec.KubernetesConfig.UpdateStrategy.Type,
+			[]string{string(appsv1.RollingUpdateStatefulSetStrategyType), string(appsv1.OnDeleteStatefulSetStrategyType)},
+		))
+	}
+
+	// a master group or a replication monitored twice would be failed over twice
+	groups := map[string]bool{}
+	replications := map[string]bool{}
+	for i, master := range r.Spec.GetMasters() {
+		path := field.NewPath("spec").Child("redisSentinelConfig", "masters").Index(i - 1)
+		if i == 0 {
+			path = field.NewPath("spec").Child("redisSentinelConfig")
+		}
+		if groups[master.MasterGroupName] {
+			errors = append(errors, field.Duplicate(path.Child("masterGroupName"), master.MasterGroupName))
+		}
+		if replications[master.RedisReplicationName] {
+			errors = append(errors, field.Duplicate(path.Child("redisReplicationName"), master.RedisReplicationName))
+		}
+		groups[master.MasterGroupName] = true
+		replications[master.RedisReplicationName] = true
+	}
+
 	if len(errors) == 0 {
 		return nil, nil
 	}
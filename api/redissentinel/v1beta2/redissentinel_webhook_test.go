@@ -39,6 +39,45 @@ func TestRedisSentinelWebhook(t *testing.T) {
 			},
 			Check: webhook.ValidationWebhookFailed("Redis Sentinel cluster size must be an odd number for proper leader election"),
 		},
+		{
+			Name:      "success-create-v1beta2-redissentinel-validate-masters",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				sentinel := mkRedisSentinelMonitoring(uid)
+				sentinel.Spec.RedisSentinelConfig.Masters = []v1beta2.SentinelMaster{
+					{MasterGroupName: "orders", RedisReplicationName: "orders"},
+				}
+				return marshal(t, sentinel)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-redissentinel-validate-masters-duplicate-group",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				sentinel := mkRedisSentinelMonitoring(uid)
+				sentinel.Spec.RedisSentinelConfig.Masters = []v1beta2.SentinelMaster{
+					{MasterGroupName: "myMaster", RedisReplicationName: "orders"},
+				}
+				return marshal(t, sentinel)
+			},
+			Check: webhook.ValidationWebhookFailed(`Duplicate value: "myMaster"`),
+		},
+		{
+			Name:      "failed-create-v1beta2-redissentinel-validate-masters-duplicate-replication",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				sentinel := mkRedisSentinelMonitoring(uid)
+				sentinel.Spec.RedisSentinelConfig.Masters = []v1beta2.SentinelMaster{
+					{MasterGroupName: "orders", RedisReplicationName: "redis-replication"},
+				}
+				return marshal(t, sentinel)
+			},
+			Check: webhook.ValidationWebhookFailed(`Duplicate value: "redis-replication"`),
+		},
 	}
 
 	gvk := metav1.GroupVersionKind{
@@ -61,6 +100,15 @@ func mkRedisSentinel(uid string) *v1beta2.RedisSentinel {
 	}
 }
 
+func mkRedisSentinelMonitoring(uid string) *v1beta2.RedisSentinel {
+	sentinel := mkRedisSentinel(uid)
+	sentinel.Spec.Size = ptr.To(int32(3))
+	sentinel.Spec.RedisSentinelConfig = &v1beta2.RedisSentinelConfig{}
+	sentinel.Spec.RedisSentinelConfig.MasterGroupName = "myMaster"
+	sentinel.Spec.RedisSentinelConfig.RedisReplicationName = "redis-replication"
+	return sentinel
+}
+
 func marshal(t *testing.T, obj interface{}) []byte {
 	t.Helper()
 	bytes, err := json.Marshal(obj)
@@ -23,6 +23,7 @@ package v1beta2
 import (
 	commonv1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -32,7 +33,7 @@ func (in *RedisSentinel) DeepCopyInto(out *RedisSentinel) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinel.
@@ -57,6 +58,13 @@ func (in *RedisSentinel) DeepCopyObject() runtime.Object {
 func (in *RedisSentinelConfig) DeepCopyInto(out *RedisSentinelConfig) {
 	*out = *in
 	in.RedisSentinelConfig.DeepCopyInto(&out.RedisSentinelConfig)
+	if in.Masters != nil {
+		in, out := &in.Masters, &out.Masters
+		*out = make([]SentinelMaster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelConfig.
@@ -247,6 +255,23 @@ func (in *RedisSentinelSpec) DeepCopy() *RedisSentinelSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisSentinelStatus) DeepCopyInto(out *RedisSentinelStatus) {
 	*out = *in
+	if in.TLSRotation != nil {
+		in, out := &in.TLSRotation, &out.TLSRotation
+		*out = new(commonv1beta2.TLSRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Masters != nil {
+		in, out := &in.Masters, &out.Masters
+		*out = make([]SentinelMasterStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelStatus.
@@ -258,3 +283,38 @@ func (in *RedisSentinelStatus) DeepCopy() *RedisSentinelStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SentinelMaster) DeepCopyInto(out *SentinelMaster) {
+	*out = *in
+	if in.RedisReplicationPassword != nil {
+		in, out := &in.RedisReplicationPassword, &out.RedisReplicationPassword
+		*out = new(v1.EnvVarSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SentinelMaster.
+func (in *SentinelMaster) DeepCopy() *SentinelMaster {
+	if in == nil {
+		return nil
+	}
+	out := new(SentinelMaster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SentinelMasterStatus) DeepCopyInto(out *SentinelMasterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SentinelMasterStatus.
+func (in *SentinelMasterStatus) DeepCopy() *SentinelMasterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SentinelMasterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisUserSpec defines the desired state of RedisUser
+type RedisUserSpec struct {
+	// Target is the Redis deployment, in the same namespace, the user is created on
+	Target UserTarget `json:"target"`
+	// Username is the name of the ACL user, it defaults to the name of the RedisUser.
+	// The default user is used by the operator itself and cannot be managed.
+	// +kubebuilder:validation:Pattern=`^[^\s]+$`
+	// +optional
+	Username string `json:"username,omitempty"`
+	// PasswordSecret references the key of a Secret, in the same namespace, holding the password of the user
+	PasswordSecret common.ExistingPasswordSecret `json:"passwordSecret"`
+	// Enabled switches the user on or off, a disabled user keeps its rules but cannot authenticate
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Rules are the permissions of the user, a user without rules can authenticate but run no command
+	// +optional
+	Rules ACLRules `json:"rules,omitempty"`
+}
+
+// UserTarget references the Redis deployment a user is managed on
+type UserTarget struct {
+	// +kubebuilder:validation:Enum=Redis;RedisCluster;RedisReplication
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+const (
+	UserTargetRedisCluster     = "RedisCluster"
+	UserTargetRedisReplication = "RedisReplication"
+	UserTargetRedis            = "Redis"
+)
+
+// ACLRules are the permissions granted to a user, see https://redis.io/docs/latest/operate/oss_and_stack/management/security/acl/
+type ACLRules struct {
+	// Commands are command rules applied in order, e.g. "+@read", "+set" or "-flushall"
+	// +optional
+	Commands []string `json:"commands,omitempty"`
+	// Keys are glob-style patterns of the keys the user can access, e.g. "app:*". Patterns
+	// starting with "%R~", "%W~" or "%RW~" are passed as is to grant read or write access only.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+	// Channels are glob-style patterns of the pub/sub channels the user can access
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+}
+
+// RedisUserStatus defines the observed state of RedisUser
+type RedisUserStatus struct {
+	State  RedisUserState `json:"state,omitempty"`
+	Reason string         `json:"reason,omitempty"`
+	// Username is the ACL user last applied, it is removed from the nodes when the username changes
+	Username string `json:"username,omitempty"`
+	// SyncedNodes is the number of nodes the user is applied on out of all nodes of the target
+	SyncedNodes string `json:"syncedNodes,omitempty"`
+	// Nodes is the sync state of the user on every node of the target
+	Nodes []RedisUserNodeStatus `json:"nodes,omitempty"`
+}
+
+// RedisUserNodeStatus is the sync state of a user on a single redis node
+type RedisUserNodeStatus struct {
+	PodName string `json:"podName"`
+	// Synced reports whether the user is applied on the running node
+	Synced bool `json:"synced"`
+	// Persisted reports whether the user was saved to the ACL file of the node, users that are
+	// not persisted are applied again after the node restarts
+	Persisted bool   `json:"persisted"`
+	Message   string `json:"message,omitempty"`
+}
+
+type RedisUserState string
+
+// Status Field of the Redis User
+const (
+	RedisUserPending   RedisUserState = "Pending"
+	RedisUserSynced    RedisUserState = "Synced"
+	RedisUserOutOfSync RedisUserState = "OutOfSync"
+	RedisUserFailed    RedisUserState = "Failed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The Redis deployment the user is managed on"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="The current state of the user"
+// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.syncedNodes",description="The nodes the user is applied on"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of User"
+
+// RedisUser is the Schema for the redisusers API
+type RedisUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisUserSpec   `json:"spec"`
+	Status RedisUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisUserList contains a list of RedisUser
+type RedisUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisUser `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisUser{}, &RedisUserList{})
+}
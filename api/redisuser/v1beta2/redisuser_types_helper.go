@@ -0,0 +1,36 @@
+package v1beta2
+
+import "strings"
+
+// DefaultUsername is the ACL user the operator authenticates as, it cannot be managed by a RedisUser
+const DefaultUsername = "default"
+
+// GetUsername returns the name of the ACL user, which defaults to the name of the RedisUser
+func (cr *RedisUser) GetUsername() string {
+	if cr.Spec.Username != "" {
+		return cr.Spec.Username
+	}
+	return cr.Name
+}
+
+// IsEnabled reports whether the user can authenticate
+func (cr *RedisUser) IsEnabled() bool {
+	return cr.Spec.Enabled == nil || *cr.Spec.Enabled
+}
+
+// Args returns the rules as ACL SETUSER arguments. Key patterns are prefixed with "~" unless
+// they already carry a permission prefix, channel patterns are prefixed with "&".
+func (r *ACLRules) Args() []string {
+	args := make([]string, 0, len(r.Keys)+len(r.Channels)+len(r.Commands))
+	for _, key := range r.Keys {
+		if strings.HasPrefix(key, "%") || strings.HasPrefix(key, "~") {
+			args = append(args, key)
+			continue
+		}
+		args = append(args, "~"+key)
+	}
+	for _, channel := range r.Channels {
+		args = append(args, "&"+channel)
+	}
+	return append(args, r.Commands...)
+}
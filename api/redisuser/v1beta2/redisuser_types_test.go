@@ -0,0 +1,31 @@
+package v1beta2_test
+
+import (
+	"testing"
+
+	v1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestACLRules_Args(t *testing.T) {
+	rules := v1beta2.ACLRules{
+		Commands: []string{"+@read", "-keys"},
+		Keys:     []string{"app:*", "%R~cache:*"},
+		Channels: []string{"events.*"},
+	}
+	assert.Equal(t, []string{"~app:*", "%R~cache:*", "&events.*", "+@read", "-keys"}, rules.Args())
+	assert.Empty(t, (&v1beta2.ACLRules{}).Args())
+}
+
+func TestRedisUser_GetUsername(t *testing.T) {
+	user := &v1beta2.RedisUser{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	assert.Equal(t, "app", user.GetUsername())
+	assert.True(t, user.IsEnabled())
+
+	user.Spec.Username = "app-reader"
+	user.Spec.Enabled = ptr.To(false)
+	assert.Equal(t, "app-reader", user.GetUsername())
+	assert.False(t, user.IsEnabled())
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	agentutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/agent/util"
@@ -43,6 +44,7 @@ func GenerateConfig() error {
 		redisMajorVersion  = util.CoalesceEnv1("REDIS_MAJOR_VERSION", "v7")
 		redisPort          = util.CoalesceEnv1("REDIS_PORT", "6379")
 		nodeport           = util.CoalesceEnv1("NODEPORT", "false")
+		externalAccess     = util.CoalesceEnv1("EXTERNAL_ACCESS", "false")
 		tlsMode            = util.CoalesceEnv1("TLS_MODE", "false")
 		clusterMode        = util.CoalesceEnv1("SETUP_MODE", "standalone")
 		aclMode            = util.CoalesceEnv1("ACL_MODE", "")
@@ -58,6 +60,14 @@ func GenerateConfig() error {
 		cfg.Append("protected-mode", "no")
 	}
 
+	// the operator passes the addresses the pods are exposed at keyed by their names
+	podHostname, _ := os.Hostname()
+	// a pod behind a load balancer with a hostname but no IP is only reachable by its hostname
+	var announceHostname string
+	if externalAccess == "true" {
+		announceHostname = podAnnounceEnv("announce_hostname", podHostname)
+	}
+
 	if clusterMode == "cluster" {
 		nodeConfPath := filepath.Join(nodeConfDir, "nodes.conf")
 
@@ -85,6 +95,8 @@ func GenerateConfig() error {
 		var clusterAnnounceIP string
 		if nodeport == "true" {
 			clusterAnnounceIP = os.Getenv("HOST_IP")
+		} else if externalAccess == "true" && podAnnounceEnv("announce_ip", podHostname) != "" {
+			clusterAnnounceIP = podAnnounceEnv("announce_ip", podHostname)
 		} else {
 			clusterAnnounceIP, err = util.GetLocalIP()
 			if err != nil {
@@ -94,7 +106,10 @@ func GenerateConfig() error {
 		if clusterAnnounceIP != "" {
 			cfg.Append("cluster-announce-ip", clusterAnnounceIP)
 		}
-		if redisMajorVersion == "v7" {
+		if announceHostname != "" {
+			cfg.Append("cluster-announce-hostname", announceHostname)
+			cfg.Append("cluster-preferred-endpoint-type", "hostname")
+		} else if majorVersionAtLeast(redisMajorVersion, 7) {
 			fqdnName, err := fqdn.FqdnHostname()
 			if err != nil {
 				log.Printf("Warning: Failed to get FQDN: %v", err)
@@ -117,7 +132,8 @@ func GenerateConfig() error {
 
 		if clusterMode == "cluster" {
 			cfg.Append("tls-cluster", "yes")
-			if redisMajorVersion == "v7" && nodeport == "false" {
+			// the FQDN of the pod does not resolve outside of the Kubernetes network
+			if majorVersionAtLeast(redisMajorVersion, 7) && nodeport == "false" && externalAccess == "false" {
 				cfg.Append("cluster-preferred-endpoint-type", "hostname")
 			}
 		}
@@ -150,14 +166,10 @@ func GenerateConfig() error {
 		cfg.Append("port", redisPort)
 	}
 
-	if nodeport == "true" {
-		podHostname, _ := os.Hostname()
-		announcePortVar := "announce_port_" + strings.ReplaceAll(podHostname, "-", "_")
-		announceBusPortVar := "announce_bus_port_" + strings.ReplaceAll(podHostname, "-", "_")
-
+	if nodeport == "true" || externalAccess == "true" {
 		// Get environment variables
-		clusterAnnouncePort := os.Getenv(announcePortVar)
-		clusterAnnounceBusPort := os.Getenv(announceBusPortVar)
+		clusterAnnouncePort := podAnnounceEnv("announce_port", podHostname)
+		clusterAnnounceBusPort := podAnnounceEnv("announce_bus_port", podHostname)
 
 		if clusterAnnouncePort != "" {
 			cfg.Append("cluster-announce-port", clusterAnnouncePort)
@@ -179,6 +191,19 @@ func GenerateConfig() error {
 	return cfg.Commit()
 }
 
+// podAnnounceEnv returns the environment variable the operator set for the pod, like
+// announce_port_cluster_leader_0 for the announce_port of the pod cluster-leader-0
+func podAnnounceEnv(name, podHostname string) string {
+	return os.Getenv(name + "_" + strings.ReplaceAll(podHostname, "-", "_"))
+}
+
+// majorVersionAtLeast reports whether a major version like v7 or v8 is at least major, the
+// operator sets v8 for Redis 8 and Valkey 8
+func majorVersionAtLeast(version string, major int) bool {
+	v, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	return err == nil && v >= major
+}
+
 func updateMyselfIP(nodesConfPath, newIP string) (updated []byte, err error) {
 	raw, err := os.ReadFile(nodesConfPath)
 	if err != nil {
@@ -68,6 +68,73 @@ func Test_GenerateConfig_TLS_CACertFile(t *testing.T) {
 	}
 }
 
+func Test_GenerateConfig_ExternalAccess(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	podEnv := func(name string) string {
+		return name + "_" + strings.ReplaceAll(hostname, "-", "_")
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expect   []string
+		unexpect []string
+	}{
+		{
+			name: "load balancer with an IP",
+			env: map[string]string{
+				podEnv("announce_ip"):       "203.0.113.10",
+				podEnv("announce_port"):     "6379",
+				podEnv("announce_bus_port"): "16379",
+			},
+			expect: []string{
+				"cluster-announce-ip 203.0.113.10",
+				"cluster-announce-port 6379",
+				"cluster-announce-bus-port 16379",
+			},
+			unexpect: []string{"cluster-preferred-endpoint-type"},
+		},
+		{
+			name: "load balancer with a hostname",
+			env: map[string]string{
+				podEnv("announce_hostname"): "lb-0.example.com",
+				podEnv("announce_port"):     "6379",
+				podEnv("announce_bus_port"): "16379",
+			},
+			expect: []string{
+				"cluster-announce-hostname lb-0.example.com",
+				"cluster-preferred-endpoint-type hostname",
+				"cluster-announce-port 6379",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "redis.conf")
+			t.Setenv("REDIS_CONFIG_FILE", confPath)
+			t.Setenv("NODE_CONF_DIR", t.TempDir())
+			t.Setenv("SETUP_MODE", "cluster")
+			t.Setenv("EXTERNAL_ACCESS", "true")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			require.NoError(t, GenerateConfig())
+
+			raw, err := os.ReadFile(confPath)
+			require.NoError(t, err)
+			conf := string(raw)
+			for _, line := range tt.expect {
+				assert.Contains(t, conf, line)
+			}
+			for _, line := range tt.unexpect {
+				assert.NotContains(t, conf, line)
+			}
+		})
+	}
+}
+
 func Test_updateMyselfIP(t *testing.T) {
 	testData := `7a6b5f4f99496c97f4e32c30c077aa95cab92664 10.244.0.246:0@16379,,tls-port=6379,shard-id=a03445a0d3f6d405af261041e0cb77a8a176f42b slave b66f2fa597eeda567cf05f3701419be9a3b2f50e 0 1756463509000 1 connected
 93ad60e9ce21430683a3534d2c96ab1b8077cfe8 10.244.0.237:0@16379,,tls-port=6379,shard-id=2f177491b895051f91e91e554a2a9da2cd167aeb master - 0 1756463509685 2 connected 5461-10922
@@ -104,3 +171,10 @@ vars currentEpoch 3 lastVoteEpoch 0
 
 	t.Logf("Successfully updated nodes.conf with new IP %s", newIP)
 }
+
+func Test_majorVersionAtLeast(t *testing.T) {
+	assert.True(t, majorVersionAtLeast("v7", 7))
+	assert.True(t, majorVersionAtLeast("v8", 7))
+	assert.False(t, majorVersionAtLeast("v6", 7))
+	assert.False(t, majorVersionAtLeast("", 7))
+}
@@ -25,12 +25,19 @@ import (
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/operator"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/scheme"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/switchmaster"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	rediscontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redis"
+	redisbackupcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisbackup"
+	redisbackupschedulecontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisbackupschedule"
 	redisclustercontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/rediscluster"
 	redisreplicationcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisreplication"
+	redisrestorecontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisrestore"
 	redissentinelcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redissentinel"
+	redisusercontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisuser"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
@@ -126,6 +133,7 @@ func runManager(opts *managerOptions) error {
 
 	monitoring.RegisterRedisReplicationMetrics()
 	monitoring.RegisterRedisClusterMetrics()
+	monitoring.RegisterRedisBackupMetrics()
 
 	setupLog.Info("setting up v1beta2 scheme")
 	scheme.SetupV1beta2Scheme()
@@ -240,45 +248,93 @@ func setupControllers(mgr ctrl.Manager, k8sClient kubernetes.Interface, maxConcu
 	maxConcurrentReconciles = envs.GetMaxConcurrentReconciles(maxConcurrentReconciles)
 
 	healer := redis.NewHealer(k8sClient)
+	switchMasterWatcher := switchmaster.NewWatcher(mgr.GetClient(), k8sClient, mgr.GetEventRecorderFor("redisreplication-controller"))
 
 	if err := (&rediscontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		Rotator:       passwordrotation.NewRotator(k8sClient, mgr.GetEventRecorderFor("redis-controller")),
+		TLSRotator:    tlsrotation.NewRotator(mgr.GetClient(), k8sClient, mgr.GetEventRecorderFor("redis-controller")),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Redis")
 		return err
 	}
 	if err := (&redisclustercontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		Checker:     redis.NewChecker(k8sClient),
-		Recorder:    mgr.GetEventRecorderFor("rediscluster-controller"),
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        healer,
+		Checker:       redis.NewChecker(k8sClient),
+		Recorder:      mgr.GetEventRecorderFor("rediscluster-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		Rotator:       passwordrotation.NewRotator(k8sClient, mgr.GetEventRecorderFor("rediscluster-controller")),
+		TLSRotator:    tlsrotation.NewRotator(mgr.GetClient(), k8sClient, mgr.GetEventRecorderFor("rediscluster-controller")),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisCluster")
 		return err
 	}
 	if err := (&redisreplicationcontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:              mgr.GetClient(),
+		K8sClient:           k8sClient,
+		Healer:              healer,
+		StatefulSet:         k8sutils.NewStatefulSetService(k8sClient),
+		Recorder:            mgr.GetEventRecorderFor("redisreplication-controller"),
+		Rotator:             passwordrotation.NewRotator(k8sClient, mgr.GetEventRecorderFor("redisreplication-controller")),
+		TLSRotator:          tlsrotation.NewRotator(mgr.GetClient(), k8sClient, mgr.GetEventRecorderFor("redisreplication-controller")),
+		SecretWatcher:       intctrlutil.NewResourceWatcher(),
+		SwitchMasterWatcher: switchMasterWatcher,
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisReplication")
 		return err
 	}
 	if err := (&redissentinelcontroller.RedisSentinelReconciler{
-		Client:             mgr.GetClient(),
-		Checker:            redis.NewChecker(k8sClient),
-		Healer:             healer,
-		K8sClient:          k8sClient,
-		ReplicationWatcher: intctrlutil.NewResourceWatcher(),
+		Client:              mgr.GetClient(),
+		Checker:             redis.NewChecker(k8sClient),
+		Healer:              healer,
+		K8sClient:           k8sClient,
+		ReplicationWatcher:  intctrlutil.NewResourceWatcher(),
+		SecretWatcher:       intctrlutil.NewResourceWatcher(),
+		SwitchMasterWatcher: switchMasterWatcher,
+		TLSRotator:          tlsrotation.NewRotator(mgr.GetClient(), k8sClient, mgr.GetEventRecorderFor("redissentinel-controller")),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisSentinel")
 		return err
 	}
+	if err := (&redisbackupcontroller.Reconciler{
+		Client:      mgr.GetClient(),
+		K8sClient:   k8sClient,
+		Snapshotter: redis.NewSnapshotter(k8sClient),
+		Recorder:    mgr.GetEventRecorderFor("redisbackup-controller"),
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackup")
+		return err
+	}
+	if err := (&redisbackupschedulecontroller.Reconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("redisbackupschedule-controller"),
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackupSchedule")
+		return err
+	}
+	if err := (&redisrestorecontroller.Reconciler{
+		Client:      mgr.GetClient(),
+		Recorder:    mgr.GetEventRecorderFor("redisrestore-controller"),
+		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisRestore")
+		return err
+	}
+	if err := (&redisusercontroller.Reconciler{
+		Client:    mgr.GetClient(),
+		K8sClient: k8sClient,
+		ACL:       redis.NewACLManager(k8sClient),
+		Recorder:  mgr.GetEventRecorderFor("redisuser-controller"),
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisUser")
+		return err
+	}
 
 	return nil
 }
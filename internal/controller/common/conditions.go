@@ -0,0 +1,147 @@
+package common
+
+import (
+	"fmt"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionCause is why a condition left its healthy state
+type ConditionCause struct {
+	Reason  string
+	Message string
+}
+
+// Observation is the state of a resource seen by its reconciler. A nil cause reports the condition
+// in its healthy state, Ready True and the other conditions False.
+type Observation struct {
+	// NotReady is why the resource does not serve clients in the desired topology
+	NotReady           *ConditionCause
+	Progressing        *ConditionCause
+	Degraded           *ConditionCause
+	ScalingInProgress  *ConditionCause
+	FailoverInProgress *ConditionCause
+}
+
+// SetConditions records every condition of the observation made at generation. The transition
+// time of a condition only changes with its status.
+func SetConditions(conditions *[]metav1.Condition, generation int64, o Observation) {
+	setCondition(conditions, generation, commonapi.ConditionReady, o.NotReady, metav1.ConditionFalse)
+	setCondition(conditions, generation, commonapi.ConditionProgressing, o.Progressing, metav1.ConditionTrue)
+	setCondition(conditions, generation, commonapi.ConditionDegraded, o.Degraded, metav1.ConditionTrue)
+	setCondition(conditions, generation, commonapi.ConditionScalingInProgress, o.ScalingInProgress, metav1.ConditionTrue)
+	setCondition(conditions, generation, commonapi.ConditionFailoverInProgress, o.FailoverInProgress, metav1.ConditionTrue)
+}
+
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, cause *ConditionCause, causeStatus metav1.ConditionStatus) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             commonapi.ConditionReasonAsExpected,
+	}
+	if causeStatus == metav1.ConditionTrue {
+		condition.Status = metav1.ConditionFalse
+	}
+	if cause != nil {
+		condition.Status = causeStatus
+		condition.Reason = cause.Reason
+		condition.Message = cause.Message
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// ActiveCondition returns the cause of a condition that is True, operations like a scaling are
+// reported until the reconciler observes their end
+func ActiveCondition(conditions []metav1.Condition, conditionType string) *ConditionCause {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return nil
+	}
+	return &ConditionCause{Reason: condition.Reason, Message: condition.Message}
+}
+
+// StatefulSetScaling reports the scaling of the statefulset to replicas pods. The scaling starts
+// when the statefulset runs another number of pods and lasts, as previous, until every pod is
+// ready. A statefulset without ready pods is being created rather than scaled.
+func StatefulSetScaling(sts *appsv1.StatefulSet, replicas int32, previous *ConditionCause) *ConditionCause {
+	if sts == nil {
+		return nil
+	}
+	switch {
+	case sts.Status.ReadyReplicas == 0 && previous == nil:
+		return nil
+	case sts.Status.Replicas < replicas:
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonScalingUp,
+			Message: fmt.Sprintf("scaling %s from %d to %d pods", sts.Name, sts.Status.Replicas, replicas),
+		}
+	case sts.Status.Replicas > replicas:
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonScalingDown,
+			Message: fmt.Sprintf("scaling %s from %d to %d pods", sts.Name, sts.Status.Replicas, replicas),
+		}
+	case sts.Status.ReadyReplicas != replicas:
+		return previous
+	default:
+		return nil
+	}
+}
+
+// StatefulSetDisruption reports pods of the statefulset knowingly taken down: a scale-down to
+// replicas pods or a rolling restart of its pods with a new revision. The pods of an OnDelete
+// statefulset are only restarted by the operator, under managedFailover.
+func StatefulSetDisruption(sts *appsv1.StatefulSet, replicas int32, managedFailover bool) *ConditionCause {
+	if sts == nil {
+		return nil
+	}
+	current := sts.Status.Replicas
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas > current {
+		current = *sts.Spec.Replicas
+	}
+	if current > replicas {
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonScalingDown,
+			Message: fmt.Sprintf("scaling %s from %d to %d pods", sts.Name, current, replicas),
+		}
+	}
+	onDelete := sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
+	if sts.Status.UpdateRevision != "" && sts.Status.UpdatedReplicas < sts.Status.Replicas && (!onDelete || managedFailover) {
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonRollingRestart,
+			Message: fmt.Sprintf("restarting the pods of %s with a new revision", sts.Name),
+		}
+	}
+	return nil
+}
+
+// RotationProgress reports a password or certificate change still being rolled out to the pods
+func RotationProgress(password *commonapi.PasswordRotationStatus, tls *commonapi.TLSRotationStatus) *ConditionCause {
+	if password != nil && password.Phase != "" && password.Phase != commonapi.PasswordRotationCompleted {
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonPasswordRotation,
+			Message: fmt.Sprintf("rotating the password, phase %s", password.Phase),
+		}
+	}
+	if tls != nil && tls.StartTime != nil && tls.CompletionTime == nil {
+		return &ConditionCause{
+			Reason:  commonapi.ConditionReasonTLSRotation,
+			Message: fmt.Sprintf("restarting %d pods with the new certificate", len(tls.PendingPods)),
+		}
+	}
+	return nil
+}
+
+// SetOperation records the ScalingInProgress or FailoverInProgress condition of an operation
+// started by the reconciler, True with its cause and False without
+func SetOperation(conditions *[]metav1.Condition, generation int64, conditionType string, cause *ConditionCause) {
+	setCondition(conditions, generation, conditionType, cause, metav1.ConditionTrue)
+}
+
+// SetQuorumReachable records the QuorumReachable condition of sentinels, True without a cause
+func SetQuorumReachable(conditions *[]metav1.Condition, generation int64, cause *ConditionCause) {
+	setCondition(conditions, generation, commonapi.ConditionQuorumReachable, cause, metav1.ConditionFalse)
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetConditions(t *testing.T) {
+	var conditions []metav1.Condition
+	SetConditions(&conditions, 1, Observation{})
+	require.Len(t, conditions, 5)
+	assert.True(t, meta.IsStatusConditionTrue(conditions, commonapi.ConditionReady))
+	for _, conditionType := range []string{
+		commonapi.ConditionProgressing,
+		commonapi.ConditionDegraded,
+		commonapi.ConditionScalingInProgress,
+		commonapi.ConditionFailoverInProgress,
+	} {
+		assert.True(t, meta.IsStatusConditionFalse(conditions, conditionType), conditionType)
+	}
+
+	notReady := &ConditionCause{Reason: commonapi.ConditionReasonPodsNotReady, Message: "waiting"}
+	SetConditions(&conditions, 2, Observation{NotReady: notReady, Progressing: notReady})
+	ready := meta.FindStatusCondition(conditions, commonapi.ConditionReady)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, commonapi.ConditionReasonPodsNotReady, ready.Reason)
+	assert.Equal(t, int64(2), ready.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(conditions, commonapi.ConditionProgressing))
+	assert.Equal(t, notReady, ActiveCondition(conditions, commonapi.ConditionProgressing))
+	assert.Nil(t, ActiveCondition(conditions, commonapi.ConditionDegraded))
+}
+
+func TestStatefulSetScaling(t *testing.T) {
+	sts := func(replicas, ready int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{Replicas: replicas, ReadyReplicas: ready}}
+	}
+
+	assert.Nil(t, StatefulSetScaling(nil, 3, nil))
+	// a new statefulset is created, not scaled
+	assert.Nil(t, StatefulSetScaling(sts(1, 0), 3, nil))
+
+	scaling := StatefulSetScaling(sts(2, 2), 3, nil)
+	require.NotNil(t, scaling)
+	assert.Equal(t, commonapi.ConditionReasonScalingUp, scaling.Reason)
+	assert.Equal(t, commonapi.ConditionReasonScalingDown, StatefulSetScaling(sts(3, 3), 2, nil).Reason)
+
+	// the scaling lasts until the added pods are ready
+	assert.Equal(t, scaling, StatefulSetScaling(sts(3, 2), 3, scaling))
+	assert.Nil(t, StatefulSetScaling(sts(3, 3), 3, scaling))
+	// a pod that is not ready is no scaling
+	assert.Nil(t, StatefulSetScaling(sts(3, 2), 3, nil))
+}
+
+func TestStatefulSetDisruption(t *testing.T) {
+	three := int32(3)
+	sts := func(strategy appsv1.StatefulSetUpdateStrategyType, replicas, updated int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{Replicas: &three, UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: strategy}},
+			Status: appsv1.StatefulSetStatus{
+				Replicas:        replicas,
+				UpdatedReplicas: updated,
+				UpdateRevision:  "redis-2",
+			},
+		}
+	}
+
+	assert.Nil(t, StatefulSetDisruption(nil, 3, false))
+	assert.Nil(t, StatefulSetDisruption(sts(appsv1.RollingUpdateStatefulSetStrategyType, 3, 3), 3, false))
+	// scaling up takes no pod down
+	assert.Nil(t, StatefulSetDisruption(sts(appsv1.RollingUpdateStatefulSetStrategyType, 3, 3), 4, false))
+
+	scaleDown := StatefulSetDisruption(sts(appsv1.RollingUpdateStatefulSetStrategyType, 3, 3), 2, false)
+	require.NotNil(t, scaleDown)
+	assert.Equal(t, commonapi.ConditionReasonScalingDown, scaleDown.Reason)
+
+	restart := StatefulSetDisruption(sts(appsv1.RollingUpdateStatefulSetStrategyType, 3, 1), 3, false)
+	require.NotNil(t, restart)
+	assert.Equal(t, commonapi.ConditionReasonRollingRestart, restart.Reason)
+
+	// the pods of an OnDelete statefulset are only restarted by a managed failover
+	assert.Nil(t, StatefulSetDisruption(sts(appsv1.OnDeleteStatefulSetStrategyType, 3, 1), 3, false))
+	assert.Equal(t, commonapi.ConditionReasonRollingRestart, StatefulSetDisruption(sts(appsv1.OnDeleteStatefulSetStrategyType, 3, 1), 3, true).Reason)
+}
+
+func TestRotationProgress(t *testing.T) {
+	now := metav1.Now()
+	assert.Nil(t, RotationProgress(nil, nil))
+	assert.Nil(t, RotationProgress(&commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationCompleted}, nil))
+	assert.Nil(t, RotationProgress(nil, &commonapi.TLSRotationStatus{SecretHash: "hash"}))
+
+	password := RotationProgress(&commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationGracePeriod}, nil)
+	require.NotNil(t, password)
+	assert.Equal(t, commonapi.ConditionReasonPasswordRotation, password.Reason)
+
+	tls := RotationProgress(nil, &commonapi.TLSRotationStatus{StartTime: &now, PendingPods: []string{"redis-0"}})
+	require.NotNil(t, tls)
+	assert.Equal(t, commonapi.ConditionReasonTLSRotation, tls.Reason)
+}
+
+func TestSetQuorumReachable(t *testing.T) {
+	var conditions []metav1.Condition
+	SetQuorumReachable(&conditions, 1, nil)
+	assert.True(t, meta.IsStatusConditionTrue(conditions, commonapi.ConditionQuorumReachable))
+
+	SetQuorumReachable(&conditions, 2, &ConditionCause{Reason: commonapi.ConditionReasonNoQuorum, Message: "NOQUORUM"})
+	quorum := meta.FindStatusCondition(conditions, commonapi.ConditionQuorumReachable)
+	assert.Equal(t, metav1.ConditionFalse, quorum.Status)
+	assert.Equal(t, commonapi.ConditionReasonNoQuorum, quorum.Reason)
+	assert.Equal(t, "NOQUORUM", quorum.Message)
+}
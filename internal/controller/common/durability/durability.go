@@ -0,0 +1,72 @@
+package durability
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Target is a RedisCluster or RedisReplication whose masters need replicas to accept writes
+type Target struct {
+	Object     client.Object
+	Durability *commonapi.Durability
+	Status     *commonapi.DurabilityStatus
+	// Disruption is the planned scale-down or rolling restart taking replicas down, if any
+	Disruption *common.ConditionCause
+	// Apply sets the config on every pod of the target
+	Apply func(ctx context.Context, config []string) error
+}
+
+// Reconcile sets min-replicas-to-write and min-replicas-max-lag on the pods of the target. While
+// replicas are knowingly taken down min-replicas-to-write is relaxed to 0, a master would otherwise
+// reject the writes until they are back, and it is restored once they are. It returns the status
+// to record, nil once the durability is removed from the spec.
+func Reconcile(ctx context.Context, recorder record.EventRecorder, t Target) (*commonapi.DurabilityStatus, error) {
+	if t.Durability == nil {
+		if t.Status == nil {
+			return nil, nil
+		}
+		if err := t.Apply(ctx, []string{"min-replicas-to-write 0"}); err != nil {
+			return t.Status, err
+		}
+		log.FromContext(ctx).Info("Removed the durability of the pods")
+		return nil, nil
+	}
+
+	status := &commonapi.DurabilityStatus{
+		MinReplicasToWrite: t.Durability.MinReplicasToWrite,
+		MinReplicasMaxLag:  t.Durability.GetMinReplicasMaxLag(),
+	}
+	if t.Disruption != nil {
+		status.MinReplicasToWrite = 0
+		status.Relaxed = true
+		status.Reason = t.Disruption.Message
+	}
+	// the config is applied on every reconcile, restarted pods start without it
+	config := []string{
+		"min-replicas-to-write " + strconv.Itoa(int(status.MinReplicasToWrite)),
+		"min-replicas-max-lag " + strconv.Itoa(int(status.MinReplicasMaxLag)),
+	}
+	if err := t.Apply(ctx, config); err != nil {
+		return t.Status, err
+	}
+
+	wasRelaxed := t.Status != nil && t.Status.Relaxed
+	switch {
+	case status.Relaxed && !wasRelaxed:
+		recorder.Event(t.Object, corev1.EventTypeNormal, events.EventReasonDurabilityRelaxed,
+			fmt.Sprintf("Relaxed min-replicas-to-write from %d to 0 while %s", t.Durability.MinReplicasToWrite, status.Reason))
+	case !status.Relaxed && wasRelaxed:
+		recorder.Event(t.Object, corev1.EventTypeNormal, events.EventReasonDurabilityRestored,
+			fmt.Sprintf("Restored min-replicas-to-write to %d", status.MinReplicasToWrite))
+	}
+	return status, nil
+}
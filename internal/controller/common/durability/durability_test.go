@@ -0,0 +1,89 @@
+package durability
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcile(t *testing.T) {
+	replication := &rrvb2.RedisReplication{ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "default"}}
+	recorder := record.NewFakeRecorder(10)
+	var applied []string
+	target := Target{
+		Object:     replication,
+		Durability: &commonapi.Durability{MinReplicasToWrite: 2},
+		Apply: func(ctx context.Context, config []string) error {
+			applied = config
+			return nil
+		},
+	}
+
+	status, err := Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.Equal(t, &commonapi.DurabilityStatus{MinReplicasToWrite: 2, MinReplicasMaxLag: 10}, status)
+	assert.Equal(t, []string{"min-replicas-to-write 2", "min-replicas-max-lag 10"}, applied)
+	assert.Empty(t, recorder.Events)
+
+	// a scale-down relaxes the durability
+	target.Status = status
+	target.Disruption = &common.ConditionCause{Reason: commonapi.ConditionReasonScalingDown, Message: "scaling replication from 3 to 2 pods"}
+	status, err = Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.True(t, status.Relaxed)
+	assert.Equal(t, []string{"min-replicas-to-write 0", "min-replicas-max-lag 10"}, applied)
+	assert.Equal(t, "Normal DurabilityRelaxed Relaxed min-replicas-to-write from 2 to 0 while scaling replication from 3 to 2 pods", <-recorder.Events)
+
+	// the transition is recorded once
+	target.Status = status
+	_, err = Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+
+	target.Disruption = nil
+	status, err = Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.False(t, status.Relaxed)
+	assert.Equal(t, []string{"min-replicas-to-write 2", "min-replicas-max-lag 10"}, applied)
+	assert.Equal(t, "Normal DurabilityRestored Restored min-replicas-to-write to 2", <-recorder.Events)
+
+	// a failure keeps the previous status
+	target.Status = &commonapi.DurabilityStatus{MinReplicasToWrite: 0, MinReplicasMaxLag: 10, Relaxed: true}
+	target.Apply = func(ctx context.Context, config []string) error { return errors.New("connection refused") }
+	status, err = Reconcile(context.TODO(), recorder, target)
+	assert.Error(t, err)
+	assert.Equal(t, target.Status, status)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestReconcileRemoved(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	var applied []string
+	target := Target{
+		Object: &rrvb2.RedisReplication{},
+		Apply: func(ctx context.Context, config []string) error {
+			applied = config
+			return nil
+		},
+	}
+
+	// nothing was applied, nothing is reset
+	status, err := Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	assert.Nil(t, applied)
+
+	target.Status = &commonapi.DurabilityStatus{MinReplicasToWrite: 1, MinReplicasMaxLag: 10}
+	status, err = Reconcile(context.TODO(), recorder, target)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	assert.Equal(t, []string{"min-replicas-to-write 0"}, applied)
+}
@@ -1,7 +1,43 @@
 package events
 
 const (
-	EventReasonRedisClusterDownscale = "RedisClusterDownscale"
+	EventReasonRedisClusterDownscale        = "RedisClusterDownscale"
+	EventReasonRedisClusterFollowerMoved    = "RedisClusterFollowerMoved"
+	EventReasonRedisClusterSlotsMoved       = "RedisClusterSlotsMoved"
+	EventReasonRedisClusterKeysReplaced     = "RedisClusterKeysReplaced"
+	EventReasonRedisClusterScalingPlanned   = "RedisClusterScalingPlanned"
+	EventReasonRedisClusterNodesForgotten   = "RedisClusterNodesForgotten"
+	EventReasonRolloutFailover              = "RolloutFailover"
+	EventReasonRolloutPodRestarted          = "RolloutPodRestarted"
+	EventReasonRolloutBlocked               = "RolloutBlocked"
+	EventReasonRedisBackupStarted           = "RedisBackupStarted"
+	EventReasonRedisBackupCompleted         = "RedisBackupCompleted"
+	EventReasonRedisBackupFailed            = "RedisBackupFailed"
+	EventReasonRedisBackupRetained          = "RedisBackupRetained"
+	EventReasonRedisBackupScheduled         = "RedisBackupScheduled"
+	EventReasonRedisBackupSkipped           = "RedisBackupSkipped"
+	EventReasonRedisBackupPruned            = "RedisBackupPruned"
+	EventReasonInvalidBackupSchedule        = "InvalidBackupSchedule"
+	EventReasonRedisRestoreStarted          = "RedisRestoreStarted"
+	EventReasonRedisRestoreCompleted        = "RedisRestoreCompleted"
+	EventReasonRedisRestoreFailed           = "RedisRestoreFailed"
+	EventReasonRedisUserSynced              = "RedisUserSynced"
+	EventReasonRedisUserOutOfSync           = "RedisUserOutOfSync"
+	EventReasonRedisUserDeleted             = "RedisUserDeleted"
+	EventReasonInvalidRedisUser             = "InvalidRedisUser"
+	EventReasonPasswordRotationStarted      = "PasswordRotationStarted"
+	EventReasonPasswordRotationPodRestarted = "PasswordRotationPodRestarted"
+	EventReasonPasswordRotationCompleted    = "PasswordRotationCompleted"
+	EventReasonTLSRotationStarted           = "TLSRotationStarted"
+	EventReasonTLSRotationPodRestarted      = "TLSRotationPodRestarted"
+	EventReasonTLSRotationFailover          = "TLSRotationFailover"
+	EventReasonTLSRotationCompleted         = "TLSRotationCompleted"
+	EventReasonSwitchoverStarted            = "SwitchoverStarted"
+	EventReasonSwitchoverCompleted          = "SwitchoverCompleted"
+	EventReasonSwitchoverFailed             = "SwitchoverFailed"
+	EventReasonSentinelSwitchMaster         = "SentinelSwitchMaster"
+	EventReasonDurabilityRelaxed            = "DurabilityRelaxed"
+	EventReasonDurabilityRestored           = "DurabilityRestored"
 )
 
 type Event struct {
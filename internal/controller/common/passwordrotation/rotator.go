@@ -0,0 +1,288 @@
+package passwordrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// StateSecretSuffix names the secret the operator keeps the applied passwords in, the
+	// previous password is needed to log in to the pods until every pod accepts the new one
+	StateSecretSuffix = "-password-rotation"
+
+	passwordKey     = "password"
+	previousKey     = "previous"
+	retryInterval   = 15 * time.Second
+	restartInterval = 10 * time.Second
+)
+
+// Target is a Redis, RedisCluster or RedisReplication whose ExistingPasswordSecret is rotated
+type Target struct {
+	Object client.Object
+	Secret *commonapi.ExistingPasswordSecret
+	Spec   *commonapi.PasswordRotation
+	Status *commonapi.PasswordRotationStatus
+	// Primaries are restarted after every other pod, restarting them triggers a failover
+	Primaries []string
+	// Sentinels points the sentinels monitoring the target at the password, it is optional
+	Sentinels func(ctx context.Context, password string) error
+}
+
+// Rotator rolls a changed ExistingPasswordSecret out to the pods of a target without a moment
+// where clients using either the previous or the new password are rejected:
+//
+//  1. the new password is added next to the previous one on every pod, and masterauth and the
+//     sentinels are switched to it
+//  2. the previous password keeps working for the grace period
+//  3. pods whose containers were started with the previous password are restarted one at a time,
+//     their probes and exporter read the password from the environment set at container start
+//  4. the previous password is removed from every pod
+type Rotator struct {
+	K8sClient kubernetes.Interface
+	Passwords redis.PasswordManager
+	Recorder  record.EventRecorder
+	// Now is overridden in tests
+	Now func() time.Time
+}
+
+func NewRotator(clientset kubernetes.Interface, recorder record.EventRecorder) *Rotator {
+	return &Rotator{
+		K8sClient: clientset,
+		Passwords: redis.NewPasswordManager(clientset),
+		Recorder:  recorder,
+		Now:       time.Now,
+	}
+}
+
+// Rotating reports whether the pods may still reject the password of the secret, the operator
+// must not log in with it until the rotation leaves the AddingPassword phase
+func Rotating(status *commonapi.PasswordRotationStatus) bool {
+	return status != nil && status.Phase == commonapi.PasswordRotationAddingPassword
+}
+
+// Reconcile advances the rotation of the target by at most one phase. It returns the status to
+// record and when to reconcile again, zero when no rotation is in progress.
+func (r *Rotator) Reconcile(ctx context.Context, t Target) (*commonapi.PasswordRotationStatus, time.Duration, error) {
+	status := t.Status.DeepCopy()
+	if t.Secret == nil || t.Secret.Name == nil || t.Secret.Key == nil {
+		return status, 0, nil
+	}
+	password, err := r.getPassword(ctx, t.Object.GetNamespace(), t.Secret)
+	if err != nil {
+		return status, 0, err
+	}
+
+	stateSecret, err := r.K8sClient.CoreV1().Secrets(t.Object.GetNamespace()).Get(ctx, t.Object.GetName()+StateSecretSuffix, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the pods were created with the password of the secret
+		return status, 0, r.createState(ctx, t.Object, password)
+	}
+	if err != nil {
+		return status, 0, err
+	}
+	applied, previous, err := readState(stateSecret)
+	if err != nil {
+		return status, 0, err
+	}
+
+	now := metav1.NewTime(r.Now())
+	if password != applied {
+		// a password changed again mid rotation is kept as previous, pods restarted in between
+		// only know that one
+		if !slices.Contains(previous, applied) {
+			previous = append(previous, applied)
+		}
+		previous = slices.DeleteFunc(previous, func(p string) bool { return p == password })
+		if err := r.updateState(ctx, stateSecret, password, previous); err != nil {
+			return status, 0, err
+		}
+		status = &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationAddingPassword, StartTime: &now}
+		r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonPasswordRotationStarted, "Rotating the password of secret %s", *t.Secret.Name)
+	}
+	if len(previous) == 0 {
+		return status, 0, nil
+	}
+	if status == nil || status.StartTime == nil || status.Phase == commonapi.PasswordRotationCompleted {
+		status = &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationAddingPassword, StartTime: &now}
+	}
+
+	nodes, err := r.Passwords.Nodes(t.Object)
+	if err != nil {
+		return status, 0, err
+	}
+	// applied again in every phase, pods restarted by the rotation come up with the new
+	// password only
+	if err := r.addPassword(ctx, t, nodes, password, previous); err != nil {
+		status.Message = err.Error()
+		return status, retryInterval, nil
+	}
+
+	gracePeriod := t.Spec.GetGracePeriod()
+	switch status.Phase {
+	case commonapi.PasswordRotationAddingPassword:
+		end := metav1.NewTime(now.Add(gracePeriod))
+		status.Phase = commonapi.PasswordRotationGracePeriod
+		status.GracePeriodEnd = &end
+		status.Message = fmt.Sprintf("every pod accepts the new password, the previous password is accepted until %s", end.UTC().Format(time.RFC3339))
+		return status, gracePeriod, nil
+	case commonapi.PasswordRotationGracePeriod:
+		if status.GracePeriodEnd != nil {
+			if remaining := status.GracePeriodEnd.Sub(now.Time); remaining > 0 {
+				return status, remaining, nil
+			}
+		}
+	}
+
+	pending, err := r.pendingPods(ctx, t, nodes, status.StartTime.Time)
+	if err != nil {
+		return status, 0, err
+	}
+	if len(pending) > 0 {
+		// every pod accepted the password above, so every pod is ready to lose another one
+		pod := pending[0]
+		if err := r.K8sClient.CoreV1().Pods(t.Object.GetNamespace()).Delete(ctx, pod, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return status, 0, err
+		}
+		log.FromContext(ctx).Info("Restarted pod started with the previous password", "pod", pod)
+		r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonPasswordRotationPodRestarted, "Restarted pod %s started with the previous password", pod)
+		status.Phase = commonapi.PasswordRotationRestartingPods
+		status.PendingPods = pending
+		status.Message = fmt.Sprintf("restarting pod %s", pod)
+		return status, restartInterval, nil
+	}
+
+	status.Phase = commonapi.PasswordRotationRemovingPassword
+	status.PendingPods = nil
+	for _, node := range nodes {
+		if err := r.Passwords.ResetPassword(ctx, t.Object, node, password, previous); err != nil {
+			status.Message = fmt.Sprintf("failed to remove the previous password from pod %s: %s", node, err)
+			return status, retryInterval, nil
+		}
+	}
+	if err := r.updateState(ctx, stateSecret, password, nil); err != nil {
+		return status, 0, err
+	}
+	status.Phase = commonapi.PasswordRotationCompleted
+	status.CompletionTime = &now
+	status.Message = "the previous password is removed from every pod"
+	r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonPasswordRotationCompleted, "Rotated the password of secret %s", *t.Secret.Name)
+	return status, 0, nil
+}
+
+func (r *Rotator) addPassword(ctx context.Context, t Target, nodes []string, password string, previous []string) error {
+	for _, node := range nodes {
+		if err := r.Passwords.AddPassword(ctx, t.Object, node, password, previous); err != nil {
+			return fmt.Errorf("failed to add the new password to pod %s: %w", node, err)
+		}
+	}
+	if t.Sentinels != nil {
+		if err := t.Sentinels(ctx, password); err != nil {
+			return fmt.Errorf("failed to set the new password on the sentinels: %w", err)
+		}
+	}
+	return nil
+}
+
+// pendingPods returns the pods with a container started before the rotation, in the order they
+// are restarted: pods last in their statefulset first and primaries last. Containers started
+// between the change of the secret and the rotation are restarted as well, the time the secret
+// changed is not known.
+func (r *Rotator) pendingPods(ctx context.Context, t Target, nodes []string, start time.Time) ([]string, error) {
+	var pending, primaries []string
+	for i := len(nodes) - 1; i >= 0; i-- {
+		pod, err := r.K8sClient.CoreV1().Pods(t.Object.GetNamespace()).Get(ctx, nodes[i], metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !startedBefore(pod, start) {
+			continue
+		}
+		if slices.Contains(t.Primaries, pod.Name) {
+			primaries = append(primaries, pod.Name)
+		} else {
+			pending = append(pending, pod.Name)
+		}
+	}
+	return append(pending, primaries...), nil
+}
+
+// startedBefore counts containers started within the second the rotation started in, both times
+// are recorded with second precision
+func startedBefore(pod *corev1.Pod, start time.Time) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil && !status.State.Running.StartedAt.Time.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rotator) getPassword(ctx context.Context, namespace string, ref *commonapi.ExistingPasswordSecret) (string, error) {
+	secret, err := r.K8sClient.CoreV1().Secrets(namespace).Get(ctx, *ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	password, ok := secret.Data[*ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no password in key %s", *ref.Name, *ref.Key)
+	}
+	return strings.TrimSpace(string(password)), nil
+}
+
+func (r *Rotator) createState(ctx context.Context, owner client.Object, password string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      owner.GetName() + StateSecretSuffix,
+			Namespace: owner.GetNamespace(),
+		},
+		Data: map[string][]byte{passwordKey: []byte(password)},
+	}
+	if err := controllerutil.SetControllerReference(owner, secret, scheme.Scheme); err != nil {
+		return err
+	}
+	_, err := r.K8sClient.CoreV1().Secrets(owner.GetNamespace()).Create(ctx, secret, metav1.CreateOptions{})
+	return err
+}
+
+func (r *Rotator) updateState(ctx context.Context, secret *corev1.Secret, password string, previous []string) error {
+	secret.Data = map[string][]byte{passwordKey: []byte(password)}
+	if len(previous) > 0 {
+		data, err := json.Marshal(previous)
+		if err != nil {
+			return err
+		}
+		secret.Data[previousKey] = data
+	}
+	_, err := r.K8sClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+func readState(secret *corev1.Secret) (string, []string, error) {
+	var previous []string
+	if data, ok := secret.Data[previousKey]; ok {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			return "", nil, fmt.Errorf("secret %s has malformed previous passwords: %w", secret.Name, err)
+		}
+	}
+	return string(secret.Data[passwordKey]), previous, nil
+}
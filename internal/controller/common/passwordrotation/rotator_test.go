@@ -0,0 +1,233 @@
+package passwordrotation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+// fakePasswordManager keeps the accepted passwords of every pod in memory
+type fakePasswordManager struct {
+	passwords   map[string][]string
+	unreachable map[string]bool
+}
+
+func (f *fakePasswordManager) Nodes(target metav1.Object) ([]string, error) {
+	return k8sutils.GetRedisACLNodes(target)
+}
+
+func (f *fakePasswordManager) AddPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error {
+	if f.unreachable[podName] {
+		return errors.New("connection refused")
+	}
+	f.passwords[podName] = append([]string{password}, previous...)
+	return nil
+}
+
+func (f *fakePasswordManager) ResetPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error {
+	if f.unreachable[podName] {
+		return errors.New("connection refused")
+	}
+	f.passwords[podName] = []string{password}
+	return nil
+}
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newPod(name string, startedAt time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "redis",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+			}},
+		},
+	}
+}
+
+type rotationTest struct {
+	rotator     *Rotator
+	passwords   *fakePasswordManager
+	now         time.Time
+	replication *rrvb2.RedisReplication
+}
+
+func newRotationTest(t *testing.T) *rotationTest {
+	t.Helper()
+	require.NoError(t, rrvb2.AddToScheme(scheme.Scheme))
+	rt := &rotationTest{
+		passwords: &fakePasswordManager{passwords: map[string][]string{}, unreachable: map[string]bool{}},
+		now:       start,
+		replication: &rrvb2.RedisReplication{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rrvb2.GroupVersion.String(), Kind: "RedisReplication"},
+			ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "default", UID: "uid"},
+			Spec: rrvb2.RedisReplicationSpec{
+				Size: ptr.To(int32(2)),
+				KubernetesConfig: commonapi.KubernetesConfig{
+					ExistingPasswordSecret: &commonapi.ExistingPasswordSecret{Name: ptr.To("redis-secret"), Key: ptr.To("password")},
+				},
+			},
+		},
+	}
+	rt.rotator = &Rotator{
+		K8sClient: k8sfake.NewSimpleClientset(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "redis-secret", Namespace: "default"},
+				Data:       map[string][]byte{"password": []byte("old\n")},
+			},
+			newPod("replication-0", start.Add(-time.Hour)),
+			newPod("replication-1", start.Add(-time.Hour)),
+		),
+		Passwords: rt.passwords,
+		Recorder:  record.NewFakeRecorder(10),
+		Now:       func() time.Time { return rt.now },
+	}
+	return rt
+}
+
+func (rt *rotationTest) setPassword(t *testing.T, password string) {
+	t.Helper()
+	secrets := rt.rotator.K8sClient.CoreV1().Secrets("default")
+	secret, err := secrets.Get(context.Background(), "redis-secret", metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Data["password"] = []byte(password)
+	_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func (rt *rotationTest) reconcile(t *testing.T) time.Duration {
+	t.Helper()
+	status, requeueAfter, err := rt.rotator.Reconcile(context.Background(), Target{
+		Object:    rt.replication,
+		Secret:    rt.replication.Spec.KubernetesConfig.ExistingPasswordSecret,
+		Spec:      rt.replication.Spec.PasswordRotation,
+		Status:    rt.replication.Status.PasswordRotation,
+		Primaries: []string{"replication-1"},
+	})
+	require.NoError(t, err)
+	rt.replication.Status.PasswordRotation = status
+	return requeueAfter
+}
+
+func (rt *rotationTest) state(t *testing.T) *corev1.Secret {
+	t.Helper()
+	secret, err := rt.rotator.K8sClient.CoreV1().Secrets("default").Get(context.Background(), "replication"+StateSecretSuffix, metav1.GetOptions{})
+	require.NoError(t, err)
+	return secret
+}
+
+func TestReconcileWithoutChange(t *testing.T) {
+	rt := newRotationTest(t)
+
+	assert.Zero(t, rt.reconcile(t))
+	assert.Nil(t, rt.replication.Status.PasswordRotation)
+	state := rt.state(t)
+	assert.Equal(t, map[string][]byte{passwordKey: []byte("old")}, state.Data)
+	require.Len(t, state.OwnerReferences, 1)
+	assert.Equal(t, "replication", state.OwnerReferences[0].Name)
+
+	assert.Zero(t, rt.reconcile(t))
+	assert.Nil(t, rt.replication.Status.PasswordRotation)
+	assert.Empty(t, rt.passwords.passwords)
+}
+
+func TestReconcileRotatesPassword(t *testing.T) {
+	rt := newRotationTest(t)
+	rt.reconcile(t)
+
+	// both passwords are accepted for the grace period
+	rt.setPassword(t, "new")
+	assert.Equal(t, commonapi.DefaultPasswordRotationGracePeriod, rt.reconcile(t))
+	status := rt.replication.Status.PasswordRotation
+	assert.Equal(t, commonapi.PasswordRotationGracePeriod, status.Phase)
+	assert.Equal(t, start, status.StartTime.Time)
+	assert.Equal(t, start.Add(commonapi.DefaultPasswordRotationGracePeriod), status.GracePeriodEnd.Time)
+	assert.Equal(t, []string{"new", "old"}, rt.passwords.passwords["replication-0"])
+	assert.Equal(t, []string{"new", "old"}, rt.passwords.passwords["replication-1"])
+	assert.Equal(t, `["old"]`, string(rt.state(t).Data[previousKey]))
+
+	rt.now = start.Add(time.Minute)
+	assert.Equal(t, 4*time.Minute, rt.reconcile(t))
+	assert.Equal(t, commonapi.PasswordRotationGracePeriod, rt.replication.Status.PasswordRotation.Phase)
+
+	// replicas are restarted before the master, one at a time
+	rt.now = start.Add(10 * time.Minute)
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	status = rt.replication.Status.PasswordRotation
+	assert.Equal(t, commonapi.PasswordRotationRestartingPods, status.Phase)
+	assert.Equal(t, []string{"replication-0", "replication-1"}, status.PendingPods)
+	_, err := rt.rotator.K8sClient.CoreV1().Pods("default").Get(context.Background(), "replication-0", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	_, err = rt.rotator.K8sClient.CoreV1().Pods("default").Create(context.Background(), newPod("replication-0", rt.now), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	assert.Equal(t, []string{"replication-1"}, rt.replication.Status.PasswordRotation.PendingPods)
+
+	_, err = rt.rotator.K8sClient.CoreV1().Pods("default").Create(context.Background(), newPod("replication-1", rt.now), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, rt.reconcile(t))
+	status = rt.replication.Status.PasswordRotation
+	assert.Equal(t, commonapi.PasswordRotationCompleted, status.Phase)
+	assert.Empty(t, status.PendingPods)
+	assert.Equal(t, rt.now, status.CompletionTime.Time)
+	assert.Equal(t, []string{"new"}, rt.passwords.passwords["replication-0"])
+	assert.Equal(t, []string{"new"}, rt.passwords.passwords["replication-1"])
+	assert.Equal(t, map[string][]byte{passwordKey: []byte("new")}, rt.state(t).Data)
+
+	// a completed rotation is left as is
+	assert.Zero(t, rt.reconcile(t))
+	assert.Equal(t, commonapi.PasswordRotationCompleted, rt.replication.Status.PasswordRotation.Phase)
+}
+
+func TestReconcileWaitsForEveryPod(t *testing.T) {
+	rt := newRotationTest(t)
+	rt.reconcile(t)
+
+	rt.passwords.unreachable["replication-1"] = true
+	rt.setPassword(t, "new")
+	assert.Equal(t, retryInterval, rt.reconcile(t))
+	status := rt.replication.Status.PasswordRotation
+	assert.True(t, Rotating(status))
+	assert.Equal(t, "failed to add the new password to pod replication-1: connection refused", status.Message)
+
+	rt.passwords.unreachable["replication-1"] = false
+	rt.now = start.Add(time.Minute)
+	rt.reconcile(t)
+	status = rt.replication.Status.PasswordRotation
+	assert.False(t, Rotating(status))
+	// the grace period starts once every pod accepts the new password
+	assert.Equal(t, start, status.StartTime.Time)
+	assert.Equal(t, rt.now.Add(commonapi.DefaultPasswordRotationGracePeriod), status.GracePeriodEnd.Time)
+}
+
+func TestReconcileKeepsPasswordsChangedMidRotation(t *testing.T) {
+	rt := newRotationTest(t)
+	rt.reconcile(t)
+
+	rt.setPassword(t, "new")
+	rt.reconcile(t)
+	rt.setPassword(t, "newer")
+	rt.now = start.Add(time.Minute)
+	rt.reconcile(t)
+
+	assert.Equal(t, commonapi.PasswordRotationGracePeriod, rt.replication.Status.PasswordRotation.Phase)
+	assert.Equal(t, rt.now, rt.replication.Status.PasswordRotation.StartTime.Time)
+	assert.Equal(t, []string{"newer", "old", "new"}, rt.passwords.passwords["replication-0"])
+	assert.Equal(t, `["old","new"]`, string(rt.state(t).Data[previousKey]))
+}
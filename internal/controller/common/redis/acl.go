@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ACLManager manages ACL users on the pods of a RedisCluster, RedisReplication or Redis
+type ACLManager interface {
+	// Nodes returns every pod of the target a user has to be applied on
+	Nodes(target metav1.Object) ([]string, error)
+	SetUser(ctx context.Context, target metav1.Object, podName, username string, rules []string) error
+	// DeleteUser returns an error wrapping k8sutils.ErrRedisPodNotFound when the pod does not exist
+	DeleteUser(ctx context.Context, target metav1.Object, podName, username string) error
+	// Save persists the users of the pod and reports whether redis is configured with an ACL file
+	Save(ctx context.Context, target metav1.Object, podName string) (bool, error)
+}
+
+type aclManager struct {
+	k8s kubernetes.Interface
+}
+
+func NewACLManager(clientset kubernetes.Interface) ACLManager {
+	return &aclManager{
+		k8s: clientset,
+	}
+}
+
+func (m *aclManager) Nodes(target metav1.Object) ([]string, error) {
+	return k8sutils.GetRedisACLNodes(target)
+}
+
+func (m *aclManager) SetUser(ctx context.Context, target metav1.Object, podName, username string, rules []string) error {
+	return k8sutils.SetRedisACLUser(ctx, m.k8s, target, podName, username, rules)
+}
+
+func (m *aclManager) DeleteUser(ctx context.Context, target metav1.Object, podName, username string) error {
+	return k8sutils.DeleteRedisACLUser(ctx, m.k8s, target, podName, username)
+}
+
+func (m *aclManager) Save(ctx context.Context, target metav1.Object, podName string) (bool, error) {
+	return k8sutils.SaveRedisACL(ctx, m.k8s, target, podName)
+}
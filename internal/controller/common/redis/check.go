@@ -4,21 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	rr "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type Checker interface {
 	GetMasterFromReplication(ctx context.Context, rr *rr.RedisReplication) (corev1.Pod, error)
 	GetPassword(ctx context.Context, ns string, secret *commonapi.ExistingPasswordSecret) (string, error)
 	CheckClusterSlotsAssigned(ctx context.Context, cr *rcvb2.RedisCluster) (bool, error)
+	// GetSentinelMasters returns the master groups monitored by the sentinels as seen by the
+	// running ones
+	GetSentinelMasters(ctx context.Context, rs *rsvb2.RedisSentinel) ([]rsvb2.SentinelMasterStatus, error)
+	// GetSentinelConnections returns the connection info of the running sentinels
+	GetSentinelConnections(ctx context.Context, rs *rsvb2.RedisSentinel) ([]*redis.ConnectionInfo, error)
 }
 
 type checker struct {
@@ -128,3 +136,109 @@ func (c *checker) CheckClusterSlotsAssigned(ctx context.Context, cr *rcvb2.Redis
 
 	return allAssigned, nil
 }
+
+func (c *checker) GetSentinelMasters(ctx context.Context, rs *rsvb2.RedisSentinel) ([]rsvb2.SentinelMasterStatus, error) {
+	if rs.Spec.RedisSentinelConfig == nil {
+		return nil, nil
+	}
+	connInfos, err := c.GetSentinelConnections(ctx, rs)
+	if err != nil {
+		return nil, err
+	}
+	sentinels := make([]redis.Service, 0, len(connInfos))
+	for _, connInfo := range connInfos {
+		sentinels = append(sentinels, c.redis.Connect(connInfo))
+	}
+	var groups []string
+	for _, master := range rs.Spec.GetMasters() {
+		groups = append(groups, master.MasterGroupName)
+	}
+	return sentinelMasters(ctx, sentinels, groups), nil
+}
+
+func (c *checker) GetSentinelConnections(ctx context.Context, rs *rsvb2.RedisSentinel) ([]*redis.ConnectionInfo, error) {
+	pods, err := getSentinelPods(ctx, c.k8s, rs)
+	if err != nil {
+		return nil, err
+	}
+	password, err := c.GetPassword(ctx, rs.Namespace, rs.Spec.KubernetesConfig.ExistingPasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	var connInfos []*redis.ConnectionInfo
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.PodIP == "" {
+			continue
+		}
+		connInfos = append(connInfos, createConnectionInfo(ctx, pod, password, rs.Spec.TLS, c.k8s, rs.Namespace, "26379"))
+	}
+	return connInfos, nil
+}
+
+// sentinelMasters returns the status of every master group from the INFO sentinel of the
+// sentinels. The address of a master is the one most sentinels agree on, the sentinel reporting
+// it first is asked for SENTINEL CKQUORUM. A sentinel which does not answer agrees on nothing.
+func sentinelMasters(ctx context.Context, sentinels []redis.Service, groups []string) []rsvb2.SentinelMasterStatus {
+	infos := make([]*redis.InfoSentinelResult, len(sentinels))
+	for i, sentinel := range sentinels {
+		info, err := sentinel.GetInfoSentinel(ctx)
+		if err != nil {
+			log.FromContext(ctx).V(1).Error(err, "Failed to get the info of the sentinel")
+			continue
+		}
+		infos[i] = info
+	}
+
+	statuses := make([]rsvb2.SentinelMasterStatus, 0, len(groups))
+	for _, group := range groups {
+		votes := map[string]int{}
+		masters := map[string]redis.SentinelMasterInfo{}
+		reporters := map[string]redis.Service{}
+		for i, info := range infos {
+			if info == nil {
+				continue
+			}
+			for _, master := range info.Masters {
+				if master.Name != group {
+					continue
+				}
+				votes[master.Address]++
+				if _, ok := masters[master.Address]; !ok {
+					masters[master.Address] = master
+					reporters[master.Address] = sentinels[i]
+				}
+			}
+		}
+		addresses := make([]string, 0, len(votes))
+		for address := range votes {
+			addresses = append(addresses, address)
+		}
+		sort.Slice(addresses, func(i, j int) bool {
+			if votes[addresses[i]] != votes[addresses[j]] {
+				return votes[addresses[i]] > votes[addresses[j]]
+			}
+			return addresses[i] < addresses[j]
+		})
+
+		status := rsvb2.SentinelMasterStatus{Name: group}
+		if len(addresses) == 0 {
+			status.CKQuorum = "no running sentinel monitors the master group"
+			statuses = append(statuses, status)
+			continue
+		}
+		master := masters[addresses[0]]
+		status.Address = master.Address
+		status.Status = master.Status
+		status.Sentinels = int32(votes[master.Address])
+		status.KnownSentinels = int32(master.Sentinels)
+		status.Replicas = int32(master.Slaves)
+		reachable, message, err := reporters[master.Address].SentinelCKQuorum(ctx, group)
+		if err != nil {
+			message = fmt.Sprintf("failed to check the quorum: %v", err)
+		}
+		status.QuorumReachable = reachable
+		status.CKQuorum = message
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSentinelService struct {
+	redisservice.Service
+	masters   []redisservice.SentinelMasterInfo
+	infoErr   error
+	reachable bool
+	ckquorum  string
+}
+
+func (f *fakeSentinelService) GetInfoSentinel(context.Context) (*redisservice.InfoSentinelResult, error) {
+	if f.infoErr != nil {
+		return nil, f.infoErr
+	}
+	return &redisservice.InfoSentinelResult{Masters: f.masters}, nil
+}
+
+func (f *fakeSentinelService) SentinelCKQuorum(context.Context, string) (bool, string, error) {
+	return f.reachable, f.ckquorum, nil
+}
+
+func TestSentinelMasters(t *testing.T) {
+	current := redisservice.SentinelMasterInfo{Name: "mymaster", Status: "ok", Address: "10.0.0.2:6379", Slaves: 2, Sentinels: 3}
+	stale := redisservice.SentinelMasterInfo{Name: "mymaster", Status: "ok", Address: "10.0.0.1:6379", Slaves: 2, Sentinels: 3}
+	sentinels := []redisservice.Service{
+		&fakeSentinelService{masters: []redisservice.SentinelMasterInfo{current}, reachable: true, ckquorum: "OK 3 usable Sentinels. Quorum and failover authorization can be reached"},
+		// a sentinel which did not see the failover yet
+		&fakeSentinelService{masters: []redisservice.SentinelMasterInfo{stale}},
+		&fakeSentinelService{masters: []redisservice.SentinelMasterInfo{current}},
+		&fakeSentinelService{infoErr: errors.New("connection refused")},
+	}
+
+	assert.Equal(t, []rsvb2.SentinelMasterStatus{
+		{
+			Name:            "mymaster",
+			Address:         "10.0.0.2:6379",
+			Status:          "ok",
+			Sentinels:       2,
+			KnownSentinels:  3,
+			Replicas:        2,
+			QuorumReachable: true,
+			CKQuorum:        "OK 3 usable Sentinels. Quorum and failover authorization can be reached",
+		},
+		{
+			Name:     "other",
+			CKQuorum: "no running sentinel monitors the master group",
+		},
+	}, sentinelMasters(context.Background(), sentinels, []string{"mymaster", "other"}))
+}
@@ -23,11 +23,12 @@ import (
 )
 
 type Healer interface {
-	SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, master string) error
+	// SentinelMonitor points every sentinel at the master of the master group
+	SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster, master string) error
 	// SentinelSet set the config for specific master
 	// reference: https://redis.io/docs/latest/operate/oss_and_stack/management/sentinel/#reconfiguring-sentinel-at-runtime
-	SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, master string) error
-	SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel) error
+	SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster, master string) error
+	SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster) error
 
 	// UpdatePodRoleLabel connect to all redis pods and update pod role label `redis-role` to `master` or `slave` according to their role.
 	UpdateRedisRoleLabel(ctx context.Context, ns string, labels map[string]string, secret *commonapi.ExistingPasswordSecret, tlsConfig *commonapi.TLSConfig) error
@@ -100,8 +101,8 @@ func (h *healer) UpdateRedisRoleLabel(ctx context.Context, ns string, labels map
 	return nil
 }
 
-func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, master string) error {
-	pods, err := h.getSentinelPods(ctx, rs)
+func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster, master string) error {
+	pods, err := getSentinelPods(ctx, h.k8s, rs)
 	if err != nil {
 		return err
 	}
@@ -109,18 +110,24 @@ func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, maste
 	if err != nil {
 		return err
 	}
+	// auth-pass follows a rotated replication password, SENTINEL MONITOR only sets it once
+	masterPass, err := h.getMasterPassword(ctx, rs, group)
+	if err != nil {
+		return err
+	}
 	for _, pod := range pods.Items {
 		connInfo := createConnectionInfo(ctx, pod, sentinelPass, rs.Spec.TLS, h.k8s, rs.Namespace, "26379")
 
 		for k, v := range map[string]string{
-			"down-after-milliseconds": rs.Spec.RedisSentinelConfig.DownAfterMilliseconds,
-			"parallel-syncs":          rs.Spec.RedisSentinelConfig.ParallelSyncs,
-			"failover-timeout":        rs.Spec.RedisSentinelConfig.FailoverTimeout,
+			"down-after-milliseconds": group.DownAfterMilliseconds,
+			"parallel-syncs":          group.ParallelSyncs,
+			"failover-timeout":        group.FailoverTimeout,
+			"auth-pass":               masterPass,
 		} {
 			if v == "" {
 				continue
 			}
-			err = h.redis.Connect(connInfo).SentinelSet(ctx, rs.Spec.RedisSentinelConfig.MasterGroupName, k, v)
+			err = h.redis.Connect(connInfo).SentinelSet(ctx, group.MasterGroupName, k, v)
 			if err != nil {
 				return err
 			}
@@ -130,8 +137,8 @@ func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, maste
 }
 
 // SentinelReset range all sentinel execute `sentinel reset *`
-func (h *healer) SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel) error {
-	pods, err := h.getSentinelPods(ctx, rs)
+func (h *healer) SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster) error {
+	pods, err := getSentinelPods(ctx, h.k8s, rs)
 	if err != nil {
 		return err
 	}
@@ -144,7 +151,7 @@ func (h *healer) SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel) err
 	for _, pod := range pods.Items {
 		connInfo := createConnectionInfo(ctx, pod, sentinelPass, rs.Spec.TLS, h.k8s, rs.Namespace, "26379")
 
-		err = h.redis.Connect(connInfo).SentinelReset(ctx, rs.Spec.RedisSentinelConfig.MasterGroupName)
+		err = h.redis.Connect(connInfo).SentinelReset(ctx, group.MasterGroupName)
 		if err != nil {
 			return err
 		}
@@ -153,8 +160,8 @@ func (h *healer) SentinelReset(ctx context.Context, rs *rsvb2.RedisSentinel) err
 }
 
 // SentinelMonitor range all sentinel execute `sentinel monitor`
-func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, master string) error {
-	pods, err := h.getSentinelPods(ctx, rs)
+func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster, master string) error {
+	pods, err := getSentinelPods(ctx, h.k8s, rs)
 	if err != nil {
 		return err
 	}
@@ -164,30 +171,28 @@ func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, m
 		return err
 	}
 
-	var masterPass string
-	if rs.Spec.RedisSentinelConfig.RedisReplicationPassword != nil && rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef != nil {
-		masterPass, err = NewChecker(h.k8s).GetPassword(ctx, rs.Namespace, &commonapi.ExistingPasswordSecret{
-			Name: &rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef.Name,
-			Key:  &rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef.Key,
-		})
-		if err != nil {
-			return err
-		}
+	masterPass, err := h.getMasterPassword(ctx, rs, group)
+	if err != nil {
+		return err
 	}
 
+	masterPort := group.RedisPort
+	if masterPort == "" {
+		masterPort = "6379"
+	}
 	for _, pod := range pods.Items {
 		connInfo := createConnectionInfo(ctx, pod, sentinelPass, rs.Spec.TLS, h.k8s, rs.Namespace, "26379")
 
 		masterConnInfo := &redis.ConnectionInfo{
 			Host:     master,
-			Port:     "6379",
+			Port:     masterPort,
 			Password: masterPass,
 		}
 		err = h.redis.Connect(connInfo).SentinelMonitor(
 			ctx,
 			masterConnInfo,
-			rs.Spec.RedisSentinelConfig.MasterGroupName,
-			rs.Spec.RedisSentinelConfig.Quorum,
+			group.MasterGroupName,
+			group.Quorum,
 		)
 		if err != nil {
 			return err
@@ -197,8 +202,20 @@ func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, m
 	return nil
 }
 
-func (h *healer) getSentinelPods(ctx context.Context, rs *rsvb2.RedisSentinel) (*v1.PodList, error) {
-	sentinelSTS, err := h.k8s.AppsV1().StatefulSets(rs.Namespace).Get(ctx, rs.GetStatefulSetName(), metav1.GetOptions{})
+// getMasterPassword returns the password of the replication of the master group, empty when it
+// has none
+func (h *healer) getMasterPassword(ctx context.Context, rs *rsvb2.RedisSentinel, group rsvb2.SentinelMaster) (string, error) {
+	if group.RedisReplicationPassword == nil || group.RedisReplicationPassword.SecretKeyRef == nil {
+		return "", nil
+	}
+	return NewChecker(h.k8s).GetPassword(ctx, rs.Namespace, &commonapi.ExistingPasswordSecret{
+		Name: &group.RedisReplicationPassword.SecretKeyRef.Name,
+		Key:  &group.RedisReplicationPassword.SecretKeyRef.Key,
+	})
+}
+
+func getSentinelPods(ctx context.Context, k8s kubernetes.Interface, rs *rsvb2.RedisSentinel) (*v1.PodList, error) {
+	sentinelSTS, err := k8s.AppsV1().StatefulSets(rs.Namespace).Get(ctx, rs.GetStatefulSetName(), metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +224,7 @@ func (h *healer) getSentinelPods(ctx context.Context, rs *rsvb2.RedisSentinel) (
 	for k, v := range sentinelSTS.Spec.Selector.MatchLabels {
 		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
 	}
-	pods, err := h.k8s.CoreV1().Pods(rs.Namespace).List(ctx, metav1.ListOptions{
+	pods, err := k8s.CoreV1().Pods(rs.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: strings.Join(labels, ","),
 	})
 	if err != nil {
@@ -89,6 +89,22 @@ func (f *fakeRedisService) GetInfoSentinel(context.Context) (*redisservice.InfoS
 	return &redisservice.InfoSentinelResult{}, nil
 }
 
+func (f *fakeRedisService) SentinelCKQuorum(context.Context, string) (bool, string, error) {
+	return true, "", nil
+}
+
+func (f *fakeRedisService) SentinelFailover(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeRedisService) SentinelGetMasterAddrByName(context.Context, string) (string, string, error) {
+	return "", "", nil
+}
+
+func (f *fakeRedisService) SentinelReplicaPriorities(context.Context, string) (map[string]int, error) {
+	return nil, nil
+}
+
 func (f *fakeRedisService) GetClusterInfo(context.Context) (*redisservice.ClusterStatus, error) {
 	return &redisservice.ClusterStatus{}, nil
 }
@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PasswordManager switches the password of the default user on the pods of a RedisCluster,
+// RedisReplication or Redis. The pod is logged in with the first of password and previous it
+// accepts.
+type PasswordManager interface {
+	// Nodes returns every pod of the target the password has to be applied on
+	Nodes(target metav1.Object) ([]string, error)
+	// AddPassword makes the pod accept password next to the previous passwords
+	AddPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error
+	// ResetPassword makes the pod accept password only
+	ResetPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error
+}
+
+type passwordManager struct {
+	k8s kubernetes.Interface
+}
+
+func NewPasswordManager(clientset kubernetes.Interface) PasswordManager {
+	return &passwordManager{
+		k8s: clientset,
+	}
+}
+
+func (m *passwordManager) Nodes(target metav1.Object) ([]string, error) {
+	return k8sutils.GetRedisACLNodes(target)
+}
+
+func (m *passwordManager) AddPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error {
+	return k8sutils.AddRedisPassword(ctx, m.k8s, target, podName, password, previous)
+}
+
+func (m *passwordManager) ResetPassword(ctx context.Context, target metav1.Object, podName, password string, previous []string) error {
+	return k8sutils.ResetRedisPassword(ctx, m.k8s, target, podName, password, previous)
+}
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Snapshotter takes RDB snapshots of the pods of a RedisCluster, RedisReplication or Redis
+type Snapshotter interface {
+	// Nodes returns the pods a snapshot has to be taken on to back up the whole target
+	Nodes(ctx context.Context, target metav1.Object) ([]k8sutils.RedisBackupNode, error)
+	// TriggerBGSave starts a background save and returns the request, which tells when it ended
+	TriggerBGSave(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveRequest, error)
+	BGSaveStatus(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveStatus, error)
+	// StreamRDB copies the RDB file of the pod into w and returns the number of bytes written
+	StreamRDB(ctx context.Context, target metav1.Object, podName string, w io.Writer) (int64, error)
+}
+
+type snapshotter struct {
+	k8s kubernetes.Interface
+}
+
+func NewSnapshotter(clientset kubernetes.Interface) Snapshotter {
+	return &snapshotter{
+		k8s: clientset,
+	}
+}
+
+func (s *snapshotter) Nodes(ctx context.Context, target metav1.Object) ([]k8sutils.RedisBackupNode, error) {
+	switch cr := target.(type) {
+	case *rcvb2.RedisCluster:
+		return k8sutils.GetRedisClusterBackupNodes(ctx, s.k8s, cr)
+	case *rrvb2.RedisReplication:
+		return k8sutils.GetRedisReplicationBackupNodes(ctx, s.k8s, cr)
+	case *rvb2.Redis:
+		return k8sutils.GetRedisStandaloneBackupNodes(ctx, s.k8s, cr)
+	default:
+		return nil, fmt.Errorf("unsupported backup target %T", target)
+	}
+}
+
+func (s *snapshotter) TriggerBGSave(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveRequest, error) {
+	return k8sutils.TriggerRedisBGSave(ctx, s.k8s, target, podName)
+}
+
+func (s *snapshotter) BGSaveStatus(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveStatus, error) {
+	return k8sutils.GetRedisBGSaveStatus(ctx, s.k8s, target, podName)
+}
+
+func (s *snapshotter) StreamRDB(ctx context.Context, target metav1.Object, podName string, w io.Writer) (int64, error) {
+	return k8sutils.StreamRedisRDB(ctx, s.k8s, target, podName, w)
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"context"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetRestore returns the RedisRestore that seeds the given target, or nil when there is none.
+// Failed restores are ignored, so the target is deployed empty once its restore was rejected.
+// When several restores reference the target the oldest one wins.
+func GetRestore(ctx context.Context, cl client.Client, kind, namespace, name string) (*rbvb2.RedisRestore, error) {
+	restores := &rbvb2.RedisRestoreList{}
+	if err := cl.List(ctx, restores, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var found *rbvb2.RedisRestore
+	for i := range restores.Items {
+		restore := &restores.Items[i]
+		if restore.Spec.Target.Kind != kind || restore.Spec.Target.Name != name {
+			continue
+		}
+		if restore.Status.State == rbvb2.RedisRestoreFailed || restore.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if found == nil || restore.CreationTimestamp.Before(&found.CreationTimestamp) {
+			found = restore
+		}
+	}
+	return found, nil
+}
@@ -4,9 +4,11 @@ import (
 	"sync"
 
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 )
@@ -33,6 +35,8 @@ func SetupV1beta2Scheme() {
 		rcvb2.AddToScheme,
 		rrvb2.AddToScheme,
 		rsvb2.AddToScheme,
+		rbvb2.AddToScheme,
+		ruvb2.AddToScheme,
 	}
 	mustAddSchemeOnce(&oncev1beta2, schemes)
 }
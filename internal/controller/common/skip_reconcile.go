@@ -4,19 +4,25 @@ import (
 	"context"
 
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
-	RedisClusterSkipReconcileAnnotation     = "rediscluster.opstreelabs.in/skip-reconcile"
-	RedisSkipReconcileAnnotation            = "redis.opstreelabs.in/skip-reconcile"
-	RedisReplicationSkipReconcileAnnotation = "redisreplication.opstreelabs.in/skip-reconcile"
-	RedisSentinelSkipReconcileAnnotation    = "redissentinel.opstreelabs.in/skip-reconcile"
+	RedisClusterSkipReconcileAnnotation        = "rediscluster.opstreelabs.in/skip-reconcile"
+	RedisSkipReconcileAnnotation               = "redis.opstreelabs.in/skip-reconcile"
+	RedisReplicationSkipReconcileAnnotation    = "redisreplication.opstreelabs.in/skip-reconcile"
+	RedisSentinelSkipReconcileAnnotation       = "redissentinel.opstreelabs.in/skip-reconcile"
+	RedisBackupSkipReconcileAnnotation         = "redisbackup.opstreelabs.in/skip-reconcile"
+	RedisBackupScheduleSkipReconcileAnnotation = "redisbackupschedule.opstreelabs.in/skip-reconcile"
+	RedisRestoreSkipReconcileAnnotation        = "redisrestore.opstreelabs.in/skip-reconcile"
+	RedisUserSkipReconcileAnnotation           = "redisuser.opstreelabs.in/skip-reconcile"
 )
 
 func ShouldSkipReconcile(ctx context.Context, obj metav1.Object) (skip bool) {
@@ -50,6 +56,22 @@ func ShouldSkipReconcile(ctx context.Context, obj metav1.Object) (skip bool) {
 		if value, found := annotations[RedisSentinelSkipReconcileAnnotation]; found && value == "true" {
 			return true
 		}
+	case *rbvb2.RedisBackup:
+		if value, found := annotations[RedisBackupSkipReconcileAnnotation]; found && value == "true" {
+			return true
+		}
+	case *rbvb2.RedisBackupSchedule:
+		if value, found := annotations[RedisBackupScheduleSkipReconcileAnnotation]; found && value == "true" {
+			return true
+		}
+	case *rbvb2.RedisRestore:
+		if value, found := annotations[RedisRestoreSkipReconcileAnnotation]; found && value == "true" {
+			return true
+		}
+	case *ruvb2.RedisUser:
+		if value, found := annotations[RedisUserSkipReconcileAnnotation]; found && value == "true" {
+			return true
+		}
 	}
 	return false
 }
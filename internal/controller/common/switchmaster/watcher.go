@@ -0,0 +1,246 @@
+package switchmaster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	rediscli "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// channel is published by the sentinels once they switched the master of a group
+const channel = "+switch-master"
+
+// Switch is a +switch-master message, the master group and the addresses of its old and new master
+type Switch struct {
+	MasterGroupName string
+	OldMaster       string
+	NewMaster       string
+}
+
+// parseSwitch parses the payload of a +switch-master message:
+// <master-name> <old-ip> <old-port> <new-ip> <new-port>
+func parseSwitch(payload string) (Switch, error) {
+	fields := strings.Fields(payload)
+	if len(fields) != 5 {
+		return Switch{}, fmt.Errorf("unexpected %s message %q", channel, payload)
+	}
+	return Switch{
+		MasterGroupName: fields[0],
+		OldMaster:       net.JoinHostPort(fields[1], fields[2]),
+		NewMaster:       net.JoinHostPort(fields[3], fields[4]),
+	}, nil
+}
+
+// Subscription is the sentinels of a RedisReplication or RedisSentinel and the master groups they
+// monitor
+type Subscription struct {
+	Sentinels []*redis.ConnectionInfo
+	// Replications maps the master groups to the RedisReplications of their masters, in the
+	// namespace of the subscriber
+	Replications map[string]string
+}
+
+type subscription struct {
+	Subscription
+	namespace string
+	cancel    context.CancelFunc
+	// last is the last switch of every master group, every sentinel publishes it
+	last map[string]Switch
+}
+
+// Watcher subscribes to the +switch-master channel of the sentinels, so that the RedisReplication
+// whose master the sentinels switched is reconciled right away rather than on its next periodic
+// reconcile. The switch is recorded as an event of the RedisReplication.
+type Watcher struct {
+	Client    client.Reader
+	K8sClient kubernetes.Interface
+	Recorder  record.EventRecorder
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	sources       []chan event.GenericEvent
+}
+
+func NewWatcher(cl client.Reader, clientset kubernetes.Interface, recorder record.EventRecorder) *Watcher {
+	return &Watcher{
+		Client:        cl,
+		K8sClient:     clientset,
+		Recorder:      recorder,
+		subscriptions: map[string]*subscription{},
+	}
+}
+
+// Source returns a channel receiving the RedisReplication of every switched master, to be watched
+// through a source.Channel. Every controller needs its own.
+func (w *Watcher) Source() <-chan event.GenericEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	source := make(chan event.GenericEvent, 16)
+	w.sources = append(w.sources, source)
+	return source
+}
+
+// Watch subscribes to the sentinels of the subscriber, replacing its previous subscription unless
+// it is to the same sentinels and master groups
+func (w *Watcher) Watch(ctx context.Context, subscriber client.Object, sub Subscription) {
+	key := subscriberKey(subscriber)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if existing, ok := w.subscriptions[key]; ok {
+		if sameSubscription(existing.Subscription, sub) {
+			return
+		}
+		existing.cancel()
+	}
+
+	// the subscription outlives the reconcile it is made in
+	subCtx, cancel := context.WithCancel(log.IntoContext(context.Background(), log.FromContext(ctx)))
+	s := &subscription{
+		Subscription: sub,
+		namespace:    subscriber.GetNamespace(),
+		cancel:       cancel,
+		last:         map[string]Switch{},
+	}
+	w.subscriptions[key] = s
+	for _, sentinel := range sub.Sentinels {
+		go w.subscribe(subCtx, s, sentinel)
+	}
+	log.FromContext(ctx).V(1).Info("Subscribed to the master switches of the sentinels", "Sentinels", len(sub.Sentinels))
+}
+
+// Forget unsubscribes from the sentinels of the subscriber
+func (w *Watcher) Forget(subscriber client.Object) {
+	key := subscriberKey(subscriber)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if existing, ok := w.subscriptions[key]; ok {
+		existing.cancel()
+		delete(w.subscriptions, key)
+	}
+}
+
+func (w *Watcher) subscribe(ctx context.Context, s *subscription, sentinel *redis.ConnectionInfo) {
+	redisClient := rediscli.NewClient(&rediscli.Options{
+		Addr:      sentinel.GetAddress(),
+		Password:  sentinel.Password,
+		TLSConfig: sentinel.TLSConfig,
+	})
+	defer redisClient.Close()
+	pubsub := redisClient.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	// the channel reconnects and subscribes again after a connection failure until it is closed
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			sw, err := parseSwitch(msg.Payload)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Ignoring the message of the sentinel", "Sentinel", sentinel.GetAddress())
+				continue
+			}
+			w.handle(ctx, s, sw)
+		}
+	}
+}
+
+// handle records the switch on the RedisReplication of the master group and sends it to the
+// sources, once for all the sentinels publishing it
+func (w *Watcher) handle(ctx context.Context, s *subscription, sw Switch) {
+	name, ok := s.Replications[sw.MasterGroupName]
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	if s.last[sw.MasterGroupName] == sw {
+		w.mu.Unlock()
+		return
+	}
+	s.last[sw.MasterGroupName] = sw
+	sources := slices.Clone(w.sources)
+	w.mu.Unlock()
+
+	logger := log.FromContext(ctx).WithValues("MasterGroup", sw.MasterGroupName, "RedisReplication", name)
+	logger.Info("The sentinels switched the master", "OldMaster", sw.OldMaster, "NewMaster", sw.NewMaster)
+	rr := &rrvb2.RedisReplication{}
+	if err := w.Client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: name}, rr); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get the RedisReplication of the switched master")
+		}
+		return
+	}
+	w.Recorder.Eventf(rr, corev1.EventTypeNormal, events.EventReasonSentinelSwitchMaster,
+		"Sentinels switched the master of group %s from %s to %s",
+		sw.MasterGroupName, w.describeMaster(ctx, rr, sw.OldMaster), w.describeMaster(ctx, rr, sw.NewMaster))
+	for _, source := range sources {
+		select {
+		case source <- event.GenericEvent{Object: rr}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// describeMaster names the pod of the replication at the address, if any
+func (w *Watcher) describeMaster(ctx context.Context, rr *rrvb2.RedisReplication, address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	pods, err := w.K8sClient.CoreV1().Pods(rr.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=" + rr.RedisStatefulSet(),
+	})
+	if err != nil {
+		return address
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == host || strings.HasPrefix(host, pod.Name+".") {
+			return fmt.Sprintf("%s (%s)", pod.Name, address)
+		}
+	}
+	return address
+}
+
+func subscriberKey(subscriber client.Object) string {
+	return fmt.Sprintf("%T/%s/%s", subscriber, subscriber.GetNamespace(), subscriber.GetName())
+}
+
+// sameSubscription compares the addresses and passwords of the sentinels, the TLS configs are
+// built anew on every reconcile
+func sameSubscription(a, b Subscription) bool {
+	if len(a.Sentinels) != len(b.Sentinels) || len(a.Replications) != len(b.Replications) {
+		return false
+	}
+	for i := range a.Sentinels {
+		if a.Sentinels[i].GetAddress() != b.Sentinels[i].GetAddress() || a.Sentinels[i].Password != b.Sentinels[i].Password {
+			return false
+		}
+	}
+	for group, name := range a.Replications {
+		if b.Replications[group] != name {
+			return false
+		}
+	}
+	return true
+}
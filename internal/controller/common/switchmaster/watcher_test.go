@@ -0,0 +1,187 @@
+package switchmaster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// fakeSentinel speaks just enough RESP for a client to subscribe to a channel and receive the
+// messages published to it
+type fakeSentinel struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	subscribers []net.Conn
+	subscribed  chan struct{}
+}
+
+func newFakeSentinel(t *testing.T) *fakeSentinel {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSentinel{listener: listener, subscribed: make(chan struct{}, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSentinel) connectionInfo() *redis.ConnectionInfo {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return &redis.ConnectionInfo{Host: host, Port: port}
+}
+
+func (s *fakeSentinel) serve(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		switch strings.ToLower(args[0]) {
+		case "hello":
+			io.WriteString(conn, "-ERR unknown command 'HELLO'\r\n")
+		case "subscribe":
+			for i, name := range args[1:] {
+				io.WriteString(conn, fmt.Sprintf("*3\r\n%s%s:%d\r\n", bulk("subscribe"), bulk(name), i+1))
+			}
+			s.subscribers = append(s.subscribers, conn)
+			s.subscribed <- struct{}{}
+		case "ping":
+			io.WriteString(conn, "*2\r\n"+bulk("pong")+bulk(""))
+		default:
+			io.WriteString(conn, "+OK\r\n")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *fakeSentinel) publish(payload string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.subscribers {
+		io.WriteString(conn, "*3\r\n"+bulk("message")+bulk(channel)+bulk(payload))
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func TestParseSwitch(t *testing.T) {
+	sw, err := parseSwitch("mymaster 10.0.0.1 6379 10.0.0.2 6379")
+	require.NoError(t, err)
+	assert.Equal(t, Switch{MasterGroupName: "mymaster", OldMaster: "10.0.0.1:6379", NewMaster: "10.0.0.2:6379"}, sw)
+
+	_, err = parseSwitch("mymaster 10.0.0.1 6379")
+	assert.Error(t, err)
+}
+
+func TestWatcher(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+	rr := &rrvb2.RedisReplication{ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "default"}}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rr).Build()
+	clientset := k8sfake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "replication-0", Namespace: "default", Labels: map[string]string{"app": "replication"}},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "replication-1", Namespace: "default", Labels: map[string]string{"app": "replication"}},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.2"},
+		},
+	)
+	recorder := record.NewFakeRecorder(10)
+	w := NewWatcher(cl, clientset, recorder)
+	source := w.Source()
+
+	sentinels := []*fakeSentinel{newFakeSentinel(t), newFakeSentinel(t)}
+	sub := Subscription{Replications: map[string]string{"mymaster": "replication"}}
+	for _, sentinel := range sentinels {
+		sub.Sentinels = append(sub.Sentinels, sentinel.connectionInfo())
+	}
+	w.Watch(context.Background(), rr, sub)
+	t.Cleanup(func() { w.Forget(rr) })
+	for _, sentinel := range sentinels {
+		select {
+		case <-sentinel.subscribed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the watcher did not subscribe to the sentinel")
+		}
+	}
+	// the same subscription is kept
+	w.Watch(context.Background(), rr, sub)
+
+	// every sentinel publishes the switch, it is handled once
+	for _, sentinel := range sentinels {
+		sentinel.publish("othermaster 10.0.1.1 6379 10.0.1.2 6379")
+		sentinel.publish("mymaster 10.0.0.1 6379 10.0.0.2 6379")
+	}
+	var received event.GenericEvent
+	select {
+	case received = <-source:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the switch did not reach the source")
+	}
+	assert.Equal(t, "replication", received.Object.GetName())
+	assert.Equal(t, "Normal SentinelSwitchMaster Sentinels switched the master of group mymaster from replication-0 (10.0.0.1:6379) to replication-1 (10.0.0.2:6379)", <-recorder.Events)
+
+	select {
+	case <-source:
+		t.Fatal("the switch was handled twice")
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.Empty(t, recorder.Events)
+}
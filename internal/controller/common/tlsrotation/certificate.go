@@ -0,0 +1,88 @@
+package tlsrotation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CertificateGVK is the cert-manager Certificate, it is handled as unstructured so the operator
+// runs without cert-manager installed as long as no issuerRef is set
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// EnsureCertificate creates or updates the cert-manager Certificate issuing the TLS secret of the
+// owner for dnsNames. The Certificate is named after the secret and owned by the owner.
+func (r *Rotator) EnsureCertificate(ctx context.Context, owner client.Object, tls *commonapi.TLSConfig, dnsNames []string) error {
+	if tls == nil || tls.IssuerRef == nil {
+		return nil
+	}
+	if tls.Secret.SecretName == "" {
+		return fmt.Errorf("TLS secret name is required to issue a certificate from %s", tls.IssuerRef.Name)
+	}
+	desired := generateCertificate(owner, tls, dnsNames)
+	if err := controllerutil.SetControllerReference(owner, desired, scheme.Scheme); err != nil {
+		return err
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(CertificateGVK)
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if apierrors.IsNotFound(err) {
+		log.FromContext(ctx).Info("Creating certificate", "Certificate", desired.GetName(), "Issuer", tls.IssuerRef.Name)
+		return r.Client.Create(ctx, desired)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get certificate %s, is cert-manager installed: %w", desired.GetName(), err)
+	}
+	if reflect.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	// a changed list of names is issued again by cert-manager, the pods are rolled once the
+	// secret changes
+	log.FromContext(ctx).Info("Updating certificate", "Certificate", desired.GetName())
+	existing.Object["spec"] = desired.Object["spec"]
+	return r.Client.Update(ctx, existing)
+}
+
+func generateCertificate(owner client.Object, tls *commonapi.TLSConfig, dnsNames []string) *unstructured.Unstructured {
+	kind := tls.IssuerRef.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	group := tls.IssuerRef.Group
+	if group == "" {
+		group = CertificateGVK.Group
+	}
+	names := make([]interface{}, 0, len(dnsNames))
+	for _, name := range dnsNames {
+		names = append(names, name)
+	}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretName": tls.Secret.SecretName,
+			"dnsNames":   names,
+			"issuerRef": map[string]interface{}{
+				"name":  tls.IssuerRef.Name,
+				"kind":  kind,
+				"group": group,
+			},
+			// the cluster bus and replication connect with the certificate as client
+			"usages": []interface{}{"server auth", "client auth", "digital signature", "key encipherment"},
+		},
+	}}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	certificate.SetName(tls.Secret.SecretName)
+	certificate.SetNamespace(owner.GetNamespace())
+	certificate.SetLabels(owner.GetLabels())
+	return certificate
+}
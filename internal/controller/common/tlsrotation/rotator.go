@@ -0,0 +1,200 @@
+package tlsrotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const restartInterval = 10 * time.Second
+
+// Target is a Redis, RedisCluster, RedisReplication or RedisSentinel whose pods load the
+// certificate of the TLS secret
+type Target struct {
+	Object client.Object
+	TLS    *commonapi.TLSConfig
+	Status *commonapi.TLSRotationStatus
+	// Nodes are the pods of the target in the order they are restarted
+	Nodes []string
+	// Primaries are restarted after every other pod
+	Primaries []string
+	// Failover moves the primary role away from the pod before it is restarted, it reports false
+	// when the pod is no primary or there is nothing to fail over to. It is optional.
+	Failover func(ctx context.Context, podName string) (bool, error)
+}
+
+// Rotator issues the TLS certificates of the targets from cert-manager and rolls a changed TLS
+// secret out to their pods. Redis loads the certificate at start, so pods started before the
+// change are restarted one at a time, waiting for every pod to be ready in between, with the
+// primaries moved away first.
+type Rotator struct {
+	Client    client.Client
+	K8sClient kubernetes.Interface
+	Recorder  record.EventRecorder
+	// Now is overridden in tests
+	Now func() time.Time
+}
+
+func NewRotator(cl client.Client, clientset kubernetes.Interface, recorder record.EventRecorder) *Rotator {
+	return &Rotator{
+		Client:    cl,
+		K8sClient: clientset,
+		Recorder:  recorder,
+		Now:       time.Now,
+	}
+}
+
+// Rotating reports whether pods still run with the previous certificate
+func Rotating(status *commonapi.TLSRotationStatus) bool {
+	return status != nil && status.StartTime != nil && status.CompletionTime == nil
+}
+
+// Reconcile restarts at most one pod of the target. It returns the status to record and when to
+// reconcile again, zero when no rollout is in progress.
+func (r *Rotator) Reconcile(ctx context.Context, t Target) (*commonapi.TLSRotationStatus, time.Duration, error) {
+	status := t.Status.DeepCopy()
+	if t.TLS == nil || t.TLS.Secret.SecretName == "" {
+		return status, 0, nil
+	}
+	secret, err := r.K8sClient.CoreV1().Secrets(t.Object.GetNamespace()).Get(ctx, t.TLS.Secret.SecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// not issued yet, the secret is watched
+		return status, 0, nil
+	}
+	if err != nil {
+		return status, 0, err
+	}
+
+	hash := secretHash(secret)
+	if status == nil || status.SecretHash == "" {
+		// the pods were started with the secret or before it was tracked
+		return &commonapi.TLSRotationStatus{SecretHash: hash}, 0, nil
+	}
+	now := metav1.NewTime(r.Now())
+	if status.SecretHash != hash {
+		status = &commonapi.TLSRotationStatus{SecretHash: hash, StartTime: &now}
+		r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonTLSRotationStarted, "Rolling out the certificate of secret %s", t.TLS.Secret.SecretName)
+	}
+	if !Rotating(status) {
+		return status, 0, nil
+	}
+
+	pods, err := r.getPods(ctx, t)
+	if err != nil {
+		return status, 0, err
+	}
+	status.PendingPods = pendingPods(t, pods, status.StartTime.Time)
+	if len(status.PendingPods) == 0 {
+		status.CompletionTime = &now
+		r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonTLSRotationCompleted, "Rolled out the certificate of secret %s", t.TLS.Secret.SecretName)
+		return status, 0, nil
+	}
+	for _, node := range t.Nodes {
+		if pod, ok := pods[node]; !ok || !podReady(pod) {
+			log.FromContext(ctx).V(1).Info("Waiting for pod to be ready before restarting the next one", "pod", node)
+			return status, restartInterval, nil
+		}
+	}
+
+	pod := status.PendingPods[0]
+	if t.Failover != nil {
+		moved, err := t.Failover(ctx, pod)
+		if err != nil {
+			return status, 0, err
+		}
+		if moved {
+			r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonTLSRotationFailover, "Failed over pod %s before restarting it", pod)
+			return status, restartInterval, nil
+		}
+	}
+	if err := r.K8sClient.CoreV1().Pods(t.Object.GetNamespace()).Delete(ctx, pod, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return status, 0, err
+	}
+	log.FromContext(ctx).Info("Restarted pod started with the previous certificate", "pod", pod)
+	r.Recorder.Eventf(t.Object, corev1.EventTypeNormal, events.EventReasonTLSRotationPodRestarted, "Restarted pod %s started with the previous certificate", pod)
+	return status, restartInterval, nil
+}
+
+func (r *Rotator) getPods(ctx context.Context, t Target) (map[string]*corev1.Pod, error) {
+	pods := make(map[string]*corev1.Pod, len(t.Nodes))
+	for _, node := range t.Nodes {
+		pod, err := r.K8sClient.CoreV1().Pods(t.Object.GetNamespace()).Get(ctx, node, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pods[node] = pod
+	}
+	return pods, nil
+}
+
+// pendingPods returns the pods with a container started before the rollout in the order they are
+// restarted, primaries last
+func pendingPods(t Target, pods map[string]*corev1.Pod, start time.Time) []string {
+	var pending, primaries []string
+	for _, node := range t.Nodes {
+		pod, ok := pods[node]
+		if !ok || !startedBefore(pod, start) {
+			continue
+		}
+		if slices.Contains(t.Primaries, node) {
+			primaries = append(primaries, node)
+		} else {
+			pending = append(pending, node)
+		}
+	}
+	return append(pending, primaries...)
+}
+
+// startedBefore counts containers started within the second the rollout started in, both times
+// are recorded with second precision
+func startedBefore(pod *corev1.Pod, start time.Time) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil && !status.State.Running.StartedAt.Time.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// secretHash identifies the content of the secret, the keys are hashed in order
+func secretHash(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%d:", key, len(secret.Data[key]))
+		h.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,211 @@
+package tlsrotation
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newPod(name string, startedAt time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "redis",
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(startedAt)}},
+			}},
+		},
+	}
+}
+
+type rotationTest struct {
+	rotator   *Rotator
+	now       time.Time
+	cluster   *rcvb2.RedisCluster
+	primaries []string
+	failovers []string
+}
+
+func newRotationTest(t *testing.T) *rotationTest {
+	t.Helper()
+	require.NoError(t, rcvb2.AddToScheme(scheme.Scheme))
+	rt := &rotationTest{
+		now:       start,
+		primaries: []string{"cluster-leader-0"},
+		cluster: &rcvb2.RedisCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rcvb2.GroupVersion.String(), Kind: "RedisCluster"},
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default", UID: "uid", Labels: map[string]string{"app": "cluster"}},
+			Spec: rcvb2.RedisClusterSpec{
+				TLS: &commonapi.TLSConfig{
+					Secret:    corev1.SecretVolumeSource{SecretName: "cluster-tls"},
+					IssuerRef: &commonapi.CertificateIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"},
+				},
+			},
+		},
+	}
+	rt.rotator = &Rotator{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		K8sClient: k8sfake.NewSimpleClientset(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-tls", Namespace: "default"},
+				Data:       map[string][]byte{"tls.crt": []byte("old")},
+			},
+			newPod("cluster-follower-0", start.Add(-time.Hour)),
+			newPod("cluster-leader-0", start.Add(-time.Hour)),
+		),
+		Recorder: record.NewFakeRecorder(10),
+		Now:      func() time.Time { return rt.now },
+	}
+	return rt
+}
+
+func (rt *rotationTest) setCertificate(t *testing.T, certificate string) {
+	t.Helper()
+	secrets := rt.rotator.K8sClient.CoreV1().Secrets("default")
+	secret, err := secrets.Get(context.Background(), "cluster-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	secret.Data["tls.crt"] = []byte(certificate)
+	_, err = secrets.Update(context.Background(), secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func (rt *rotationTest) reconcile(t *testing.T) time.Duration {
+	t.Helper()
+	status, requeueAfter, err := rt.rotator.Reconcile(context.Background(), Target{
+		Object:    rt.cluster,
+		TLS:       rt.cluster.Spec.TLS,
+		Status:    rt.cluster.Status.TLSRotation,
+		Nodes:     []string{"cluster-follower-0", "cluster-leader-0"},
+		Primaries: rt.primaries,
+		Failover: func(ctx context.Context, podName string) (bool, error) {
+			if !slices.Contains(rt.primaries, podName) {
+				return false, nil
+			}
+			rt.failovers = append(rt.failovers, podName)
+			rt.primaries = []string{"cluster-follower-0"}
+			return true, nil
+		},
+	})
+	require.NoError(t, err)
+	rt.cluster.Status.TLSRotation = status
+	return requeueAfter
+}
+
+func (rt *rotationTest) restart(t *testing.T, name string) {
+	t.Helper()
+	pods := rt.rotator.K8sClient.CoreV1().Pods("default")
+	_, err := pods.Get(context.Background(), name, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "pod %s was not deleted", name)
+	_, err = pods.Create(context.Background(), newPod(name, rt.now), metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func TestReconcileWithoutChange(t *testing.T) {
+	rt := newRotationTest(t)
+
+	assert.Zero(t, rt.reconcile(t))
+	status := rt.cluster.Status.TLSRotation
+	require.NotNil(t, status)
+	assert.NotEmpty(t, status.SecretHash)
+	assert.False(t, Rotating(status))
+
+	assert.Zero(t, rt.reconcile(t))
+	assert.Equal(t, status, rt.cluster.Status.TLSRotation)
+}
+
+func TestReconcileRollsPods(t *testing.T) {
+	rt := newRotationTest(t)
+	rt.reconcile(t)
+	previousHash := rt.cluster.Status.TLSRotation.SecretHash
+
+	// followers are restarted first
+	rt.setCertificate(t, "new")
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	status := rt.cluster.Status.TLSRotation
+	assert.True(t, Rotating(status))
+	assert.NotEqual(t, previousHash, status.SecretHash)
+	assert.Equal(t, start, status.StartTime.Time)
+	assert.Equal(t, []string{"cluster-follower-0", "cluster-leader-0"}, status.PendingPods)
+	rt.now = start.Add(10 * time.Second)
+	rt.restart(t, "cluster-follower-0")
+
+	// the leader is failed over to the restarted follower before its restart
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	assert.Equal(t, []string{"cluster-leader-0"}, rt.failovers)
+	assert.Equal(t, []string{"cluster-leader-0"}, rt.cluster.Status.TLSRotation.PendingPods)
+
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	rt.restart(t, "cluster-leader-0")
+
+	assert.Zero(t, rt.reconcile(t))
+	status = rt.cluster.Status.TLSRotation
+	assert.False(t, Rotating(status))
+	assert.Empty(t, status.PendingPods)
+	assert.Equal(t, rt.now, status.CompletionTime.Time)
+	assert.Equal(t, []string{"cluster-leader-0"}, rt.failovers)
+}
+
+func TestReconcileWaitsForReadyPods(t *testing.T) {
+	rt := newRotationTest(t)
+	rt.reconcile(t)
+
+	pods := rt.rotator.K8sClient.CoreV1().Pods("default")
+	pod, err := pods.Get(context.Background(), "cluster-leader-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	pod.Status.Conditions[0].Status = corev1.ConditionFalse
+	_, err = pods.Update(context.Background(), pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	rt.setCertificate(t, "new")
+	assert.Equal(t, restartInterval, rt.reconcile(t))
+	_, err = pods.Get(context.Background(), "cluster-follower-0", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestEnsureCertificate(t *testing.T) {
+	rt := newRotationTest(t)
+	ctx := context.Background()
+	dnsNames := []string{"cluster-leader-0.cluster-leader-headless.default.svc"}
+
+	require.NoError(t, rt.rotator.EnsureCertificate(ctx, rt.cluster, rt.cluster.Spec.TLS, dnsNames))
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	require.NoError(t, rt.rotator.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-tls"}, certificate))
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, "cluster-tls", secretName)
+	issuer, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "ca-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"}, issuer)
+	names, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, dnsNames, names)
+	require.Len(t, certificate.GetOwnerReferences(), 1)
+	assert.Equal(t, "cluster", certificate.GetOwnerReferences()[0].Name)
+
+	// scaling changes the names the certificate is issued for
+	dnsNames = append(dnsNames, "cluster-leader-1.cluster-leader-headless.default.svc")
+	require.NoError(t, rt.rotator.EnsureCertificate(ctx, rt.cluster, rt.cluster.Spec.TLS, dnsNames))
+	require.NoError(t, rt.rotator.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-tls"}, certificate))
+	names, _, _ = unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, dnsNames, names)
+
+	// without an issuer the secret is managed by the user
+	rt.cluster.Spec.TLS.IssuerRef = nil
+	assert.NoError(t, rt.rotator.EnsureCertificate(ctx, rt.cluster, rt.cluster.Spec.TLS, dnsNames))
+}
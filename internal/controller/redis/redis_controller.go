@@ -18,14 +18,19 @@ package redis
 
 import (
 	"context"
+	"reflect"
         "time"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,7 +45,10 @@ const (
 type Reconciler struct {
 	client.Client
 	k8sutils.StatefulSet
-	K8sClient kubernetes.Interface
+	K8sClient     kubernetes.Interface
+	Rotator       *passwordrotation.Rotator
+	TLSRotator    *tlsrotation.Rotator
+	SecretWatcher *intctrlutil.ResourceWatcher
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -62,6 +70,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err = k8sutils.AddFinalizer(ctx, instance, RedisFinalizer, r.Client); err != nil {
 		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
 	}
+	rotating, rotationRequeue, err := r.reconcilePassword(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to rotate password")
+	}
+	if rotating {
+		if err = r.reconcileConditions(ctx, instance); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to update redis conditions")
+		}
+		return intctrlutil.RequeueAfter(ctx, rotationRequeue, "waiting for redis to accept the new password")
+	}
+	if err = r.reconcileCertificate(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to issue TLS certificate")
+	}
 	err = k8sutils.CreateStandaloneRedis(ctx, instance, r.K8sClient)
 	if err != nil {
 		return intctrlutil.RequeueE(ctx, err, "failed to create redis")
@@ -73,6 +94,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	if len(instance.Spec.GetRedisDynamicConfig()) > 0 {
 		if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name) {
+			if err = r.reconcileConditions(ctx, instance); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to update redis conditions")
+			}
 			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis statefulset to be ready before applying dynamic config")
 		}
 		applied, err := k8sutils.SetRedisStandaloneDynamicConfig(ctx, r.K8sClient, instance)
@@ -83,9 +107,131 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis to become reachable to apply dynamic config")
 		}
 	}
+	tlsRequeue, err := r.reconcileTLSRotation(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to roll out TLS certificate")
+	}
+	if tlsRequeue > 0 && (rotationRequeue == 0 || tlsRequeue < rotationRequeue) {
+		rotationRequeue = tlsRequeue
+	}
+	if err = r.reconcileConditions(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update redis conditions")
+	}
+	if rotationRequeue > 0 {
+		return intctrlutil.RequeueAfter(ctx, rotationRequeue, "password or certificate rotation in progress")
+	}
 	return intctrlutil.Reconciled()
 }
 
+// reconcilePassword rolls a change of the password secret out to the pod. It reports whether the
+// pod may still reject the password of the secret, the rest of the reconcile logs in with it.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rvb2.Redis) (bool, time.Duration, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil || secret.Name == nil {
+		return false, 0, nil
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	status, requeueAfter, err := r.Rotator.Reconcile(ctx, passwordrotation.Target{
+		Object: instance,
+		Secret: secret,
+		Spec:   instance.Spec.PasswordRotation,
+		Status: instance.Status.PasswordRotation,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if !reflect.DeepEqual(status, instance.Status.PasswordRotation) {
+		copy := instance.DeepCopy()
+		copy.Spec = rvb2.RedisSpec{}
+		copy.Status.PasswordRotation = status
+		if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+			return false, 0, err
+		}
+		instance.ResourceVersion = copy.ResourceVersion
+		instance.Status.PasswordRotation = status
+	}
+	return passwordrotation.Rotating(status), requeueAfter, nil
+}
+
+// reconcileCertificate requests the certificate of the TLS secret from cert-manager when an
+// issuer is configured. The secret is watched either way, its changes are rolled out to the pod.
+func (r *Reconciler) reconcileCertificate(ctx context.Context, instance *rvb2.Redis) error {
+	tls := instance.Spec.TLS
+	if tls == nil || tls.Secret.SecretName == "" {
+		return nil
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: tls.Secret.SecretName},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+	dnsNames, err := k8sutils.GetRedisCertificateDNSNames(instance)
+	if err != nil {
+		return err
+	}
+	return r.TLSRotator.EnsureCertificate(ctx, instance, tls, dnsNames)
+}
+
+// reconcileTLSRotation restarts the pod when it was started with a previous certificate
+func (r *Reconciler) reconcileTLSRotation(ctx context.Context, instance *rvb2.Redis) (time.Duration, error) {
+	nodes, err := k8sutils.GetRedisTLSNodes(instance)
+	if err != nil {
+		return 0, err
+	}
+	status, requeueAfter, err := r.TLSRotator.Reconcile(ctx, tlsrotation.Target{
+		Object: instance,
+		TLS:    instance.Spec.TLS,
+		Status: instance.Status.TLSRotation,
+		Nodes:  nodes,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !reflect.DeepEqual(status, instance.Status.TLSRotation) {
+		copy := instance.DeepCopy()
+		copy.Spec = rvb2.RedisSpec{}
+		copy.Status.TLSRotation = status
+		if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+			return 0, err
+		}
+		instance.ResourceVersion = copy.ResourceVersion
+		instance.Status.TLSRotation = status
+	}
+	return requeueAfter, nil
+}
+
+// reconcileConditions records the conditions of the standalone pod, it has no replica to scale or
+// fail over to
+func (r *Reconciler) reconcileConditions(ctx context.Context, instance *rvb2.Redis) error {
+	var o common.Observation
+	if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name) {
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonPodsNotReady, Message: "waiting for the redis pod to be ready"}
+		o.Progressing = o.NotReady
+	}
+	if rotation := common.RotationProgress(instance.Status.PasswordRotation, instance.Status.TLSRotation); rotation != nil {
+		o.Progressing = rotation
+	}
+
+	copy := instance.DeepCopy()
+	copy.Spec = rvb2.RedisSpec{}
+	copy.Status.ObservedGeneration = instance.Generation
+	common.SetConditions(&copy.Status.Conditions, instance.Generation, o)
+	if reflect.DeepEqual(copy.Status, instance.Status) {
+		return nil
+	}
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.ResourceVersion = copy.ResourceVersion
+	instance.Status = copy.Status
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 //
 // Unlike RedisCluster, RedisReplication, and RedisSentinel controllers, the Redis standalone
@@ -93,12 +239,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 // continuously monitor cluster topology, replication health, slot distribution, and sentinel
 // readiness — state that can change independently of Kubernetes resource events. The standalone
 // controller only creates a StatefulSet and a Service with no ongoing distributed state to poll,
-// so a timed requeue is unnecessary.
+// so a timed requeue is unnecessary. Only a password or certificate rotation requeues until it
+// completes.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rvb2.Redis{}).
 		WithOptions(opts).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
+		Watches(&corev1.Secret{}, r.SecretWatcher).
 		Complete(r)
 }
@@ -23,6 +23,9 @@ import (
 	"time"
 
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -95,9 +98,12 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        k8sManager.GetClient(),
+		K8sClient:     k8sClient,
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		Rotator:       passwordrotation.NewRotator(k8sClient, k8sManager.GetEventRecorderFor("redis-controller")),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
+		TLSRotator:    tlsrotation.NewRotator(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("redis-controller")),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
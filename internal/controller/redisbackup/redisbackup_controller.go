@@ -0,0 +1,493 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	RedisBackupFinalizer = "redisBackupFinalizer"
+	// bgsavePollInterval is how often the persistence state of shards with a running save is checked
+	bgsavePollInterval = 5 * time.Second
+	// targetPendingInterval is how long to wait before retrying when the target is not ready yet
+	targetPendingInterval = 30 * time.Second
+)
+
+// Reconciler reconciles a RedisBackup object
+type Reconciler struct {
+	client.Client
+	K8sClient   kubernetes.Interface
+	Snapshotter redis.Snapshotter
+	Recorder    record.EventRecorder
+	NewStorage  func(kubernetes.Interface, *rbvb2.RedisBackup, podTemplate) (Storage, error)
+
+	streams shardStreams
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &rbvb2.RedisBackup{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisBackup instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return r.finalize(ctx, instance)
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+	if instance.Spec.DeletionPolicy == rbvb2.BackupDeletionPolicyDelete {
+		if err := k8sutils.AddFinalizer(ctx, instance, RedisBackupFinalizer, r.Client); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
+		}
+	}
+	if instance.IsFinished() {
+		return intctrlutil.Reconciled()
+	}
+
+	if err := instance.Spec.Storage.Validate(); err != nil {
+		return r.fail(ctx, instance, nil, err.Error())
+	}
+
+	target, tmpl, err := r.getTarget(ctx, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.pending(ctx, instance, fmt.Sprintf("%s %s not found", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to get backup target")
+	}
+
+	if len(instance.Status.Shards) == 0 {
+		nodes, err := r.Snapshotter.Nodes(ctx, target)
+		if err != nil {
+			log.FromContext(ctx).Info("backup target is not ready", "error", err.Error())
+			return r.pending(ctx, instance, fmt.Sprintf("waiting for %s %s: %s", instance.Spec.Target.Kind, instance.Spec.Target.Name, err))
+		}
+		return r.start(ctx, instance, nodes)
+	}
+
+	storage, err := r.newStorage(instance, tmpl)
+	if err != nil {
+		return r.fail(ctx, instance, nil, err.Error())
+	}
+	ready, err := storage.Prepare(ctx)
+	if err != nil {
+		return r.fail(ctx, instance, storage, fmt.Sprintf("failed to prepare backup storage: %s", err))
+	}
+	if !ready {
+		return intctrlutil.RequeueAfter(ctx, bgsavePollInterval, "waiting for backup storage to be ready")
+	}
+
+	status := instance.Status.DeepCopy()
+	waiting := r.snapshotShards(ctx, instance, target, storage, status.Shards)
+	status.Progress, status.Size = shardProgress(status.Shards)
+
+	if !waiting {
+		now := metav1.Now()
+		status.CompletionTime = &now
+		status.State = rbvb2.RedisBackupCompleted
+		status.Reason = "all shards were backed up"
+		eventType, reason := corev1.EventTypeNormal, events.EventReasonRedisBackupCompleted
+		if failed := failedShards(status.Shards); failed > 0 {
+			status.State = rbvb2.RedisBackupFailed
+			status.Reason = fmt.Sprintf("%d of %d shards failed", failed, len(status.Shards))
+			eventType, reason = corev1.EventTypeWarning, events.EventReasonRedisBackupFailed
+		}
+		if err := storage.Cleanup(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "failed to clean up backup storage")
+		}
+		r.streams.forget(streamKey(instance, ""))
+		r.Recorder.Event(instance, eventType, reason, fmt.Sprintf("Redis backup finished: %s, %s stored", status.Reason, status.Progress))
+	}
+
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackup status")
+	}
+	if waiting {
+		return intctrlutil.RequeueAfter(ctx, bgsavePollInterval, "waiting for background saves to finish")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// snapshotShards advances every unfinished shard as far as possible and reports whether any
+// shard still has to be waited for. Saves are triggered on all pending shards before any is
+// streamed, so the shards of a cluster are snapshotted as close in time as possible. The
+// snapshots are streamed in the background and their results recorded by a later reconcile.
+func (r *Reconciler) snapshotShards(ctx context.Context, instance *rbvb2.RedisBackup, target metav1.Object, storage Storage, shards []rbvb2.RedisBackupShard) bool {
+	for i := range shards {
+		shard := &shards[i]
+		if shard.State != rbvb2.RedisBackupShardPending {
+			continue
+		}
+		request, err := r.Snapshotter.TriggerBGSave(ctx, target, shard.PodName)
+		if errors.Is(err, k8sutils.ErrBGSaveInProgress) {
+			// the running save predates the backup, another one is requested once it ended
+			shard.Message = err.Error()
+			continue
+		}
+		if err != nil {
+			shard.State = rbvb2.RedisBackupShardFailed
+			shard.Message = err.Error()
+			continue
+		}
+		shard.State = rbvb2.RedisBackupShardSaving
+		shard.Message = ""
+		shard.SaveRequestTime = &metav1.Time{Time: request.Time}
+		shard.SavedBeforeRequest = &rbvb2.RedisBackupSaveState{
+			Saves:        request.Before.Saves,
+			LastSaveTime: &metav1.Time{Time: time.Unix(request.Before.LastSaveTime, 0)},
+			LastStatus:   request.Before.LastStatus,
+		}
+	}
+
+	waiting := false
+	for i := range shards {
+		shard := &shards[i]
+		if shard.State == rbvb2.RedisBackupShardPending {
+			waiting = true
+			continue
+		}
+		if shard.State != rbvb2.RedisBackupShardSaving {
+			continue
+		}
+		key := streamKey(instance, shard.PodName)
+		if stream, started := r.streams.poll(key); started {
+			if stream == nil {
+				waiting = true
+				continue
+			}
+			if stream.err != nil {
+				shard.State = rbvb2.RedisBackupShardFailed
+				shard.Message = stream.err.Error()
+				continue
+			}
+			shard.State = rbvb2.RedisBackupShardCompleted
+			shard.Size = stream.size
+			shard.Message = ""
+			log.FromContext(ctx).Info("Redis shard backed up", "pod", shard.PodName, "location", shard.Location, "size", stream.size)
+			continue
+		}
+		saveStatus, err := r.Snapshotter.BGSaveStatus(ctx, target, shard.PodName)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "failed to get background save status", "pod", shard.PodName)
+			waiting = true
+			continue
+		}
+		if !bgsaveRequest(shard).Done(saveStatus) {
+			// the save is running, or was scheduled behind an AOF rewrite and has not started yet
+			waiting = true
+			continue
+		}
+		if saveStatus.LastStatus != "ok" {
+			shard.State = rbvb2.RedisBackupShardFailed
+			shard.Message = fmt.Sprintf("background save finished with status %q", saveStatus.LastStatus)
+			continue
+		}
+
+		// the stream outlives the reconcile, it is bounded by its own timeout
+		streamCtx := log.IntoContext(context.Background(), log.FromContext(ctx))
+		podName, location := shard.PodName, shard.Location
+		r.streams.start(key, func() (int64, error) {
+			return r.streamShard(streamCtx, target, storage, podName, location)
+		})
+		shard.Message = "streaming the snapshot to the storage"
+		waiting = true
+	}
+	return waiting
+}
+
+func (r *Reconciler) streamShard(ctx context.Context, target metav1.Object, storage Storage, podName, location string) (int64, error) {
+	w, err := storage.Writer(ctx, location)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", location, err)
+	}
+	size, err := r.Snapshotter.StreamRDB(ctx, target, podName, w)
+	if closeErr := w.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", location, closeErr)
+	}
+	return size, err
+}
+
+// bgsaveRequest returns the background save requested for the shard
+func bgsaveRequest(shard *rbvb2.RedisBackupShard) k8sutils.BGSaveRequest {
+	request := k8sutils.BGSaveRequest{}
+	if shard.SaveRequestTime != nil {
+		request.Time = shard.SaveRequestTime.Time
+	}
+	if before := shard.SavedBeforeRequest; before != nil {
+		request.Before.Saves = before.Saves
+		request.Before.LastStatus = before.LastStatus
+		if before.LastSaveTime != nil {
+			request.Before.LastSaveTime = before.LastSaveTime.Unix()
+		}
+	}
+	return request
+}
+
+// streamKey identifies the stream of the snapshot of the pod taken by the backup
+func streamKey(instance *rbvb2.RedisBackup, podName string) string {
+	return string(instance.UID) + "/" + podName
+}
+
+func (r *Reconciler) start(ctx context.Context, instance *rbvb2.RedisBackup, nodes []k8sutils.RedisBackupNode) (ctrl.Result, error) {
+	now := metav1.Now()
+	status := rbvb2.RedisBackupStatus{
+		State:     rbvb2.RedisBackupRunning,
+		Reason:    fmt.Sprintf("backing up %d shards", len(nodes)),
+		StartTime: &now,
+	}
+	for _, node := range nodes {
+		status.Shards = append(status.Shards, rbvb2.RedisBackupShard{
+			PodName:  node.PodName,
+			NodeID:   node.NodeID,
+			Slots:    node.Slots,
+			State:    rbvb2.RedisBackupShardPending,
+			Location: instance.ShardLocation(node.PodName),
+		})
+	}
+	status.Progress, status.Size = shardProgress(status.Shards)
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackup status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupStarted, fmt.Sprintf("Redis backup of %s %s started", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+	return intctrlutil.Requeue()
+}
+
+func (r *Reconciler) pending(ctx context.Context, instance *rbvb2.RedisBackup, reason string) (ctrl.Result, error) {
+	status := instance.Status.DeepCopy()
+	status.State = rbvb2.RedisBackupPending
+	status.Reason = reason
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackup status")
+	}
+	return intctrlutil.RequeueAfter(ctx, targetPendingInterval, reason)
+}
+
+func (r *Reconciler) fail(ctx context.Context, instance *rbvb2.RedisBackup, storage Storage, reason string) (ctrl.Result, error) {
+	r.streams.forget(streamKey(instance, ""))
+	if storage != nil {
+		if err := storage.Cleanup(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "failed to clean up backup storage")
+		}
+	}
+	now := metav1.Now()
+	status := instance.Status.DeepCopy()
+	status.State = rbvb2.RedisBackupFailed
+	status.Reason = reason
+	status.CompletionTime = &now
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackup status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupFailed, reason)
+	return intctrlutil.Reconciled()
+}
+
+// finalize removes the stored snapshots of a backup with the Delete deletion policy before the
+// finalizer is released
+func (r *Reconciler) finalize(ctx context.Context, instance *rbvb2.RedisBackup) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, RedisBackupFinalizer) {
+		return intctrlutil.Reconciled()
+	}
+	if instance.Spec.DeletionPolicy == rbvb2.BackupDeletionPolicyDelete {
+		done, err := r.deleteSnapshots(ctx, instance)
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to delete backup snapshots")
+		}
+		if !done {
+			return intctrlutil.RequeueAfter(ctx, bgsavePollInterval, "waiting for backup storage to be ready")
+		}
+	}
+	controllerutil.RemoveFinalizer(instance, RedisBackupFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to remove finalizer")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// deleteSnapshots removes every snapshot the backup wrote, it returns false while the storage
+// is not ready to delete them yet
+func (r *Reconciler) deleteSnapshots(ctx context.Context, instance *rbvb2.RedisBackup) (bool, error) {
+	var locations []string
+	for _, shard := range instance.Status.Shards {
+		// failed shards may have left a partially written snapshot behind
+		if shard.Location != "" && (shard.State == rbvb2.RedisBackupShardCompleted || shard.State == rbvb2.RedisBackupShardFailed) {
+			locations = append(locations, shard.Location)
+		}
+	}
+	if len(locations) == 0 || instance.Spec.Storage.Validate() != nil {
+		return true, nil
+	}
+
+	_, tmpl, err := r.getTarget(ctx, instance)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		if instance.Spec.Storage.PersistentVolumeClaim != nil {
+			// the writer pod is created from the image of the target, without it the claim
+			// cannot be mounted
+			r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupRetained,
+				fmt.Sprintf("Snapshots were not deleted, %s %s no longer exists", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+			return true, nil
+		}
+	}
+
+	storage, err := r.newStorage(instance, tmpl)
+	if err != nil {
+		return false, err
+	}
+	ready, err := storage.Prepare(ctx)
+	if err != nil || !ready {
+		return false, err
+	}
+	for _, location := range locations {
+		if err := storage.Delete(ctx, location); err != nil {
+			return false, fmt.Errorf("failed to delete %s: %w", location, err)
+		}
+	}
+	if err := storage.Cleanup(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "failed to clean up backup storage")
+	}
+	log.FromContext(ctx).Info("Redis backup snapshots deleted", "count", len(locations))
+	return true, nil
+}
+
+// getTarget fetches the object referenced by the backup along with the pod settings the
+// storage writer pod inherits from it
+func (r *Reconciler) getTarget(ctx context.Context, instance *rbvb2.RedisBackup) (metav1.Object, podTemplate, error) {
+	key := client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.Target.Name}
+	switch instance.Spec.Target.Kind {
+	case rbvb2.BackupTargetRedisCluster:
+		cr := &rcvb2.RedisCluster{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, podTemplate{}, err
+		}
+		return cr, newPodTemplate(cr.Spec.KubernetesConfig.Image, cr.Spec.KubernetesConfig.ImagePullPolicy, cr.Spec.KubernetesConfig.ImagePullSecrets, cr.Spec.PodSecurityContext), nil
+	case rbvb2.BackupTargetRedisReplication:
+		cr := &rrvb2.RedisReplication{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, podTemplate{}, err
+		}
+		return cr, newPodTemplate(cr.Spec.KubernetesConfig.Image, cr.Spec.KubernetesConfig.ImagePullPolicy, cr.Spec.KubernetesConfig.ImagePullSecrets, cr.Spec.PodSecurityContext), nil
+	case rbvb2.BackupTargetRedis:
+		cr := &rvb2.Redis{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, podTemplate{}, err
+		}
+		return cr, newPodTemplate(cr.Spec.KubernetesConfig.Image, cr.Spec.KubernetesConfig.ImagePullPolicy, cr.Spec.KubernetesConfig.ImagePullSecrets, cr.Spec.PodSecurityContext), nil
+	default:
+		return nil, podTemplate{}, fmt.Errorf("unsupported backup target kind %q", instance.Spec.Target.Kind)
+	}
+}
+
+func newPodTemplate(image string, pullPolicy corev1.PullPolicy, pullSecrets *[]corev1.LocalObjectReference, securityContext *corev1.PodSecurityContext) podTemplate {
+	return podTemplate{
+		image:              image,
+		imagePullPolicy:    pullPolicy,
+		imagePullSecrets:   pullSecrets,
+		podSecurityContext: securityContext,
+	}
+}
+
+func (r *Reconciler) newStorage(instance *rbvb2.RedisBackup, tmpl podTemplate) (Storage, error) {
+	if r.NewStorage != nil {
+		return r.NewStorage(r.K8sClient, instance, tmpl)
+	}
+	return newStorage(r.K8sClient, instance, tmpl)
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, instance *rbvb2.RedisBackup, status rbvb2.RedisBackupStatus) error {
+	copy := instance.DeepCopy()
+	copy.Status = status
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.Status = status
+	return nil
+}
+
+// shardProgress returns the finished shards out of the total and the size of the stored snapshots
+func shardProgress(shards []rbvb2.RedisBackupShard) (string, int64) {
+	var (
+		finished int
+		size     int64
+	)
+	for _, shard := range shards {
+		if shard.State == rbvb2.RedisBackupShardCompleted || shard.State == rbvb2.RedisBackupShardFailed {
+			finished++
+		}
+		size += shard.Size
+	}
+	return fmt.Sprintf("%d/%d", finished, len(shards)), size
+}
+
+func failedShards(shards []rbvb2.RedisBackupShard) int {
+	failed := 0
+	for _, shard := range shards {
+		if shard.State == rbvb2.RedisBackupShardFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+func redisBackupAsOwner(cr *rbvb2.RedisBackup) metav1.OwnerReference {
+	trueVar := true
+	return metav1.OwnerReference{
+		APIVersion: rbvb2.GroupVersion.String(),
+		Kind:       "RedisBackup",
+		Name:       cr.Name,
+		UID:        cr.UID,
+		Controller: &trueVar,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+//
+// The writer pod of PVC storage is not watched, doing so would make the manager cache every pod
+// of the cluster. Its readiness is polled through timed requeues like the background saves are.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rbvb2.RedisBackup{}).
+		WithOptions(opts).
+		Complete(r)
+}
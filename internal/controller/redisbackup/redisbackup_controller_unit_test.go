@@ -0,0 +1,404 @@
+package redisbackup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeSnapshotter struct {
+	redis.Snapshotter
+	nodes      []k8sutils.RedisBackupNode
+	nodesErr   error
+	requested  time.Time
+	saveStatus map[string]k8sutils.BGSaveStatus
+	data       map[string][]byte
+	triggered  []string
+	// busy pods run a save that started before the request
+	busy map[string]bool
+}
+
+func (f *fakeSnapshotter) Nodes(ctx context.Context, target metav1.Object) ([]k8sutils.RedisBackupNode, error) {
+	return f.nodes, f.nodesErr
+}
+
+func (f *fakeSnapshotter) TriggerBGSave(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveRequest, error) {
+	if f.busy[podName] {
+		return k8sutils.BGSaveRequest{}, k8sutils.ErrBGSaveInProgress
+	}
+	f.triggered = append(f.triggered, podName)
+	return k8sutils.BGSaveRequest{Time: f.requested, Before: k8sutils.BGSaveStatus{LastStatus: "ok", LastSaveTime: f.requested.Unix() - 60}}, nil
+}
+
+func (f *fakeSnapshotter) BGSaveStatus(ctx context.Context, target metav1.Object, podName string) (k8sutils.BGSaveStatus, error) {
+	return f.saveStatus[podName], nil
+}
+
+func (f *fakeSnapshotter) StreamRDB(ctx context.Context, target metav1.Object, podName string, w io.Writer) (int64, error) {
+	n, err := w.Write(f.data[podName])
+	return int64(n), err
+}
+
+type memoryStorage struct {
+	files    map[string]*bytes.Buffer
+	cleaned  bool
+	notReady bool
+}
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func (s *memoryStorage) Prepare(ctx context.Context) (bool, error) { return !s.notReady, nil }
+
+func (s *memoryStorage) Writer(ctx context.Context, location string) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	s.files[location] = buf
+	return nopCloser{buf}, nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, location string) error {
+	delete(s.files, location)
+	return nil
+}
+
+func (s *memoryStorage) Cleanup(ctx context.Context) error {
+	s.cleaned = true
+	return nil
+}
+
+func newBackupForTest() *rbvb2.RedisBackup {
+	return &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: rbvb2.RedisBackupSpec{
+			Target: rbvb2.BackupTarget{Kind: rbvb2.BackupTargetRedisCluster, Name: "cluster"},
+			Storage: rbvb2.BackupStorage{
+				Filesystem: &rbvb2.FilesystemBackupStorage{Path: "/backups"},
+			},
+		},
+	}
+}
+
+func newClusterForTest() *rcvb2.RedisCluster {
+	return &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: rcvb2.RedisClusterSpec{
+			KubernetesConfig: commonapi.KubernetesConfig{Image: "quay.io/opstree/redis:v7.0.15"},
+		},
+	}
+}
+
+func newReconcilerForTest(t *testing.T, snapshotter *fakeSnapshotter, storage *memoryStorage, objs ...client.Object) *Reconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&rbvb2.RedisBackup{}).
+			WithObjects(objs...).
+			Build(),
+		K8sClient:   fake.NewSimpleClientset(),
+		Snapshotter: snapshotter,
+		Recorder:    record.NewFakeRecorder(10),
+		NewStorage: func(kubernetes.Interface, *rbvb2.RedisBackup, podTemplate) (Storage, error) {
+			return storage, nil
+		},
+	}
+}
+
+func reconcileBackup(t *testing.T, r *Reconciler) (ctrl.Result, *rbvb2.RedisBackup) {
+	t.Helper()
+	key := types.NamespacedName{Namespace: "default", Name: "nightly"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	return result, backup
+}
+
+func TestReconcileBacksUpEveryShard(t *testing.T) {
+	requested := time.Unix(1700000000, 0)
+	snapshotter := &fakeSnapshotter{
+		nodes: []k8sutils.RedisBackupNode{
+			{PodName: "cluster-leader-0", NodeID: "a", Slots: []string{"0-8191"}},
+			{PodName: "cluster-leader-1", NodeID: "b", Slots: []string{"8192-16383"}},
+		},
+		requested: requested,
+		saveStatus: map[string]k8sutils.BGSaveStatus{
+			"cluster-leader-0": {InProgress: true, LastStatus: "ok", LastSaveTime: requested.Unix() - 60},
+			"cluster-leader-1": {LastStatus: "ok", LastSaveTime: requested.Unix()},
+		},
+		data: map[string][]byte{
+			"cluster-leader-0": []byte("REDIS0011-shard-0"),
+			"cluster-leader-1": []byte("REDIS0011-1"),
+		},
+	}
+	storage := &memoryStorage{files: map[string]*bytes.Buffer{}}
+	r := newReconcilerForTest(t, snapshotter, storage, newBackupForTest(), newClusterForTest())
+
+	// first pass resolves the shards
+	result, backup := reconcileBackup(t, r)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, rbvb2.RedisBackupRunning, backup.Status.State)
+	require.Len(t, backup.Status.Shards, 2)
+	assert.Equal(t, "0/2", backup.Status.Progress)
+	assert.Equal(t, "default/nightly/cluster-leader-0.rdb", backup.Status.Shards[0].Location)
+	assert.Equal(t, []string{"8192-16383"}, backup.Status.Shards[1].Slots)
+
+	// second pass triggers both saves, only the finished one is streamed in the background
+	result, backup = reconcileBackup(t, r)
+	assert.Equal(t, bgsavePollInterval, result.RequeueAfter)
+	assert.Equal(t, []string{"cluster-leader-0", "cluster-leader-1"}, snapshotter.triggered)
+	assert.Equal(t, rbvb2.RedisBackupShardSaving, backup.Status.Shards[0].State)
+	assert.Equal(t, rbvb2.RedisBackupShardSaving, backup.Status.Shards[1].State)
+	assert.Equal(t, "streaming the snapshot to the storage", backup.Status.Shards[1].Message)
+	assert.Equal(t, "0/2", backup.Status.Progress)
+
+	// the next pass records the result of the stream
+	r.streams.wait()
+	result, backup = reconcileBackup(t, r)
+	assert.Equal(t, bgsavePollInterval, result.RequeueAfter)
+	assert.Equal(t, rbvb2.RedisBackupShardCompleted, backup.Status.Shards[1].State)
+	assert.Empty(t, backup.Status.Shards[1].Message)
+	assert.Equal(t, "1/2", backup.Status.Progress)
+	assert.Equal(t, int64(11), backup.Status.Size)
+
+	// the stale save of the first shard has been replaced by the requested one
+	snapshotter.saveStatus["cluster-leader-0"] = k8sutils.BGSaveStatus{LastStatus: "ok", LastSaveTime: requested.Unix() + 2}
+	reconcileBackup(t, r)
+	r.streams.wait()
+	result, backup = reconcileBackup(t, r)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, rbvb2.RedisBackupCompleted, backup.Status.State)
+	assert.Equal(t, "2/2", backup.Status.Progress)
+	assert.Equal(t, int64(28), backup.Status.Size)
+	assert.NotNil(t, backup.Status.CompletionTime)
+	assert.Equal(t, "REDIS0011-shard-0", storage.files["default/nightly/cluster-leader-0.rdb"].String())
+	assert.True(t, storage.cleaned)
+	assert.Len(t, snapshotter.triggered, 2, "saves must not be triggered again")
+}
+
+func TestReconcileFailsShardWhenBackgroundSaveFails(t *testing.T) {
+	requested := time.Unix(1700000000, 0)
+	snapshotter := &fakeSnapshotter{
+		nodes:     []k8sutils.RedisBackupNode{{PodName: "cluster-leader-0"}},
+		requested: requested,
+		saveStatus: map[string]k8sutils.BGSaveStatus{
+			"cluster-leader-0": {LastStatus: "err", LastSaveTime: requested.Unix() - 60},
+		},
+	}
+	storage := &memoryStorage{files: map[string]*bytes.Buffer{}}
+	r := newReconcilerForTest(t, snapshotter, storage, newBackupForTest(), newClusterForTest())
+
+	reconcileBackup(t, r)
+	result, backup := reconcileBackup(t, r)
+
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.State)
+	assert.Equal(t, rbvb2.RedisBackupShardFailed, backup.Status.Shards[0].State)
+	assert.Contains(t, backup.Status.Shards[0].Message, `"err"`)
+	assert.Empty(t, storage.files)
+}
+
+func TestReconcileWaitsForRunningSaveBeforeRequestingOne(t *testing.T) {
+	requested := time.Unix(1700000000, 0)
+	snapshotter := &fakeSnapshotter{
+		nodes:     []k8sutils.RedisBackupNode{{PodName: "cluster-leader-0"}},
+		requested: requested,
+		saveStatus: map[string]k8sutils.BGSaveStatus{
+			"cluster-leader-0": {LastStatus: "ok", LastSaveTime: requested.Unix() - 60},
+		},
+		busy: map[string]bool{"cluster-leader-0": true},
+	}
+	storage := &memoryStorage{files: map[string]*bytes.Buffer{}}
+	r := newReconcilerForTest(t, snapshotter, storage, newBackupForTest(), newClusterForTest())
+
+	reconcileBackup(t, r)
+	result, backup := reconcileBackup(t, r)
+	assert.Equal(t, bgsavePollInterval, result.RequeueAfter)
+	assert.Empty(t, snapshotter.triggered)
+	assert.Equal(t, rbvb2.RedisBackupShardPending, backup.Status.Shards[0].State)
+	assert.Equal(t, k8sutils.ErrBGSaveInProgress.Error(), backup.Status.Shards[0].Message)
+
+	// the save that predates the backup ended, its snapshot is not taken for the requested one
+	delete(snapshotter.busy, "cluster-leader-0")
+	result, backup = reconcileBackup(t, r)
+	assert.Equal(t, bgsavePollInterval, result.RequeueAfter)
+	assert.Equal(t, []string{"cluster-leader-0"}, snapshotter.triggered)
+	assert.Equal(t, rbvb2.RedisBackupShardSaving, backup.Status.Shards[0].State)
+	assert.Equal(t, "ok", backup.Status.Shards[0].SavedBeforeRequest.LastStatus)
+	assert.Empty(t, storage.files, "the save that predates the request must not be streamed")
+}
+
+func TestReconcileWaitsForTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		objs       []client.Object
+		nodesErr   error
+		wantReason string
+	}{
+		{
+			name:       "missing target",
+			objs:       []client.Object{newBackupForTest()},
+			wantReason: "RedisCluster cluster not found",
+		},
+		{
+			name:       "target without ready nodes",
+			objs:       []client.Object{newBackupForTest(), newClusterForTest()},
+			nodesErr:   errors.New("no master with assigned slots found"),
+			wantReason: "waiting for RedisCluster cluster: no master with assigned slots found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshotter := &fakeSnapshotter{nodesErr: tt.nodesErr}
+			r := newReconcilerForTest(t, snapshotter, &memoryStorage{}, tt.objs...)
+
+			result, backup := reconcileBackup(t, r)
+
+			assert.Equal(t, targetPendingInterval, result.RequeueAfter)
+			assert.Equal(t, rbvb2.RedisBackupPending, backup.Status.State)
+			assert.Equal(t, tt.wantReason, backup.Status.Reason)
+			assert.Empty(t, snapshotter.triggered)
+		})
+	}
+}
+
+func TestReconcileWaitsForStorage(t *testing.T) {
+	snapshotter := &fakeSnapshotter{nodes: []k8sutils.RedisBackupNode{{PodName: "cluster-leader-0"}}}
+	storage := &memoryStorage{notReady: true}
+	r := newReconcilerForTest(t, snapshotter, storage, newBackupForTest(), newClusterForTest())
+
+	reconcileBackup(t, r)
+	result, backup := reconcileBackup(t, r)
+
+	assert.Equal(t, bgsavePollInterval, result.RequeueAfter)
+	assert.Equal(t, rbvb2.RedisBackupShardPending, backup.Status.Shards[0].State)
+	assert.Empty(t, snapshotter.triggered)
+}
+
+func TestReconcileRejectsInvalidStorage(t *testing.T) {
+	backup := newBackupForTest()
+	backup.Spec.Storage.PersistentVolumeClaim = &rbvb2.PVCBackupStorage{ClaimName: "backups"}
+	r := newReconcilerForTest(t, &fakeSnapshotter{}, &memoryStorage{}, backup, newClusterForTest())
+
+	result, got := reconcileBackup(t, r)
+
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, rbvb2.RedisBackupFailed, got.Status.State)
+	assert.Contains(t, got.Status.Reason, "only one of")
+}
+
+func TestReconcileDeletesSnapshotsOfDeletedBackup(t *testing.T) {
+	requested := time.Unix(1700000000, 0)
+	snapshotter := &fakeSnapshotter{
+		nodes:      []k8sutils.RedisBackupNode{{PodName: "cluster-leader-0"}},
+		requested:  requested,
+		saveStatus: map[string]k8sutils.BGSaveStatus{"cluster-leader-0": {LastStatus: "ok", LastSaveTime: requested.Unix()}},
+		data:       map[string][]byte{"cluster-leader-0": []byte("REDIS0011")},
+	}
+	storage := &memoryStorage{files: map[string]*bytes.Buffer{}}
+	backup := newBackupForTest()
+	backup.Spec.DeletionPolicy = rbvb2.BackupDeletionPolicyDelete
+	r := newReconcilerForTest(t, snapshotter, storage, backup, newClusterForTest())
+
+	reconcileBackup(t, r)
+	reconcileBackup(t, r)
+	r.streams.wait()
+	_, backup = reconcileBackup(t, r)
+	require.Equal(t, rbvb2.RedisBackupCompleted, backup.Status.State)
+	assert.Contains(t, backup.Finalizers, RedisBackupFinalizer)
+	assert.Contains(t, storage.files, "default/nightly/cluster-leader-0.rdb")
+
+	require.NoError(t, r.Delete(context.Background(), backup))
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nightly"}})
+	require.NoError(t, err)
+
+	assert.Empty(t, storage.files)
+	err = r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nightly"}, &rbvb2.RedisBackup{})
+	assert.True(t, apierrors.IsNotFound(err), "backup must be gone once the finalizer is released")
+}
+
+func TestReconcileRetainsSnapshotsByDefault(t *testing.T) {
+	r := newReconcilerForTest(t, &fakeSnapshotter{}, &memoryStorage{}, newBackupForTest())
+
+	_, backup := reconcileBackup(t, r)
+
+	assert.NotContains(t, backup.Finalizers, RedisBackupFinalizer)
+}
+
+func TestFilesystemStorageWriter(t *testing.T) {
+	root := t.TempDir()
+	storage := &filesystemStorage{root: root}
+
+	ready, err := storage.Prepare(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	w, err := storage.Writer(context.Background(), "default/nightly/redis-0.rdb")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("REDIS0011"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(filepath.Join(root, "default", "nightly", "redis-0.rdb"))
+	require.NoError(t, err)
+	assert.Equal(t, "REDIS0011", string(data))
+
+	require.NoError(t, storage.Delete(context.Background(), "default/nightly/redis-0.rdb"))
+	_, err = os.Stat(filepath.Join(root, "default", "nightly", "redis-0.rdb"))
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, storage.Delete(context.Background(), "default/nightly/redis-0.rdb"), "deleting a missing snapshot is not an error")
+}
+
+func TestPVCStorageWriterPod(t *testing.T) {
+	backup := newBackupForTest()
+	backup.UID = "uid"
+	backup.Spec.Storage = rbvb2.BackupStorage{
+		PersistentVolumeClaim: &rbvb2.PVCBackupStorage{ClaimName: "backups"},
+	}
+	k8sClient := fake.NewSimpleClientset()
+	storage, err := newStorage(k8sClient, backup, podTemplate{image: "quay.io/opstree/redis:v7.0.15"})
+	require.NoError(t, err)
+
+	ready, err := storage.Prepare(context.Background())
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	pod, err := k8sClient.CoreV1().Pods("default").Get(context.Background(), "nightly-backup-writer", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/opstree/redis:v7.0.15", pod.Spec.Containers[0].Image)
+	assert.Equal(t, "backups", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	require.Len(t, pod.OwnerReferences, 1)
+	assert.Equal(t, "RedisBackup", pod.OwnerReferences[0].Kind)
+
+	require.NoError(t, storage.Cleanup(context.Background()))
+	_, err = k8sClient.CoreV1().Pods("default").Get(context.Background(), "nightly-backup-writer", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
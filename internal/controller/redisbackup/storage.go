@@ -0,0 +1,217 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	writerContainerName = "writer"
+	writerMountPath     = "/backup"
+)
+
+// Storage is a backend RDB snapshots are streamed into
+type Storage interface {
+	// Prepare makes the backend ready to accept snapshots, it returns false while it is not ready yet
+	Prepare(ctx context.Context) (bool, error)
+	// Writer opens the snapshot at location, relative to the storage root, replacing any previous content.
+	// The snapshot is only complete once Close returned without error.
+	Writer(ctx context.Context, location string) (io.WriteCloser, error)
+	// Delete removes the snapshot at location, a missing snapshot is not an error
+	Delete(ctx context.Context, location string) error
+	// Cleanup releases everything Prepare acquired for the backup
+	Cleanup(ctx context.Context) error
+}
+
+// podTemplate carries the settings of the backup target the writer pod is created with
+type podTemplate struct {
+	image              string
+	imagePullPolicy    corev1.PullPolicy
+	imagePullSecrets   *[]corev1.LocalObjectReference
+	podSecurityContext *corev1.PodSecurityContext
+}
+
+func newStorage(k8s kubernetes.Interface, backup *rbvb2.RedisBackup, tmpl podTemplate) (Storage, error) {
+	switch {
+	case backup.Spec.Storage.PersistentVolumeClaim != nil:
+		return &pvcStorage{k8s: k8s, backup: backup, tmpl: tmpl}, nil
+	case backup.Spec.Storage.Filesystem != nil:
+		return &filesystemStorage{root: backup.Spec.Storage.Filesystem.Path}, nil
+	default:
+		return nil, fmt.Errorf("no storage backend configured")
+	}
+}
+
+// filesystemStorage writes snapshots below a directory of the operator's own filesystem
+type filesystemStorage struct {
+	root string
+}
+
+func (s *filesystemStorage) Prepare(ctx context.Context) (bool, error) {
+	return true, os.MkdirAll(s.root, 0o750)
+}
+
+func (s *filesystemStorage) Writer(ctx context.Context, location string) (io.WriteCloser, error) {
+	file := filepath.Join(s.root, filepath.FromSlash(location))
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return nil, err
+	}
+	return os.Create(file)
+}
+
+func (s *filesystemStorage) Delete(ctx context.Context, location string) error {
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(location)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *filesystemStorage) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// pvcStorage writes snapshots to a PersistentVolumeClaim through a writer pod that mounts the
+// claim, the snapshot is piped into `cat` running in that pod.
+type pvcStorage struct {
+	k8s    kubernetes.Interface
+	backup *rbvb2.RedisBackup
+	tmpl   podTemplate
+}
+
+func (s *pvcStorage) Prepare(ctx context.Context) (bool, error) {
+	pods := s.k8s.CoreV1().Pods(s.backup.Namespace)
+	pod, err := pods.Get(ctx, s.backup.WriterPodName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = pods.Create(ctx, s.writerPod(), metav1.CreateOptions{})
+		return false, err
+	}
+	if err != nil {
+		return false, err
+	}
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		return true, nil
+	case corev1.PodFailed, corev1.PodSucceeded:
+		return false, fmt.Errorf("backup writer pod %s terminated with phase %s", pod.Name, pod.Status.Phase)
+	default:
+		return false, nil
+	}
+}
+
+func (s *pvcStorage) writerPod() *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.backup.WriterPodName(),
+			Namespace: s.backup.Namespace,
+			Labels: map[string]string{
+				"app":          s.backup.WriterPodName(),
+				"redis-backup": s.backup.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:   corev1.RestartPolicyNever,
+			SecurityContext: s.tmpl.podSecurityContext,
+			Containers: []corev1.Container{{
+				Name:            writerContainerName,
+				Image:           s.tmpl.image,
+				ImagePullPolicy: s.tmpl.imagePullPolicy,
+				Command:         []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 5; done"},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "backup",
+					MountPath: writerMountPath,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "backup",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: s.backup.Spec.Storage.PersistentVolumeClaim.ClaimName,
+					},
+				},
+			}},
+		},
+	}
+	if s.tmpl.imagePullSecrets != nil {
+		pod.Spec.ImagePullSecrets = *s.tmpl.imagePullSecrets
+	}
+	k8sutils.AddOwnerRefToObject(pod, redisBackupAsOwner(s.backup))
+	return pod
+}
+
+func (s *pvcStorage) Writer(ctx context.Context, location string) (io.WriteCloser, error) {
+	file := s.path(location)
+	cmd := []string{"sh", "-c", `mkdir -p "$(dirname "$0")" && cat > "$0"`, file}
+
+	pr, pw := io.Pipe()
+	w := &execWriter{PipeWriter: pw, done: make(chan error, 1)}
+	go func() {
+		err := k8sutils.ExecPodCommand(ctx, s.k8s, s.backup.Namespace, s.backup.WriterPodName(), writerContainerName, cmd, pr, nil)
+		// unblock the producer when the exec stream ended before all data was consumed
+		if err != nil {
+			pr.CloseWithError(fmt.Errorf("backup writer exited: %w", err))
+		} else {
+			pr.Close()
+		}
+		w.done <- err
+	}()
+	return w, nil
+}
+
+func (s *pvcStorage) Delete(ctx context.Context, location string) error {
+	cmd := []string{"rm", "-f", s.path(location)}
+	return k8sutils.ExecPodCommand(ctx, s.k8s, s.backup.Namespace, s.backup.WriterPodName(), writerContainerName, cmd, nil, nil)
+}
+
+// path returns the path of a snapshot inside the writer pod
+func (s *pvcStorage) path(location string) string {
+	return path.Join(writerMountPath, s.backup.Spec.Storage.PersistentVolumeClaim.Path, location)
+}
+
+func (s *pvcStorage) Cleanup(ctx context.Context) error {
+	err := s.k8s.CoreV1().Pods(s.backup.Namespace).Delete(ctx, s.backup.WriterPodName(), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// execWriter feeds the stdin of an exec stream, Close waits for the remote command to finish
+type execWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func (w *execWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-w.done
+}
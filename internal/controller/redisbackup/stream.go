@@ -0,0 +1,89 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisbackup
+
+import (
+	"strings"
+	"sync"
+)
+
+// shardStreams runs the copies of the snapshots of the shards in the background. A reconcile
+// starts the copy of a shard and the next ones poll it, so no worker is blocked for the whole
+// transfer. A copy interrupted by a restart of the operator is started over.
+type shardStreams struct {
+	mu      sync.Mutex
+	streams map[string]*shardStream
+	wg      sync.WaitGroup
+}
+
+type shardStream struct {
+	done bool
+	size int64
+	err  error
+}
+
+// poll returns the result of the copy started under the key once it finished, and whether one
+// was started. A finished copy is forgotten once its result is returned.
+func (s *shardStreams) poll(key string) (*shardStream, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream, found := s.streams[key]
+	if !found || !stream.done {
+		return nil, found
+	}
+	delete(s.streams, key)
+	return stream, true
+}
+
+// start runs the copy under the key in the background, unless one is already running
+func (s *shardStreams) start(key string, run func() (int64, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams == nil {
+		s.streams = map[string]*shardStream{}
+	}
+	if _, found := s.streams[key]; found {
+		return
+	}
+	stream := &shardStream{}
+	s.streams[key] = stream
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		size, err := run()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		stream.done, stream.size, stream.err = true, size, err
+	}()
+}
+
+// forget drops the copies started under the prefix, whose results are no longer polled. A running
+// one keeps running until it ends.
+func (s *shardStreams) forget(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.streams {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.streams, key)
+		}
+	}
+}
+
+// wait blocks until every copy that was started ended
+func (s *shardStreams) wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,42 @@
+package redisbackup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardStreams(t *testing.T) {
+	var streams shardStreams
+	_, started := streams.poll("uid/cluster-leader-0")
+	assert.False(t, started)
+
+	release := make(chan struct{})
+	streams.start("uid/cluster-leader-0", func() (int64, error) {
+		<-release
+		return 42, nil
+	})
+	// a running stream is not started twice
+	streams.start("uid/cluster-leader-0", func() (int64, error) { return 0, errors.New("started twice") })
+	stream, started := streams.poll("uid/cluster-leader-0")
+	assert.True(t, started)
+	assert.Nil(t, stream, "the stream is still running")
+
+	close(release)
+	streams.wait()
+	stream, started = streams.poll("uid/cluster-leader-0")
+	require.True(t, started)
+	require.NotNil(t, stream)
+	assert.Equal(t, int64(42), stream.size)
+	assert.NoError(t, stream.err)
+	_, started = streams.poll("uid/cluster-leader-0")
+	assert.False(t, started, "a finished stream is forgotten once polled")
+
+	streams.start("uid/cluster-leader-1", func() (int64, error) { return 0, errors.New("connection reset") })
+	streams.wait()
+	streams.forget("uid/")
+	_, started = streams.poll("uid/cluster-leader-1")
+	assert.False(t, started)
+}
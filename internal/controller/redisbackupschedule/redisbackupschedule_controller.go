@@ -0,0 +1,375 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisbackupschedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/cron"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ScheduleLabel is set on every backup taken by a schedule, its value is the schedule name
+	ScheduleLabel = "redisbackupschedule.opstreelabs.in/name"
+	// failedBackupsHistoryLimit is the number of most recent failed backups kept for inspection
+	failedBackupsHistoryLimit = 1
+)
+
+// Reconciler reconciles a RedisBackupSchedule object
+type Reconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	// Now returns the current time, the real clock is used when it is nil
+	Now func() time.Time
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &rbvb2.RedisBackupSchedule{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			monitoring.RedisBackupScheduleLastSuccessTimestamp.DeleteLabelValues(req.Namespace, req.Name)
+			monitoring.RedisBackupScheduleConsecutiveFailures.DeleteLabelValues(req.Namespace, req.Name)
+		}
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisBackupSchedule instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return intctrlutil.Reconciled()
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+
+	schedule, err := cron.Parse(instance.Spec.Schedule)
+	if err != nil {
+		return r.invalid(ctx, instance, fmt.Sprintf("invalid schedule: %s", err))
+	}
+	if err := instance.Spec.Storage.Validate(); err != nil {
+		return r.invalid(ctx, instance, err.Error())
+	}
+
+	backups, err := r.listBackups(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to list backups of schedule")
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Reason = ""
+	status.ActiveBackup = ""
+	var finished []rbvb2.RedisBackup
+	for _, backup := range backups {
+		if backup.IsFinished() {
+			finished = append(finished, backup)
+		} else {
+			status.ActiveBackup = backup.Name
+		}
+	}
+	accountBackups(status, finished)
+
+	if instance.Spec.Suspend {
+		status.Reason = "schedule is suspended"
+	}
+
+	now := r.now()
+	last := instance.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		last = status.LastScheduleTime.Time
+	}
+	next := schedule.Next(last.UTC())
+	if next.IsZero() {
+		return r.invalid(ctx, instance, fmt.Sprintf("schedule %q never runs", instance.Spec.Schedule))
+	}
+	if !next.After(now) {
+		tick := latestTick(schedule, next, now)
+		switch {
+		case instance.Spec.Suspend:
+		case status.ActiveBackup != "":
+			status.Reason = fmt.Sprintf("skipped backup at %s, %s is still running", tick.Format(time.RFC3339), status.ActiveBackup)
+			r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupSkipped, status.Reason)
+		default:
+			backup, err := r.createBackup(ctx, instance, tick)
+			if err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to create scheduled backup")
+			}
+			status.LastBackup = backup.Name
+			status.ActiveBackup = backup.Name
+		}
+		status.LastScheduleTime = &metav1.Time{Time: tick}
+		next = schedule.Next(tick)
+	}
+
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackupSchedule status")
+	}
+	if status.LastSuccessfulBackupTime != nil {
+		monitoring.RedisBackupScheduleLastSuccessTimestamp.WithLabelValues(instance.Namespace, instance.Name).Set(float64(status.LastSuccessfulBackupTime.Unix()))
+	}
+	monitoring.RedisBackupScheduleConsecutiveFailures.WithLabelValues(instance.Namespace, instance.Name).Set(float64(status.ConsecutiveFailures))
+
+	expired := expiredBackups(finished, instance.Spec.Retention)
+	for i := range expired {
+		backup := &expired[i]
+		if err := r.Delete(ctx, backup); err != nil && !apierrors.IsNotFound(err) {
+			return intctrlutil.RequeueE(ctx, err, "failed to prune backup", "backup", backup.Name)
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupPruned, fmt.Sprintf("Pruned backup %s", backup.Name))
+	}
+
+	if next.IsZero() {
+		return intctrlutil.Reconciled()
+	}
+	return intctrlutil.RequeueAfter(ctx, next.Sub(now), "waiting for next scheduled backup", "next", next.Format(time.RFC3339))
+}
+
+// accountBackups adds the finished backups that were not counted yet to the status, in the order
+// they finished. The counted backups are recorded in the same status write as the counters, a
+// retry after a failed update starts again from the previous status so that no backup is counted
+// twice or lost.
+func accountBackups(status *rbvb2.RedisBackupScheduleStatus, finished []rbvb2.RedisBackup) {
+	counted := make(map[string]bool, len(status.AccountedBackups))
+	for _, name := range status.AccountedBackups {
+		counted[name] = true
+	}
+	var pending []rbvb2.RedisBackup
+	// backups that no longer exist are dropped from the record
+	var accounted []string
+	for _, backup := range finished {
+		if counted[backup.Name] {
+			accounted = append(accounted, backup.Name)
+		} else {
+			pending = append(pending, backup)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return backupTime(&pending[i]).Before(backupTime(&pending[j]))
+	})
+
+	for i := range pending {
+		backup := &pending[i]
+		if backup.Status.State == rbvb2.RedisBackupCompleted {
+			status.SuccessfulBackups++
+			status.ConsecutiveFailures = 0
+			completed := backupTime(backup)
+			if status.LastSuccessfulBackupTime == nil || completed.After(status.LastSuccessfulBackupTime.Time) {
+				status.LastSuccessfulBackupTime = &metav1.Time{Time: completed}
+			}
+		} else {
+			status.FailedBackups++
+			status.ConsecutiveFailures++
+		}
+		accounted = append(accounted, backup.Name)
+	}
+	sort.Strings(accounted)
+	status.AccountedBackups = accounted
+}
+
+// expiredBackups returns the finished backups the retention rules no longer select. The most
+// recent completed backup and the most recent failed ones are always kept.
+func expiredBackups(finished []rbvb2.RedisBackup, retention *rbvb2.BackupRetention) []rbvb2.RedisBackup {
+	if retention == nil {
+		return nil
+	}
+	var completed, failed []rbvb2.RedisBackup
+	for _, backup := range finished {
+		if backup.Status.State == rbvb2.RedisBackupCompleted {
+			completed = append(completed, backup)
+		} else {
+			failed = append(failed, backup)
+		}
+	}
+	newestFirst := func(backups []rbvb2.RedisBackup) {
+		sort.SliceStable(backups, func(i, j int) bool {
+			return backupTime(&backups[i]).After(backupTime(&backups[j]))
+		})
+	}
+	newestFirst(completed)
+	newestFirst(failed)
+
+	keep := make(map[string]bool)
+	if len(completed) > 0 {
+		keep[completed[0].Name] = true
+	}
+	for i := 0; i < len(completed) && i < int(valueOrZero(retention.KeepLast)); i++ {
+		keep[completed[i].Name] = true
+	}
+	keepPeriods(completed, keep, valueOrZero(retention.KeepDaily), func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPeriods(completed, keep, valueOrZero(retention.KeepWeekly), func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	for i := 0; i < len(failed) && i < failedBackupsHistoryLimit; i++ {
+		keep[failed[i].Name] = true
+	}
+
+	var expired []rbvb2.RedisBackup
+	for _, backup := range append(completed, failed...) {
+		if !keep[backup.Name] {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
+// keepPeriods keeps the newest backup of each of the count most recent periods, backups must
+// be sorted newest first
+func keepPeriods(backups []rbvb2.RedisBackup, keep map[string]bool, count int32, period func(time.Time) string) {
+	seen := make(map[string]bool)
+	for _, backup := range backups {
+		if int32(len(seen)) >= count {
+			return
+		}
+		key := period(backupTime(&backup).UTC())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keep[backup.Name] = true
+	}
+}
+
+// backupTime returns the time a backup finished, falling back to its creation for backups
+// that never recorded one
+func backupTime(backup *rbvb2.RedisBackup) time.Time {
+	if backup.Status.CompletionTime != nil {
+		return backup.Status.CompletionTime.Time
+	}
+	return backup.CreationTimestamp.Time
+}
+
+// latestTick returns the most recent activation not after now, starting from next. Missed
+// activations, e.g. while the operator was down, result in a single backup.
+func latestTick(schedule *cron.Schedule, next, now time.Time) time.Time {
+	tick := next
+	for t := schedule.Next(tick); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		tick = t
+	}
+	return tick
+}
+
+func (r *Reconciler) createBackup(ctx context.Context, instance *rbvb2.RedisBackupSchedule, tick time.Time) (*rbvb2.RedisBackup, error) {
+	// Backups are intentionally not owned by the schedule, deleting the schedule must not
+	// garbage collect the backups and, through their deletion policy, the snapshots.
+	backup := &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", instance.Name, tick.Unix()),
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{ScheduleLabel: instance.Name},
+		},
+		Spec: rbvb2.RedisBackupSpec{
+			Target:         instance.Spec.Target,
+			Storage:        *instance.Spec.Storage.DeepCopy(),
+			DeletionPolicy: rbvb2.BackupDeletionPolicyDelete,
+		},
+	}
+	// the name is derived from the tick, a backup created by an earlier attempt is reused
+	if err := r.Create(ctx, backup); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("Created scheduled backup", "backup", backup.Name, "tick", tick)
+	r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupScheduled, fmt.Sprintf("Created backup %s", backup.Name))
+	return backup, nil
+}
+
+// listBackups returns the backups taken by the schedule that are not being deleted
+func (r *Reconciler) listBackups(ctx context.Context, instance *rbvb2.RedisBackupSchedule) ([]rbvb2.RedisBackup, error) {
+	list := &rbvb2.RedisBackupList{}
+	if err := r.List(ctx, list, client.InNamespace(instance.Namespace), client.MatchingLabels{ScheduleLabel: instance.Name}); err != nil {
+		return nil, err
+	}
+	backups := make([]rbvb2.RedisBackup, 0, len(list.Items))
+	for _, backup := range list.Items {
+		if !k8sutils.IsDeleted(&backup) {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
+}
+
+func (r *Reconciler) invalid(ctx context.Context, instance *rbvb2.RedisBackupSchedule, reason string) (ctrl.Result, error) {
+	status := instance.Status.DeepCopy()
+	status.Reason = reason
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackupSchedule status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonInvalidBackupSchedule, reason)
+	return intctrlutil.Reconciled()
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, instance *rbvb2.RedisBackupSchedule, status rbvb2.RedisBackupScheduleStatus) error {
+	copy := instance.DeepCopy()
+	copy.Status = status
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.Status = status
+	return nil
+}
+
+func (r *Reconciler) now() time.Time {
+	if r.Now != nil {
+		return r.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func valueOrZero(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// scheduleOfBackup maps a backup to the schedule that took it
+func scheduleOfBackup(ctx context.Context, obj client.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[ScheduleLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rbvb2.RedisBackupSchedule{}).
+		Watches(&rbvb2.RedisBackup{}, handler.EnqueueRequestsFromMapFunc(scheduleOfBackup)).
+		WithOptions(opts).
+		Complete(r)
+}
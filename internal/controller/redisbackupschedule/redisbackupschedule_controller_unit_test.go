@@ -0,0 +1,325 @@
+package redisbackupschedule
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var created = time.Date(2024, time.March, 1, 0, 30, 0, 0, time.UTC)
+
+func newScheduleForTest() *rbvb2.RedisBackupSchedule {
+	return &rbvb2.RedisBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "nightly",
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Spec: rbvb2.RedisBackupScheduleSpec{
+			Schedule: "0 2 * * *",
+			Target:   rbvb2.BackupTarget{Kind: rbvb2.BackupTargetRedisCluster, Name: "cluster"},
+			Storage: rbvb2.BackupStorage{
+				Filesystem: &rbvb2.FilesystemBackupStorage{Path: "/backups"},
+			},
+		},
+	}
+}
+
+func newBackupForTest(name string, state rbvb2.RedisBackupState, completed time.Time) *rbvb2.RedisBackup {
+	backup := &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{ScheduleLabel: "nightly"},
+		},
+		Status: rbvb2.RedisBackupStatus{State: state},
+	}
+	if !completed.IsZero() {
+		backup.Status.CompletionTime = &metav1.Time{Time: completed}
+	}
+	return backup
+}
+
+func newReconcilerForTest(t *testing.T, now time.Time, objs ...client.Object) *Reconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&rbvb2.RedisBackupSchedule{}, &rbvb2.RedisBackup{}).
+			WithObjects(objs...).
+			Build(),
+		Recorder: record.NewFakeRecorder(10),
+		Now:      func() time.Time { return now },
+	}
+}
+
+func reconcileSchedule(t *testing.T, r *Reconciler) (ctrl.Result, *rbvb2.RedisBackupSchedule) {
+	t.Helper()
+	key := types.NamespacedName{Namespace: "default", Name: "nightly"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	schedule := &rbvb2.RedisBackupSchedule{}
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	return result, schedule
+}
+
+func listBackupNames(t *testing.T, r *Reconciler) []string {
+	t.Helper()
+	list := &rbvb2.RedisBackupList{}
+	require.NoError(t, r.List(context.Background(), list))
+	var names []string
+	for _, backup := range list.Items {
+		names = append(names, backup.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReconcileWaitsForFirstTick(t *testing.T) {
+	r := newReconcilerForTest(t, created.Add(time.Hour), newScheduleForTest())
+
+	result, schedule := reconcileSchedule(t, r)
+
+	assert.Equal(t, 30*time.Minute, result.RequeueAfter)
+	assert.Nil(t, schedule.Status.LastScheduleTime)
+	assert.Empty(t, listBackupNames(t, r))
+}
+
+func TestReconcileCreatesBackupOnTick(t *testing.T) {
+	tick := time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)
+	r := newReconcilerForTest(t, tick.Add(10*time.Second), newScheduleForTest())
+
+	result, schedule := reconcileSchedule(t, r)
+
+	assert.Equal(t, 24*time.Hour-10*time.Second, result.RequeueAfter)
+	require.NotNil(t, schedule.Status.LastScheduleTime)
+	assert.True(t, tick.Equal(schedule.Status.LastScheduleTime.Time))
+	assert.Equal(t, "nightly-1709258400", schedule.Status.LastBackup)
+	assert.Equal(t, "nightly-1709258400", schedule.Status.ActiveBackup)
+
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nightly-1709258400"}, backup))
+	assert.Equal(t, "nightly", backup.Labels[ScheduleLabel])
+	assert.Equal(t, rbvb2.BackupDeletionPolicyDelete, backup.Spec.DeletionPolicy)
+	assert.Equal(t, "cluster", backup.Spec.Target.Name)
+	assert.Empty(t, backup.OwnerReferences, "deleting the schedule must not delete its backups")
+
+	// reconciling again before the next tick must not create another backup
+	reconcileSchedule(t, r)
+	assert.Equal(t, []string{"nightly-1709258400"}, listBackupNames(t, r))
+}
+
+func TestReconcileTakesSingleBackupForMissedTicks(t *testing.T) {
+	schedule := newScheduleForTest()
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)}
+	r := newReconcilerForTest(t, time.Date(2024, time.March, 5, 3, 0, 0, 0, time.UTC), schedule)
+
+	_, got := reconcileSchedule(t, r)
+
+	assert.Equal(t, []string{"nightly-1709604000"}, listBackupNames(t, r))
+	assert.True(t, time.Date(2024, time.March, 5, 2, 0, 0, 0, time.UTC).Equal(got.Status.LastScheduleTime.Time))
+}
+
+func TestReconcileSkipsTickWhileBackupIsRunning(t *testing.T) {
+	schedule := newScheduleForTest()
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)}
+	running := newBackupForTest("nightly-1709258400", rbvb2.RedisBackupRunning, time.Time{})
+	r := newReconcilerForTest(t, time.Date(2024, time.March, 2, 2, 0, 0, 0, time.UTC), schedule, running)
+
+	_, got := reconcileSchedule(t, r)
+
+	assert.Equal(t, []string{"nightly-1709258400"}, listBackupNames(t, r))
+	assert.Equal(t, "nightly-1709258400", got.Status.ActiveBackup)
+	assert.Contains(t, got.Status.Reason, "still running")
+}
+
+func TestReconcileSuspended(t *testing.T) {
+	schedule := newScheduleForTest()
+	schedule.Spec.Suspend = true
+	r := newReconcilerForTest(t, time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC), schedule)
+
+	_, got := reconcileSchedule(t, r)
+
+	assert.Empty(t, listBackupNames(t, r))
+	assert.Equal(t, "schedule is suspended", got.Status.Reason)
+	assert.NotNil(t, got.Status.LastScheduleTime, "ticks passed while suspended must not run on resume")
+}
+
+func TestReconcileAccountsFinishedBackups(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 2, 5, 0, 0, time.UTC) }
+	schedule := newScheduleForTest()
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, time.March, 4, 2, 0, 0, 0, time.UTC)}
+	r := newReconcilerForTest(t, day(4), schedule,
+		newBackupForTest("b1", rbvb2.RedisBackupCompleted, day(1)),
+		newBackupForTest("b2", rbvb2.RedisBackupFailed, day(2)),
+		newBackupForTest("b3", rbvb2.RedisBackupFailed, day(3)),
+	)
+
+	_, got := reconcileSchedule(t, r)
+
+	assert.True(t, day(1).Equal(got.Status.LastSuccessfulBackupTime.Time))
+	assert.Equal(t, int32(2), got.Status.ConsecutiveFailures)
+	assert.Equal(t, int32(2), got.Status.FailedBackups)
+	assert.Equal(t, int32(1), got.Status.SuccessfulBackups)
+	assert.Equal(t, float64(day(1).Unix()), testutil.ToFloat64(monitoring.RedisBackupScheduleLastSuccessTimestamp.WithLabelValues("default", "nightly")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(monitoring.RedisBackupScheduleConsecutiveFailures.WithLabelValues("default", "nightly")))
+
+	// finished backups are only counted once
+	_, got = reconcileSchedule(t, r)
+	assert.Equal(t, int32(2), got.Status.FailedBackups)
+
+	require.NoError(t, r.Create(context.Background(), newBackupForTest("b4", rbvb2.RedisBackupCompleted, day(4))))
+	_, got = reconcileSchedule(t, r)
+	assert.Equal(t, int32(0), got.Status.ConsecutiveFailures)
+	assert.Equal(t, int32(2), got.Status.SuccessfulBackups)
+	assert.True(t, day(4).Equal(got.Status.LastSuccessfulBackupTime.Time))
+}
+
+func TestReconcileAccountsBackupsOnceAfterFailedStatusUpdate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 2, 5, 0, 0, time.UTC) }
+	schedule := newScheduleForTest()
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, time.March, 4, 2, 0, 0, 0, time.UTC)}
+	r := newReconcilerForTest(t, day(4), schedule,
+		newBackupForTest("b1", rbvb2.RedisBackupCompleted, day(1)),
+		newBackupForTest("b2", rbvb2.RedisBackupFailed, day(2)),
+	)
+	failUpdate := true
+	r.Client = interceptor.NewClient(r.Client.(client.WithWatch), interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			if failUpdate {
+				failUpdate = false
+				return errors.New("connection refused")
+			}
+			return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+		},
+	})
+	key := types.NamespacedName{Namespace: "default", Name: "nightly"}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.Error(t, err)
+
+	// the retry counts every backup once
+	_, got := reconcileSchedule(t, r)
+	assert.Equal(t, int32(1), got.Status.SuccessfulBackups)
+	assert.Equal(t, int32(1), got.Status.FailedBackups)
+	assert.True(t, day(1).Equal(got.Status.LastSuccessfulBackupTime.Time))
+	assert.Equal(t, []string{"b1", "b2"}, got.Status.AccountedBackups)
+
+	_, got = reconcileSchedule(t, r)
+	assert.Equal(t, int32(1), got.Status.SuccessfulBackups)
+	assert.Equal(t, int32(1), got.Status.FailedBackups)
+
+	// deleted backups are dropped from the record
+	require.NoError(t, r.Delete(context.Background(), newBackupForTest("b1", rbvb2.RedisBackupCompleted, day(1))))
+	_, got = reconcileSchedule(t, r)
+	assert.Equal(t, []string{"b2"}, got.Status.AccountedBackups)
+	assert.Equal(t, int32(1), got.Status.SuccessfulBackups)
+}
+
+func TestReconcilePrunesExpiredBackups(t *testing.T) {
+	schedule := newScheduleForTest()
+	schedule.Spec.Retention = &rbvb2.BackupRetention{KeepLast: ptr.To(int32(2))}
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2024, time.March, 4, 2, 0, 0, 0, time.UTC)}
+	r := newReconcilerForTest(t, time.Date(2024, time.March, 4, 3, 0, 0, 0, time.UTC), schedule,
+		newBackupForTest("b1", rbvb2.RedisBackupCompleted, time.Date(2024, time.March, 1, 2, 5, 0, 0, time.UTC)),
+		newBackupForTest("b2", rbvb2.RedisBackupCompleted, time.Date(2024, time.March, 2, 2, 5, 0, 0, time.UTC)),
+		newBackupForTest("b3", rbvb2.RedisBackupCompleted, time.Date(2024, time.March, 3, 2, 5, 0, 0, time.UTC)),
+		newBackupForTest("b4", rbvb2.RedisBackupRunning, time.Time{}),
+	)
+
+	reconcileSchedule(t, r)
+
+	assert.Equal(t, []string{"b2", "b3", "b4"}, listBackupNames(t, r))
+}
+
+func TestReconcileRejectsInvalidSchedule(t *testing.T) {
+	schedule := newScheduleForTest()
+	schedule.Spec.Schedule = "every night"
+	r := newReconcilerForTest(t, created, schedule)
+
+	result, got := reconcileSchedule(t, r)
+
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Contains(t, got.Status.Reason, "invalid schedule")
+	assert.Empty(t, listBackupNames(t, r))
+}
+
+func TestExpiredBackups(t *testing.T) {
+	// one completed backup a day at 02:05 from Monday 2024-01-01 to Sunday 2024-01-21
+	var backups []rbvb2.RedisBackup
+	for d := 1; d <= 21; d++ {
+		backups = append(backups, *newBackupForTest(time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC).Format("jan-02"),
+			rbvb2.RedisBackupCompleted, time.Date(2024, time.January, d, 2, 5, 0, 0, time.UTC)))
+	}
+	backups = append(backups,
+		*newBackupForTest("failed-1", rbvb2.RedisBackupFailed, time.Date(2024, time.January, 20, 3, 0, 0, 0, time.UTC)),
+		*newBackupForTest("failed-2", rbvb2.RedisBackupFailed, time.Date(2024, time.January, 21, 3, 0, 0, 0, time.UTC)),
+	)
+
+	tests := []struct {
+		name      string
+		retention *rbvb2.BackupRetention
+		wantKept  []string
+	}{
+		{
+			name:      "no retention keeps everything",
+			retention: nil,
+		},
+		{
+			name:      "keep last",
+			retention: &rbvb2.BackupRetention{KeepLast: ptr.To(int32(3))},
+			wantKept:  []string{"failed-2", "jan-19", "jan-20", "jan-21"},
+		},
+		{
+			name:      "newest completed backup is always kept",
+			retention: &rbvb2.BackupRetention{KeepLast: ptr.To(int32(0))},
+			wantKept:  []string{"failed-2", "jan-21"},
+		},
+		{
+			name:      "keep daily and weekly",
+			retention: &rbvb2.BackupRetention{KeepDaily: ptr.To(int32(2)), KeepWeekly: ptr.To(int32(3))},
+			wantKept:  []string{"failed-2", "jan-07", "jan-14", "jan-20", "jan-21"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := expiredBackups(backups, tt.retention)
+			if tt.retention == nil {
+				assert.Empty(t, expired)
+				return
+			}
+			expiredNames := make(map[string]bool)
+			for _, backup := range expired {
+				expiredNames[backup.Name] = true
+			}
+			var kept []string
+			for _, backup := range backups {
+				if !expiredNames[backup.Name] {
+					kept = append(kept, backup.Name)
+				}
+			}
+			sort.Strings(kept)
+			assert.Equal(t, tt.wantKept, kept)
+		})
+	}
+}
@@ -18,14 +18,22 @@ package rediscluster
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/durability"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
@@ -33,8 +41,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -43,16 +54,22 @@ import (
 
 const (
 	RedisClusterFinalizer = "redisClusterFinalizer"
+	// ApplyPlanAnnotation confirms the scaling plan held by the dry run of the scaling policy, its
+	// value is the generation of the plan. It is removed once the scaling completed.
+	ApplyPlanAnnotation = "rediscluster.opstreelabs.in/apply-plan"
 )
 
 // Reconciler reconciles a RedisCluster object
 type Reconciler struct {
 	client.Client
 	k8sutils.StatefulSet
-	Healer    redis.Healer
-	Checker   redis.Checker
-	K8sClient kubernetes.Interface
-	Recorder  record.EventRecorder
+	Healer        redis.Healer
+	Checker       redis.Checker
+	K8sClient     kubernetes.Interface
+	Recorder      record.EventRecorder
+	Rotator       *passwordrotation.Rotator
+	TLSRotator    *tlsrotation.Rotator
+	SecretWatcher *intctrlutil.ResourceWatcher
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -82,14 +99,66 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
 	}
 
+	rotating, requeueAfter, err := r.reconcilePassword(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to rotate password")
+	}
+	if rotating {
+		return intctrlutil.RequeueAfter(ctx, requeueAfter, "waiting for every pod to accept the new password")
+	}
+	if err = r.reconcileCertificate(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to issue TLS certificate")
+	}
+	// the durability is relaxed before the scale down or the restart of the pods goes on
+	if err = r.reconcileDurability(ctx, instance); err != nil {
+		logger.Error(err, "failed to apply the durability")
+	}
+	// the gates are set before any wait for the statefulsets, the pods of a rollout rely on them
+	if err = k8sutils.ReconcileRedisClusterReadinessGates(ctx, r.K8sClient, instance); err != nil {
+		logger.Error(err, "failed to update the readiness gates of the pods")
+	}
+
+	restore, err := common.GetRestore(ctx, r.Client, rbvb2.BackupTargetRedisCluster, instance.Namespace, instance.Name)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to get redis restore")
+	}
+	wait, err := k8sutils.WaitForRestore(ctx, r.K8sClient, restore, instance.Namespace, instance.Name+"-leader")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if wait {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the redis restore to resolve its snapshots", "RedisRestore", restore.Name)
+	}
+	// the leaders of a restored cluster own the slots of their snapshots, the cluster is
+	// assembled from them instead of being created with evenly split slots
+	restoring := restore != nil && restore.Status.State == rbvb2.RedisRestoreRestoring
+
+	// a scaling held by the dry run of the scaling policy waits for its plan to be confirmed
+	held, err := r.reconcileScalingPlan(ctx, instance, leaderReplicas, followerReplicas)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to plan the scaling")
+	}
+	if held {
+		return intctrlutil.RequeueAfter(ctx, time.Second*30, "waiting for the scaling plan to be confirmed")
+	}
+
 	// Check if the cluster is downscaled
 	if leaderCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-leader"); leaderReplicas < leaderCount {
 		if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-leader") || !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-follower") {
 			return intctrlutil.Reconciled()
 		}
-		if masterCount := k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "leader"); masterCount == leaderCount {
+		// an interrupted scale down is resumed even when the shard whose slots were moved off
+		// already turned into a replica
+		resumedShard, resuming := reshardingShard(instance)
+		if masterCount := k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "leader"); masterCount == leaderCount || resuming {
 			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterDownscale, "Redis cluster is downscaling...")
 			logger.Info("Redis cluster is downscaling...", "Current.LeaderReplicas", leaderCount, "Desired.LeaderReplicas", leaderReplicas)
+			if err = r.updateOperation(ctx, instance, commonapi.ConditionScalingInProgress, &common.ConditionCause{
+				Reason:  commonapi.ConditionReasonScalingDown,
+				Message: fmt.Sprintf("removing shards %d to %d", leaderReplicas, leaderCount-1),
+			}); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "")
+			}
 
 			// Before resharding, ensure all remaining leader pods (the transfer targets) are masters.
 			// After scale-out, a failover may have converted some leader pods to slaves, which causes
@@ -99,6 +168,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			for i := int32(0); i < leaderReplicas; i++ {
 				if !(k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, i)) {
 					logger.Info("Transfer target leader pod is not a master, initiating failover before scale-down", "Pod.Index", i)
+					if err = r.updateOperation(ctx, instance, commonapi.ConditionFailoverInProgress, &common.ConditionCause{
+						Reason:  commonapi.ConditionReasonFailover,
+						Message: fmt.Sprintf("failing over to leader %d before the scale down", i),
+					}); err != nil {
+						return intctrlutil.RequeueE(ctx, err, "")
+					}
 					if err = k8sutils.ClusterFailover(ctx, r.K8sClient, instance, i); err != nil {
 						logger.Error(err, "Failed to initiate cluster failover for transfer target")
 						return intctrlutil.RequeueE(ctx, err, "")
@@ -107,44 +182,85 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				}
 			}
 
-			for shardIdx := leaderCount - 1; shardIdx >= leaderReplicas; shardIdx-- {
+			fromShard := leaderCount - 1
+			if resuming {
+				// the shards above were removed before the reconcile was interrupted
+				fromShard = min(fromShard, resumedShard)
+			}
+			for shardIdx := fromShard; shardIdx >= leaderReplicas; shardIdx-- {
 				logger.Info("Remove the shard", "Shard.Index", shardIdx)
+				// the failover and the removal of the followers already happened for a shard
+				// whose slots are being moved off
+				resumed := resuming && shardIdx == resumedShard
 				//  Imp if the last index of leader sts is not leader make it then
 				// check whether the redis is leader or not ?
 				// if not true then make it leader pod
-				if !(k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, shardIdx)) {
+				if !resumed && !(k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, shardIdx)) {
 					// lastLeaderPod is slaving right now Make it the master Pod
 					// We have to bring a manual failover here to make it a leaderPod
 					// clusterFailover should also include the clusterReplicate since we have to map the followers to new leader
 					logger.Info("Cluster Failover is initiated", "Shard.Index", shardIdx)
+					if err = r.updateOperation(ctx, instance, commonapi.ConditionFailoverInProgress, &common.ConditionCause{
+						Reason:  commonapi.ConditionReasonFailover,
+						Message: fmt.Sprintf("failing over to leader %d before removing its shard", shardIdx),
+					}); err != nil {
+						return intctrlutil.RequeueE(ctx, err, "")
+					}
 					if err = k8sutils.ClusterFailover(ctx, r.K8sClient, instance, shardIdx); err != nil {
 						logger.Error(err, "Failed to initiate cluster failover")
 						return intctrlutil.RequeueE(ctx, err, "")
 					}
 				}
 				// Step 1 Remove the Follower Node
-				k8sutils.RemoveRedisFollowerNodesFromCluster(ctx, r.K8sClient, instance, shardIdx)
-				monitoring.RedisClusterRemoveFollowerAttempt.WithLabelValues(instance.Namespace, instance.Name).Inc()
+				if !resumed {
+					k8sutils.RemoveRedisFollowerNodesFromCluster(ctx, r.K8sClient, instance, shardIdx)
+					monitoring.RedisClusterRemoveFollowerAttempt.WithLabelValues(instance.Namespace, instance.Name).Inc()
+				}
 				// Step 2 Reshard the Cluster
 				// We round robin over the remaining nodes to pick a node where to move the shard to.
 				// This helps reduce the chance of overloading/OOMing the remaining nodes
 				// and makes the subsequent rebalancing step more efficient.
+				// The slots are moved in batches and the progress is recorded after each of them.
 				// TODO: consider doing the resharding in parallel
 				shardMoveNodeIdx := shardIdx % leaderReplicas
-				k8sutils.ReshardRedisCluster(ctx, r.K8sClient, instance, shardIdx, shardMoveNodeIdx, true)
+				for done := false; !done; {
+					var progress *rcvb2.ReshardingStatus
+					var replaced int
+					progress, done, replaced, err = k8sutils.ReshardRedisCluster(ctx, r.K8sClient, instance, shardIdx, shardMoveNodeIdx, instance.Status.Resharding)
+					if replaced > 0 {
+						r.recordReplacedKeys(instance, replaced, progress.SourcePod, progress.TargetPod)
+					}
+					if serr := r.updateResharding(ctx, instance, progress); serr != nil {
+						return intctrlutil.RequeueE(ctx, serr, "")
+					}
+					if err != nil {
+						return intctrlutil.RequeueE(ctx, err, "failed to move the slots of the shard")
+					}
+				}
 				monitoring.RedisClusterReshardTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
+				k8sutils.RemoveRedisNodeFromCluster(ctx, r.K8sClient, instance, k8sutils.RedisDetails{
+					PodName:   instance.Name + "-leader-" + strconv.Itoa(int(shardIdx)),
+					Namespace: instance.Namespace,
+				})
+			}
+			if progress := instance.Status.Resharding.DeepCopy(); progress != nil && progress.CompletionTime == nil {
+				progress.CompletionTime = ptr.To(metav1.Now())
+				if err = r.updateResharding(ctx, instance, progress); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "")
+				}
 			}
 			// Step 3 Rebalance the cluster. With a single remaining leader there is
 			// nothing to rebalance: all slots were already resharded to leader-0 and
 			// the rebalance command targets leader-1, which is no longer part of the
-			// cluster at this point.
-			if leaderReplicas > 1 {
+			// cluster at this point. The weighted and pinned distributions leave the
+			// slots to the weighted balancing and to the user.
+			if leaderReplicas > 1 && instance.Spec.GetSlotDistributionStrategy() == rcvb2.SlotDistributionEven {
 				logger.Info("Redis cluster is downscaled... Rebalancing the cluster")
 				k8sutils.RebalanceRedisCluster(ctx, r.K8sClient, instance)
 				logger.Info("Redis cluster is downscaled... Rebalancing the cluster is done")
 				monitoring.RedisClusterRebalanceTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
 			} else {
-				logger.Info("Redis cluster is downscaled... Skipping rebalance for single-node cluster")
+				logger.Info("Redis cluster is downscaled... Skipping rebalance", "Strategy", instance.Spec.GetSlotDistributionStrategy())
 			}
 			return intctrlutil.RequeueAfter(ctx, time.Second*10, "")
 		} else {
@@ -169,7 +285,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
-	err = k8sutils.CreateRedisLeader(ctx, instance, r.K8sClient)
+	// the leaders announce the addresses of their load balancers
+	pending, err := k8sutils.ReconcileRedisClusterExternalServices(ctx, instance, r.K8sClient, "leader")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to create the external services of the leaders")
+	}
+	if len(pending) > 0 {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the load balancers of the leaders", "Services", pending)
+	}
+	err = k8sutils.CreateRedisLeader(ctx, instance, r.K8sClient, k8sutils.NewRestoreSource(restore))
 	if err != nil {
 		return intctrlutil.RequeueE(ctx, err, "")
 	}
@@ -209,6 +333,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				return intctrlutil.RequeueE(ctx, err, "")
 			}
 		}
+		pending, err = k8sutils.ReconcileRedisClusterExternalServices(ctx, instance, r.K8sClient, "follower")
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to create the external services of the followers")
+		}
+		if len(pending) > 0 {
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the load balancers of the followers", "Services", pending)
+		}
 		err = k8sutils.CreateRedisFollower(ctx, instance, r.K8sClient)
 		if err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
@@ -268,16 +399,36 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		} else {
 			if !slotsAssigned {
 				logger.Info("Start creating a single-node redis cluster")
-				k8sutils.ExecuteRedisClusterCommand(ctx, r.K8sClient, instance)
+				if err := r.createCluster(ctx, instance, restore, restoring); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to create restored redis cluster")
+				}
 			}
 		}
 	}
 
+	// every node counts the nodes left behind by deleted pods, they are forgotten before counting
+	forgotten, err := k8sutils.ForgetOrphanedRedisClusterNodes(ctx, r.K8sClient, instance)
+	if len(forgotten) > 0 {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterNodesForgotten,
+			fmt.Sprintf("Forgot the orphaned nodes %s", strings.Join(forgotten, ", ")))
+	}
+	if err != nil {
+		logger.Error(err, "failed to forget the orphaned cluster nodes")
+	}
+
 	if nc := k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, ""); nc != totalReplicas {
 		logger.Info("Creating redis cluster by executing cluster creation commands")
 		leaderCount := k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "leader")
 		if leaderCount != leaderReplicas {
 			logger.Info("Not all leader are part of the cluster...", "Leaders.Count", leaderCount, "Instance.Size", leaderReplicas)
+			if leaderCount < leaderReplicas && restoring {
+				// the seeded leaders are introduced to each other, gossip may not have spread yet
+				logger.Info("Creating restored cluster", "Current.Leaders", leaderCount, "Desired.Leaders", leaderReplicas, "RedisRestore", restore.Name)
+				if err := r.createCluster(ctx, instance, restore, restoring); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to create restored redis cluster")
+				}
+				return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the restored leaders to join the cluster")
+			}
 			if leaderCount < leaderReplicas {
 				scaleUp, err := r.shouldScaleUpExistingCluster(ctx, instance, leaderCount)
 				if err != nil {
@@ -286,6 +437,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				if scaleUp {
 					// Scale up the cluster
 					logger.Info("Scaling up existing cluster", "Current.Leaders", leaderCount, "Desired.Leaders", leaderReplicas)
+					if err = r.updateOperation(ctx, instance, commonapi.ConditionScalingInProgress, &common.ConditionCause{
+						Reason:  commonapi.ConditionReasonScalingUp,
+						Message: fmt.Sprintf("adding leaders, %d of %d in the cluster", leaderCount, leaderReplicas),
+					}); err != nil {
+						return intctrlutil.RequeueE(ctx, err, "")
+					}
 					// Step 1 : Fix any open slots from previous interrupted operations
 					if err := k8sutils.FixRedisCluster(ctx, r.K8sClient, instance); err != nil {
 						logger.Error(err, "Failed to fix redis cluster slots, proceeding with scale-up")
@@ -313,7 +470,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			if err != nil {
 				return ctrl.Result{}, err
 			}
-			if empty {
+			if empty && instance.Spec.GetSlotDistributionStrategy() == rcvb2.SlotDistributionEven {
 				k8sutils.RebalanceRedisClusterEmptyMasters(ctx, r.K8sClient, instance)
 			}
 
@@ -328,6 +485,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	logger.Info("Number of Redis nodes match desired")
+	if err = r.reconcileTopology(ctx, instance); err != nil {
+		logger.Error(err, "failed to report the cluster topology")
+	}
 	unhealthyNodeCount, err := k8sutils.UnhealthyNodesInCluster(ctx, r.K8sClient, instance)
 	if err != nil {
 		logger.Error(err, "failed to determine unhealthy node count in cluster")
@@ -394,9 +554,62 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
-	// Check If there is No Empty Master Node
-	if k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "") == totalReplicas {
-		k8sutils.CheckIfEmptyMasters(ctx, r.K8sClient, instance)
+	// spread the followers evenly across the masters once the number per shard is managed
+	if followerReplicas > 0 && instance.Spec.ReplicasPerShard != nil {
+		moved, err := k8sutils.RebalanceRedisClusterFollowers(ctx, r.K8sClient, instance)
+		if err != nil {
+			logger.Error(err, "failed to rebalance the followers across the masters")
+		}
+		if moved {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterFollowerMoved, "Moved a follower to balance the replicas of the shards")
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "moved a follower to another master, rechecking")
+		}
+	}
+
+	switch instance.Spec.GetSlotDistributionStrategy() {
+	case rcvb2.SlotDistributionWeighted:
+		// converge the slots of the shards to their weights, one batch of slots per reconcile
+		move, err := k8sutils.BalanceRedisClusterSlotWeights(ctx, r.K8sClient, instance)
+		if errors.Is(err, k8sutils.ErrClusterSlotsOpen) {
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "the cluster has open slots, waiting before matching the shard weights")
+		}
+		if move != nil && move.Slots > 0 {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterSlotsMoved,
+				fmt.Sprintf("Moved %d slots from %s to %s to match the shard weights", move.Slots, move.SourcePod, move.TargetPod))
+		}
+		if move != nil && move.ReplacedKeys > 0 {
+			r.recordReplacedKeys(instance, move.ReplacedKeys, move.SourcePod, move.TargetPod)
+		}
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to move the slots to match the shard weights")
+		}
+		if move != nil {
+			return intctrlutil.RequeueAfter(ctx, time.Second, "moved slots to match the shard weights, rechecking")
+		}
+	case rcvb2.SlotDistributionPinned:
+		// move the pinned slots onto their shard, one batch of slots per reconcile
+		move, err := k8sutils.PinRedisClusterSlots(ctx, r.K8sClient, instance)
+		if errors.Is(err, k8sutils.ErrClusterSlotsOpen) {
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "the cluster has open slots, waiting before moving the pinned slots")
+		}
+		if move != nil && move.Slots > 0 {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterSlotsMoved,
+				fmt.Sprintf("Moved %d slots from %s to %s to match the pinned slots", move.Slots, move.SourcePod, move.TargetPod))
+		}
+		if move != nil && move.ReplacedKeys > 0 {
+			r.recordReplacedKeys(instance, move.ReplacedKeys, move.SourcePod, move.TargetPod)
+		}
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to move the pinned slots onto their shard")
+		}
+		if move != nil {
+			return intctrlutil.RequeueAfter(ctx, time.Second, "moved pinned slots onto their shard, rechecking")
+		}
+	case rcvb2.SlotDistributionEven:
+		// Check If there is No Empty Master Node
+		if k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "") == totalReplicas {
+			k8sutils.CheckIfEmptyMasters(ctx, r.K8sClient, instance)
+		}
 	}
 
 	// Mark the cluster status as ready if all the leader and follower nodes are ready
@@ -433,6 +646,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
+	// pods are only restarted for a changed certificate while the cluster is healthy
+	if err = r.reconcileTLSRotation(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to roll out TLS certificate")
+	}
+	// the leaders of the ManagedFailover update strategy are restarted one at a time, each after
+	// its master role moved to a replica
+	if !tlsrotation.Rotating(instance.Status.TLSRotation) {
+		restarting, err := r.reconcileManagedRollout(ctx, instance)
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to roll out the leaders")
+		}
+		if restarting {
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "rolling out the leaders")
+		}
+	}
+	// records the generation of a spec change that needed no other status change
+	if _, err = r.updateStatus(ctx, instance, *instance.Status.DeepCopy()); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+
 	return intctrlutil.RequeueAfter(ctx, time.Second*10, "")
 }
 
@@ -455,7 +688,316 @@ func (r *Reconciler) shouldScaleUpExistingCluster(ctx context.Context, instance
 	return r.Checker.CheckClusterSlotsAssigned(ctx, instance)
 }
 
+// createCluster creates the cluster from its leaders, restored leaders keep the slots of the
+// shards they were seeded with
+func (r *Reconciler) createCluster(ctx context.Context, instance *rcvb2.RedisCluster, restore *rbvb2.RedisRestore, restoring bool) error {
+	if !restoring {
+		k8sutils.ExecuteRedisClusterCommand(ctx, r.K8sClient, instance)
+		return nil
+	}
+	slots := make(map[string][]string, len(restore.Status.Shards))
+	for _, shard := range restore.Status.Shards {
+		slots[shard.PodName] = shard.Slots
+	}
+	return k8sutils.RestoreRedisClusterSlots(ctx, r.K8sClient, instance, slots)
+}
+
+// reconcilePassword rolls a change of the password secret out to the pods. It reports whether the
+// pods may still reject the password of the secret, the rest of the reconcile logs in with it.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rcvb2.RedisCluster) (bool, time.Duration, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil || secret.Name == nil {
+		return false, 0, nil
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	// leaders come after followers in the node list and are restarted last
+	status, requeueAfter, err := r.Rotator.Reconcile(ctx, passwordrotation.Target{
+		Object: instance,
+		Secret: secret,
+		Spec:   instance.Spec.PasswordRotation,
+		Status: instance.Status.PasswordRotation,
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if !reflect.DeepEqual(status, instance.Status.PasswordRotation) {
+		newStatus := instance.Status.DeepCopy()
+		newStatus.PasswordRotation = status
+		if _, err := r.updateStatus(ctx, instance, *newStatus); err != nil {
+			return false, 0, err
+		}
+	}
+	return passwordrotation.Rotating(status), requeueAfter, nil
+}
+
+// reconcileCertificate requests the certificate of the TLS secret from cert-manager when an
+// issuer is configured. The secret is watched either way, its changes are rolled out to the pods.
+func (r *Reconciler) reconcileCertificate(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	tls := instance.Spec.TLS
+	if tls == nil || tls.Secret.SecretName == "" {
+		return nil
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: tls.Secret.SecretName},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+	dnsNames, err := k8sutils.GetRedisCertificateDNSNames(instance)
+	if err != nil {
+		return err
+	}
+	return r.TLSRotator.EnsureCertificate(ctx, instance, tls, dnsNames)
+}
+
+// reconcileTLSRotation restarts a pod started with a previous certificate, followers first and
+// masters last, each master failed over to one of its replicas first
+func (r *Reconciler) reconcileTLSRotation(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	nodes, err := k8sutils.GetRedisTLSNodes(instance)
+	if err != nil {
+		return err
+	}
+	var primaries []string
+	if tlsrotation.Rotating(instance.Status.TLSRotation) {
+		if primaries, err = k8sutils.GetRedisClusterMasterPods(ctx, r.K8sClient, instance); err != nil {
+			return err
+		}
+	}
+	var failover *common.ConditionCause
+	status, _, err := r.TLSRotator.Reconcile(ctx, tlsrotation.Target{
+		Object:    instance,
+		TLS:       instance.Spec.TLS,
+		Status:    instance.Status.TLSRotation,
+		Nodes:     nodes,
+		Primaries: primaries,
+		Failover: func(ctx context.Context, podName string) (bool, error) {
+			moved, err := k8sutils.FailoverRedisClusterMaster(ctx, r.K8sClient, instance, podName)
+			if moved {
+				failover = &common.ConditionCause{
+					Reason:  commonapi.ConditionReasonFailover,
+					Message: fmt.Sprintf("failing over master %s before restarting it with the new certificate", podName),
+				}
+			}
+			return moved, err
+		},
+	})
+	if err != nil {
+		return err
+	}
+	newStatus := instance.Status.DeepCopy()
+	newStatus.TLSRotation = status
+	common.SetOperation(&newStatus.Conditions, instance.Generation, commonapi.ConditionFailoverInProgress, failover)
+	_, err = r.updateStatus(ctx, instance, *newStatus)
+	return err
+}
+
+// reconcileManagedRollout restarts a leader pod running an outdated revision of the leader
+// statefulset once every pod is ready and the cluster is stable. A pod running a master is failed
+// over to one of its replicas first and deleted by a later reconcile, it is kept while no replica
+// may take over. It reports whether leaders are still to be restarted.
+func (r *Reconciler) reconcileManagedRollout(ctx context.Context, instance *rcvb2.RedisCluster) (bool, error) {
+	if !instance.Spec.KubernetesConfig.UsesManagedFailover() {
+		return false, nil
+	}
+	pods, err := k8sutils.GetStatefulSetOutdatedPods(ctx, r.K8sClient, instance.Namespace, instance.Name+"-leader")
+	if err != nil || len(pods) == 0 {
+		return false, err
+	}
+	if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-leader") || !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-follower") {
+		return true, nil
+	}
+	if stable, err := k8sutils.ClusterStableNoOpenSlots(ctx, r.K8sClient, instance); err != nil || !stable {
+		return true, err
+	}
+
+	pod := pods[0]
+	moved, err := k8sutils.FailoverRedisClusterMaster(ctx, r.K8sClient, instance, pod)
+	if errors.Is(err, k8sutils.ErrNoClusterReplica) {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRolloutBlocked, "Not restarting the master %s, no replica may take over its slots", pod)
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	if moved {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRolloutFailover, "Failed over pod %s before restarting it", pod)
+		return true, r.updateOperation(ctx, instance, commonapi.ConditionFailoverInProgress, &common.ConditionCause{
+			Reason:  commonapi.ConditionReasonFailover,
+			Message: fmt.Sprintf("failing over master %s before restarting it with the new revision", pod),
+		})
+	}
+	if err := r.K8sClient.CoreV1().Pods(instance.Namespace).Delete(ctx, pod, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return true, err
+	}
+	log.FromContext(ctx).Info("Restarted leader pod running an outdated revision", "pod", pod)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRolloutPodRestarted, "Restarted pod %s with the new revision", pod)
+	return true, nil
+}
+
+// reconcileScalingPlan holds a change of the number of leaders or followers of a running cluster
+// whose scaling policy is a dry run: the steps of the scaling are recorded in the pending plan
+// until the apply-plan annotation confirms the generation it was computed for. It reports whether
+// the scaling is held, the plan and the annotation are cleared once no scaling is left.
+func (r *Reconciler) reconcileScalingPlan(ctx context.Context, instance *rcvb2.RedisCluster, leaderReplicas, followerReplicas int32) (bool, error) {
+	leaderCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-leader")
+	followerCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-follower")
+	// the cluster is only planned once its statefulsets were created
+	created := leaderCount > 0 && (followerCount > 0 || followerReplicas == 0)
+	scaling := created && (leaderCount != leaderReplicas || followerCount != followerReplicas)
+	if !instance.Spec.ScalingDryRun() || !scaling {
+		return false, r.clearScalingPlan(ctx, instance)
+	}
+	plan := instance.Status.PendingPlan
+	if plan != nil && plan.Generation == instance.Generation {
+		return instance.GetAnnotations()[ApplyPlanAnnotation] != strconv.FormatInt(plan.Generation, 10), nil
+	}
+
+	plan, err := k8sutils.PlanRedisClusterScaling(ctx, r.K8sClient, instance, leaderCount, followerCount)
+	if err != nil {
+		return true, err
+	}
+	status := instance.Status.DeepCopy()
+	status.PendingPlan = plan
+	if _, err := r.updateStatus(ctx, instance, *status); err != nil {
+		return true, err
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterScalingPlanned,
+		"Planned %d steps scaling the leaders from %d to %d and the followers from %d to %d, annotate with %s=%d to apply them",
+		len(plan.Steps), plan.CurrentLeaders, plan.DesiredLeaders, plan.CurrentFollowers, plan.DesiredFollowers, ApplyPlanAnnotation, plan.Generation)
+	return true, nil
+}
+
+// clearScalingPlan removes the pending plan and the annotation that confirmed it
+func (r *Reconciler) clearScalingPlan(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	if instance.Status.PendingPlan != nil {
+		// updateStatus carries the plan over
+		copy := instance.DeepCopy()
+		copy.Spec = rcvb2.RedisClusterSpec{}
+		copy.Status.PendingPlan = nil
+		if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+			return err
+		}
+		instance.ResourceVersion = copy.ResourceVersion
+		instance.Status = copy.Status
+	}
+	if _, found := instance.GetAnnotations()[ApplyPlanAnnotation]; !found {
+		return nil
+	}
+	patch := client.MergeFrom(instance.DeepCopy())
+	delete(instance.Annotations, ApplyPlanAnnotation)
+	return r.Patch(ctx, instance, patch)
+}
+
+// reconcileDurability sets min-replicas-to-write on the pods, relaxed while a statefulset is
+// scaled down or its pods are restarted
+func (r *Reconciler) reconcileDurability(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	if instance.Spec.Durability == nil && instance.Status.Durability == nil {
+		return nil
+	}
+	var disruption *common.ConditionCause
+	for _, role := range []string{"leader", "follower"} {
+		sts, err := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.Name+"-"+role, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if disruption = common.StatefulSetDisruption(sts, instance.Spec.GetReplicaCounts(role), instance.Spec.KubernetesConfig.UsesManagedFailover()); disruption != nil {
+			break
+		}
+	}
+	if disruption == nil {
+		disruption = common.RotationProgress(instance.Status.PasswordRotation, instance.Status.TLSRotation)
+	}
+	status, err := durability.Reconcile(ctx, r.Recorder, durability.Target{
+		Object:     instance,
+		Durability: instance.Spec.Durability,
+		Status:     instance.Status.Durability,
+		Disruption: disruption,
+		Apply: func(ctx context.Context, config []string) error {
+			return k8sutils.SetRedisClusterConfig(ctx, r.K8sClient, instance, config)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(status, instance.Status.Durability) {
+		return nil
+	}
+	if status == nil {
+		// updateStatus carries the durability over
+		copy := instance.DeepCopy()
+		copy.Spec = rcvb2.RedisClusterSpec{}
+		copy.Status.Durability = nil
+		if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+			return err
+		}
+		instance.ResourceVersion = copy.ResourceVersion
+		instance.Status = copy.Status
+		return nil
+	}
+	newStatus := instance.Status.DeepCopy()
+	newStatus.Durability = status
+	_, err = r.updateStatus(ctx, instance, *newStatus)
+	return err
+}
+
+// reconcileTopology publishes the nodes of the cluster and their slots in the status
+func (r *Reconciler) reconcileTopology(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	nodes, slotsAssigned, err := k8sutils.GetRedisClusterTopology(ctx, r.K8sClient, instance)
+	if err != nil {
+		return err
+	}
+	status := instance.Status.DeepCopy()
+	status.Nodes = nodes
+	status.SlotsAssigned = slotsAssigned
+	_, err = r.updateStatus(ctx, instance, *status)
+	return err
+}
+
+// updateOperation records a scaling or failover started by the reconciler, it is reported until
+// the cluster is ready again
+func (r *Reconciler) updateOperation(ctx context.Context, rc *rcvb2.RedisCluster, conditionType string, cause *common.ConditionCause) error {
+	status := rc.Status.DeepCopy()
+	common.SetOperation(&status.Conditions, rc.Generation, conditionType, cause)
+	_, err := r.updateStatus(ctx, rc, *status)
+	return err
+}
+
 func (r *Reconciler) updateStatus(ctx context.Context, rc *rcvb2.RedisCluster, status rcvb2.RedisClusterStatus) (requeue bool, err error) {
+	// the password and certificate rotations are recorded by their own steps
+	if status.PasswordRotation == nil {
+		status.PasswordRotation = rc.Status.PasswordRotation
+	}
+	if status.TLSRotation == nil {
+		status.TLSRotation = rc.Status.TLSRotation
+	}
+	if status.Conditions == nil {
+		status.Conditions = append([]metav1.Condition(nil), rc.Status.Conditions...)
+	}
+	// the topology is refreshed by reconcileTopology while every node is part of the cluster
+	if status.Nodes == nil {
+		status.Nodes = rc.Status.Nodes
+		status.SlotsAssigned = rc.Status.SlotsAssigned
+	}
+	// the progress of the resharding is recorded by the scale down
+	if status.Resharding == nil {
+		status.Resharding = rc.Status.Resharding
+	}
+	// the scaling plan is recorded and cleared by reconcileScalingPlan
+	if status.PendingPlan == nil {
+		status.PendingPlan = rc.Status.PendingPlan
+	}
+	// the durability is recorded and cleared by reconcileDurability
+	if status.Durability == nil {
+		status.Durability = rc.Status.Durability
+	}
+	status.ObservedGeneration = rc.Generation
+	common.SetConditions(&status.Conditions, rc.Generation, clusterObservation(rc, &status))
 	if reflect.DeepEqual(rc.Status, status) {
 		return false, nil
 	}
@@ -463,6 +1005,11 @@ func (r *Reconciler) updateStatus(ctx context.Context, rc *rcvb2.RedisCluster, s
 	copy.Spec = rcvb2.RedisClusterSpec{}
 	copy.Status = status
 	err = common.UpdateStatus(ctx, r.Client, copy)
+	if err == nil {
+		// later steps of the same reconcile update the status again
+		rc.ResourceVersion = copy.ResourceVersion
+		rc.Status = copy.Status
+	}
 	if err != nil && apierrors.IsConflict(err) {
 		log.FromContext(ctx).Info("conflict detected, reloading instance and retrying status update")
 		namespacedName := client.ObjectKey{
@@ -480,6 +1027,68 @@ func (r *Reconciler) updateStatus(ctx context.Context, rc *rcvb2.RedisCluster, s
 	return false, nil
 }
 
+// updateResharding records the progress of the move of the slots of a shard removed by a scale
+// down
+func (r *Reconciler) updateResharding(ctx context.Context, rc *rcvb2.RedisCluster, progress *rcvb2.ReshardingStatus) error {
+	if progress == nil || reflect.DeepEqual(progress, rc.Status.Resharding) {
+		return nil
+	}
+	newStatus := rc.Status.DeepCopy()
+	newStatus.Resharding = progress
+	_, err := r.updateStatus(ctx, rc, *newStatus)
+	return err
+}
+
+// recordReplacedKeys warns that moving slots overwrote keys of the target with MIGRATE REPLACE,
+// the keys of the same name the target held are lost
+func (r *Reconciler) recordReplacedKeys(rc *rcvb2.RedisCluster, keys int, sourcePod, targetPod string) {
+	r.Recorder.Eventf(rc, corev1.EventTypeWarning, events.EventReasonRedisClusterKeysReplaced,
+		"Moved %d keys from %s to %s with REPLACE, overwriting the keys of the same name %s held", keys, sourcePod, targetPod, targetPod)
+}
+
+// reshardingShard returns the index of the shard whose slots were being moved off when a scale
+// down was interrupted
+func reshardingShard(rc *rcvb2.RedisCluster) (int32, bool) {
+	progress := rc.Status.Resharding
+	if progress == nil || progress.CompletionTime != nil {
+		return 0, false
+	}
+	shardIdx, err := strconv.Atoi(strings.TrimPrefix(progress.SourcePod, rc.Name+"-leader-"))
+	if err != nil {
+		return 0, false
+	}
+	return int32(shardIdx), true
+}
+
+// clusterObservation derives the conditions from the state of the cluster. Scalings and failovers
+// started by the reconciler are reported until the cluster becomes ready.
+func clusterObservation(rc *rcvb2.RedisCluster, status *rcvb2.RedisClusterStatus) common.Observation {
+	o := common.Observation{
+		ScalingInProgress:  common.ActiveCondition(status.Conditions, commonapi.ConditionScalingInProgress),
+		FailoverInProgress: common.ActiveCondition(status.Conditions, commonapi.ConditionFailoverInProgress),
+	}
+	switch status.State {
+	case rcvb2.RedisClusterReady:
+		if rc.Status.State != rcvb2.RedisClusterReady {
+			o.ScalingInProgress = nil
+			o.FailoverInProgress = nil
+		}
+	case rcvb2.RedisClusterBootstrap:
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonBootstrapping, Message: status.Reason}
+		o.Progressing = o.NotReady
+	case rcvb2.RedisClusterFailed:
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonUnhealthyNodes, Message: status.Reason}
+		o.Degraded = o.NotReady
+	default:
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonInitializing, Message: status.Reason}
+		o.Progressing = o.NotReady
+	}
+	if rotation := common.RotationProgress(status.PasswordRotation, status.TLSRotation); rotation != nil && o.Progressing == nil {
+		o.Progressing = rotation
+	}
+	return o
+}
+
 // getStatefulSetReadyReplicas returns the number of ready replicas reported by
 // the StatefulSet status, or 0 if the StatefulSet does not exist yet.
 func (r *Reconciler) getStatefulSetReadyReplicas(ctx context.Context, namespace, name string) int32 {
@@ -499,5 +1108,6 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options)
 		For(&rcvb2.RedisCluster{}).
 		Owns(&appsv1.StatefulSet{}).
 		WithOptions(opts).
+		Watches(&corev1.Secret{}, r.SecretWatcher).
 		Complete(r)
 }
@@ -23,7 +23,10 @@ import (
 	"time"
 
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -96,11 +99,14 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      redis.NewHealer(k8sClient),
-		Recorder:    k8sManager.GetEventRecorderFor("rediscluster-controller"),
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        k8sManager.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        redis.NewHealer(k8sClient),
+		Recorder:      k8sManager.GetEventRecorderFor("rediscluster-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		Rotator:       passwordrotation.NewRotator(k8sClient, k8sManager.GetEventRecorderFor("rediscluster-controller")),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
+		TLSRotator:    tlsrotation.NewRotator(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("rediscluster-controller")),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -5,9 +5,19 @@ import (
 	"errors"
 	"testing"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type fakeChecker struct {
@@ -88,3 +98,176 @@ func TestShouldScaleUpExistingCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterObservation(t *testing.T) {
+	rc := &rcvb2.RedisCluster{Status: rcvb2.RedisClusterStatus{State: rcvb2.RedisClusterReady}}
+	scaling := &common.ConditionCause{Reason: commonapi.ConditionReasonScalingUp, Message: "adding leaders"}
+	status := &rcvb2.RedisClusterStatus{State: rcvb2.RedisClusterInitializing, Reason: rcvb2.InitializingClusterLeaderReason}
+	common.SetOperation(&status.Conditions, 1, commonapi.ConditionScalingInProgress, scaling)
+
+	// the scaling is reported while the cluster initializes the added leaders
+	o := clusterObservation(rc, status)
+	assert.Equal(t, scaling, o.ScalingInProgress)
+	assert.Equal(t, commonapi.ConditionReasonInitializing, o.NotReady.Reason)
+	assert.Equal(t, o.NotReady, o.Progressing)
+	assert.Nil(t, o.Degraded)
+
+	// and ends once the cluster becomes ready
+	rc.Status = *status
+	status = &rcvb2.RedisClusterStatus{State: rcvb2.RedisClusterReady, Conditions: status.Conditions}
+	o = clusterObservation(rc, status)
+	assert.Nil(t, o.ScalingInProgress)
+	assert.Nil(t, o.NotReady)
+	assert.Nil(t, o.Progressing)
+
+	status = &rcvb2.RedisClusterStatus{State: rcvb2.RedisClusterFailed, Reason: "RedisCluster has unhealthy nodes"}
+	o = clusterObservation(rc, status)
+	assert.Equal(t, commonapi.ConditionReasonUnhealthyNodes, o.Degraded.Reason)
+	assert.Equal(t, o.Degraded, o.NotReady)
+}
+
+func TestReshardingShard(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name       string
+		resharding *rcvb2.ReshardingStatus
+		shard      int32
+		resuming   bool
+	}{
+		{name: "no resharding"},
+		{
+			name:       "interrupted resharding",
+			resharding: &rcvb2.ReshardingStatus{SourcePod: "cluster-leader-4", TargetPod: "cluster-leader-1"},
+			shard:      4,
+			resuming:   true,
+		},
+		{
+			name:       "completed resharding",
+			resharding: &rcvb2.ReshardingStatus{SourcePod: "cluster-leader-4", CompletionTime: &now},
+		},
+		{
+			name:       "pod of another cluster",
+			resharding: &rcvb2.ReshardingStatus{SourcePod: "other-leader-4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &rcvb2.RedisCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     rcvb2.RedisClusterStatus{Resharding: tt.resharding},
+			}
+			shard, resuming := reshardingShard(rc)
+			assert.Equal(t, tt.shard, shard)
+			assert.Equal(t, tt.resuming, resuming)
+		})
+	}
+}
+
+func TestRecordReplacedKeys(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	r := &Reconciler{Recorder: recorder}
+	rc := &rcvb2.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"}}
+
+	r.recordReplacedKeys(rc, 3, "cluster-leader-3", "cluster-leader-0")
+
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning RedisClusterKeysReplaced Moved 3 keys from cluster-leader-3 to cluster-leader-0 with REPLACE, overwriting the keys of the same name cluster-leader-0 held", <-recorder.Events)
+}
+
+type fakeStatefulSetService struct {
+	k8sutils.StatefulSet
+	replicas map[string]int32
+}
+
+func (f *fakeStatefulSetService) GetStatefulSetReplicas(_ context.Context, _, name string) int32 {
+	return f.replicas[name]
+}
+
+func newScalingPlanReconcilerForTest(t *testing.T, instance *rcvb2.RedisCluster, leaders, followers int32) (*Reconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+	ctrlClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(instance).
+		WithObjects(instance).
+		Build()
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), instance))
+	return &Reconciler{
+		Client: ctrlClient,
+		StatefulSet: &fakeStatefulSetService{replicas: map[string]int32{
+			instance.Name + "-leader":   leaders,
+			instance.Name + "-follower": followers,
+		}},
+		Recorder: record.NewFakeRecorder(10),
+	}, ctrlClient
+}
+
+func newScalingPlanClusterForTest(generation int64) *rcvb2.RedisCluster {
+	return &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "default", Generation: generation},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize:   ptr.To(int32(3)),
+			ScalingPolicy: &rcvb2.ScalingPolicy{DryRun: true},
+		},
+		Status: rcvb2.RedisClusterStatus{
+			PendingPlan: &rcvb2.ScalingPlan{Generation: generation, CurrentLeaders: 4, DesiredLeaders: 3},
+		},
+	}
+}
+
+func TestReconcileScalingPlan(t *testing.T) {
+	t.Run("holds the scaling until the plan is confirmed", func(t *testing.T) {
+		instance := newScalingPlanClusterForTest(2)
+		r, _ := newScalingPlanReconcilerForTest(t, instance, 4, 4)
+
+		held, err := r.reconcileScalingPlan(context.Background(), instance, 3, 3)
+		require.NoError(t, err)
+		assert.True(t, held)
+
+		// a confirmation of another generation does not apply the plan
+		instance.Annotations = map[string]string{ApplyPlanAnnotation: "1"}
+		held, err = r.reconcileScalingPlan(context.Background(), instance, 3, 3)
+		require.NoError(t, err)
+		assert.True(t, held)
+	})
+
+	t.Run("applies the confirmed plan", func(t *testing.T) {
+		instance := newScalingPlanClusterForTest(2)
+		instance.Annotations = map[string]string{ApplyPlanAnnotation: "2"}
+		r, _ := newScalingPlanReconcilerForTest(t, instance, 4, 4)
+
+		held, err := r.reconcileScalingPlan(context.Background(), instance, 3, 3)
+		require.NoError(t, err)
+		assert.False(t, held)
+	})
+
+	t.Run("clears the plan once the scaling completed", func(t *testing.T) {
+		instance := newScalingPlanClusterForTest(2)
+		instance.Annotations = map[string]string{ApplyPlanAnnotation: "2"}
+		r, ctrlClient := newScalingPlanReconcilerForTest(t, instance, 3, 3)
+
+		held, err := r.reconcileScalingPlan(context.Background(), instance, 3, 3)
+		require.NoError(t, err)
+		assert.False(t, held)
+
+		updated := &rcvb2.RedisCluster{}
+		require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+		assert.Nil(t, updated.Status.PendingPlan)
+		assert.NotContains(t, updated.Annotations, ApplyPlanAnnotation)
+	})
+
+	t.Run("scales without a plan when the policy is not a dry run", func(t *testing.T) {
+		instance := newScalingPlanClusterForTest(2)
+		instance.Spec.ScalingPolicy = nil
+		r, ctrlClient := newScalingPlanReconcilerForTest(t, instance, 4, 4)
+
+		held, err := r.reconcileScalingPlan(context.Background(), instance, 3, 3)
+		require.NoError(t, err)
+		assert.False(t, held)
+
+		updated := &rcvb2.RedisCluster{}
+		require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+		assert.Nil(t, updated.Status.PendingPlan)
+	})
+}
@@ -3,31 +3,50 @@ package redisreplication
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/durability"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
 	redishealer "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/service"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/statefulset"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/switchmaster"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	RedisReplicationFinalizer = "redisReplicationFinalizer"
 	masterGroupName           = "mymaster"
+	// SwitchoverAnnotation requests a single switchover of the master to the pod it names, it is
+	// removed once the switchover completed or failed
+	SwitchoverAnnotation = "redisreplication.opstreelabs.in/switchover-to"
+	// switchoverRetryInterval is how long a failed switchover to the preferred master is not retried
+	switchoverRetryInterval = 5 * time.Minute
 )
 
 // Reconciler reconciles a RedisReplication object
@@ -36,10 +55,17 @@ type Reconciler struct {
 	k8sutils.StatefulSet
 	Healer                     redishealer.Healer
 	K8sClient                  kubernetes.Interface
+	Recorder                   record.EventRecorder
+	Rotator                    *passwordrotation.Rotator
+	TLSRotator                 *tlsrotation.Rotator
+	SecretWatcher              *intctrlutil.ResourceWatcher
+	SwitchMasterWatcher        *switchmaster.Watcher
 	RedisNodesByRole           func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, string) ([]string, error)
 	RedisReplicationRealMaster func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string) string
 	CreateRedisReplicationLink func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string, string) error
 	ConfigureSentinel          func(context.Context, *rrvb2.RedisReplication, string) error
+	SwitchoverRedisReplication func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, string, string, []string) error
+	ReplicasInSync             func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, string, []string) (bool, error)
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -51,6 +77,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	if k8sutils.IsDeleted(instance) {
+		r.SwitchMasterWatcher.Forget(instance)
 		if err := k8sutils.HandleRedisReplicationFinalizer(ctx, r.Client, instance, RedisReplicationFinalizer); err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
@@ -61,22 +88,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return intctrlutil.Reconciled()
 	}
 
-	reconcilers := []reconciler{
-		{typ: "finalizer", rec: r.reconcileFinalizer},
-		{typ: "resources", rec: r.reconcileResources},
-		{typ: "redis", rec: r.reconcileRedis},
-		{typ: "status", rec: r.reconcileStatus},
-	}
-
-	for _, reconciler := range reconcilers {
+	for _, reconciler := range r.reconcilers() {
 		result, err := reconciler.rec(ctx, instance)
 		if err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
 		if result.Requeue {
+			if err := r.reconcileConditions(ctx, instance); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "")
+			}
 			return result, nil
 		}
 	}
+	if err := r.reconcileConditions(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
 
 	return intctrlutil.RequeueAfter(ctx, time.Second*30, "")
 }
@@ -94,17 +120,27 @@ func (r *Reconciler) UpdateRedisReplicationMaster(ctx context.Context, instance
 		return nil
 	}
 
+	// the other fields are recorded by their own steps
+	status := instance.Status.DeepCopy()
+	status.MasterNode = masterNode
+	status.ConnectionInfo = connectionInfo
 	if instance.Status.MasterNode != masterNode {
 		monitoring.RedisReplicationMasterRoleChangesTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
 		logger := log.FromContext(ctx)
 		logger.Info("Updating master node",
 			"previous", instance.Status.MasterNode,
 			"new", masterNode)
+		// a lost master is failing over until another pod is identified as master
+		var failover *common.ConditionCause
+		if masterNode == "" {
+			failover = &common.ConditionCause{
+				Reason:  commonapi.ConditionReasonFailover,
+				Message: fmt.Sprintf("master %s was lost", instance.Status.MasterNode),
+			}
+		}
+		common.SetOperation(&status.Conditions, instance.Generation, commonapi.ConditionFailoverInProgress, failover)
 	}
-	return r.updateStatus(ctx, instance, rrvb2.RedisReplicationStatus{
-		MasterNode:     masterNode,
-		ConnectionInfo: connectionInfo,
-	})
+	return r.updateStatus(ctx, instance, *status)
 }
 
 func connectionInfoEqual(a, b *rrvb2.ConnectionInfo) bool {
@@ -145,6 +181,28 @@ func (r *Reconciler) configureReplicationSentinel(ctx context.Context, instance
 	return r.configureSentinel(ctx, instance, masterPodName)
 }
 
+func (r *Reconciler) switchoverRedisReplication(ctx context.Context, instance *rrvb2.RedisReplication, master, target string, pods []string) error {
+	if r.SwitchoverRedisReplication != nil {
+		return r.SwitchoverRedisReplication(ctx, r.K8sClient, instance, master, target, pods)
+	}
+	if instance.EnableSentinel() {
+		// the sentinels would fail a master promoted behind their back over again
+		sentinel, err := r.getSentinelService(ctx, instance)
+		if err != nil {
+			return err
+		}
+		return k8sutils.SwitchoverRedisReplicationMasterWithSentinel(ctx, r.K8sClient, instance, sentinel, masterGroupName, master, target, pods)
+	}
+	return k8sutils.SwitchoverRedisReplicationMaster(ctx, r.K8sClient, instance, master, target, pods)
+}
+
+func (r *Reconciler) replicasInSync(ctx context.Context, instance *rrvb2.RedisReplication, master string, replicas []string) (bool, error) {
+	if r.ReplicasInSync != nil {
+		return r.ReplicasInSync(ctx, r.K8sClient, instance, master, replicas)
+	}
+	return k8sutils.RedisReplicationReplicasInSync(ctx, r.K8sClient, instance, master, replicas)
+}
+
 func (r *Reconciler) observedRedisReplicationMaster(ctx context.Context, instance *rrvb2.RedisReplication, masterPods []string) (string, bool) {
 	switch len(masterPods) {
 	case 0:
@@ -162,6 +220,25 @@ type reconciler struct {
 	rec func(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error)
 }
 
+// reconcilers are the steps of a reconcile in their order. The durability is relaxed before the
+// resources step scales the statefulset down and takes replicas away from the master.
+func (r *Reconciler) reconcilers() []reconciler {
+	return []reconciler{
+		{typ: "finalizer", rec: r.reconcileFinalizer},
+		{typ: "password", rec: r.reconcilePassword},
+		{typ: "certificate", rec: r.reconcileCertificate},
+		{typ: "durability", rec: r.reconcileDurability},
+		{typ: "resources", rec: r.reconcileResources},
+		{typ: "redis", rec: r.reconcileRedis},
+		{typ: "replicapriority", rec: r.reconcileReplicaPriority},
+		{typ: "switchmaster", rec: r.reconcileSwitchMasterWatch},
+		{typ: "switchover", rec: r.reconcileSwitchover},
+		{typ: "status", rec: r.reconcileStatus},
+		{typ: "tls", rec: r.reconcileTLSRotation},
+		{typ: "rollout", rec: r.reconcileManagedRollout},
+	}
+}
+
 func (r *Reconciler) reconcileFinalizer(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
 	if k8sutils.IsDeleted(instance) {
 		if err := k8sutils.HandleRedisReplicationFinalizer(ctx, r.Client, instance, RedisReplicationFinalizer); err != nil {
@@ -175,8 +252,249 @@ func (r *Reconciler) reconcileFinalizer(ctx context.Context, instance *rrvb2.Red
 	return intctrlutil.Reconciled()
 }
 
+// reconcilePassword rolls a change of the password secret out to the pods. The other steps log in
+// with the password of the secret, they wait until every pod accepts it.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil || secret.Name == nil {
+		return intctrlutil.Reconciled()
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	target := passwordrotation.Target{
+		Object:    instance,
+		Secret:    secret,
+		Spec:      instance.Spec.PasswordRotation,
+		Status:    instance.Status.PasswordRotation,
+		Primaries: []string{instance.Status.MasterNode},
+	}
+	if instance.EnableSentinel() {
+		target.Sentinels = func(ctx context.Context, password string) error {
+			return r.setSentinelAuthPass(ctx, instance, password)
+		}
+	}
+	status, requeueAfter, err := r.Rotator.Reconcile(ctx, target)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to rotate password")
+	}
+	if !reflect.DeepEqual(status, instance.Status.PasswordRotation) {
+		newStatus := instance.Status.DeepCopy()
+		newStatus.PasswordRotation = status
+		if err := r.updateStatus(ctx, instance, *newStatus); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+	}
+	if passwordrotation.Rotating(status) {
+		return intctrlutil.RequeueAfter(ctx, requeueAfter, "waiting for every pod to accept the new password")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileCertificate requests the certificate of the TLS secret from cert-manager when an
+// issuer is configured. The secret is watched either way, its changes are rolled out to the pods.
+func (r *Reconciler) reconcileCertificate(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	tls := instance.Spec.TLS
+	if tls == nil || tls.Secret.SecretName == "" {
+		return intctrlutil.Reconciled()
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: tls.Secret.SecretName},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+	dnsNames, err := k8sutils.GetRedisCertificateDNSNames(instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if err := r.TLSRotator.EnsureCertificate(ctx, instance, tls, dnsNames); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to issue TLS certificate")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileTLSRotation restarts a pod started with a previous certificate, replicas first and the
+// master last. It runs last so the other steps repair the replication between restarts.
+func (r *Reconciler) reconcileTLSRotation(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if instance.Spec.TLS == nil || instance.Status.MasterNode == "" {
+		return intctrlutil.Reconciled()
+	}
+	nodes, err := k8sutils.GetRedisTLSNodes(instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	status, requeueAfter, err := r.TLSRotator.Reconcile(ctx, tlsrotation.Target{
+		Object:    instance,
+		TLS:       instance.Spec.TLS,
+		Status:    instance.Status.TLSRotation,
+		Nodes:     nodes,
+		Primaries: []string{instance.Status.MasterNode},
+	})
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to roll out TLS certificate")
+	}
+	if !reflect.DeepEqual(status, instance.Status.TLSRotation) {
+		newStatus := instance.Status.DeepCopy()
+		newStatus.TLSRotation = status
+		if err := r.updateStatus(ctx, instance, *newStatus); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+	}
+	if requeueAfter > 0 {
+		return intctrlutil.RequeueAfter(ctx, requeueAfter, "rolling out TLS certificate")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileDurability sets min-replicas-to-write on the pods, relaxed while the statefulset is
+// scaled down or its pods are restarted. It runs before the resources step updates the
+// statefulset: a scale down is seen from the replicas of the statefulset exceeding the size of
+// the spec, and the durability stays relaxed until the removed pods are gone.
+func (r *Reconciler) reconcileDurability(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if instance.Spec.Durability == nil && instance.Status.Durability == nil {
+		return intctrlutil.Reconciled()
+	}
+	// the pods may still reject the password of the secret
+	if passwordrotation.Rotating(instance.Status.PasswordRotation) {
+		return intctrlutil.Reconciled()
+	}
+	sts, err := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.RedisStatefulSet(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return intctrlutil.Reconciled()
+	} else if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	disruption := common.StatefulSetDisruption(sts, instance.Spec.GetReplicationCounts(""), instance.Spec.KubernetesConfig.UsesManagedFailover())
+	if disruption == nil {
+		disruption = common.RotationProgress(instance.Status.PasswordRotation, instance.Status.TLSRotation)
+	}
+	status, err := durability.Reconcile(ctx, r.Recorder, durability.Target{
+		Object:     instance,
+		Durability: instance.Spec.Durability,
+		Status:     instance.Status.Durability,
+		Disruption: disruption,
+		Apply: func(ctx context.Context, config []string) error {
+			return k8sutils.SetRedisReplicationConfig(ctx, r.K8sClient, instance, config)
+		},
+	})
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to apply the durability")
+	}
+	if !reflect.DeepEqual(status, instance.Status.Durability) {
+		newStatus := instance.Status.DeepCopy()
+		newStatus.Durability = status
+		if err := r.updateStatus(ctx, instance, *newStatus); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileManagedRollout restarts a pod running an outdated revision of the statefulset with the
+// ManagedFailover update strategy, one at a time and only while every replica is in sync with the
+// master. The replicas are restarted first, the master is switched over to a replica before its
+// pod is restarted last.
+func (r *Reconciler) reconcileManagedRollout(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if !instance.Spec.KubernetesConfig.UsesManagedFailover() || tlsrotation.Rotating(instance.Status.TLSRotation) {
+		return intctrlutil.Reconciled()
+	}
+	outdated, err := k8sutils.GetStatefulSetOutdatedPods(ctx, r.K8sClient, instance.Namespace, instance.RedisStatefulSet())
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if len(outdated) == 0 {
+		return intctrlutil.Reconciled()
+	}
+	if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.RedisStatefulSet()) ||
+		(instance.EnableSentinel() && !r.IsStatefulSetReady(ctx, instance.Namespace, instance.SentinelStatefulSet())) {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the pods to be ready before restarting the next one")
+	}
+	masterNodes, err := r.redisNodesByRole(ctx, instance, "master")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	slaveNodes, err := r.redisNodesByRole(ctx, instance, "slave")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if len(masterNodes) != 1 || masterNodes[0] != instance.Status.MasterNode {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for a single master before restarting the next pod", "masters", masterNodes)
+	}
+	master := masterNodes[0]
+	inSync, err := r.replicasInSync(ctx, instance, master, slaveNodes)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if !inSync {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the replicas to catch up with the master before restarting the next pod")
+	}
+
+	var pod string
+	for _, name := range outdated {
+		if name != master {
+			pod = name
+			break
+		}
+	}
+	if pod == "" && instance.Spec.GetReplicationCounts("replication") > 1 {
+		// only the master is left, a replica in sync takes over its role first. A replica which
+		// may never be promoted does not, and the master is not restarted without one.
+		priorities, err := k8sutils.GetRedisReplicationReplicaPriorities(ctx, r.K8sClient, instance)
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to read the replica priorities")
+		}
+		candidates := k8sutils.PromotableReplicas(slaveNodes, priorities)
+		if len(candidates) == 0 {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRolloutBlocked, "Not restarting the master %s, no replica may take over its role", master)
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for a promotable replica before restarting the master", "replicas", slaveNodes)
+		}
+		target := candidates[0]
+		if slices.Contains(candidates, instance.Spec.PreferredMaster) {
+			target = instance.Spec.PreferredMaster
+		}
+		if err := r.switchoverRedisReplication(ctx, instance, master, target, append(masterNodes, slaveNodes...)); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to switch the master over before restarting it")
+		}
+		if instance.EnableSentinel() {
+			// the sentinels are pointed at the new master again by the next reconcile when this fails
+			if err := r.configureReplicationSentinel(ctx, instance, target); err != nil {
+				log.FromContext(ctx).Error(err, "failed to configure sentinel")
+			}
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRolloutFailover, "Switched the master over from %s to %s before restarting it", master, target)
+		// the new master and the role labels are recorded before the previous master goes away
+		if result, err := r.reconcileStatus(ctx, instance); err != nil || result.Requeue {
+			return result, err
+		}
+	}
+	if pod == "" {
+		// a single pod has no replica to take over its role
+		pod = master
+	}
+	if err := r.K8sClient.CoreV1().Pods(instance.Namespace).Delete(ctx, pod, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	log.FromContext(ctx).Info("Restarted pod running an outdated revision", "pod", pod)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRolloutPodRestarted, "Restarted pod %s with the new revision", pod)
+	return intctrlutil.RequeueAfter(ctx, time.Second*10, "restarting the pods with the new revision")
+}
+
 func (r *Reconciler) reconcileResources(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
-	if err := k8sutils.CreateReplicationRedis(ctx, instance, r.K8sClient); err != nil {
+	restore, err := common.GetRestore(ctx, r.Client, rbvb2.BackupTargetRedisReplication, instance.Namespace, instance.Name)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to get redis restore")
+	}
+	wait, err := k8sutils.WaitForRestore(ctx, r.K8sClient, restore, instance.Namespace, instance.Name)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if wait {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the redis restore to resolve its snapshots", "RedisRestore", restore.Name)
+	}
+	if err := k8sutils.CreateReplicationRedis(ctx, instance, r.K8sClient, k8sutils.NewRestoreSource(restore)); err != nil {
 		return intctrlutil.RequeueAfter(ctx, time.Second*60, "")
 	}
 	if err := k8sutils.CreateReplicationService(ctx, instance, r.K8sClient); err != nil {
@@ -276,6 +594,29 @@ func (r *Reconciler) getSentinelPods(ctx context.Context, inst *rrvb2.RedisRepli
 	})
 }
 
+// getSentinelService connects to the first sentinel pod with an IP
+func (r *Reconciler) getSentinelService(ctx context.Context, inst *rrvb2.RedisReplication) (redis.Service, error) {
+	sentinelPods, err := r.getSentinelPods(ctx, inst)
+	if err != nil {
+		return nil, fmt.Errorf("get sentinel pods: %w", err)
+	}
+	sentinelPassword, err := r.getSentinelPassword(ctx, inst)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range sentinelPods.Items {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		return redis.NewClient().Connect(&redis.ConnectionInfo{
+			Host:     pod.Status.PodIP,
+			Port:     "26379",
+			Password: sentinelPassword,
+		}), nil
+	}
+	return nil, fmt.Errorf("no sentinel pod of %s is running", inst.SentinelStatefulSet())
+}
+
 func (r *Reconciler) configureSentinelPod(
 	ctx context.Context,
 	redisClient redis.Client,
@@ -284,17 +625,9 @@ func (r *Reconciler) configureSentinelPod(
 	masterAddr string,
 	masterPassword string,
 ) error {
-	var sentinelPassword string
-	if inst.Spec.Sentinel.ExistingPasswordSecret != nil {
-		secret, err := r.K8sClient.CoreV1().Secrets(inst.Namespace).Get(
-			ctx,
-			*inst.Spec.Sentinel.ExistingPasswordSecret.Name,
-			metav1.GetOptions{},
-		)
-		if err != nil {
-			return err
-		}
-		sentinelPassword = string(secret.Data[*inst.Spec.Sentinel.ExistingPasswordSecret.Key])
+	sentinelPassword, err := r.getSentinelPassword(ctx, inst)
+	if err != nil {
+		return err
 	}
 
 	sentinelConnInfo := &redis.ConnectionInfo{
@@ -341,6 +674,50 @@ func (r *Reconciler) configureSentinelPod(
 	return nil
 }
 
+func (r *Reconciler) getSentinelPassword(ctx context.Context, inst *rrvb2.RedisReplication) (string, error) {
+	if inst.Spec.Sentinel.ExistingPasswordSecret == nil {
+		return "", nil
+	}
+	secret, err := r.K8sClient.CoreV1().Secrets(inst.Namespace).Get(
+		ctx,
+		*inst.Spec.Sentinel.ExistingPasswordSecret.Name,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[*inst.Spec.Sentinel.ExistingPasswordSecret.Key]), nil
+}
+
+// setSentinelAuthPass points the sentinels at the new master password, a sentinel that does not
+// monitor the master yet is given the password with SENTINEL MONITOR
+func (r *Reconciler) setSentinelAuthPass(ctx context.Context, inst *rrvb2.RedisReplication, password string) error {
+	sentinelPods, err := r.getSentinelPods(ctx, inst)
+	if err != nil {
+		return fmt.Errorf("get sentinel pods: %w", err)
+	}
+	sentinelPassword, err := r.getSentinelPassword(ctx, inst)
+	if err != nil {
+		return err
+	}
+
+	redisClient := redis.NewClient()
+	for _, pod := range sentinelPods.Items {
+		if pod.Status.PodIP == "" {
+			continue
+		}
+		sentinelService := redisClient.Connect(&redis.ConnectionInfo{
+			Host:     pod.Status.PodIP,
+			Port:     "26379",
+			Password: sentinelPassword,
+		})
+		if err := sentinelService.SentinelSet(ctx, masterGroupName, "auth-pass", password); err != nil && !strings.Contains(err.Error(), "No such master") {
+			return fmt.Errorf("sentinel pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
 func (r *Reconciler) sentinelResetIfNeed(ctx context.Context, inst *rrvb2.RedisReplication, redisService redis.Service) error {
 	logger := log.FromContext(ctx)
 
@@ -433,7 +810,10 @@ func (r *Reconciler) reconcileRedis(ctx context.Context, instance *rrvb2.RedisRe
 
 			// Elect a new master based on redis offset. This is a best-effort attempt to pick the most up-to-date master.
 			if realMaster == "" {
-				bestMaster := k8sutils.GetRedisReplicationBestMaster(ctx, r.K8sClient, instance, masterNodes)
+				bestMaster, err := k8sutils.GetRedisReplicationBestMaster(ctx, r.K8sClient, instance, masterNodes)
+				if err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to read the replica priorities")
+				}
 				if bestMaster != "" {
 					log.FromContext(ctx).Info("No master with attached slaves found, falling back to best master based on Redis offset",
 						"bestMaster", bestMaster)
@@ -445,7 +825,10 @@ func (r *Reconciler) reconcileRedis(ctx context.Context, instance *rrvb2.RedisRe
 			// Arbitrarily pick masterNodes[0] as the new master to bootstrap replication.
 			// This choice is stable within a reconcile cycle and will be corrected by
 			// Status.MasterNode on subsequent cycles once replication is established.
-			if realMaster == "" {
+			// With replica priorities no pod may be promoted, the conditions report it.
+			if realMaster == "" && len(instance.Spec.ReplicaPriority) > 0 {
+				log.FromContext(ctx).Info("No master with attached slaves found and no pod may be promoted with the replica priorities")
+			} else if realMaster == "" {
 				log.FromContext(ctx).Info("No real master found via slave count or Status.MasterNode; "+
 					"electing first master node as bootstrap master", "podName", masterNodes[0])
 				realMaster = masterNodes[0]
@@ -504,6 +887,156 @@ func (r *Reconciler) reconcileRedis(ctx context.Context, instance *rrvb2.RedisRe
 	return intctrlutil.Reconciled()
 }
 
+// reconcileReplicaPriority sets the replica-priority of spec.replicaPriority on the pods on every
+// reconcile, a restarted pod starts with the default one
+func (r *Reconciler) reconcileReplicaPriority(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if len(instance.Spec.ReplicaPriority) == 0 && len(instance.Status.ReplicaPriorities) == 0 {
+		return intctrlutil.Reconciled()
+	}
+	priorities, err := k8sutils.SetRedisReplicationReplicaPriority(ctx, r.K8sClient, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to set the replica priority")
+	}
+	if !reflect.DeepEqual(priorities, instance.Status.ReplicaPriorities) {
+		newStatus := instance.Status.DeepCopy()
+		newStatus.ReplicaPriorities = priorities
+		if err := r.updateStatus(ctx, instance, *newStatus); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileSwitchMasterWatch subscribes to the master switches of the sentinels, a switch
+// reconciles the replication right away to record the new master and update the role labels
+func (r *Reconciler) reconcileSwitchMasterWatch(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if !instance.EnableSentinel() {
+		r.SwitchMasterWatcher.Forget(instance)
+		return intctrlutil.Reconciled()
+	}
+	sentinelPods, err := r.getSentinelPods(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to get the sentinel pods")
+	}
+	sentinelPassword, err := r.getSentinelPassword(ctx, instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to get the sentinel password")
+	}
+	sub := switchmaster.Subscription{
+		Replications: map[string]string{masterGroupName: instance.Name},
+	}
+	for _, pod := range sentinelPods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.PodIP == "" {
+			continue
+		}
+		sub.Sentinels = append(sub.Sentinels, &redis.ConnectionInfo{
+			Host:     pod.Status.PodIP,
+			Port:     "26379",
+			Password: sentinelPassword,
+		})
+	}
+	r.SwitchMasterWatcher.Watch(ctx, instance, sub)
+	return intctrlutil.Reconciled()
+}
+
+// reconcileSwitchover moves the master to the pod requested by the switchover annotation, or to
+// the preferred master when it runs as a replica. It runs before the status step, which records
+// the new master and updates the role labels.
+func (r *Reconciler) reconcileSwitchover(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	target, requested := instance.GetAnnotations()[SwitchoverAnnotation]
+	if !requested {
+		target = instance.Spec.PreferredMaster
+		// a failed switchover to the preferred master is not retried on every reconcile
+		if last := instance.Status.Switchover; last != nil && last.To == target && last.Phase == rrvb2.SwitchoverFailed &&
+			last.CompletionTime != nil && time.Since(last.CompletionTime.Time) < switchoverRetryInterval {
+			return intctrlutil.Reconciled()
+		}
+	}
+	if target == "" {
+		return intctrlutil.Reconciled()
+	}
+	if !instance.IsRedisPod(target) {
+		return r.finishSwitchover(ctx, instance, requested, &rrvb2.SwitchoverStatus{
+			To:      target,
+			Phase:   rrvb2.SwitchoverFailed,
+			Message: fmt.Sprintf("%s is not a pod of the replication", target),
+		})
+	}
+
+	masterNodes, err := r.redisNodesByRole(ctx, instance, "master")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if len(masterNodes) == 1 && masterNodes[0] == target {
+		if requested {
+			if err := r.removeSwitchoverAnnotation(ctx, instance); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "")
+			}
+		}
+		return intctrlutil.Reconciled()
+	}
+	slaveNodes, err := r.redisNodesByRole(ctx, instance, "slave")
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if len(masterNodes) != 1 || !slices.Contains(slaveNodes, target) ||
+		!r.IsStatefulSetReady(ctx, instance.Namespace, instance.RedisStatefulSet()) {
+		logger.Info("Waiting for the switchover target to replicate from a single master", "target", target, "masters", masterNodes)
+		return intctrlutil.Reconciled()
+	}
+
+	master := masterNodes[0]
+	startTime := metav1.Now()
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonSwitchoverStarted, "Switching the master over from %s to %s", master, target)
+	status := &rrvb2.SwitchoverStatus{
+		From:      master,
+		To:        target,
+		Phase:     rrvb2.SwitchoverCompleted,
+		StartTime: &startTime,
+	}
+	if err := r.switchoverRedisReplication(ctx, instance, master, target, append(masterNodes, slaveNodes...)); err != nil {
+		logger.Error(err, "Switchover failed", "master", master, "target", target)
+		status.Phase = rrvb2.SwitchoverFailed
+		status.Message = err.Error()
+	} else if instance.EnableSentinel() {
+		// the sentinels are pointed at the new master again by the next reconcile when this fails
+		if err := r.configureReplicationSentinel(ctx, instance, target); err != nil {
+			logger.Error(err, "failed to configure sentinel")
+		}
+	}
+	return r.finishSwitchover(ctx, instance, requested, status)
+}
+
+// finishSwitchover records the result of a switchover and removes the annotation that requested it
+func (r *Reconciler) finishSwitchover(ctx context.Context, instance *rrvb2.RedisReplication, requested bool, switchover *rrvb2.SwitchoverStatus) (ctrl.Result, error) {
+	completionTime := metav1.Now()
+	switchover.CompletionTime = &completionTime
+	if switchover.Phase == rrvb2.SwitchoverFailed {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonSwitchoverFailed, "Switchover to %s failed: %s", switchover.To, switchover.Message)
+	} else {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonSwitchoverCompleted, "Switched the master over from %s to %s", switchover.From, switchover.To)
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Switchover = switchover
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if requested {
+		if err := r.removeSwitchoverAnnotation(ctx, instance); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+	}
+	return intctrlutil.Reconciled()
+}
+
+func (r *Reconciler) removeSwitchoverAnnotation(ctx context.Context, instance *rrvb2.RedisReplication) error {
+	patch := client.MergeFrom(instance.DeepCopy())
+	delete(instance.Annotations, SwitchoverAnnotation)
+	return r.Patch(ctx, instance, patch)
+}
+
 // reconcileStatus update status and label.
 func (r *Reconciler) reconcileStatus(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
 	var err error
@@ -535,11 +1068,74 @@ func (r *Reconciler) reconcileStatus(ctx context.Context, instance *rrvb2.RedisR
 	return intctrlutil.Reconciled()
 }
 
+// reconcileConditions records the conditions of the replication after every pass
+func (r *Reconciler) reconcileConditions(ctx context.Context, instance *rrvb2.RedisReplication) error {
+	sts, err := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.RedisStatefulSet(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		sts = nil
+	} else if err != nil {
+		return err
+	}
+	conditions := instance.Status.Conditions
+	o := common.Observation{
+		ScalingInProgress:  common.StatefulSetScaling(sts, *instance.Spec.Size, common.ActiveCondition(conditions, commonapi.ConditionScalingInProgress)),
+		FailoverInProgress: common.ActiveCondition(conditions, commonapi.ConditionFailoverInProgress),
+	}
+	ready := sts != nil && r.IsStatefulSetReady(ctx, instance.Namespace, instance.RedisStatefulSet())
+	if ready && instance.EnableSentinel() {
+		ready = r.IsStatefulSetReady(ctx, instance.Namespace, instance.SentinelStatefulSet())
+	}
+	switch {
+	case !ready:
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonPodsNotReady, Message: "waiting for the redis pods to be ready"}
+		o.Progressing = o.NotReady
+	case instance.Status.MasterNode == "" && noPromotableReplica(instance):
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonNoPromotableReplica, Message: "no pod is identified as master and no pod may be promoted, every pod has replica-priority 0 or an unknown one"}
+		o.Degraded = o.NotReady
+	case instance.Status.MasterNode == "":
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonNoMaster, Message: "no pod is identified as master"}
+		// every pod is ready, without a failover replication is broken
+		if o.FailoverInProgress == nil {
+			o.Degraded = &common.ConditionCause{Reason: commonapi.ConditionReasonBrokenReplication, Message: o.NotReady.Message}
+		}
+	}
+	if rotation := common.RotationProgress(instance.Status.PasswordRotation, instance.Status.TLSRotation); rotation != nil && o.Progressing == nil {
+		o.Progressing = rotation
+	}
+
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+	common.SetConditions(&status.Conditions, instance.Generation, o)
+	if reflect.DeepEqual(*status, instance.Status) {
+		return nil
+	}
+	return r.updateStatus(ctx, instance, *status)
+}
+
+// noPromotableReplica reports whether the replica priorities leave no pod to elect as master
+func noPromotableReplica(instance *rrvb2.RedisReplication) bool {
+	if len(instance.Spec.ReplicaPriority) == 0 {
+		return false
+	}
+	for _, priority := range instance.Status.ReplicaPriorities {
+		if priority > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *Reconciler) updateStatus(ctx context.Context, rr *rrvb2.RedisReplication, status rrvb2.RedisReplicationStatus) error {
 	copy := rr.DeepCopy()
 	copy.Spec = rrvb2.RedisReplicationSpec{}
 	copy.Status = status
-	return common.UpdateStatus(ctx, r.Client, copy)
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	// later steps of the same reconcile update the status again
+	rr.ResourceVersion = copy.ResourceVersion
+	rr.Status = copy.Status
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -547,5 +1143,7 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rrvb2.RedisReplication{}).
 		WithOptions(opts).
+		Watches(&corev1.Secret{}, r.SecretWatcher).
+		WatchesRawSource(&source.Channel{Source: r.SwitchMasterWatcher.Source()}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
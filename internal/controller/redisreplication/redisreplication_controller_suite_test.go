@@ -23,7 +23,11 @@ import (
 	"time"
 
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/passwordrotation"
 	redis "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/switchmaster"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -98,10 +102,15 @@ var _ = BeforeSuite(func() {
 	healer := redis.NewHealer(k8sClient)
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:              k8sManager.GetClient(),
+		K8sClient:           k8sClient,
+		Healer:              healer,
+		Recorder:            k8sManager.GetEventRecorderFor("redisreplication-controller"),
+		StatefulSet:         k8sutils.NewStatefulSetService(k8sClient),
+		Rotator:             passwordrotation.NewRotator(k8sClient, k8sManager.GetEventRecorderFor("redisreplication-controller")),
+		SecretWatcher:       intctrlutil.NewResourceWatcher(),
+		TLSRotator:          tlsrotation.NewRotator(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("redisreplication-controller")),
+		SwitchMasterWatcher: switchmaster.NewWatcher(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("redisreplication-controller")),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
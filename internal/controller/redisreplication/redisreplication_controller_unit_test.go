@@ -2,6 +2,9 @@ package redisreplication
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
 
 	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
@@ -9,10 +12,15 @@ import (
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -101,6 +109,57 @@ func TestReconcileRedisKeepsHealthyBehaviorWhenTopologyIsComplete(t *testing.T)
 	assert.Equal(t, "example-replication-1", gotMaster)
 }
 
+func TestReconcileRedisDoesNotElectAMasterThatIsNeverPromoted(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{{Ordinals: []int32{0, 1, 2}, Priority: 0}}
+	createCalled := false
+	r := &Reconciler{
+		K8sClient: fake.NewSimpleClientset(),
+		RedisNodesByRole: func(_ context.Context, _ kubernetes.Interface, _ *rrvb2.RedisReplication, role string) ([]string, error) {
+			if role == "master" {
+				return []string{"example-replication-0", "example-replication-1", "example-replication-2"}, nil
+			}
+			return nil, nil
+		},
+		RedisReplicationRealMaster: func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string) string {
+			return ""
+		},
+		CreateRedisReplicationLink: func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string, string) error {
+			createCalled = true
+			return nil
+		},
+	}
+	result, err := r.reconcileRedis(context.Background(), instance)
+
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.False(t, createCalled)
+}
+
+func TestReconcileConditionsReportsNoPromotableReplica(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{{Ordinals: []int32{0, 1, 2}, Priority: 0}}
+	r, _ := newStatusReconcilerForTest(t, instance)
+	instance.Status.ReplicaPriorities = map[string]int32{"example-replication-0": 0, "example-replication-1": 0, "example-replication-2": 0}
+	r.StatefulSet = &fakeStatefulSetService{}
+	r.K8sClient = fake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.RedisStatefulSet(), Namespace: instance.Namespace},
+		Spec:       appsv1.StatefulSetSpec{Replicas: instance.Spec.Size},
+		Status:     appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 3},
+	})
+
+	require.NoError(t, r.reconcileConditions(context.Background(), instance))
+
+	ready := meta.FindStatusCondition(instance.Status.Conditions, commonapi.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, commonapi.ConditionReasonNoPromotableReplica, ready.Reason)
+	degraded := meta.FindStatusCondition(instance.Status.Conditions, commonapi.ConditionDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, commonapi.ConditionReasonNoPromotableReplica, degraded.Reason)
+}
+
 func TestReconcileStatusStillRunsWhenOnePodIsUnobserved(t *testing.T) {
 	scheme := runtime.NewScheme()
 	require.NoError(t, rrvb2.AddToScheme(scheme))
@@ -241,15 +300,15 @@ type fakeHealer struct {
 	updateCalled bool
 }
 
-func (f *fakeHealer) SentinelMonitor(context.Context, *rsvb2.RedisSentinel, string) error {
+func (f *fakeHealer) SentinelMonitor(context.Context, *rsvb2.RedisSentinel, rsvb2.SentinelMaster, string) error {
 	return nil
 }
 
-func (f *fakeHealer) SentinelSet(context.Context, *rsvb2.RedisSentinel, string) error {
+func (f *fakeHealer) SentinelSet(context.Context, *rsvb2.RedisSentinel, rsvb2.SentinelMaster, string) error {
 	return nil
 }
 
-func (f *fakeHealer) SentinelReset(context.Context, *rsvb2.RedisSentinel) error {
+func (f *fakeHealer) SentinelReset(context.Context, *rsvb2.RedisSentinel, rsvb2.SentinelMaster) error {
 	return nil
 }
 
@@ -257,3 +316,337 @@ func (f *fakeHealer) UpdateRedisRoleLabel(context.Context, string, map[string]st
 	f.updateCalled = true
 	return nil
 }
+
+func newSwitchoverReconcilerForTest(t *testing.T, instance *rrvb2.RedisReplication, switchover func(master, target string, pods []string) error) (*Reconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+	ctrlClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(instance).
+		WithObjects(instance).
+		Build()
+	r := &Reconciler{
+		Client:      ctrlClient,
+		K8sClient:   fake.NewSimpleClientset(),
+		StatefulSet: &fakeStatefulSetService{},
+		Recorder:    record.NewFakeRecorder(10),
+		RedisNodesByRole: func(_ context.Context, _ kubernetes.Interface, _ *rrvb2.RedisReplication, role string) ([]string, error) {
+			if role == "master" {
+				return []string{"example-replication-0"}, nil
+			}
+			return []string{"example-replication-1", "example-replication-2"}, nil
+		},
+		SwitchoverRedisReplication: func(_ context.Context, _ kubernetes.Interface, _ *rrvb2.RedisReplication, master, target string, pods []string) error {
+			return switchover(master, target, pods)
+		},
+	}
+	return r, ctrlClient
+}
+
+func TestReconcileSwitchoverToAnnotatedPod(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Annotations = map[string]string{SwitchoverAnnotation: "example-replication-2"}
+	var gotMaster, gotTarget string
+	var gotPods []string
+	r, ctrlClient := newSwitchoverReconcilerForTest(t, instance, func(master, target string, pods []string) error {
+		gotMaster, gotTarget, gotPods = master, target, pods
+		return nil
+	})
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), instance))
+
+	result, err := r.reconcileSwitchover(context.Background(), instance)
+
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, "example-replication-0", gotMaster)
+	assert.Equal(t, "example-replication-2", gotTarget)
+	assert.ElementsMatch(t, []string{"example-replication-0", "example-replication-1", "example-replication-2"}, gotPods)
+
+	updated := &rrvb2.RedisReplication{}
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+	assert.NotContains(t, updated.Annotations, SwitchoverAnnotation)
+	require.NotNil(t, updated.Status.Switchover)
+	assert.Equal(t, rrvb2.SwitchoverCompleted, updated.Status.Switchover.Phase)
+	assert.Equal(t, "example-replication-0", updated.Status.Switchover.From)
+	assert.Equal(t, "example-replication-2", updated.Status.Switchover.To)
+}
+
+func TestReconcileSwitchoverRejectsPodOutsideReplication(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Annotations = map[string]string{SwitchoverAnnotation: "example-replication-3"}
+	r, ctrlClient := newSwitchoverReconcilerForTest(t, instance, func(string, string, []string) error {
+		t.Fatal("switchover to a pod outside the replication")
+		return nil
+	})
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), instance))
+
+	_, err := r.reconcileSwitchover(context.Background(), instance)
+
+	require.NoError(t, err)
+	updated := &rrvb2.RedisReplication{}
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+	assert.NotContains(t, updated.Annotations, SwitchoverAnnotation)
+	require.NotNil(t, updated.Status.Switchover)
+	assert.Equal(t, rrvb2.SwitchoverFailed, updated.Status.Switchover.Phase)
+}
+
+func TestReconcileSwitchoverToPreferredMasterIsNotRetriedRightAfterFailure(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Spec.PreferredMaster = "example-replication-1"
+	calls := 0
+	r, ctrlClient := newSwitchoverReconcilerForTest(t, instance, func(string, string, []string) error {
+		calls++
+		return errors.New("example-replication-1 did not catch up")
+	})
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), instance))
+
+	_, err := r.reconcileSwitchover(context.Background(), instance)
+	require.NoError(t, err)
+	require.NotNil(t, instance.Status.Switchover)
+	assert.Equal(t, rrvb2.SwitchoverFailed, instance.Status.Switchover.Phase)
+	assert.Equal(t, "example-replication-1 did not catch up", instance.Status.Switchover.Message)
+
+	_, err = r.reconcileSwitchover(context.Background(), instance)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+// rolloutTest is a replication of three pods with the ManagedFailover update strategy whose pods
+// run the given revisions, the revision of the statefulset being "new"
+type rolloutTest struct {
+	r          *Reconciler
+	k8sClient  *fake.Clientset
+	ctrlClient client.Client
+	healer     *fakeHealer
+	instance   *rrvb2.RedisReplication
+	master     string
+	inSync     bool
+	switchedTo string
+}
+
+func newRolloutTest(t *testing.T, revisions ...string) *rolloutTest {
+	t.Helper()
+	instance := newReplicationInstanceForTest()
+	instance.Spec.KubernetesConfig.UpdateStrategy.Type = commonapi.ManagedFailoverUpdateStrategyType
+	instance.Status.MasterNode = "example-replication-0"
+
+	objects := []runtime.Object{&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.RedisStatefulSet(), Namespace: instance.Namespace},
+		Spec:       appsv1.StatefulSetSpec{Replicas: instance.Spec.Size},
+		Status:     appsv1.StatefulSetStatus{UpdateRevision: "new"},
+	}}
+	for i, revision := range revisions {
+		objects = append(objects, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("example-replication-%d", i),
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{appsv1.StatefulSetRevisionLabel: revision},
+		}})
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+	tt := &rolloutTest{
+		k8sClient: fake.NewSimpleClientset(objects...),
+		ctrlClient: clientfake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(instance).
+			WithObjects(instance).
+			Build(),
+		healer: &fakeHealer{},
+		master: "example-replication-0",
+		inSync: true,
+	}
+	tt.r = &Reconciler{
+		Client:      tt.ctrlClient,
+		K8sClient:   tt.k8sClient,
+		StatefulSet: &fakeStatefulSetService{},
+		Healer:      tt.healer,
+		Recorder:    record.NewFakeRecorder(10),
+		RedisNodesByRole: func(_ context.Context, _ kubernetes.Interface, _ *rrvb2.RedisReplication, role string) ([]string, error) {
+			var pods []string
+			for i := range revisions {
+				pod := fmt.Sprintf("example-replication-%d", i)
+				if (pod == tt.master) == (role == "master") {
+					pods = append(pods, pod)
+				}
+			}
+			return pods, nil
+		},
+		ReplicasInSync: func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, string, []string) (bool, error) {
+			return tt.inSync, nil
+		},
+		SwitchoverRedisReplication: func(_ context.Context, _ kubernetes.Interface, _ *rrvb2.RedisReplication, _, target string, _ []string) error {
+			tt.master, tt.switchedTo = target, target
+			return nil
+		},
+	}
+	tt.instance = &rrvb2.RedisReplication{}
+	require.NoError(t, tt.ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), tt.instance))
+	return tt
+}
+
+func (tt *rolloutTest) podExists(t *testing.T, name string) bool {
+	t.Helper()
+	_, err := tt.k8sClient.CoreV1().Pods("default").Get(context.Background(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func TestReconcileManagedRolloutRestartsReplicasFirst(t *testing.T) {
+	tt := newRolloutTest(t, "old", "old", "new")
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.False(t, tt.podExists(t, "example-replication-1"))
+	assert.True(t, tt.podExists(t, "example-replication-0"))
+	assert.Empty(t, tt.switchedTo)
+}
+
+func TestReconcileManagedRolloutWaitsForReplicasToCatchUp(t *testing.T) {
+	tt := newRolloutTest(t, "old", "new", "old")
+	tt.inSync = false
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.True(t, tt.podExists(t, "example-replication-2"))
+}
+
+func TestReconcileManagedRolloutSwitchesMasterOverBeforeRestartingIt(t *testing.T) {
+	tt := newRolloutTest(t, "old", "new", "new")
+	tt.instance.Spec.PreferredMaster = "example-replication-2"
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, "example-replication-2", tt.switchedTo)
+	assert.False(t, tt.podExists(t, "example-replication-0"))
+	assert.True(t, tt.healer.updateCalled)
+
+	updated := &rrvb2.RedisReplication{}
+	require.NoError(t, tt.ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(tt.instance), updated))
+	assert.Equal(t, "example-replication-2", updated.Status.MasterNode)
+}
+
+func TestReconcileManagedRolloutSkipsReplicasThatAreNeverPromoted(t *testing.T) {
+	tt := newRolloutTest(t, "old", "new", "new")
+	tt.instance.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{{Ordinals: []int32{1}, Priority: 0}}
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, "example-replication-2", tt.switchedTo)
+	assert.False(t, tt.podExists(t, "example-replication-0"))
+}
+
+func TestReconcileManagedRolloutKeepsTheMasterWithoutPromotableReplica(t *testing.T) {
+	tt := newRolloutTest(t, "old", "new", "new")
+	tt.instance.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{{Ordinals: []int32{1, 2}, Priority: 0}}
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Empty(t, tt.switchedTo)
+	assert.True(t, tt.podExists(t, "example-replication-0"))
+	assert.Equal(t, "Warning RolloutBlocked Not restarting the master example-replication-0, no replica may take over its role",
+		<-tt.r.Recorder.(*record.FakeRecorder).Events)
+}
+
+func TestReconcileManagedRolloutIgnoresOtherUpdateStrategies(t *testing.T) {
+	tt := newRolloutTest(t, "old", "old", "old")
+	tt.instance.Spec.KubernetesConfig.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+
+	result, err := tt.r.reconcileManagedRollout(context.Background(), tt.instance)
+
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.True(t, tt.podExists(t, "example-replication-2"))
+}
+
+func newStatusReconcilerForTest(t *testing.T, instance *rrvb2.RedisReplication) (*Reconciler, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+	ctrlClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(instance).
+		WithObjects(instance).
+		Build()
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), instance))
+	return &Reconciler{Client: ctrlClient, K8sClient: fake.NewSimpleClientset()}, ctrlClient
+}
+
+func TestUpdateRedisReplicationMasterKeepsDurability(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	r, ctrlClient := newStatusReconcilerForTest(t, instance)
+	instance.Status.MasterNode = "example-replication-0"
+	instance.Status.Durability = &commonapi.DurabilityStatus{MinReplicasMaxLag: 10, Relaxed: true, Reason: "scaling example-replication from 3 to 2 pods"}
+	require.NoError(t, r.updateStatus(context.Background(), instance, instance.Status))
+
+	require.NoError(t, r.UpdateRedisReplicationMaster(context.Background(), instance, "example-replication-1"))
+
+	updated := &rrvb2.RedisReplication{}
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+	assert.Equal(t, "example-replication-1", updated.Status.MasterNode)
+	assert.Equal(t, instance.Status.Durability, updated.Status.Durability)
+}
+
+func TestUpdateRedisReplicationMasterKeepsReplicaPriorities(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	r, ctrlClient := newStatusReconcilerForTest(t, instance)
+	instance.Status.MasterNode = "example-replication-0"
+	instance.Status.ReplicaPriorities = map[string]int32{"example-replication-0": 100, "example-replication-1": 0, "example-replication-2": 10}
+	require.NoError(t, r.updateStatus(context.Background(), instance, instance.Status))
+
+	require.NoError(t, r.UpdateRedisReplicationMaster(context.Background(), instance, "example-replication-2"))
+
+	updated := &rrvb2.RedisReplication{}
+	require.NoError(t, ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(instance), updated))
+	assert.Equal(t, "example-replication-2", updated.Status.MasterNode)
+	assert.Equal(t, instance.Status.ReplicaPriorities, updated.Status.ReplicaPriorities)
+}
+
+func TestDurabilityIsRelaxedBeforeTheResourcesAreUpdated(t *testing.T) {
+	var steps []string
+	for _, reconciler := range (&Reconciler{}).reconcilers() {
+		steps = append(steps, reconciler.typ)
+	}
+	durability, resources := slices.Index(steps, "durability"), slices.Index(steps, "resources")
+	require.NotEqual(t, -1, durability)
+	require.NotEqual(t, -1, resources)
+	assert.Less(t, durability, resources, "the statefulset is scaled down by the resources step")
+}
+
+func TestReconcileDurabilityRelaxesBeforeTheStatefulSetIsScaledDown(t *testing.T) {
+	instance := newReplicationInstanceForTest()
+	instance.Spec.Size = ptr.To(int32(2))
+	instance.Spec.Durability = &commonapi.Durability{MinReplicasToWrite: 1}
+	r, _ := newStatusReconcilerForTest(t, instance)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+	// the resources step did not update the statefulset of three pods yet
+	r.K8sClient = fake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.RedisStatefulSet(), Namespace: instance.Namespace},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(3))},
+		Status:     appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 3},
+	})
+
+	result, err := r.reconcileDurability(context.Background(), instance)
+
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	require.NotNil(t, instance.Status.Durability)
+	assert.True(t, instance.Status.Durability.Relaxed)
+	assert.Equal(t, int32(0), instance.Status.Durability.MinReplicasToWrite)
+	assert.Equal(t, "Normal DurabilityRelaxed Relaxed min-replicas-to-write from 1 to 0 while scaling example-replication from 3 to 2 pods", <-recorder.Events)
+}
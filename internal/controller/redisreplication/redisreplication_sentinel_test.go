@@ -300,6 +300,20 @@ func (f *fakeSentinelRedisService) GetInfoSentinel(context.Context) (*redis.Info
 	}, nil
 }
 
+func (f *fakeSentinelRedisService) SentinelCKQuorum(context.Context, string) (bool, string, error) {
+	return true, "", nil
+}
+
+func (f *fakeSentinelRedisService) SentinelFailover(context.Context, string) error { return nil }
+
+func (f *fakeSentinelRedisService) SentinelGetMasterAddrByName(context.Context, string) (string, string, error) {
+	return "", "", nil
+}
+
+func (f *fakeSentinelRedisService) SentinelReplicaPriorities(context.Context, string) (map[string]int, error) {
+	return nil, nil
+}
+
 func (f *fakeSentinelRedisService) GetClusterInfo(context.Context) (*redis.ClusterStatus, error) {
 	return &redis.ClusterStatus{}, nil
 }
@@ -0,0 +1,342 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisrestore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// pollInterval is how often a restore waiting on its backup or target is checked
+const pollInterval = 30 * time.Second
+
+// Reconciler reconciles a RedisRestore object
+type Reconciler struct {
+	client.Client
+	k8sutils.StatefulSet
+	Recorder record.EventRecorder
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &rbvb2.RedisRestore{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisRestore instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return intctrlutil.Reconciled()
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+	if instance.IsFinished() {
+		return intctrlutil.Reconciled()
+	}
+	if instance.Status.State == rbvb2.RedisRestoreRestoring {
+		return r.checkRestored(ctx, instance)
+	}
+
+	backup := &rbvb2.RedisBackup{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.BackupName}, backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.fail(ctx, instance, fmt.Sprintf("RedisBackup %s not found", instance.Spec.BackupName))
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to get RedisBackup")
+	}
+	switch backup.Status.State {
+	case rbvb2.RedisBackupCompleted:
+	case rbvb2.RedisBackupFailed:
+		return r.fail(ctx, instance, fmt.Sprintf("RedisBackup %s failed: %s", backup.Name, backup.Status.Reason))
+	default:
+		return r.pending(ctx, instance, fmt.Sprintf("waiting for RedisBackup %s to complete", backup.Name))
+	}
+	if backup.Spec.Storage.PersistentVolumeClaim == nil {
+		return r.fail(ctx, instance, "only backups stored on a persistentVolumeClaim can be restored")
+	}
+
+	target, err := r.getTarget(ctx, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.pending(ctx, instance, fmt.Sprintf("waiting for %s %s to be created", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to get restore target")
+	}
+	shards, err := assignShards(target, backup)
+	if err != nil {
+		return r.fail(ctx, instance, err.Error())
+	}
+
+	sts := &appsv1.StatefulSet{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: seededStatefulSet(target)}, sts)
+	if err == nil {
+		return r.fail(ctx, instance, fmt.Sprintf("%s %s is already deployed, only new deployments can be restored", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+	}
+	if !apierrors.IsNotFound(err) {
+		return intctrlutil.RequeueE(ctx, err, "failed to get restore target statefulset")
+	}
+
+	now := metav1.Now()
+	status := rbvb2.RedisRestoreStatus{
+		State:     rbvb2.RedisRestoreRestoring,
+		Reason:    fmt.Sprintf("seeding %d pods from RedisBackup %s", len(shards), backup.Name),
+		Source:    backup.Spec.Storage.PersistentVolumeClaim.DeepCopy(),
+		Shards:    shards,
+		StartTime: &now,
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisRestore status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisRestoreStarted, fmt.Sprintf("Restore of %s %s from RedisBackup %s started", instance.Spec.Target.Kind, instance.Spec.Target.Name, backup.Name))
+	return intctrlutil.RequeueAfter(ctx, pollInterval, "waiting for the restored target to be ready")
+}
+
+// checkRestored completes the restore once the seeded target is serving
+func (r *Reconciler) checkRestored(ctx context.Context, instance *rbvb2.RedisRestore) (ctrl.Result, error) {
+	target, err := r.getTarget(ctx, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.fail(ctx, instance, fmt.Sprintf("%s %s was deleted during the restore", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to get restore target")
+	}
+
+	ready := false
+	switch cr := target.(type) {
+	case *rcvb2.RedisCluster:
+		ready = cr.Status.State == rcvb2.RedisClusterReady
+	case *rrvb2.RedisReplication:
+		ready = cr.Status.MasterNode != "" && r.IsStatefulSetReady(ctx, cr.Namespace, cr.Name)
+	}
+	if !ready {
+		return intctrlutil.RequeueAfter(ctx, pollInterval, "waiting for the restored target to be ready")
+	}
+
+	now := metav1.Now()
+	status := instance.Status.DeepCopy()
+	status.State = rbvb2.RedisRestoreCompleted
+	status.Reason = fmt.Sprintf("%s %s was restored", instance.Spec.Target.Kind, instance.Spec.Target.Name)
+	status.CompletionTime = &now
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisRestore status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisRestoreCompleted, status.Reason)
+	return intctrlutil.Reconciled()
+}
+
+func (r *Reconciler) pending(ctx context.Context, instance *rbvb2.RedisRestore, reason string) (ctrl.Result, error) {
+	status := instance.Status.DeepCopy()
+	status.State = rbvb2.RedisRestorePending
+	status.Reason = reason
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisRestore status")
+	}
+	return intctrlutil.RequeueAfter(ctx, pollInterval, reason)
+}
+
+func (r *Reconciler) fail(ctx context.Context, instance *rbvb2.RedisRestore, reason string) (ctrl.Result, error) {
+	now := metav1.Now()
+	status := instance.Status.DeepCopy()
+	status.State = rbvb2.RedisRestoreFailed
+	status.Reason = reason
+	status.CompletionTime = &now
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisRestore status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisRestoreFailed, reason)
+	return intctrlutil.Reconciled()
+}
+
+func (r *Reconciler) getTarget(ctx context.Context, instance *rbvb2.RedisRestore) (client.Object, error) {
+	key := client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.Target.Name}
+	switch instance.Spec.Target.Kind {
+	case rbvb2.BackupTargetRedisCluster:
+		cr := &rcvb2.RedisCluster{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, err
+		}
+		cr.SetDefault()
+		return cr, nil
+	case rbvb2.BackupTargetRedisReplication:
+		cr := &rrvb2.RedisReplication{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, err
+		}
+		return cr, nil
+	default:
+		return nil, fmt.Errorf("unsupported restore target kind %q", instance.Spec.Target.Kind)
+	}
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, instance *rbvb2.RedisRestore, status rbvb2.RedisRestoreStatus) error {
+	copy := instance.DeepCopy()
+	copy.Status = status
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.Status = status
+	return nil
+}
+
+// seededStatefulSet is the statefulset whose pods load the snapshots
+func seededStatefulSet(target client.Object) string {
+	if _, ok := target.(*rcvb2.RedisCluster); ok {
+		return target.GetName() + "-leader"
+	}
+	return target.GetName()
+}
+
+// assignShards maps the snapshots of the backup onto the pods of the target. The leaders of a
+// cluster take over the shards in slot order, every pod of a replication loads the snapshot of
+// the master so whichever pod is elected master serves the data.
+func assignShards(target client.Object, backup *rbvb2.RedisBackup) ([]rbvb2.RedisRestoreShard, error) {
+	var snapshots []rbvb2.RedisBackupShard
+	for _, shard := range backup.Status.Shards {
+		if shard.State == rbvb2.RedisBackupShardCompleted {
+			snapshots = append(snapshots, shard)
+		}
+	}
+
+	switch cr := target.(type) {
+	case *rcvb2.RedisCluster:
+		if backup.Spec.Target.Kind != rbvb2.BackupTargetRedisCluster {
+			return nil, fmt.Errorf("a RedisCluster can only be restored from a backup of a RedisCluster, RedisBackup %s is of a %s", backup.Name, backup.Spec.Target.Kind)
+		}
+		if cr.Spec.Storage == nil || cr.Spec.PersistenceEnabled == nil || !*cr.Spec.PersistenceEnabled {
+			return nil, fmt.Errorf("RedisCluster %s has no persistent storage to restore into", cr.Name)
+		}
+		leaders := cr.Spec.GetReplicaCounts("leader")
+		if int(leaders) != len(snapshots) {
+			return nil, fmt.Errorf("RedisCluster %s has %d leaders but RedisBackup %s has %d shards", cr.Name, leaders, backup.Name, len(snapshots))
+		}
+		for _, snapshot := range snapshots {
+			if len(snapshot.Slots) == 0 {
+				return nil, fmt.Errorf("shard %s of RedisBackup %s owns no slots", snapshot.PodName, backup.Name)
+			}
+		}
+		sort.SliceStable(snapshots, func(i, j int) bool {
+			return firstSlot(snapshots[i].Slots) < firstSlot(snapshots[j].Slots)
+		})
+		shards := make([]rbvb2.RedisRestoreShard, 0, len(snapshots))
+		for i, snapshot := range snapshots {
+			shards = append(shards, rbvb2.RedisRestoreShard{
+				PodName:  fmt.Sprintf("%s-leader-%d", cr.Name, i),
+				Location: snapshot.Location,
+				Slots:    snapshot.Slots,
+			})
+		}
+		return shards, nil
+	case *rrvb2.RedisReplication:
+		if backup.Spec.Target.Kind != rbvb2.BackupTargetRedisReplication && backup.Spec.Target.Kind != rbvb2.BackupTargetRedis {
+			return nil, fmt.Errorf("a RedisReplication can only be restored from a backup of a RedisReplication or Redis, RedisBackup %s is of a %s", backup.Name, backup.Spec.Target.Kind)
+		}
+		if !k8sutils.HasVolumeClaimTemplate(cr.Spec.Storage) {
+			return nil, fmt.Errorf("RedisReplication %s has no persistent storage to restore into", cr.Name)
+		}
+		if len(snapshots) != 1 {
+			return nil, fmt.Errorf("RedisBackup %s has %d snapshots, a RedisReplication is restored from exactly one", backup.Name, len(snapshots))
+		}
+		replicas := cr.Spec.GetReplicationCounts("Replication")
+		shards := make([]rbvb2.RedisRestoreShard, 0, replicas)
+		for i := int32(0); i < replicas; i++ {
+			shards = append(shards, rbvb2.RedisRestoreShard{
+				PodName:  fmt.Sprintf("%s-%d", cr.Name, i),
+				Location: snapshots[0].Location,
+			})
+		}
+		return shards, nil
+	default:
+		return nil, fmt.Errorf("unsupported restore target %T", target)
+	}
+}
+
+// firstSlot returns the lowest slot of the given slot ranges
+func firstSlot(ranges []string) int {
+	lowest := -1
+	for _, r := range ranges {
+		first, _, _ := strings.Cut(r, "-")
+		slot, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		if lowest == -1 || slot < lowest {
+			lowest = slot
+		}
+	}
+	return lowest
+}
+
+// restoresOf enqueues the restores the changed object is referenced by
+func (r *Reconciler) restoresOf(references func(restore *rbvb2.RedisRestore, obj client.Object) bool) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		restores := &rbvb2.RedisRestoreList{}
+		if err := r.List(ctx, restores, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range restores.Items {
+			if references(&restores.Items[i], obj) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: restores.Items[i].Name}})
+			}
+		}
+		return requests
+	}
+}
+
+func referencesBackup(restore *rbvb2.RedisRestore, obj client.Object) bool {
+	return restore.Spec.BackupName == obj.GetName()
+}
+
+func referencesTarget(kind string) func(restore *rbvb2.RedisRestore, obj client.Object) bool {
+	return func(restore *rbvb2.RedisRestore, obj client.Object) bool {
+		return restore.Spec.Target.Kind == kind && restore.Spec.Target.Name == obj.GetName()
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rbvb2.RedisRestore{}).
+		Watches(&rbvb2.RedisBackup{}, handler.EnqueueRequestsFromMapFunc(r.restoresOf(referencesBackup))).
+		Watches(&rcvb2.RedisCluster{}, handler.EnqueueRequestsFromMapFunc(r.restoresOf(referencesTarget(rbvb2.BackupTargetRedisCluster)))).
+		Watches(&rrvb2.RedisReplication{}, handler.EnqueueRequestsFromMapFunc(r.restoresOf(referencesTarget(rbvb2.BackupTargetRedisReplication)))).
+		WithOptions(opts).
+		Complete(r)
+}
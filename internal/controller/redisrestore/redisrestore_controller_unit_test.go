@@ -0,0 +1,257 @@
+package redisrestore
+
+import (
+	"context"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeStatefulSet struct {
+	ready bool
+}
+
+func (f *fakeStatefulSet) IsStatefulSetReady(ctx context.Context, namespace, name string) bool {
+	return f.ready
+}
+
+func (f *fakeStatefulSet) GetStatefulSetReplicas(ctx context.Context, namespace, name string) int32 {
+	return 0
+}
+
+func newRestoreForTest(kind, target string) *rbvb2.RedisRestore {
+	return &rbvb2.RedisRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+		Spec: rbvb2.RedisRestoreSpec{
+			Target:     rbvb2.RestoreTarget{Kind: kind, Name: target},
+			BackupName: "nightly",
+		},
+	}
+}
+
+func newClusterBackupForTest(state rbvb2.RedisBackupState) *rbvb2.RedisBackup {
+	return &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: rbvb2.RedisBackupSpec{
+			Target: rbvb2.BackupTarget{Kind: rbvb2.BackupTargetRedisCluster, Name: "old"},
+			Storage: rbvb2.BackupStorage{
+				PersistentVolumeClaim: &rbvb2.PVCBackupStorage{ClaimName: "backups", Path: "/snapshots"},
+			},
+		},
+		Status: rbvb2.RedisBackupStatus{
+			State: state,
+			Shards: []rbvb2.RedisBackupShard{
+				{PodName: "old-leader-0", Slots: []string{"10923-16383"}, State: rbvb2.RedisBackupShardCompleted, Location: "default/nightly/old-leader-0.rdb"},
+				{PodName: "old-leader-1", Slots: []string{"0-5460"}, State: rbvb2.RedisBackupShardCompleted, Location: "default/nightly/old-leader-1.rdb"},
+				{PodName: "old-leader-2", Slots: []string{"5461-10922"}, State: rbvb2.RedisBackupShardCompleted, Location: "default/nightly/old-leader-2.rdb"},
+			},
+		},
+	}
+}
+
+func newClusterForTest(size int32) *rcvb2.RedisCluster {
+	return &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "restored", Namespace: "default"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize:        ptr.To(size),
+			PersistenceEnabled: ptr.To(true),
+			Storage:            &rcvb2.ClusterStorage{},
+		},
+	}
+}
+
+func newReconcilerForTest(t *testing.T, objs ...client.Object) *Reconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&rbvb2.RedisRestore{}).
+			WithObjects(objs...).
+			Build(),
+		StatefulSet: &fakeStatefulSet{},
+		Recorder:    record.NewFakeRecorder(10),
+	}
+}
+
+func reconcileRestore(t *testing.T, r *Reconciler) (ctrl.Result, *rbvb2.RedisRestore) {
+	t.Helper()
+	key := types.NamespacedName{Namespace: "default", Name: "restore"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	restore := &rbvb2.RedisRestore{}
+	require.NoError(t, r.Get(context.Background(), key, restore))
+	return result, restore
+}
+
+func TestReconcileAssignsClusterShardsInSlotOrder(t *testing.T) {
+	r := newReconcilerForTest(t,
+		newRestoreForTest(rbvb2.BackupTargetRedisCluster, "restored"),
+		newClusterBackupForTest(rbvb2.RedisBackupCompleted),
+		newClusterForTest(3),
+	)
+
+	result, restore := reconcileRestore(t, r)
+	assert.Equal(t, pollInterval, result.RequeueAfter)
+	assert.Equal(t, rbvb2.RedisRestoreRestoring, restore.Status.State)
+	assert.Equal(t, &rbvb2.PVCBackupStorage{ClaimName: "backups", Path: "/snapshots"}, restore.Status.Source)
+	assert.Equal(t, []rbvb2.RedisRestoreShard{
+		{PodName: "restored-leader-0", Location: "default/nightly/old-leader-1.rdb", Slots: []string{"0-5460"}},
+		{PodName: "restored-leader-1", Location: "default/nightly/old-leader-2.rdb", Slots: []string{"5461-10922"}},
+		{PodName: "restored-leader-2", Location: "default/nightly/old-leader-0.rdb", Slots: []string{"10923-16383"}},
+	}, restore.Status.Shards)
+	assert.NotNil(t, restore.Status.StartTime)
+}
+
+func TestReconcileSeedsEveryReplicationPod(t *testing.T) {
+	backup := newClusterBackupForTest(rbvb2.RedisBackupCompleted)
+	backup.Spec.Target.Kind = rbvb2.BackupTargetRedisReplication
+	backup.Status.Shards = backup.Status.Shards[:1]
+	replication := &rrvb2.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "restored", Namespace: "default"},
+		Spec: rrvb2.RedisReplicationSpec{
+			Size: ptr.To(int32(2)),
+			Storage: &commonapi.Storage{
+				VolumeClaimTemplate: corev1.PersistentVolumeClaim{
+					Spec: corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+				},
+			},
+		},
+	}
+	r := newReconcilerForTest(t, newRestoreForTest(rbvb2.BackupTargetRedisReplication, "restored"), backup, replication)
+
+	_, restore := reconcileRestore(t, r)
+	assert.Equal(t, rbvb2.RedisRestoreRestoring, restore.Status.State)
+	assert.Equal(t, []rbvb2.RedisRestoreShard{
+		{PodName: "restored-0", Location: "default/nightly/old-leader-0.rdb"},
+		{PodName: "restored-1", Location: "default/nightly/old-leader-0.rdb"},
+	}, restore.Status.Shards)
+}
+
+func TestReconcileWaits(t *testing.T) {
+	tests := []struct {
+		name   string
+		objs   []client.Object
+		reason string
+	}{
+		{
+			name:   "backup still running",
+			objs:   []client.Object{newClusterBackupForTest(rbvb2.RedisBackupRunning), newClusterForTest(3)},
+			reason: "waiting for RedisBackup nightly to complete",
+		},
+		{
+			name:   "target not created yet",
+			objs:   []client.Object{newClusterBackupForTest(rbvb2.RedisBackupCompleted)},
+			reason: "waiting for RedisCluster restored to be created",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReconcilerForTest(t, append(tt.objs, newRestoreForTest(rbvb2.BackupTargetRedisCluster, "restored"))...)
+
+			result, restore := reconcileRestore(t, r)
+			assert.Equal(t, pollInterval, result.RequeueAfter)
+			assert.Equal(t, rbvb2.RedisRestorePending, restore.Status.State)
+			assert.Equal(t, tt.reason, restore.Status.Reason)
+		})
+	}
+}
+
+func TestReconcileRejectsInvalidRestores(t *testing.T) {
+	filesystemBackup := newClusterBackupForTest(rbvb2.RedisBackupCompleted)
+	filesystemBackup.Spec.Storage = rbvb2.BackupStorage{Filesystem: &rbvb2.FilesystemBackupStorage{Path: "/backups"}}
+	noPersistence := newClusterForTest(3)
+	noPersistence.Spec.PersistenceEnabled = ptr.To(false)
+
+	tests := []struct {
+		name   string
+		objs   []client.Object
+		reason string
+	}{
+		{
+			name:   "backup not found",
+			objs:   []client.Object{newClusterForTest(3)},
+			reason: "RedisBackup nightly not found",
+		},
+		{
+			name:   "backup failed",
+			objs:   []client.Object{newClusterBackupForTest(rbvb2.RedisBackupFailed), newClusterForTest(3)},
+			reason: "RedisBackup nightly failed: ",
+		},
+		{
+			name:   "backup not on a claim",
+			objs:   []client.Object{filesystemBackup, newClusterForTest(3)},
+			reason: "only backups stored on a persistentVolumeClaim can be restored",
+		},
+		{
+			name:   "leader count does not match the shards",
+			objs:   []client.Object{newClusterBackupForTest(rbvb2.RedisBackupCompleted), newClusterForTest(4)},
+			reason: "RedisCluster restored has 4 leaders but RedisBackup nightly has 3 shards",
+		},
+		{
+			name:   "target without persistence",
+			objs:   []client.Object{newClusterBackupForTest(rbvb2.RedisBackupCompleted), noPersistence},
+			reason: "RedisCluster restored has no persistent storage to restore into",
+		},
+		{
+			name: "target already deployed",
+			objs: []client.Object{
+				newClusterBackupForTest(rbvb2.RedisBackupCompleted),
+				newClusterForTest(3),
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "restored-leader", Namespace: "default"}},
+			},
+			reason: "RedisCluster restored is already deployed, only new deployments can be restored",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReconcilerForTest(t, append(tt.objs, newRestoreForTest(rbvb2.BackupTargetRedisCluster, "restored"))...)
+
+			result, restore := reconcileRestore(t, r)
+			assert.Equal(t, ctrl.Result{}, result)
+			assert.Equal(t, rbvb2.RedisRestoreFailed, restore.Status.State)
+			assert.Equal(t, tt.reason, restore.Status.Reason)
+			assert.NotNil(t, restore.Status.CompletionTime)
+		})
+	}
+}
+
+func TestReconcileCompletesOnceTargetIsReady(t *testing.T) {
+	restore := newRestoreForTest(rbvb2.BackupTargetRedisCluster, "restored")
+	restore.Status.State = rbvb2.RedisRestoreRestoring
+	cluster := newClusterForTest(3)
+	r := newReconcilerForTest(t, restore, cluster)
+
+	result, got := reconcileRestore(t, r)
+	assert.Equal(t, pollInterval, result.RequeueAfter)
+	assert.Equal(t, rbvb2.RedisRestoreRestoring, got.Status.State)
+
+	cluster.Status.State = rcvb2.RedisClusterReady
+	require.NoError(t, r.Update(context.Background(), cluster))
+
+	result, got = reconcileRestore(t, r)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, rbvb2.RedisRestoreCompleted, got.Status.State)
+	assert.NotNil(t, got.Status.CompletionTime)
+}
@@ -2,22 +2,33 @@ package redissentinel
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/switchmaster"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
@@ -27,10 +38,13 @@ const (
 // RedisSentinelReconciler reconciles a RedisSentinel object
 type RedisSentinelReconciler struct {
 	client.Client
-	Checker            redis.Checker
-	Healer             redis.Healer
-	K8sClient          kubernetes.Interface
-	ReplicationWatcher *intctrlutil.ResourceWatcher
+	Checker             redis.Checker
+	Healer              redis.Healer
+	K8sClient           kubernetes.Interface
+	ReplicationWatcher  *intctrlutil.ResourceWatcher
+	SecretWatcher       *intctrlutil.ResourceWatcher
+	SwitchMasterWatcher *switchmaster.Watcher
+	TLSRotator          *tlsrotation.Rotator
 }
 
 func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -42,6 +56,7 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	if k8sutils.IsDeleted(instance) {
+		r.SwitchMasterWatcher.Forget(instance)
 		if err := k8sutils.HandleRedisSentinelFinalizer(ctx, r.Client, instance, RedisSentinelFinalizer); err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
@@ -54,10 +69,12 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	reconcilers := []reconciler{
 		{typ: "finalizer", rec: r.reconcileFinalizer},
+		{typ: "certificate", rec: r.reconcileCertificate},
 		{typ: "replication", rec: r.reconcileReplication},
 		{typ: "pdb", rec: r.reconcilePDB},
 		{typ: "service", rec: r.reconcileService},
 		{typ: "sentinel", rec: r.reconcileSentinel},
+		{typ: "tls", rec: r.reconcileTLSRotation},
 	}
 
 	for _, reconciler := range reconcilers {
@@ -66,9 +83,19 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
 		if result.Requeue {
+			if err := r.reconcileConditions(ctx, instance); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "")
+			}
 			return result, nil
 		}
 	}
+	if err := r.reconcileConditions(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	// the monitored masters change with the failovers of the sentinels
+	if instance.Spec.RedisSentinelConfig != nil {
+		return intctrlutil.RequeueAfter(ctx, time.Second*60, "refreshing the status of the monitored masters")
+	}
 
 	return intctrlutil.Reconciled()
 }
@@ -91,17 +118,19 @@ func (r *RedisSentinelReconciler) reconcileFinalizer(ctx context.Context, instan
 	return intctrlutil.Reconciled()
 }
 
+// reconcileReplication watches the monitored replications. The sentinels are only created once the
+// replication of the redisSentinelConfig is ready, they start monitoring its master.
 func (r *RedisSentinelReconciler) reconcileReplication(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
-	if instance.Spec.RedisSentinelConfig != nil && !k8sutils.IsRedisReplicationReady(ctx, r.K8sClient, r.Client, instance) {
+	if instance.Spec.RedisSentinelConfig != nil && !k8sutils.IsRedisReplicationReady(ctx, r.K8sClient, r.Client, instance, instance.Spec.RedisSentinelConfig.RedisReplicationName) {
 		return intctrlutil.RequeueAfter(ctx, time.Second*10, "Redis Replication is specified but not ready")
 	}
 
-	if instance.Spec.RedisSentinelConfig != nil {
+	for _, master := range instance.Spec.GetMasters() {
 		r.ReplicationWatcher.Watch(
 			ctx,
 			types.NamespacedName{
 				Namespace: instance.Namespace,
-				Name:      instance.Spec.RedisSentinelConfig.RedisReplicationName,
+				Name:      master.RedisReplicationName,
 			},
 			types.NamespacedName{
 				Namespace: instance.Namespace,
@@ -117,20 +146,68 @@ func (r *RedisSentinelReconciler) reconcileSentinel(ctx context.Context, instanc
 		return intctrlutil.RequeueE(ctx, err, "")
 	}
 	if instance.Spec.RedisSentinelConfig == nil {
+		r.SwitchMasterWatcher.Forget(instance)
 		return intctrlutil.Reconciled()
 	}
+	if err := r.watchSwitchMaster(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to subscribe to the master switches of the sentinels")
+	}
 
+	// a master group whose replication is missing or not ready does not hold back the others
+	var (
+		pending []string
+		errs    []error
+	)
+	for _, group := range instance.Spec.GetMasters() {
+		if group.RedisReplicationName != instance.Spec.RedisSentinelConfig.RedisReplicationName &&
+			!k8sutils.IsRedisReplicationReady(ctx, r.K8sClient, r.Client, instance, group.RedisReplicationName) {
+			pending = append(pending, group.MasterGroupName)
+			continue
+		}
+		if err := r.reconcileMasterGroup(ctx, instance, group); err != nil {
+			errs = append(errs, fmt.Errorf("master group %s: %w", group.MasterGroupName, err))
+		}
+	}
+	if len(errs) > 0 {
+		return intctrlutil.RequeueE(ctx, errors.Join(errs...), "")
+	}
+	if len(pending) > 0 {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the replications of the master groups to be ready", "MasterGroups", pending)
+	}
+	return intctrlutil.Reconciled()
+}
+
+// watchSwitchMaster subscribes to the master switches of the sentinels, a switch reconciles the
+// replication of the group right away, which in turn reconciles the sentinel
+func (r *RedisSentinelReconciler) watchSwitchMaster(ctx context.Context, instance *rsvb2.RedisSentinel) error {
+	sentinels, err := r.Checker.GetSentinelConnections(ctx, instance)
+	if err != nil {
+		return err
+	}
+	sub := switchmaster.Subscription{
+		Sentinels:    sentinels,
+		Replications: map[string]string{},
+	}
+	for _, group := range instance.Spec.GetMasters() {
+		sub.Replications[group.MasterGroupName] = group.RedisReplicationName
+	}
+	r.SwitchMasterWatcher.Watch(ctx, instance, sub)
+	return nil
+}
+
+// reconcileMasterGroup points the sentinels at the master of the replication of the group
+func (r *RedisSentinelReconciler) reconcileMasterGroup(ctx context.Context, instance *rsvb2.RedisSentinel, group rsvb2.SentinelMaster) error {
 	rr := &rrvb2.RedisReplication{}
 	if err := r.Get(ctx, types.NamespacedName{
 		Namespace: instance.Namespace,
-		Name:      instance.Spec.RedisSentinelConfig.RedisReplicationName,
+		Name:      group.RedisReplicationName,
 	}, rr); err != nil {
-		return intctrlutil.RequeueE(ctx, err, "")
+		return err
 	}
 
 	var monitorAddr string
 	if master, err := r.Checker.GetMasterFromReplication(ctx, rr); err != nil {
-		return intctrlutil.RequeueE(ctx, err, "")
+		return err
 	} else {
 		if instance.Spec.RedisSentinelConfig.ResolveHostnames == "yes" {
 			monitorAddr = fmt.Sprintf("%s.%s.%s.svc.%s", master.Name, common.GetHeadlessServiceNameFromPodName(master.Name), rr.Namespace, envs.GetServiceDNSDomain())
@@ -138,16 +215,13 @@ func (r *RedisSentinelReconciler) reconcileSentinel(ctx context.Context, instanc
 			monitorAddr = master.Status.PodIP
 		}
 	}
-	if err := r.Healer.SentinelMonitor(ctx, instance, monitorAddr); err != nil {
-		return intctrlutil.RequeueE(ctx, err, "")
+	if err := r.Healer.SentinelMonitor(ctx, instance, group, monitorAddr); err != nil {
+		return err
 	}
-	if err := r.Healer.SentinelSet(ctx, instance, monitorAddr); err != nil {
-		return intctrlutil.RequeueE(ctx, err, "")
+	if err := r.Healer.SentinelSet(ctx, instance, group, monitorAddr); err != nil {
+		return err
 	}
-	if err := r.Healer.SentinelReset(ctx, instance); err != nil {
-		return intctrlutil.RequeueE(ctx, err, "")
-	}
-	return intctrlutil.Reconciled()
+	return r.Healer.SentinelReset(ctx, instance, group)
 }
 
 func (r *RedisSentinelReconciler) reconcilePDB(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
@@ -164,6 +238,158 @@ func (r *RedisSentinelReconciler) reconcileService(ctx context.Context, instance
 	return intctrlutil.Reconciled()
 }
 
+// reconcileCertificate requests the certificate of the TLS secret from cert-manager when an
+// issuer is configured. The secret is watched either way, its changes are rolled out to the pods.
+func (r *RedisSentinelReconciler) reconcileCertificate(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
+	tls := instance.Spec.TLS
+	if tls == nil || tls.Secret.SecretName == "" {
+		return intctrlutil.Reconciled()
+	}
+	r.SecretWatcher.Watch(
+		ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: tls.Secret.SecretName},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+	dnsNames, err := k8sutils.GetRedisCertificateDNSNames(instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if err := r.TLSRotator.EnsureCertificate(ctx, instance, tls, dnsNames); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to issue TLS certificate")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileTLSRotation restarts a sentinel started with a previous certificate, one at a time
+func (r *RedisSentinelReconciler) reconcileTLSRotation(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
+	if instance.Spec.TLS == nil {
+		return intctrlutil.Reconciled()
+	}
+	nodes, err := k8sutils.GetRedisTLSNodes(instance)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	status, requeueAfter, err := r.TLSRotator.Reconcile(ctx, tlsrotation.Target{
+		Object: instance,
+		TLS:    instance.Spec.TLS,
+		Status: instance.Status.TLSRotation,
+		Nodes:  nodes,
+	})
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to roll out TLS certificate")
+	}
+	if !reflect.DeepEqual(status, instance.Status.TLSRotation) {
+		copy := instance.DeepCopy()
+		copy.Spec = rsvb2.RedisSentinelSpec{}
+		copy.Status.TLSRotation = status
+		if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+		instance.ResourceVersion = copy.ResourceVersion
+		instance.Status = copy.Status
+	}
+	if requeueAfter > 0 {
+		return intctrlutil.RequeueAfter(ctx, requeueAfter, "rolling out TLS certificate")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileConditions records the conditions of the sentinels. They fail over the monitored master
+// only with a quorum of ready sentinels, and are ready once the replication they monitor is.
+func (r *RedisSentinelReconciler) reconcileConditions(ctx context.Context, instance *rsvb2.RedisSentinel) error {
+	sts, err := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.GetStatefulSetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		sts = nil
+	} else if err != nil {
+		return err
+	}
+	size := instance.Spec.GetSentinelCounts("sentinel")
+	conditions := instance.Status.Conditions
+	o := common.Observation{
+		ScalingInProgress: common.StatefulSetScaling(sts, size, common.ActiveCondition(conditions, commonapi.ConditionScalingInProgress)),
+	}
+	switch {
+	case sts == nil || sts.Status.ReadyReplicas != size || sts.Status.CurrentRevision != sts.Status.UpdateRevision:
+		o.NotReady = &common.ConditionCause{Reason: commonapi.ConditionReasonPodsNotReady, Message: "waiting for the sentinel pods to be ready"}
+		o.Progressing = o.NotReady
+		if quorum := size/2 + 1; sts != nil && sts.Status.Replicas >= size && sts.Status.ReadyReplicas < quorum {
+			o.Degraded = &common.ConditionCause{
+				Reason:  commonapi.ConditionReasonNoQuorum,
+				Message: fmt.Sprintf("%d of %d sentinels are ready, %d are needed to fail over", sts.Status.ReadyReplicas, size, quorum),
+			}
+		}
+	case instance.Spec.RedisSentinelConfig != nil:
+		var notReady []string
+		for _, group := range instance.Spec.GetMasters() {
+			rr := &rrvb2.RedisReplication{}
+			err := r.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: group.RedisReplicationName}, rr)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			if err != nil || !meta.IsStatusConditionTrue(rr.Status.Conditions, commonapi.ConditionReady) {
+				notReady = append(notReady, group.RedisReplicationName)
+			}
+		}
+		if len(notReady) > 0 {
+			o.NotReady = &common.ConditionCause{
+				Reason:  commonapi.ConditionReasonReplicationNotReady,
+				Message: fmt.Sprintf("redis replication %s is not ready", strings.Join(notReady, ", ")),
+			}
+		}
+	}
+	if rotation := common.RotationProgress(nil, instance.Status.TLSRotation); rotation != nil && o.Progressing == nil {
+		o.Progressing = rotation
+	}
+	var (
+		masters []rsvb2.SentinelMasterStatus
+		quorum  *common.ConditionCause
+	)
+	if instance.Spec.RedisSentinelConfig != nil {
+		if sts == nil || sts.Status.ReadyReplicas == 0 {
+			quorum = &common.ConditionCause{Reason: commonapi.ConditionReasonPodsNotReady, Message: "no sentinel pod is ready"}
+		} else {
+			if masters, err = r.Checker.GetSentinelMasters(ctx, instance); err != nil {
+				return err
+			}
+			quorum = sentinelQuorum(masters)
+		}
+	}
+
+	copy := instance.DeepCopy()
+	copy.Spec = rsvb2.RedisSentinelSpec{}
+	copy.Status.ObservedGeneration = instance.Generation
+	copy.Status.Masters = masters
+	common.SetConditions(&copy.Status.Conditions, instance.Generation, o)
+	if instance.Spec.RedisSentinelConfig != nil {
+		common.SetQuorumReachable(&copy.Status.Conditions, instance.Generation, quorum)
+	} else {
+		meta.RemoveStatusCondition(&copy.Status.Conditions, commonapi.ConditionQuorumReachable)
+	}
+	if reflect.DeepEqual(copy.Status, instance.Status) {
+		return nil
+	}
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.ResourceVersion = copy.ResourceVersion
+	instance.Status = copy.Status
+	return nil
+}
+
+// sentinelQuorum returns why the sentinels cannot fail over one of the masters they monitor
+func sentinelQuorum(masters []rsvb2.SentinelMasterStatus) *common.ConditionCause {
+	var messages []string
+	for _, master := range masters {
+		if !master.QuorumReachable {
+			messages = append(messages, fmt.Sprintf("master group %s: %s", master.Name, master.CKQuorum))
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return &common.ConditionCause{Reason: commonapi.ConditionReasonNoQuorum, Message: strings.Join(messages, "; ")}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RedisSentinelReconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -171,5 +397,8 @@ func (r *RedisSentinelReconciler) SetupWithManager(mgr ctrl.Manager, opts contro
 		Owns(&appsv1.StatefulSet{}).
 		WithOptions(opts).
 		Watches(&rrvb2.RedisReplication{}, r.ReplicationWatcher).
+		Watches(&corev1.Secret{}, r.SecretWatcher).
+		// the replication of a switched master is mapped to its sentinels
+		WatchesRawSource(&source.Channel{Source: r.SwitchMasterWatcher.Source()}, r.ReplicationWatcher).
 		Complete(r)
 }
@@ -25,6 +25,8 @@ import (
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/switchmaster"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/tlsrotation"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -102,11 +104,14 @@ var _ = BeforeSuite(func() {
 	checker := redis.NewChecker(k8sClient)
 	healer := redis.NewHealer(k8sClient)
 	err = (&RedisSentinelReconciler{
-		Client:             k8sManager.GetClient(),
-		Checker:            checker,
-		Healer:             healer,
-		K8sClient:          k8sClient,
-		ReplicationWatcher: intctrlutil.NewResourceWatcher(),
+		Client:              k8sManager.GetClient(),
+		Checker:             checker,
+		Healer:              healer,
+		K8sClient:           k8sClient,
+		ReplicationWatcher:  intctrlutil.NewResourceWatcher(),
+		SecretWatcher:       intctrlutil.NewResourceWatcher(),
+		SwitchMasterWatcher: switchmaster.NewWatcher(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("redisreplication-controller")),
+		TLSRotator:          tlsrotation.NewRotator(k8sManager.GetClient(), k8sClient, k8sManager.GetEventRecorderFor("redissentinel-controller")),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -0,0 +1,351 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redisuser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	RedisUserFinalizer = "redisUserFinalizer"
+)
+
+const (
+	// resyncInterval is how often a synced user is applied again. ACL users are not replicated
+	// and only survive a restart when redis has an ACL file, so restarted and added pods are
+	// picked up by the periodic sync.
+	resyncInterval = time.Minute
+	// retryInterval is how long to wait before retrying when the user or its target is not ready
+	retryInterval = 15 * time.Second
+)
+
+// Reconciler reconciles a RedisUser object
+type Reconciler struct {
+	client.Client
+	K8sClient kubernetes.Interface
+	ACL       redis.ACLManager
+	Recorder  record.EventRecorder
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &ruvb2.RedisUser{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisUser instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return r.finalize(ctx, instance)
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+	if err := k8sutils.AddFinalizer(ctx, instance, RedisUserFinalizer, r.Client); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
+	}
+
+	username := instance.GetUsername()
+	if username == ruvb2.DefaultUsername {
+		return r.fail(ctx, instance, "the default user is used by the operator and cannot be managed")
+	}
+	if instance.Spec.PasswordSecret.Name == nil || instance.Spec.PasswordSecret.Key == nil {
+		return r.fail(ctx, instance, "passwordSecret requires both a name and a key")
+	}
+
+	target, err := r.getTarget(ctx, instance)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.pending(ctx, instance, fmt.Sprintf("waiting for %s %s to be created", instance.Spec.Target.Kind, instance.Spec.Target.Name))
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to get RedisUser target")
+	}
+	password, err := r.getPassword(ctx, instance)
+	if err != nil {
+		return r.pending(ctx, instance, err.Error())
+	}
+	nodes, err := r.ACL.Nodes(target)
+	if err != nil {
+		return r.fail(ctx, instance, err.Error())
+	}
+
+	// a renamed user is removed before the new one is created, so the old credentials stop working
+	if previous := instance.Status.Username; previous != "" && previous != username {
+		if err := r.deleteUser(ctx, target, nodes, previous); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to delete renamed ACL user")
+		}
+	}
+
+	status := instance.Status.DeepCopy()
+	status.Username = username
+	status.Nodes = r.syncUser(ctx, target, nodes, username, userRules(instance, password))
+	synced := syncedNodes(status.Nodes)
+	status.SyncedNodes = fmt.Sprintf("%d/%d", synced, len(nodes))
+	if synced == len(nodes) {
+		status.State = ruvb2.RedisUserSynced
+		status.Reason = fmt.Sprintf("user %s is applied on all nodes", username)
+	} else {
+		status.State = ruvb2.RedisUserOutOfSync
+		status.Reason = fmt.Sprintf("user %s is applied on %d of %d nodes", username, synced, len(nodes))
+	}
+
+	if status.State != instance.Status.State {
+		if status.State == ruvb2.RedisUserSynced {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisUserSynced, status.Reason)
+		} else {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisUserOutOfSync, status.Reason)
+		}
+	}
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+	}
+	if status.State != ruvb2.RedisUserSynced {
+		return intctrlutil.RequeueAfter(ctx, retryInterval, "user is not applied on all nodes")
+	}
+	return intctrlutil.RequeueAfter(ctx, resyncInterval, "resync user")
+}
+
+// syncUser applies the user on every node and saves it to the ACL file of the node when redis
+// is configured with one
+func (r *Reconciler) syncUser(ctx context.Context, target metav1.Object, nodes []string, username string, rules []string) []ruvb2.RedisUserNodeStatus {
+	status := make([]ruvb2.RedisUserNodeStatus, 0, len(nodes))
+	for _, podName := range nodes {
+		node := ruvb2.RedisUserNodeStatus{PodName: podName}
+		if err := r.ACL.SetUser(ctx, target, podName, username, rules); err != nil {
+			node.Message = err.Error()
+			status = append(status, node)
+			continue
+		}
+		node.Synced = true
+		persisted, err := r.ACL.Save(ctx, target, podName)
+		switch {
+		case err != nil:
+			node.Message = err.Error()
+		case !persisted:
+			node.Message = "redis has no aclfile configured, the user is applied again after a restart"
+		}
+		node.Persisted = persisted
+		status = append(status, node)
+	}
+	return status
+}
+
+// deleteUser removes the user from every node. Pods that do not exist have no user to remove,
+// and a failed save is only logged since the user is already gone from the running node.
+func (r *Reconciler) deleteUser(ctx context.Context, target metav1.Object, nodes []string, username string) error {
+	if username == ruvb2.DefaultUsername {
+		return nil
+	}
+	for _, podName := range nodes {
+		if err := r.ACL.DeleteUser(ctx, target, podName, username); err != nil {
+			if errors.Is(err, k8sutils.ErrRedisPodNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to delete user %s from %s: %w", username, podName, err)
+		}
+		if _, err := r.ACL.Save(ctx, target, podName); err != nil {
+			log.FromContext(ctx).Error(err, "failed to save ACL file", "pod", podName)
+		}
+	}
+	return nil
+}
+
+// finalize removes the user from the nodes of its target before the finalizer is released
+func (r *Reconciler) finalize(ctx context.Context, instance *ruvb2.RedisUser) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, RedisUserFinalizer) {
+		return intctrlutil.Reconciled()
+	}
+	if username := instance.Status.Username; username != "" {
+		target, err := r.getTarget(ctx, instance)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return intctrlutil.RequeueE(ctx, err, "failed to get RedisUser target")
+		}
+		// a deleted target took its users with it
+		if err == nil {
+			nodes, err := r.ACL.Nodes(target)
+			if err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to get RedisUser target nodes")
+			}
+			if err := r.deleteUser(ctx, target, nodes, username); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to delete ACL user")
+			}
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisUserDeleted, fmt.Sprintf("user %s was deleted from %s %s", username, instance.Spec.Target.Kind, instance.Spec.Target.Name))
+		}
+	}
+	controllerutil.RemoveFinalizer(instance, RedisUserFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to remove finalizer")
+	}
+	return intctrlutil.Reconciled()
+}
+
+func (r *Reconciler) pending(ctx context.Context, instance *ruvb2.RedisUser, reason string) (ctrl.Result, error) {
+	status := instance.Status.DeepCopy()
+	status.State = ruvb2.RedisUserPending
+	status.Reason = reason
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+	}
+	return intctrlutil.RequeueAfter(ctx, retryInterval, reason)
+}
+
+// fail records a spec that cannot be applied, the user is reconciled again once the spec changes
+func (r *Reconciler) fail(ctx context.Context, instance *ruvb2.RedisUser, reason string) (ctrl.Result, error) {
+	if instance.Status.State == ruvb2.RedisUserFailed && instance.Status.Reason == reason {
+		return intctrlutil.Reconciled()
+	}
+	status := instance.Status.DeepCopy()
+	status.State = ruvb2.RedisUserFailed
+	status.Reason = reason
+	if err := r.updateStatus(ctx, instance, *status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+	}
+	r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonInvalidRedisUser, reason)
+	return intctrlutil.Reconciled()
+}
+
+// getTarget fetches the Redis deployment the user is managed on
+func (r *Reconciler) getTarget(ctx context.Context, instance *ruvb2.RedisUser) (metav1.Object, error) {
+	key := client.ObjectKey{Namespace: instance.Namespace, Name: instance.Spec.Target.Name}
+	var target client.Object
+	switch instance.Spec.Target.Kind {
+	case ruvb2.UserTargetRedisCluster:
+		target = &rcvb2.RedisCluster{}
+	case ruvb2.UserTargetRedisReplication:
+		target = &rrvb2.RedisReplication{}
+	case ruvb2.UserTargetRedis:
+		target = &rvb2.Redis{}
+	default:
+		return nil, fmt.Errorf("unsupported user target kind %q", instance.Spec.Target.Kind)
+	}
+	if err := r.Get(ctx, key, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// getPassword reads the password of the user. The secret is read through the clientset so the
+// manager does not cache every secret of the cluster.
+func (r *Reconciler) getPassword(ctx context.Context, instance *ruvb2.RedisUser) (string, error) {
+	name, key := *instance.Spec.PasswordSecret.Name, *instance.Spec.PasswordSecret.Key
+	secret, err := r.K8sClient.CoreV1().Secrets(instance.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("waiting for secret %s to be created", name)
+		}
+		return "", fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	password := strings.TrimSpace(string(secret.Data[key]))
+	if password == "" {
+		return "", fmt.Errorf("secret %s has no password in key %s", name, key)
+	}
+	return password, nil
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, instance *ruvb2.RedisUser, status ruvb2.RedisUserStatus) error {
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	copy := instance.DeepCopy()
+	copy.Status = status
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	instance.Status = status
+	return nil
+}
+
+// userRules returns the ACL SETUSER rules of the user. The password is passed as its SHA-256
+// hash so it never shows up in plain text in the commands sent to redis.
+func userRules(instance *ruvb2.RedisUser, password string) []string {
+	state := "off"
+	if instance.IsEnabled() {
+		state = "on"
+	}
+	hash := sha256.Sum256([]byte(password))
+	return append([]string{state, "#" + hex.EncodeToString(hash[:])}, instance.Spec.Rules.Args()...)
+}
+
+func syncedNodes(nodes []ruvb2.RedisUserNodeStatus) int {
+	synced := 0
+	for _, node := range nodes {
+		if node.Synced {
+			synced++
+		}
+	}
+	return synced
+}
+
+// usersOf enqueues the users managed on the changed Redis deployment
+func (r *Reconciler) usersOf(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		users := &ruvb2.RedisUserList{}
+		if err := r.List(ctx, users, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, user := range users.Items {
+			if user.Spec.Target.Kind == kind && user.Spec.Target.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: user.Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+//
+// Password secrets are not watched, doing so would make the manager cache every secret of the
+// cluster. A changed password is applied by the periodic resync.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ruvb2.RedisUser{}).
+		Watches(&rcvb2.RedisCluster{}, handler.EnqueueRequestsFromMapFunc(r.usersOf(ruvb2.UserTargetRedisCluster))).
+		Watches(&rrvb2.RedisReplication{}, handler.EnqueueRequestsFromMapFunc(r.usersOf(ruvb2.UserTargetRedisReplication))).
+		Watches(&rvb2.Redis{}, handler.EnqueueRequestsFromMapFunc(r.usersOf(ruvb2.UserTargetRedis))).
+		WithOptions(opts).
+		Complete(r)
+}
@@ -0,0 +1,248 @@
+package redisuser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeACLManager keeps the users of every pod in memory
+type fakeACLManager struct {
+	users map[string]map[string][]string
+	// missing pods do not exist, unreachable pods fail every command
+	missing     map[string]bool
+	unreachable map[string]bool
+	aclFile     bool
+}
+
+func newFakeACLManager() *fakeACLManager {
+	return &fakeACLManager{users: map[string]map[string][]string{}, missing: map[string]bool{}, unreachable: map[string]bool{}}
+}
+
+func (f *fakeACLManager) Nodes(target metav1.Object) ([]string, error) {
+	return k8sutils.GetRedisACLNodes(target)
+}
+
+func (f *fakeACLManager) check(podName string) error {
+	if f.missing[podName] {
+		return fmt.Errorf("%w: %s", k8sutils.ErrRedisPodNotFound, podName)
+	}
+	if f.unreachable[podName] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *fakeACLManager) SetUser(ctx context.Context, target metav1.Object, podName, username string, rules []string) error {
+	if err := f.check(podName); err != nil {
+		return err
+	}
+	if f.users[podName] == nil {
+		f.users[podName] = map[string][]string{}
+	}
+	f.users[podName][username] = rules
+	return nil
+}
+
+func (f *fakeACLManager) DeleteUser(ctx context.Context, target metav1.Object, podName, username string) error {
+	if err := f.check(podName); err != nil {
+		return err
+	}
+	delete(f.users[podName], username)
+	return nil
+}
+
+func (f *fakeACLManager) Save(ctx context.Context, target metav1.Object, podName string) (bool, error) {
+	return f.aclFile, f.check(podName)
+}
+
+func newUserForTest() *ruvb2.RedisUser {
+	return &ruvb2.RedisUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: ruvb2.RedisUserSpec{
+			Target:         ruvb2.UserTarget{Kind: ruvb2.UserTargetRedisReplication, Name: "replication"},
+			PasswordSecret: commonapi.ExistingPasswordSecret{Name: ptr.To("app-password"), Key: ptr.To("password")},
+			Rules:          ruvb2.ACLRules{Commands: []string{"+@read"}, Keys: []string{"app:*"}},
+		},
+	}
+}
+
+func newReplicationForTest() *rrvb2.RedisReplication {
+	return &rrvb2.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "replication", Namespace: "default"},
+		Spec:       rrvb2.RedisReplicationSpec{Size: ptr.To(int32(2))},
+	}
+}
+
+func newReconcilerForTest(t *testing.T, acl *fakeACLManager, objs ...client.Object) *Reconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, ruvb2.AddToScheme(scheme))
+	require.NoError(t, rrvb2.AddToScheme(scheme))
+
+	return &Reconciler{
+		Client: clientfake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&ruvb2.RedisUser{}).
+			WithObjects(objs...).
+			Build(),
+		K8sClient: k8sfake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-password", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("s3cret\n")},
+		}),
+		ACL:      acl,
+		Recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func reconcileUser(t *testing.T, r *Reconciler) (ctrl.Result, *ruvb2.RedisUser) {
+	t.Helper()
+	key := types.NamespacedName{Namespace: "default", Name: "app"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	user := &ruvb2.RedisUser{}
+	require.NoError(t, r.Get(context.Background(), key, user))
+	return result, user
+}
+
+func TestReconcileAppliesUserOnEveryNode(t *testing.T) {
+	acl := newFakeACLManager()
+	acl.aclFile = true
+	r := newReconcilerForTest(t, acl, newUserForTest(), newReplicationForTest())
+
+	result, user := reconcileUser(t, r)
+	assert.Equal(t, resyncInterval, result.RequeueAfter)
+	assert.Contains(t, user.Finalizers, RedisUserFinalizer)
+	assert.Equal(t, ruvb2.RedisUserSynced, user.Status.State)
+	assert.Equal(t, "app", user.Status.Username)
+	assert.Equal(t, "2/2", user.Status.SyncedNodes)
+	assert.Equal(t, []ruvb2.RedisUserNodeStatus{
+		{PodName: "replication-0", Synced: true, Persisted: true},
+		{PodName: "replication-1", Synced: true, Persisted: true},
+	}, user.Status.Nodes)
+
+	// sha256 of the trimmed password
+	want := []string{"on", "#1ec1c26b50d5d3c58d9583181af8076655fe00756bf7285940ba3670f99fcba0", "~app:*", "+@read"}
+	assert.Equal(t, want, acl.users["replication-0"]["app"])
+	assert.Equal(t, want, acl.users["replication-1"]["app"])
+}
+
+func TestReconcileReportsUnsyncedNodes(t *testing.T) {
+	acl := newFakeACLManager()
+	acl.unreachable["replication-1"] = true
+	r := newReconcilerForTest(t, acl, newUserForTest(), newReplicationForTest())
+
+	result, user := reconcileUser(t, r)
+	assert.Equal(t, retryInterval, result.RequeueAfter)
+	assert.Equal(t, ruvb2.RedisUserOutOfSync, user.Status.State)
+	assert.Equal(t, "1/2", user.Status.SyncedNodes)
+	assert.Equal(t, []ruvb2.RedisUserNodeStatus{
+		{PodName: "replication-0", Synced: true, Message: "redis has no aclfile configured, the user is applied again after a restart"},
+		{PodName: "replication-1", Message: "connection refused"},
+	}, user.Status.Nodes)
+}
+
+func TestReconcileRenamesUser(t *testing.T) {
+	acl := newFakeACLManager()
+	user := newUserForTest()
+	r := newReconcilerForTest(t, acl, user, newReplicationForTest())
+	_, user = reconcileUser(t, r)
+
+	user.Spec.Username = "app-reader"
+	require.NoError(t, r.Update(context.Background(), user))
+	_, user = reconcileUser(t, r)
+	assert.Equal(t, "app-reader", user.Status.Username)
+	for _, pod := range []string{"replication-0", "replication-1"} {
+		assert.NotContains(t, acl.users[pod], "app")
+		assert.Contains(t, acl.users[pod], "app-reader")
+	}
+}
+
+func TestReconcileDeletesUser(t *testing.T) {
+	acl := newFakeACLManager()
+	r := newReconcilerForTest(t, acl, newUserForTest(), newReplicationForTest())
+	_, user := reconcileUser(t, r)
+
+	// a pod that is gone has no user left to delete
+	acl.missing["replication-1"] = true
+	require.NoError(t, r.Delete(context.Background(), user))
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}})
+	require.NoError(t, err)
+
+	assert.NotContains(t, acl.users["replication-0"], "app")
+	err = r.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "app"}, &ruvb2.RedisUser{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestReconcileWaits(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   func(*ruvb2.RedisUser)
+		objs   []client.Object
+		reason string
+	}{
+		{
+			name:   "target not created yet",
+			reason: "waiting for RedisReplication replication to be created",
+		},
+		{
+			name:   "secret not created yet",
+			user:   func(u *ruvb2.RedisUser) { u.Spec.PasswordSecret.Name = ptr.To("other") },
+			objs:   []client.Object{newReplicationForTest()},
+			reason: "waiting for secret other to be created",
+		},
+		{
+			name:   "key missing from secret",
+			user:   func(u *ruvb2.RedisUser) { u.Spec.PasswordSecret.Key = ptr.To("other") },
+			objs:   []client.Object{newReplicationForTest()},
+			reason: "secret app-password has no password in key other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newUserForTest()
+			if tt.user != nil {
+				tt.user(user)
+			}
+			r := newReconcilerForTest(t, newFakeACLManager(), append(tt.objs, user)...)
+
+			result, got := reconcileUser(t, r)
+			assert.Equal(t, retryInterval, result.RequeueAfter)
+			assert.Equal(t, ruvb2.RedisUserPending, got.Status.State)
+			assert.Equal(t, tt.reason, got.Status.Reason)
+		})
+	}
+}
+
+func TestReconcileRejectsDefaultUser(t *testing.T) {
+	acl := newFakeACLManager()
+	user := newUserForTest()
+	user.Spec.Username = ruvb2.DefaultUsername
+	r := newReconcilerForTest(t, acl, user, newReplicationForTest())
+
+	result, got := reconcileUser(t, r)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, ruvb2.RedisUserFailed, got.Status.State)
+	assert.Empty(t, acl.users)
+}
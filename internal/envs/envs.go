@@ -36,6 +36,9 @@ const (
 	// ExecCommandTimeoutEnv defines the timeout for commands executed inside redis pods via the Kubernetes exec API
 	ExecCommandTimeoutEnv = "EXEC_COMMAND_TIMEOUT"
 
+	// RDBStreamTimeoutEnv defines the timeout for streaming the RDB file of a redis pod into the backup storage
+	RDBStreamTimeoutEnv = "RDB_STREAM_TIMEOUT"
+
 	// EnableWebhooksEnv defines whether webhooks are enabled
 	EnableWebhooksEnv = "ENABLE_WEBHOOKS"
 
@@ -105,6 +108,17 @@ func GetExecCommandTimeout(defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// GetRDBStreamTimeout returns the timeout applied to streaming the RDB file of a redis pod into
+// the backup storage.
+func GetRDBStreamTimeout(defaultValue time.Duration) time.Duration {
+	if valueStr := os.Getenv(RDBStreamTimeoutEnv); valueStr != "" {
+		if value, err := time.ParseDuration(valueStr); err == nil && value > 0 {
+			return value
+		}
+	}
+	return defaultValue
+}
+
 // IsWebhookEnabled returns true if webhooks are enabled
 func IsWebhookEnabled() bool {
 	return os.Getenv(EnableWebhooksEnv) != "false"
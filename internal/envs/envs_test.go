@@ -155,6 +155,28 @@ func TestGetExecCommandTimeout(t *testing.T) {
 	}
 }
 
+func TestGetRDBStreamTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		envValue      string
+		expectedValue time.Duration
+	}{
+		{name: "empty value with default", envValue: "", expectedValue: time.Hour},
+		{name: "valid duration", envValue: "6h", expectedValue: 6 * time.Hour},
+		{name: "invalid value with default", envValue: "not-a-duration", expectedValue: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RDBStreamTimeoutEnv, tt.envValue)
+
+			if actualValue := GetRDBStreamTimeout(time.Hour); actualValue != tt.expectedValue {
+				t.Errorf("GetRDBStreamTimeout() = %v, want %v", actualValue, tt.expectedValue)
+			}
+		})
+	}
+}
+
 func TestIsWebhookEnabled(t *testing.T) {
 	tests := []struct {
 		name          string
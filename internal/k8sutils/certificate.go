@@ -0,0 +1,108 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetRedisTLSNodes returns every pod of the target that loads the TLS certificate, in statefulset
+// order with cluster followers before leaders
+func GetRedisTLSNodes(target metav1.Object) ([]string, error) {
+	switch cr := target.(type) {
+	case *rsvb2.RedisSentinel:
+		var pods []string
+		for i := int32(0); i < cr.Spec.GetSentinelCounts("sentinel"); i++ {
+			pods = append(pods, fmt.Sprintf("%s-%d", cr.GetStatefulSetName(), i))
+		}
+		return pods, nil
+	case *rcvb2.RedisCluster:
+		var pods []string
+		for _, role := range []string{"follower", "leader"} {
+			for i := int32(0); i < cr.Spec.GetReplicaCounts(role); i++ {
+				pods = append(pods, fmt.Sprintf("%s-%s-%d", cr.Name, role, i))
+			}
+		}
+		return pods, nil
+	default:
+		return GetRedisACLNodes(target)
+	}
+}
+
+// GetRedisCertificateDNSNames returns the names the TLS certificate of the target is issued for:
+// the headless service name of every pod and the services clients connect to
+func GetRedisCertificateDNSNames(target metav1.Object) ([]string, error) {
+	var services []string
+	switch cr := target.(type) {
+	case *rvb2.Redis:
+		services = []string{cr.Name, cr.Name + "-additional"}
+	case *rrvb2.RedisReplication:
+		services = []string{cr.Name, cr.Name + "-additional", cr.MasterService(), cr.Name + "-replica"}
+	case *rcvb2.RedisCluster:
+		for _, role := range []string{"leader", "follower"} {
+			services = append(services, cr.Name+"-"+role, cr.Name+"-"+role+"-additional")
+		}
+		services = append(services, cr.Name+"-master")
+		if cr.Spec.KubernetesConfig.ShouldCreateReplicaService(false) {
+			services = append(services, cr.Name+"-replica")
+		}
+	case *rsvb2.RedisSentinel:
+		services = []string{cr.GetStatefulSetName(), cr.GetStatefulSetName() + "-additional"}
+	default:
+		return nil, fmt.Errorf("unsupported redis target %T", target)
+	}
+	pods, err := GetRedisTLSNodes(target)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := target.GetNamespace()
+	domain := envs.GetServiceDNSDomain()
+	names := make([]string, 0, 2*(len(pods)+len(services)))
+	for _, pod := range pods {
+		host := fmt.Sprintf("%s.%s.%s.svc", pod, common.GetHeadlessServiceNameFromPodName(pod), namespace)
+		names = append(names, host+"."+domain, host)
+	}
+	for _, service := range services {
+		host := fmt.Sprintf("%s.%s.svc", service, namespace)
+		names = append(names, host+"."+domain, host)
+	}
+	return names, nil
+}
+
+// GetRedisClusterMasterPods returns the pods the cluster currently runs masters on, leaders may
+// have been replaced by their followers. It fails while the pod of a master cannot be told.
+func GetRedisClusterMasterPods(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]string, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	return clusterMasterPods(nodes, podNamesByIP(getClusterPodPlacements(ctx, client, cr)))
+}
+
+func clusterMasterPods(nodes []clusterNodesResponse, podsByIP map[string]string) ([]string, error) {
+	var pods []string
+	for _, node := range nodes {
+		if len(node) < 8 || !nodeIsOfType(node, "master") {
+			continue
+		}
+		podName, err := resolveClusterMasterPod(node, podsByIP)
+		if err != nil {
+			return nil, err
+		}
+		if podName != "" && !nodeFailedOrDisconnected(node) {
+			pods = append(pods, podName)
+		}
+	}
+	return pods, nil
+}
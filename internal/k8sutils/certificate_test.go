@@ -0,0 +1,91 @@
+package k8sutils
+
+import (
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetRedisCertificateDNSNames(t *testing.T) {
+	sentinel := &rsvb2.RedisSentinel{
+		ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Namespace: "redis"},
+		Spec:       rsvb2.RedisSentinelSpec{Size: ptr.To(int32(2))},
+	}
+	names, err := GetRedisCertificateDNSNames(sentinel)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"sentinel-sentinel-0.sentinel-sentinel-headless.redis.svc.cluster.local",
+		"sentinel-sentinel-0.sentinel-sentinel-headless.redis.svc",
+		"sentinel-sentinel-1.sentinel-sentinel-headless.redis.svc.cluster.local",
+		"sentinel-sentinel-1.sentinel-sentinel-headless.redis.svc",
+		"sentinel-sentinel.redis.svc.cluster.local",
+		"sentinel-sentinel.redis.svc",
+		"sentinel-sentinel-additional.redis.svc.cluster.local",
+		"sentinel-sentinel-additional.redis.svc",
+	}, names)
+
+	cluster := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "redis"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(1))},
+	}
+	names, err = GetRedisCertificateDNSNames(cluster)
+	require.NoError(t, err)
+	assert.Contains(t, names, "cluster-follower-0.cluster-follower-headless.redis.svc.cluster.local")
+	assert.Contains(t, names, "cluster-leader-0.cluster-leader-headless.redis.svc.cluster.local")
+	assert.Contains(t, names, "cluster-leader-additional.redis.svc")
+	assert.NotContains(t, names, "cluster-replica.redis.svc")
+
+	cluster.Spec.KubernetesConfig.Service = &commonapi.ServiceConfig{Replicas: &commonapi.Service{}}
+	names, err = GetRedisCertificateDNSNames(cluster)
+	require.NoError(t, err)
+	assert.Contains(t, names, "cluster-replica.redis.svc")
+
+	_, err = GetRedisCertificateDNSNames(&metav1.ObjectMeta{Name: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestGetRedisTLSNodes(t *testing.T) {
+	cluster := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(2))},
+	}
+	pods, err := GetRedisTLSNodes(cluster)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-follower-0", "cluster-follower-1", "cluster-leader-0", "cluster-leader-1"}, pods)
+}
+
+func TestClusterMasterPods(t *testing.T) {
+	nodes := []clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		{"id-leader-1", "10.0.0.2:6379@16379,cluster-leader-1", "slave", "id-follower-1", "0", "0", "2", "connected"},
+		{"id-follower-0", "10.0.0.3:6379@16379,cluster-follower-0", "slave", "id-leader-0", "0", "0", "1", "connected"},
+		{"id-follower-1", "10.0.0.4:6379@16379,cluster-follower-1", "master", "-", "0", "0", "2", "connected", "8192-16383"},
+	}
+
+	podsByIP := map[string]string{
+		"10.0.0.1": "cluster-leader-0",
+		"10.0.0.2": "cluster-leader-1",
+		"10.0.0.3": "cluster-follower-0",
+		"10.0.0.4": "cluster-follower-1",
+	}
+	pods, err := clusterMasterPods(nodes, podsByIP)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-0", "cluster-follower-1"}, pods)
+
+	// nodes announcing no hostname are known by their IP
+	nodes[3][1] = "10.0.0.4:6379@16379"
+	pods, err = clusterMasterPods(nodes, podsByIP)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-0", "cluster-follower-1"}, pods)
+
+	// a master whose pod cannot be told fails the rotation rather than being restarted in turn
+	nodes[3][1] = "198.51.100.4:6379@16379"
+	_, err = clusterMasterPods(nodes, podsByIP)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrNoClusterReplica is returned when a master is to be failed over but none of its replicas is
+// connected to take over its slots
+var ErrNoClusterReplica = errors.New("no connected replica to take over the master")
+
+// FailoverRedisClusterMaster hands the slots of the master running on the pod to one of its
+// replicas with CLUSTER FAILOVER. It reports false when the pod runs no master, and returns
+// ErrNoClusterReplica when the master has no connected replica, the pod must not be restarted then.
+// It fails as well while the pod of a master cannot be told, the pod could be running it.
+func FailoverRedisClusterMaster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) (bool, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return false, err
+	}
+	master, replica, err := clusterReplicaPod(nodes, podName, podNamesByIP(getClusterPodPlacements(ctx, client, cr)))
+	if err != nil || !master {
+		return false, err
+	}
+	if replica == "" {
+		return false, fmt.Errorf("failed to fail over master %s: %w", podName, ErrNoClusterReplica)
+	}
+
+	log.FromContext(ctx).Info("Failing over redis cluster master", "Master", podName, "Replica", replica)
+	replicaClient := configureRedisClient(ctx, client, cr, replica)
+	defer replicaClient.Close()
+	if err := replicaClient.ClusterFailover(ctx).Err(); err != nil {
+		return false, fmt.Errorf("failed to fail over master %s to %s: %w", podName, replica, err)
+	}
+	return true, nil
+}
+
+// clusterReplicaPod reports whether the pod runs a master and returns a connected replica of it,
+// "" when there is none. It fails when the pod of a healthy master cannot be told.
+func clusterReplicaPod(nodes []clusterNodesResponse, podName string, podsByIP map[string]string) (bool, string, error) {
+	var masterID string
+	for _, node := range nodes {
+		if len(node) < 8 || !nodeIsOfType(node, "master") {
+			continue
+		}
+		nodePod, err := resolveClusterMasterPod(node, podsByIP)
+		if err != nil {
+			return false, "", err
+		}
+		if nodePod == podName {
+			masterID = node[0]
+		}
+	}
+	if masterID == "" {
+		return false, "", nil
+	}
+	for _, node := range nodes {
+		if len(node) < 8 || !nodeIsOfType(node, "slave") || nodeFailedOrDisconnected(node) || node[3] != masterID {
+			continue
+		}
+		if replica := clusterNodePodName(node, podsByIP); isClusterPod(podsByIP, replica) {
+			return true, replica, nil
+		}
+	}
+	return true, "", nil
+}
+
+// resolveClusterMasterPod returns the pod running a master of CLUSTER NODES. It fails when the
+// master is healthy but its pod cannot be told, "" is returned for a failed one.
+func resolveClusterMasterPod(node clusterNodesResponse, podsByIP map[string]string) (string, error) {
+	podName := clusterNodePodName(node, podsByIP)
+	if isClusterPod(podsByIP, podName) {
+		return podName, nil
+	}
+	if nodeFailedOrDisconnected(node) {
+		return "", nil
+	}
+	return "", fmt.Errorf("failed to tell the pod of the master %s announced as %s", node[0], node[1])
+}
+
+// isClusterPod reports whether the pod is one of the pods of the cluster
+func isClusterPod(podsByIP map[string]string, podName string) bool {
+	return podName != "" && slices.Contains(slices.Collect(maps.Values(podsByIP)), podName)
+}
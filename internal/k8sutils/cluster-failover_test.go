@@ -0,0 +1,83 @@
+package k8sutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterReplicaPod(t *testing.T) {
+	podsByIP := map[string]string{
+		"10.0.0.1": "cluster-leader-0",
+		"10.0.0.2": "cluster-leader-1",
+		"10.0.0.3": "cluster-follower-0",
+		"10.0.0.4": "cluster-follower-1",
+	}
+	nodes := []clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		{"id-leader-1", "10.0.0.2:6379@16379,cluster-leader-1", "slave", "id-follower-1", "0", "0", "2", "connected"},
+		{"id-follower-0", "10.0.0.3:6379@16379,cluster-follower-0", "slave", "id-leader-0", "0", "0", "1", "connected"},
+		{"id-follower-1", "10.0.0.4:6379@16379,cluster-follower-1", "master", "-", "0", "0", "2", "connected", "8192-16383"},
+	}
+
+	master, replica, err := clusterReplicaPod(nodes, "cluster-leader-0", podsByIP)
+	require.NoError(t, err)
+	assert.True(t, master)
+	assert.Equal(t, "cluster-follower-0", replica)
+	master, replica, err = clusterReplicaPod(nodes, "cluster-follower-1", podsByIP)
+	require.NoError(t, err)
+	assert.True(t, master)
+	assert.Equal(t, "cluster-leader-1", replica)
+	master, replica, err = clusterReplicaPod(nodes, "cluster-follower-0", podsByIP)
+	require.NoError(t, err)
+	assert.False(t, master)
+	assert.Empty(t, replica)
+
+	// a disconnected replica cannot take over, the pod still runs a master
+	nodes[2][7] = "disconnected"
+	master, replica, err = clusterReplicaPod(nodes, "cluster-leader-0", podsByIP)
+	require.NoError(t, err)
+	assert.True(t, master)
+	assert.Empty(t, replica)
+}
+
+func TestClusterReplicaPodWithoutHostnames(t *testing.T) {
+	// redis 6 announces no hostname, external access announces the load balancer
+	podsByIP := map[string]string{
+		"10.0.0.1":          "cluster-leader-0",
+		"10.0.0.3":          "cluster-follower-0",
+		"lb-0.example.com":  "cluster-leader-0",
+		"203.0.113.3":       "cluster-follower-0",
+		"10.0.0.2":          "cluster-leader-1",
+		"lb-12.example.com": "cluster-leader-1",
+	}
+	nodes := []clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		{"id-follower-0", "10.0.0.3:6379@16379", "slave", "id-leader-0", "0", "0", "1", "connected"},
+	}
+	master, replica, err := clusterReplicaPod(nodes, "cluster-leader-0", podsByIP)
+	require.NoError(t, err)
+	assert.True(t, master)
+	assert.Equal(t, "cluster-follower-0", replica)
+
+	nodes = []clusterNodesResponse{
+		{"id-leader-0", "198.51.100.1:6379@16379,lb-0.example.com", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		{"id-follower-0", "203.0.113.3:6379@16379", "slave", "id-leader-0", "0", "0", "1", "connected"},
+	}
+	master, replica, err = clusterReplicaPod(nodes, "cluster-leader-0", podsByIP)
+	require.NoError(t, err)
+	assert.True(t, master)
+	assert.Equal(t, "cluster-follower-0", replica)
+
+	// a master whose pod cannot be told may be the pod
+	nodes = append(nodes, clusterNodesResponse{"id-leader-1", "198.51.100.2:6379@16379", "master", "-", "0", "0", "2", "connected", "8192-16383"})
+	_, _, err = clusterReplicaPod(nodes, "cluster-leader-1", podsByIP)
+	assert.Error(t, err)
+
+	// unless it failed
+	nodes[2][2] = "master,fail"
+	master, _, err = clusterReplicaPod(nodes, "cluster-leader-1", podsByIP)
+	require.NoError(t, err)
+	assert.False(t, master)
+}
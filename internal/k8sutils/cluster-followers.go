@@ -0,0 +1,253 @@
+package k8sutils
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// clusterMember is a node of the cluster, or a follower pod that did not join it yet, with the
+// kubernetes node and zone its pod runs on
+type clusterMember struct {
+	PodName string
+	// NodeID is empty for a follower pod that is not part of the cluster
+	NodeID string
+	// MasterID is the node a replica replicates
+	MasterID string
+	Node     string
+	Zone     string
+}
+
+// podPlacement is the address and the kubernetes node and zone of a pod of the cluster
+type podPlacement struct {
+	IP string
+	// Announced is the IP or hostname of the load balancer the pod announces with external access
+	Announced string
+	Node      string
+	Zone      string
+}
+
+// getClusterPodPlacements returns the placement of the leader and follower pods by pod name. The
+// zone is left empty when the node cannot be read.
+func getClusterPodPlacements(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) map[string]podPlacement {
+	placements := map[string]podPlacement{}
+	zones := map[string]string{}
+	for _, role := range []string{"leader", "follower"} {
+		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+			podName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				log.FromContext(ctx).V(1).Info("Skipping pod of the cluster", "pod", podName, "error", err.Error())
+				continue
+			}
+			zone, found := zones[pod.Spec.NodeName]
+			if !found && pod.Spec.NodeName != "" {
+				node, err := client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+				if err != nil {
+					log.FromContext(ctx).V(1).Info("Failed to read the zone of the node", "node", pod.Spec.NodeName, "error", err.Error())
+				} else {
+					zone = node.Labels[corev1.LabelTopologyZone]
+				}
+				zones[pod.Spec.NodeName] = zone
+			}
+			placement := podPlacement{IP: pod.Status.PodIP, Node: pod.Spec.NodeName, Zone: zone}
+			if cr.Spec.GetExternalServiceType() == corev1.ServiceTypeLoadBalancer {
+				if svc, err := getService(ctx, client, cr.Namespace, podName); err == nil {
+					// the pod announces the IP of the load balancer, its hostname without one
+					ip, hostname := loadBalancerAddress(svc)
+					placement.Announced = ip
+					if ip == "" {
+						placement.Announced = hostname
+					}
+				}
+			}
+			placements[podName] = placement
+		}
+	}
+	return placements
+}
+
+// clusterShards returns the healthy masters serving slots and replicas of the cluster, followed
+// by the follower pods that did not join it, with the placement of their pods. Nodes whose pod is
+// unknown are left out.
+func clusterShards(cr *rcvb2.RedisCluster, nodes []clusterNodesResponse, placements map[string]podPlacement) (masters, replicas []clusterMember) {
+	podsByIP := podNamesByIP(placements)
+	joined := map[string]bool{}
+	for _, node := range nodes {
+		if len(node) < 8 {
+			continue
+		}
+		podName := clusterNodePodName(node, podsByIP)
+		placement, found := placements[podName]
+		if !found {
+			continue
+		}
+		// a failing node is repaired rather than added again
+		joined[podName] = true
+		if nodeFailedOrDisconnected(node) {
+			continue
+		}
+		member := clusterMember{PodName: podName, NodeID: node[0], Node: placement.Node, Zone: placement.Zone}
+		switch {
+		case nodeIsOfType(node, "master") && len(node) > 8:
+			masters = append(masters, member)
+		case nodeIsOfType(node, "slave"):
+			member.MasterID = node[3]
+			replicas = append(replicas, member)
+		}
+	}
+	for i := 0; i < int(cr.Spec.GetReplicaCounts("follower")); i++ {
+		podName := cr.Name + "-follower-" + strconv.Itoa(i)
+		if placement, found := placements[podName]; found && !joined[podName] {
+			replicas = append(replicas, clusterMember{PodName: podName, Node: placement.Node, Zone: placement.Zone})
+		}
+	}
+	sort.Slice(masters, func(i, j int) bool { return masters[i].PodName < masters[j].PodName })
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].PodName < replicas[j].PodName })
+	return masters, replicas
+}
+
+// podNamesByIP returns the names of the pods by their IP and by the address of the load balancer
+// they announce
+func podNamesByIP(placements map[string]podPlacement) map[string]string {
+	podsByIP := make(map[string]string, len(placements))
+	for podName, placement := range placements {
+		if placement.IP != "" {
+			podsByIP[placement.IP] = podName
+		}
+		if placement.Announced != "" {
+			podsByIP[placement.Announced] = podName
+		}
+	}
+	return podsByIP
+}
+
+// clusterNodePodName returns the pod running a node of CLUSTER NODES, from the hostname it
+// announces or from its IP
+func clusterNodePodName(node clusterNodesResponse, podsByIP map[string]string) string {
+	if host, err := getHostFromClusterNode(node); err == nil && host != "" {
+		// a load balancer hostname is known by the pod announcing it
+		if podName, found := podsByIP[host]; found {
+			return podName
+		}
+		return strings.Split(host, ".")[0]
+	}
+	// nodes announcing no hostname are known by the IP of their ip:port@cport address
+	address := strings.Split(node[1], "@")[0]
+	return podsByIP[strings.Trim(address[:max(strings.LastIndex(address, ":"), 0)], "[]")]
+}
+
+// planFollowerAttachments returns the node ID of the master every replica should replicate, by
+// pod name. Every master gets the same number of replicas, give or take one when they do not
+// divide evenly. A replica keeps its master unless it shares the node or the zone of
+// it while another master does not, the others are attached round-robin to the masters with the
+// fewest replicas, preferring masters on another node and zone.
+func planFollowerAttachments(masters, replicas []clusterMember) map[string]string {
+	if len(masters) == 0 {
+		return nil
+	}
+	// zones are only told apart when the pods are spread over several
+	zoneSet := map[string]bool{}
+	for _, member := range append(append([]clusterMember{}, masters...), replicas...) {
+		if member.Zone != "" {
+			zoneSet[member.Zone] = true
+		}
+	}
+	spreadOverZones := len(zoneSet) > 1
+	// conflict ranks a master for a replica: 0 apart, 1 in the same zone, 2 on the same node
+	conflict := func(master, replica clusterMember) int {
+		switch {
+		case master.Node != "" && master.Node == replica.Node:
+			return 2
+		case spreadOverZones && master.Zone != "" && master.Zone == replica.Zone:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	base, extra := len(replicas)/len(masters), len(replicas)%len(masters)
+	counts := make(map[string]int, len(masters))
+	canTake := func(master clusterMember) bool {
+		return counts[master.NodeID] < base || (counts[master.NodeID] == base && extra > 0)
+	}
+	take := func(master clusterMember) {
+		if counts[master.NodeID] == base {
+			extra--
+		}
+		counts[master.NodeID]++
+	}
+
+	plan := make(map[string]string, len(replicas))
+	for _, replica := range replicas {
+		best := 2
+		for _, master := range masters {
+			best = min(best, conflict(master, replica))
+		}
+		for _, master := range masters {
+			if master.NodeID == replica.MasterID && conflict(master, replica) == best && canTake(master) {
+				take(master)
+				plan[replica.PodName] = master.NodeID
+				break
+			}
+		}
+	}
+	for _, replica := range replicas {
+		if _, planned := plan[replica.PodName]; planned {
+			continue
+		}
+		var target *clusterMember
+		for i, master := range masters {
+			if !canTake(master) {
+				continue
+			}
+			if target == nil || conflict(master, replica) < conflict(*target, replica) ||
+				(conflict(master, replica) == conflict(*target, replica) && counts[master.NodeID] < counts[target.NodeID]) {
+				target = &masters[i]
+			}
+		}
+		if target != nil {
+			take(*target)
+			plan[replica.PodName] = target.NodeID
+		}
+	}
+	return plan
+}
+
+// RebalanceRedisClusterFollowers moves a replica whose master differs from the planned one with
+// CLUSTER REPLICATE, one per call once every follower joined the cluster. It reports whether a
+// replica was moved.
+func RebalanceRedisClusterFollowers(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (bool, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return false, err
+	}
+	masters, replicas := clusterShards(cr, nodes, getClusterPodPlacements(ctx, client, cr))
+	for _, replica := range replicas {
+		if replica.NodeID == "" {
+			return false, nil
+		}
+	}
+	plan := planFollowerAttachments(masters, replicas)
+	for _, replica := range replicas {
+		master, planned := plan[replica.PodName]
+		if !planned || master == replica.MasterID {
+			continue
+		}
+		log.FromContext(ctx).Info("Moving follower to another master", "pod", replica.PodName, "from", replica.MasterID, "to", master)
+		replicaClient := configureRedisClient(ctx, client, cr, replica.PodName)
+		err := replicaClient.ClusterReplicate(ctx, master).Err()
+		replicaClient.Close()
+		return err == nil, err
+	}
+	return false, nil
+}
@@ -0,0 +1,100 @@
+package k8sutils
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestClusterShards(t *testing.T) {
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(2)), ReplicasPerShard: ptr.To(int32(1))},
+	}
+	placements := map[string]podPlacement{
+		"cluster-leader-0":   {IP: "10.0.0.1", Node: "node-a", Zone: "zone-a"},
+		"cluster-leader-1":   {IP: "10.0.0.2", Node: "node-b", Zone: "zone-b"},
+		"cluster-follower-0": {IP: "10.0.0.3", Node: "node-b", Zone: "zone-b"},
+		"cluster-follower-1": {IP: "10.0.0.4", Node: "node-a", Zone: "zone-a"},
+	}
+	nodes := []clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		// a node announcing no hostname is known by its IP
+		{"id-leader-1", "10.0.0.2:6379@16379", "master", "-", "0", "0", "2", "connected", "8192-16383"},
+		{"id-follower-0", "10.0.0.3:6379@16379,cluster-follower-0", "slave", "id-leader-0", "0", "0", "1", "connected"},
+	}
+
+	masters, replicas := clusterShards(cr, nodes, placements)
+	assert.Equal(t, []clusterMember{
+		{PodName: "cluster-leader-0", NodeID: "id-leader-0", Node: "node-a", Zone: "zone-a"},
+		{PodName: "cluster-leader-1", NodeID: "id-leader-1", Node: "node-b", Zone: "zone-b"},
+	}, masters)
+	assert.Equal(t, []clusterMember{
+		{PodName: "cluster-follower-0", NodeID: "id-follower-0", MasterID: "id-leader-0", Node: "node-b", Zone: "zone-b"},
+		{PodName: "cluster-follower-1", Node: "node-a", Zone: "zone-a"},
+	}, replicas)
+
+	// follower-1 must not replicate leader-0 which runs in its zone
+	assert.Equal(t, map[string]string{
+		"cluster-follower-0": "id-leader-0",
+		"cluster-follower-1": "id-leader-1",
+	}, planFollowerAttachments(masters, replicas))
+}
+
+func TestPlanFollowerAttachments(t *testing.T) {
+	masters := []clusterMember{
+		{PodName: "leader-0", NodeID: "m0", Node: "node-a", Zone: "zone-a"},
+		{PodName: "leader-1", NodeID: "m1", Node: "node-b", Zone: "zone-b"},
+		{PodName: "leader-2", NodeID: "m2", Node: "node-c", Zone: "zone-c"},
+	}
+
+	// the followers are attached round-robin
+	replicas := []clusterMember{
+		{PodName: "follower-0"}, {PodName: "follower-1"}, {PodName: "follower-2"},
+		{PodName: "follower-3"}, {PodName: "follower-4"},
+	}
+	plan := planFollowerAttachments(masters, replicas)
+	assert.Equal(t, map[string]string{
+		"follower-0": "m0", "follower-1": "m1", "follower-2": "m2", "follower-3": "m0", "follower-4": "m1",
+	}, plan)
+
+	// a master with too many replicas hands the extra ones to the others
+	replicas = []clusterMember{
+		{PodName: "follower-0", NodeID: "r0", MasterID: "m0", Node: "node-b", Zone: "zone-b"},
+		{PodName: "follower-1", NodeID: "r1", MasterID: "m0", Node: "node-c", Zone: "zone-c"},
+		{PodName: "follower-2", NodeID: "r2", MasterID: "m0", Node: "node-a", Zone: "zone-a"},
+	}
+	// follower-0 keeps its master, follower-2 never replicates the master of its zone
+	assert.Equal(t, map[string]string{
+		"follower-0": "m0", "follower-1": "m1", "follower-2": "m2",
+	}, planFollowerAttachments(masters, replicas))
+
+	// without zones the nodes are still kept apart
+	masters = []clusterMember{
+		{PodName: "leader-0", NodeID: "m0", Node: "node-a"},
+		{PodName: "leader-1", NodeID: "m1", Node: "node-b"},
+	}
+	replicas = []clusterMember{
+		{PodName: "follower-0", NodeID: "r0", MasterID: "m0", Node: "node-a"},
+		{PodName: "follower-1", NodeID: "r1", MasterID: "m1", Node: "node-b"},
+	}
+	assert.Equal(t, map[string]string{"follower-0": "m1", "follower-1": "m0"}, planFollowerAttachments(masters, replicas))
+	assert.Nil(t, planFollowerAttachments(nil, replicas))
+}
+
+func TestClusterNodePodName(t *testing.T) {
+	podsByIP := podNamesByIP(map[string]podPlacement{
+		"cluster-leader-0": {IP: "10.0.0.1", Announced: "lb-0.example.com"},
+		"cluster-leader-1": {IP: "10.0.0.2", Announced: "198.51.100.2"},
+	})
+
+	assert.Equal(t, "cluster-leader-0", clusterNodePodName(clusterNodesResponse{"id", "10.0.0.1:6379@16379,cluster-leader-0"}, podsByIP))
+	assert.Equal(t, "cluster-leader-0", clusterNodePodName(clusterNodesResponse{"id", "10.0.0.1:6379@16379"}, podsByIP))
+	// with external access the address of the load balancer is announced
+	assert.Equal(t, "cluster-leader-0", clusterNodePodName(clusterNodesResponse{"id", "198.51.100.1:6379@16379,lb-0.example.com"}, podsByIP))
+	assert.Equal(t, "cluster-leader-1", clusterNodePodName(clusterNodesResponse{"id", "198.51.100.2:6379@16379"}, podsByIP))
+	assert.Empty(t, clusterNodePodName(clusterNodesResponse{"id", "198.51.100.3:6379@16379"}, podsByIP))
+}
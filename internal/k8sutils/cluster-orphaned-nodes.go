@@ -0,0 +1,110 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/redis/go-redis/v9"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ForgetOrphanedRedisClusterNodes forgets the nodes of the cluster no pod runs anymore, the ones
+// left behind by deleted pods or lost node-conf volumes, and returns their IDs. A node is only
+// forgotten once it has not answered for the grace period of the spec.
+func ForgetOrphanedRedisClusterNodes(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]string, error) {
+	gracePeriod, enabled := cr.Spec.GetOrphanedNodesGracePeriod()
+	if !enabled {
+		return nil, nil
+	}
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	// the IDs of the pods are only asked for when there is a node to forget
+	if len(orphanedNodes(nodes, nil, time.Now(), gracePeriod)) == 0 {
+		return nil, nil
+	}
+
+	var podNames []string
+	for _, role := range []string{"leader", "follower"} {
+		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+			podNames = append(podNames, cr.Name+"-"+role+"-"+strconv.Itoa(i))
+		}
+	}
+	clients := make([]*redis.Client, 0, len(podNames))
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	podIDs := map[string]bool{}
+	for _, podName := range podNames {
+		c := configureRedisClient(ctx, client, cr, podName)
+		clients = append(clients, c)
+		// a pod whose ID is unknown may be running any of the candidates
+		id, err := c.ClusterMyID(ctx).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the node ID of pod %s: %w", podName, err)
+		}
+		podIDs[id] = true
+	}
+	orphans := orphanedNodes(nodes, podIDs, time.Now(), gracePeriod)
+	if len(orphans) == 0 {
+		return nil, nil
+	}
+	log.FromContext(ctx).Info("Forgetting orphaned cluster nodes", "Nodes", orphans)
+	return orphans, forgetClusterNodes(ctx, clients, orphans)
+}
+
+// orphanedNodes returns the sorted IDs of the nodes which are none of the pods, are marked fail or
+// noaddr, serve no slots, are the master of no connected node and have not answered for the grace
+// period. A node which never answered the one listing it counts as past the grace period.
+func orphanedNodes(nodes []clusterNodesResponse, podIDs map[string]bool, now time.Time, gracePeriod time.Duration) []string {
+	masters := map[string]bool{}
+	for _, node := range nodes {
+		if len(node) >= 8 && node[3] != "-" && !nodeFailedOrDisconnected(node) {
+			masters[node[3]] = true
+		}
+	}
+	var orphans []string
+	for _, node := range nodes {
+		// a node serving slots or importing or migrating them has more than 8 fields
+		if len(node) != 8 || podIDs[node[0]] || masters[node[0]] {
+			continue
+		}
+		if hasFlag(node[2], "myself") || hasFlag(node[2], "handshake") || !(hasFlag(node[2], "fail") || hasFlag(node[2], "noaddr")) {
+			continue
+		}
+		if pongRecv, err := strconv.ParseInt(node[5], 10, 64); err == nil && pongRecv > 0 && now.Sub(time.UnixMilli(pongRecv)) < gracePeriod {
+			continue
+		}
+		orphans = append(orphans, node[0])
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// forgetClusterNodes issues CLUSTER FORGET for every node on every client back to back, a node
+// forgotten by one node only is gossiped back by the others once its 60 seconds ban expires
+func forgetClusterNodes(ctx context.Context, clients []*redis.Client, nodeIDs []string) error {
+	var lastError error
+	for _, c := range clients {
+		for _, id := range nodeIDs {
+			err := c.ClusterForget(ctx, id).Err()
+			// a node which never heard of it has nothing to forget
+			if err != nil && !strings.Contains(err.Error(), "Unknown node") {
+				lastError = err
+				log.FromContext(ctx).V(1).Error(err, "Failed to execute CLUSTER FORGET on node. Continuing with other nodes.", "Node", c.Options().Addr, "NodeID", id)
+			}
+		}
+	}
+	return lastError
+}
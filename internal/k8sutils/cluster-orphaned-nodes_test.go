@@ -0,0 +1,71 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedNodes(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	pong := func(ago time.Duration) string {
+		return strconv.FormatInt(now.Add(-ago).UnixMilli(), 10)
+	}
+	nodes := []clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "myself,master", "-", "0", "0", "1", "connected", "0-8191"},
+		{"id-leader-1", "10.0.0.2:6379@16379,cluster-leader-1", "master", "-", "0", pong(0), "2", "connected", "8192-16383"},
+		{"id-follower-0", "10.0.0.3:6379@16379,cluster-follower-0", "slave", "id-leader-0", "0", pong(0), "1", "connected"},
+		// the previous node of leader-1 whose node-conf volume was lost
+		{"id-lost", "10.0.0.9:6379@16379,cluster-leader-1", "master,fail", "-", "0", pong(time.Hour), "2", "disconnected"},
+		// a deleted pod which never answered since leader-0 restarted
+		{"id-deleted", ":0@0", "slave,fail,noaddr", "id-leader-1", "0", "0", "2", "disconnected"},
+		// failed too recently to be forgotten
+		{"id-recent", "10.0.0.8:6379@16379", "slave,fail", "id-leader-1", "0", pong(time.Minute), "2", "disconnected"},
+		// only suspected to fail
+		{"id-pfail", "10.0.0.7:6379@16379", "slave,fail?", "id-leader-1", "0", pong(time.Hour), "2", "disconnected"},
+		// still serving slots
+		{"id-slots", "10.0.0.6:6379@16379", "master,fail", "-", "0", pong(time.Hour), "3", "disconnected", "100-200"},
+		// still the master of a connected follower
+		{"id-master", "10.0.0.5:6379@16379", "master,fail", "-", "0", pong(time.Hour), "4", "disconnected"},
+		{"id-follower-1", "10.0.0.4:6379@16379,cluster-follower-1", "slave", "id-master", "0", pong(0), "4", "connected"},
+	}
+	podIDs := map[string]bool{"id-leader-0": true, "id-leader-1": true, "id-follower-0": true, "id-follower-1": true}
+
+	assert.Equal(t, []string{"id-deleted", "id-lost"}, orphanedNodes(nodes, podIDs, now, 5*time.Minute))
+	// a node answering the pods is never orphaned
+	podIDs["id-lost"] = true
+	assert.Equal(t, []string{"id-deleted"}, orphanedNodes(nodes, podIDs, now, 5*time.Minute))
+	// without a grace period the recently failed node goes as well
+	assert.Equal(t, []string{"id-deleted", "id-recent"}, orphanedNodes(nodes, podIDs, now, 0))
+}
+
+func TestForgetClusterNodes(t *testing.T) {
+	ctx := context.Background()
+	leader, leaderMock := redismock.NewClientMock()
+	follower, followerMock := redismock.NewClientMock()
+
+	leaderMock.ExpectClusterForget("id-deleted").SetVal("OK")
+	leaderMock.ExpectClusterForget("id-lost").SetVal("OK")
+	// the follower never heard of the deleted node
+	followerMock.ExpectClusterForget("id-deleted").SetErr(errors.New("ERR Unknown node id-deleted"))
+	followerMock.ExpectClusterForget("id-lost").SetVal("OK")
+
+	err := forgetClusterNodes(ctx, []*redis.Client{leader, follower}, []string{"id-deleted", "id-lost"})
+	require.NoError(t, err)
+	assert.NoError(t, leaderMock.ExpectationsWereMet())
+	assert.NoError(t, followerMock.ExpectationsWereMet())
+
+	// a failure on one node does not stop the others from forgetting
+	leaderMock.ExpectClusterForget("id-lost").SetErr(errors.New("ERR Can't forget my master!"))
+	followerMock.ExpectClusterForget("id-lost").SetVal("OK")
+	err = forgetClusterNodes(ctx, []*redis.Client{leader, follower}, []string{"id-lost"})
+	assert.Error(t, err)
+	assert.NoError(t, followerMock.ExpectationsWereMet())
+}
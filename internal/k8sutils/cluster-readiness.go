@@ -0,0 +1,135 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReconcileRedisClusterReadinessGates sets the readiness gate of every pod of the cluster to
+// whether its node serves the cluster, when the gate is enabled
+func ReconcileRedisClusterReadinessGates(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	if !cr.Spec.ReadinessGate {
+		return nil
+	}
+	var lastError error
+	for _, role := range []string{"leader", "follower"} {
+		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+			podName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					lastError = err
+				}
+				continue
+			}
+			ready, message := false, "the containers of the pod are not ready"
+			if podConditionTrue(pod, corev1.ContainersReady) {
+				redisClient := configureRedisClient(ctx, client, cr, podName)
+				ready, message = clusterNodeReadiness(ctx, redisClient)
+				redisClient.Close()
+			}
+			if !setNodeReadyCondition(pod, ready, message) {
+				continue
+			}
+			log.FromContext(ctx).V(1).Info("Updating the readiness gate of the pod", "Pod", podName, "Ready", ready, "Message", message)
+			if _, err = client.CoreV1().Pods(cr.Namespace).UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+				lastError = err
+				log.FromContext(ctx).Error(err, "Failed to update the readiness gate of the pod", "Pod", podName)
+			}
+		}
+	}
+	return lastError
+}
+
+// clusterNodeReadiness reports whether the node serves the cluster: it is in its own CLUSTER NODES,
+// the state of the cluster is ok and it is either a master serving slots or a replica whose link
+// to its master is up. A leader pod replicating the follower promoted by a failover serves the
+// cluster as well as the other way round.
+func clusterNodeReadiness(ctx context.Context, redisClient *redis.Client) (bool, string) {
+	info, err := redisClient.ClusterInfo(ctx).Result()
+	if err != nil {
+		return false, fmt.Sprintf("failed to get the cluster info: %v", err)
+	}
+	if !strings.Contains(info, "cluster_state:ok") {
+		return false, "the state of the cluster is not ok"
+	}
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return false, fmt.Sprintf("failed to get the cluster nodes: %v", err)
+	}
+	var myself clusterNodesResponse
+	for _, node := range nodes {
+		if len(node) >= 8 && hasFlag(node[2], "myself") {
+			myself = node
+		}
+	}
+	switch {
+	case myself == nil:
+		return false, "the node is not in CLUSTER NODES"
+	case hasFlag(myself[2], "master"):
+		// a node serving slots has more than 8 fields
+		if len(myself) == 8 {
+			return false, "the master serves no slots"
+		}
+		return true, "the master serves slots of the cluster"
+	case hasFlag(myself[2], "slave"):
+		replication, err := redisClient.Info(ctx, "replication").Result()
+		if err != nil {
+			return false, fmt.Sprintf("failed to get the replication info: %v", err)
+		}
+		if !strings.Contains(replication, "master_link_status:up") {
+			return false, "the link of the replica to its master is down"
+		}
+		return true, "the replica is in sync with its master"
+	}
+	return false, "the node is neither a master nor a replica"
+}
+
+// setNodeReadyCondition sets the readiness gate condition of the pod, it returns false when the
+// condition is unchanged
+func setNodeReadyCondition(pod *corev1.Pod, ready bool, message string) bool {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	condition := corev1.PodCondition{
+		Type:               rcvb2.NodeReadyCondition,
+		Status:             status,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i, existing := range pod.Status.Conditions {
+		if existing.Type != rcvb2.NodeReadyCondition {
+			continue
+		}
+		if existing.Status == status && existing.Message == message {
+			return false
+		}
+		if existing.Status == status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		pod.Status.Conditions[i] = condition
+		return true
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	return true
+}
+
+func podConditionTrue(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
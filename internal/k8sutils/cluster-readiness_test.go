@@ -0,0 +1,103 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterNodeReadiness(t *testing.T) {
+	const (
+		master  = "id-leader-0 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-16383\n"
+		replica = "id-follower-0 10.0.0.2:6379@16379 myself,slave id-leader-0 0 0 1 connected\n"
+	)
+	tests := []struct {
+		name    string
+		expect  func(mock redismock.ClientMock)
+		ready   bool
+		message string
+	}{
+		{
+			name: "master serving slots",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectClusterInfo().SetVal("cluster_state:ok\r\n")
+				mock.ExpectClusterNodes().SetVal(master)
+			},
+			ready:   true,
+			message: "the master serves slots of the cluster",
+		},
+		{
+			name: "node which has not joined the cluster",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectClusterInfo().SetVal("cluster_state:fail\r\n")
+			},
+			message: "the state of the cluster is not ok",
+		},
+		{
+			name: "master without slots",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectClusterInfo().SetVal("cluster_state:ok\r\n")
+				mock.ExpectClusterNodes().SetVal("id-leader-3 10.0.0.4:6379@16379 myself,master - 0 0 4 connected\n")
+			},
+			message: "the master serves no slots",
+		},
+		{
+			name: "replica in sync",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectClusterInfo().SetVal("cluster_state:ok\r\n")
+				mock.ExpectClusterNodes().SetVal(replica)
+				mock.ExpectInfo("replication").SetVal("role:slave\r\nmaster_link_status:up\r\n")
+			},
+			ready:   true,
+			message: "the replica is in sync with its master",
+		},
+		{
+			name: "replica still syncing",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectClusterInfo().SetVal("cluster_state:ok\r\n")
+				mock.ExpectClusterNodes().SetVal(replica)
+				mock.ExpectInfo("replication").SetVal("role:slave\r\nmaster_link_status:down\r\n")
+			},
+			message: "the link of the replica to its master is down",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redisClient, mock := redismock.NewClientMock()
+			tt.expect(mock)
+			ready, message := clusterNodeReadiness(context.TODO(), redisClient)
+			assert.Equal(t, tt.ready, ready)
+			assert.Equal(t, tt.message, message)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetNodeReadyCondition(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+	}}}
+
+	assert.True(t, setNodeReadyCondition(pod, false, "the state of the cluster is not ok"))
+	assert.Len(t, pod.Status.Conditions, 2)
+	assert.Equal(t, corev1.ConditionFalse, pod.Status.Conditions[1].Status)
+
+	// an unchanged condition is not written again
+	assert.False(t, setNodeReadyCondition(pod, false, "the state of the cluster is not ok"))
+
+	transition := metav1.Unix(1700000000, 0)
+	pod.Status.Conditions[1].LastTransitionTime = transition
+	assert.True(t, setNodeReadyCondition(pod, false, "the master serves no slots"))
+	assert.Equal(t, transition, pod.Status.Conditions[1].LastTransitionTime, "the status did not change")
+
+	assert.True(t, setNodeReadyCondition(pod, true, "the master serves slots of the cluster"))
+	assert.Equal(t, rcvb2.NodeReadyCondition, pod.Status.Conditions[1].Type)
+	assert.Equal(t, corev1.ConditionTrue, pod.Status.Conditions[1].Status)
+	assert.NotEqual(t, transition, pod.Status.Conditions[1].LastTransitionTime)
+	assert.Len(t, pod.Status.Conditions, 2)
+}
@@ -0,0 +1,282 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redis "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// reshardSlotBatch is the number of slots moved by a call of ReshardRedisCluster, the progress
+	// is recorded in between
+	reshardSlotBatch = 128
+	// migrateKeyBatch is the number of keys moved by a MIGRATE
+	migrateKeyBatch = 100
+	// migrateTimeout is the timeout of a MIGRATE
+	migrateTimeout = 60 * time.Second
+)
+
+// slotMigration moves slots from a source master to a target master the way
+// redis-cli --cluster reshard does: the slot is opened on both nodes with CLUSTER SETSLOT, its
+// keys are moved with MIGRATE and the slot is assigned to the target on every master.
+type slotMigration struct {
+	source *redis.Client
+	target *redis.Client
+	// masters are the other masters of the cluster by pod name
+	masters  map[string]*redis.Client
+	sourceID string
+	targetID string
+	// host and port are the address of the target the source migrates the keys to
+	host     string
+	port     string
+	password string
+}
+
+// newSlotMigration connects to the source and the target pods, the connections are released by
+// close
+func newSlotMigration(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, sourcePod, targetPod RedisDetails, sourceID, targetID string) (slotMigration, error) {
+	endpoint := getEndpoint(ctx, client, cr, targetPod)
+	lastColon := strings.LastIndex(endpoint, ":")
+	if lastColon < 0 {
+		return slotMigration{}, fmt.Errorf("invalid endpoint format: %s", endpoint)
+	}
+	m := slotMigration{
+		sourceID: sourceID,
+		targetID: targetID,
+		host:     strings.Trim(endpoint[:lastColon], "[]"),
+		port:     endpoint[lastColon+1:],
+	}
+	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
+		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
+		if err != nil {
+			return slotMigration{}, err
+		}
+		m.password = pass
+	}
+	m.source = configureRedisClient(ctx, client, cr, sourcePod.PodName)
+	m.target = configureRedisClient(ctx, client, cr, targetPod.PodName)
+	nodes, err := clusterNodes(ctx, m.source)
+	if err != nil {
+		m.close()
+		return slotMigration{}, err
+	}
+	podsByIP := podNamesByIP(getClusterPodPlacements(ctx, client, cr))
+	m.masters = map[string]*redis.Client{}
+	for _, node := range nodes {
+		if len(node) < 8 || !nodeIsOfType(node, "master") || nodeFailedOrDisconnected(node) || node[0] == sourceID || node[0] == targetID {
+			continue
+		}
+		podName := clusterNodePodName(node, podsByIP)
+		if podName == "" {
+			log.FromContext(ctx).Info("Skipping a master the slot owner cannot be set on, its pod is unknown", "Node", node[0])
+			continue
+		}
+		m.masters[podName] = configureRedisClient(ctx, client, cr, podName)
+	}
+	return m, nil
+}
+
+func (m slotMigration) close() {
+	m.source.Close()
+	m.target.Close()
+	for _, master := range m.masters {
+		master.Close()
+	}
+}
+
+// migrateSlot moves a slot and its keys to the target and returns the number of keys it moved
+// with REPLACE, overwriting the keys of the same name on the target. Running it again on a slot left open by an interrupted call finishes the
+// move.
+func (m slotMigration) migrateSlot(ctx context.Context, slot int) (int, error) {
+	if err := m.target.Do(ctx, "cluster", "setslot", slot, "importing", m.sourceID).Err(); err != nil {
+		return 0, fmt.Errorf("failed to import slot %d on the target: %w", slot, err)
+	}
+	if err := m.source.Do(ctx, "cluster", "setslot", slot, "migrating", m.targetID).Err(); err != nil {
+		return 0, fmt.Errorf("failed to migrate slot %d from the source: %w", slot, err)
+	}
+	replaced := 0
+	for {
+		keys, err := m.source.ClusterGetKeysInSlot(ctx, slot, migrateKeyBatch).Result()
+		if err != nil {
+			return replaced, fmt.Errorf("failed to get the keys of slot %d: %w", slot, err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+		err = m.migrateKeys(ctx, keys, false)
+		if err != nil && strings.HasPrefix(err.Error(), "BUSYKEY") {
+			// the source owns the slot, a key of the same name on the target is a leftover of an
+			// earlier move and the key of the source replaces it
+			log.FromContext(ctx).Error(err, "Replacing the keys already on the target with the ones of the source", "Slot", slot, "Keys", len(keys))
+			if err = m.migrateKeys(ctx, keys, true); err == nil {
+				replaced += len(keys)
+			}
+		}
+		if err != nil {
+			return replaced, fmt.Errorf("failed to migrate the keys of slot %d: %w", slot, err)
+		}
+	}
+	// the target is assigned the slot first so that it is never left without an owner
+	for _, node := range []*redis.Client{m.target, m.source} {
+		if err := node.Do(ctx, "cluster", "setslot", slot, "node", m.targetID).Err(); err != nil {
+			return replaced, fmt.Errorf("failed to assign slot %d to the target: %w", slot, err)
+		}
+	}
+	// the other masters would learn the owner by gossip, they are told right away so that they
+	// do not redirect clients to the source in the meantime
+	for _, podName := range slices.Sorted(maps.Keys(m.masters)) {
+		if err := m.masters[podName].Do(ctx, "cluster", "setslot", slot, "node", m.targetID).Err(); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to assign the slot to the target on a master, it learns it by gossip", "Slot", slot, "Master", podName)
+		}
+	}
+	return replaced, nil
+}
+
+// migrateKeys moves the keys to the target with MIGRATE, with REPLACE the keys of the same name
+// on the target are overwritten
+func (m slotMigration) migrateKeys(ctx context.Context, keys []string, replace bool) error {
+	args := []interface{}{"migrate", m.host, m.port, "", 0, migrateTimeout.Milliseconds()}
+	if replace {
+		args = append(args, "replace")
+	}
+	if m.password != "" {
+		args = append(args, "auth", m.password)
+	}
+	args = append(args, "keys")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return m.source.Do(ctx, args...).Err()
+}
+
+// sourceSlots returns the slots served by the node nodeID according to CLUSTER NODES, the slots
+// it is migrating first, in the order they are to be moved.
+func sourceSlots(nodes []clusterNodesResponse, nodeID string) []int {
+	var owned, migrating []int
+	for _, node := range nodes {
+		if len(node) < 8 || node[0] != nodeID {
+			continue
+		}
+		for _, token := range node[8:] {
+			if strings.HasPrefix(token, "[") {
+				// [slot->-target] is a slot being migrated, [slot-<-source] one being imported
+				if slot, _, found := strings.Cut(strings.Trim(token, "[]"), "->-"); found {
+					if n, err := strconv.Atoi(slot); err == nil {
+						migrating = append(migrating, n)
+					}
+				}
+				continue
+			}
+			first, last, isRange := strings.Cut(token, "-")
+			start, err := strconv.Atoi(first)
+			if err != nil {
+				continue
+			}
+			end := start
+			if isRange {
+				if end, err = strconv.Atoi(last); err != nil {
+					continue
+				}
+			}
+			for slot := start; slot <= end; slot++ {
+				owned = append(owned, slot)
+			}
+		}
+	}
+	sort.Ints(migrating)
+	slots := migrating
+	for _, slot := range owned {
+		if !containsSlot(migrating, slot) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+func containsSlot(slots []int, slot int) bool {
+	i := sort.SearchInts(slots, slot)
+	return i < len(slots) && slots[i] == slot
+}
+
+// ReshardRedisCluster moves the slots of the shard shardIdx to the shard transferNodeIdx with
+// CLUSTER SETSLOT and MIGRATE, at most reshardSlotBatch of them per call. The slots left are read
+// from the cluster and a slot left migrating is finished first, so the move resumes where an
+// interrupted call stopped. It returns the progress, carried over from the given one while it
+// tracks the same shard, whether the shard serves no slot anymore and the number of keys moved
+// with REPLACE.
+//
+// NOTE: when all slot been transferred, the node become slave of the transfer node.
+func ReshardRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32, transferNodeIdx int32, progress *rcvb2.ReshardingStatus) (*rcvb2.ReshardingStatus, bool, int, error) {
+	sourcePod := RedisDetails{
+		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(shardIdx)),
+		Namespace: cr.Namespace,
+	}
+	targetPod := RedisDetails{
+		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(transferNodeIdx)),
+		Namespace: cr.Namespace,
+	}
+	sourceID := getRedisNodeID(ctx, client, cr, sourcePod)
+	targetID := getRedisNodeID(ctx, client, cr, targetPod)
+	if sourceID == "" || targetID == "" {
+		return progress, false, 0, fmt.Errorf("failed to get the node IDs of %s and %s", sourcePod.PodName, targetPod.PodName)
+	}
+	m, err := newSlotMigration(ctx, client, cr, sourcePod, targetPod, sourceID, targetID)
+	if err != nil {
+		return progress, false, 0, err
+	}
+	defer m.close()
+
+	nodes, err := clusterNodes(ctx, m.source)
+	if err != nil {
+		return progress, false, 0, err
+	}
+	progress, done, replaced, err := reshardSlots(ctx, m, sourceSlots(nodes, sourceID), progress, sourcePod.PodName, targetPod.PodName)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to move the slots of the shard", "Shard.Index", shardIdx, "Slot", progress.CurrentSlot)
+		return progress, false, replaced, err
+	}
+	log.FromContext(ctx).Info("Moved the slots of the shard", "Shard.Index", shardIdx, "Transfer.Index", transferNodeIdx, "Moved", progress.SlotsMoved, "Total", progress.SlotsTotal)
+	return progress, done, replaced, nil
+}
+
+// reshardSlots moves the first reshardSlotBatch slots and returns the updated progress and the
+// number of keys moved with REPLACE
+func reshardSlots(ctx context.Context, m slotMigration, slots []int, progress *rcvb2.ReshardingStatus, sourcePod, targetPod string) (*rcvb2.ReshardingStatus, bool, int, error) {
+	if progress == nil || progress.SourcePod != sourcePod || progress.CompletionTime != nil {
+		progress = &rcvb2.ReshardingStatus{
+			SourcePod:  sourcePod,
+			TargetPod:  targetPod,
+			SlotsTotal: int32(len(slots)),
+			StartTime:  ptr.To(metav1.Now()),
+		}
+	} else {
+		progress = progress.DeepCopy()
+		progress.TargetPod = targetPod
+	}
+	batch := slots[:min(len(slots), reshardSlotBatch)]
+	replaced := 0
+	for i, slot := range batch {
+		progress.CurrentSlot = ptr.To(int32(slot))
+		n, err := m.migrateSlot(ctx, slot)
+		replaced += n
+		if err != nil {
+			progress.SlotsMoved = max(progress.SlotsTotal-int32(len(slots)-i), 0)
+			return progress, false, replaced, err
+		}
+	}
+	progress.CurrentSlot = nil
+	progress.SlotsMoved = max(progress.SlotsTotal-int32(len(slots)-len(batch)), 0)
+	return progress, len(batch) == len(slots), replaced, nil
+}
@@ -0,0 +1,203 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSourceSlots(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []clusterNodesResponse
+		expected []int
+	}{
+		{
+			name: "ranges and single slots",
+			nodes: []clusterNodesResponse{
+				{"source", "10.0.0.1:6379@16379", "myself,master", "-", "0", "0", "1", "connected", "0-2", "7"},
+				{"target", "10.0.0.2:6379@16379", "master", "-", "0", "0", "2", "connected", "3-6"},
+			},
+			expected: []int{0, 1, 2, 7},
+		},
+		{
+			name: "migrating slots come first",
+			nodes: []clusterNodesResponse{
+				{"source", "10.0.0.1:6379@16379", "myself,master", "-", "0", "0", "1", "connected", "3-5", "[4->-target]", "[9-<-other]"},
+			},
+			expected: []int{4, 3, 5},
+		},
+		{
+			name: "replica serves no slot",
+			nodes: []clusterNodesResponse{
+				{"source", "10.0.0.1:6379@16379", "myself,slave", "target", "0", "0", "1", "connected"},
+			},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sourceSlots(tt.nodes, "source"))
+		})
+	}
+}
+
+func newTestSlotMigration() (slotMigration, redismock.ClientMock, redismock.ClientMock) {
+	source, sourceMock := redismock.NewClientMock()
+	target, targetMock := redismock.NewClientMock()
+	return slotMigration{
+		source:   source,
+		target:   target,
+		sourceID: "source",
+		targetID: "target",
+		host:     "10.0.0.2",
+		port:     "6379",
+		password: "secret",
+	}, sourceMock, targetMock
+}
+
+// newTestSlotMigrationWithMaster returns a migration in a cluster with a third master
+func newTestSlotMigrationWithMaster() (slotMigration, redismock.ClientMock, redismock.ClientMock, redismock.ClientMock) {
+	m, sourceMock, targetMock := newTestSlotMigration()
+	master, masterMock := redismock.NewClientMock()
+	m.masters = map[string]*redis.Client{"cluster-leader-1": master}
+	return m, sourceMock, targetMock, masterMock
+}
+
+func expectSlotMigration(sourceMock, targetMock redismock.ClientMock, slot int, keys ...string) {
+	targetMock.ExpectDo("cluster", "setslot", slot, "importing", "source").SetVal("OK")
+	sourceMock.ExpectDo("cluster", "setslot", slot, "migrating", "target").SetVal("OK")
+	if len(keys) > 0 {
+		sourceMock.ExpectClusterGetKeysInSlot(slot, migrateKeyBatch).SetVal(keys)
+		args := []interface{}{"migrate", "10.0.0.2", "6379", "", 0, migrateTimeout.Milliseconds(), "auth", "secret", "keys"}
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		sourceMock.ExpectDo(args...).SetVal("OK")
+	}
+	sourceMock.ExpectClusterGetKeysInSlot(slot, migrateKeyBatch).SetVal([]string{})
+	targetMock.ExpectDo("cluster", "setslot", slot, "node", "target").SetVal("OK")
+	sourceMock.ExpectDo("cluster", "setslot", slot, "node", "target").SetVal("OK")
+}
+
+func TestMigrateSlot(t *testing.T) {
+	m, sourceMock, targetMock, masterMock := newTestSlotMigrationWithMaster()
+	expectSlotMigration(sourceMock, targetMock, 42, "a", "b")
+	// every master is told the new owner of the slot
+	masterMock.ExpectDo("cluster", "setslot", 42, "node", "target").SetVal("OK")
+
+	replaced, err := m.migrateSlot(context.TODO(), 42)
+	require.NoError(t, err)
+	assert.Zero(t, replaced)
+	assert.NoError(t, sourceMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+}
+
+func TestMigrateSlotReplacesBusyKeys(t *testing.T) {
+	m, sourceMock, targetMock, masterMock := newTestSlotMigrationWithMaster()
+	targetMock.ExpectDo("cluster", "setslot", 42, "importing", "source").SetVal("OK")
+	sourceMock.ExpectDo("cluster", "setslot", 42, "migrating", "target").SetVal("OK")
+	sourceMock.ExpectClusterGetKeysInSlot(42, migrateKeyBatch).SetVal([]string{"a"})
+	sourceMock.ExpectDo("migrate", "10.0.0.2", "6379", "", 0, migrateTimeout.Milliseconds(), "auth", "secret", "keys", "a").
+		SetErr(errors.New("BUSYKEY Target key name already exists."))
+	sourceMock.ExpectDo("migrate", "10.0.0.2", "6379", "", 0, migrateTimeout.Milliseconds(), "replace", "auth", "secret", "keys", "a").SetVal("OK")
+	sourceMock.ExpectClusterGetKeysInSlot(42, migrateKeyBatch).SetVal([]string{})
+	targetMock.ExpectDo("cluster", "setslot", 42, "node", "target").SetVal("OK")
+	sourceMock.ExpectDo("cluster", "setslot", 42, "node", "target").SetVal("OK")
+	// a master that cannot be told learns the owner by gossip
+	masterMock.ExpectDo("cluster", "setslot", 42, "node", "target").SetErr(errors.New("connection refused"))
+
+	replaced, err := m.migrateSlot(context.TODO(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, 1, replaced)
+	assert.NoError(t, sourceMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+}
+
+func TestMigrateSlotMigrateFails(t *testing.T) {
+	m, sourceMock, targetMock := newTestSlotMigration()
+	targetMock.ExpectDo("cluster", "setslot", 42, "importing", "source").SetVal("OK")
+	sourceMock.ExpectDo("cluster", "setslot", 42, "migrating", "target").SetVal("OK")
+	sourceMock.ExpectClusterGetKeysInSlot(42, migrateKeyBatch).SetVal([]string{"a"})
+	sourceMock.ExpectDo("migrate", "10.0.0.2", "6379", "", 0, migrateTimeout.Milliseconds(), "auth", "secret", "keys", "a").
+		SetErr(errors.New("IOERR error or timeout"))
+
+	_, err := m.migrateSlot(context.TODO(), 42)
+	assert.ErrorContains(t, err, "failed to migrate the keys of slot 42")
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+}
+
+func TestReshardSlots(t *testing.T) {
+	t.Run("starts the progress of a new source", func(t *testing.T) {
+		m, sourceMock, targetMock := newTestSlotMigration()
+		expectSlotMigration(sourceMock, targetMock, 1)
+		expectSlotMigration(sourceMock, targetMock, 2)
+		previous := &rcvb2.ReshardingStatus{SourcePod: "cluster-leader-4", SlotsTotal: 10, SlotsMoved: 10}
+
+		progress, done, replaced, err := reshardSlots(context.TODO(), m, []int{1, 2}, previous, "cluster-leader-3", "cluster-leader-0")
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Zero(t, replaced)
+		assert.Equal(t, "cluster-leader-3", progress.SourcePod)
+		assert.Equal(t, "cluster-leader-0", progress.TargetPod)
+		assert.Equal(t, int32(2), progress.SlotsTotal)
+		assert.Equal(t, int32(2), progress.SlotsMoved)
+		assert.Nil(t, progress.CurrentSlot)
+		assert.NotNil(t, progress.StartTime)
+	})
+
+	t.Run("resumes the progress of the same source", func(t *testing.T) {
+		m, sourceMock, targetMock := newTestSlotMigration()
+		expectSlotMigration(sourceMock, targetMock, 7, "k")
+		start := metav1.Now()
+		previous := &rcvb2.ReshardingStatus{
+			SourcePod:   "cluster-leader-3",
+			TargetPod:   "cluster-leader-0",
+			SlotsTotal:  10,
+			SlotsMoved:  8,
+			CurrentSlot: ptr.To(int32(7)),
+			StartTime:   &start,
+		}
+
+		progress, done, replaced, err := reshardSlots(context.TODO(), m, []int{7, 9}, previous, "cluster-leader-3", "cluster-leader-0")
+		require.Error(t, err)
+		assert.False(t, done)
+		assert.Zero(t, replaced)
+		// the slot 9 failed, the slot 7 was moved
+		assert.Equal(t, int32(9), *progress.CurrentSlot)
+		assert.Equal(t, int32(9), progress.SlotsMoved)
+		assert.Equal(t, &start, progress.StartTime)
+		// the given progress is left untouched
+		assert.Equal(t, int32(8), previous.SlotsMoved)
+	})
+
+	t.Run("moves a batch of slots per call", func(t *testing.T) {
+		m, sourceMock, targetMock := newTestSlotMigration()
+		slots := make([]int, reshardSlotBatch+5)
+		for i := range slots {
+			slots[i] = i
+			if i < reshardSlotBatch {
+				expectSlotMigration(sourceMock, targetMock, i)
+			}
+		}
+
+		progress, done, replaced, err := reshardSlots(context.TODO(), m, slots, nil, "cluster-leader-3", "cluster-leader-0")
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.Zero(t, replaced)
+		assert.Equal(t, int32(reshardSlotBatch+5), progress.SlotsTotal)
+		assert.Equal(t, int32(reshardSlotBatch), progress.SlotsMoved)
+		assert.NoError(t, sourceMock.ExpectationsWereMet())
+		assert.NoError(t, targetMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,170 @@
+package k8sutils
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PlanRedisClusterScaling returns the steps the reconciler takes to bring the cluster from the
+// given number of leaders and followers to the ones of the spec, without running any of them
+func PlanRedisClusterScaling(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, currentLeaders, currentFollowers int32) (*rcvb2.ScalingPlan, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	podsByIP := map[string]string{}
+	for role, count := range map[string]int32{
+		"leader":   max(currentLeaders, cr.Spec.GetReplicaCounts("leader")),
+		"follower": max(currentFollowers, cr.Spec.GetReplicaCounts("follower")),
+	} {
+		for i := 0; i < int(count); i++ {
+			podName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				log.FromContext(ctx).V(1).Info("Skipping pod of the cluster", "pod", podName, "error", err.Error())
+				continue
+			}
+			if pod.Status.PodIP != "" {
+				podsByIP[pod.Status.PodIP] = podName
+			}
+		}
+	}
+	plan := planScaling(cr, nodes, podsByIP, currentLeaders, currentFollowers)
+	plan.Generation = cr.Generation
+	plan.ComputedTime = ptr.To(metav1.Now())
+	return plan, nil
+}
+
+// planScaling mirrors the scale down and the scale up of the reconciler: a scale down makes the
+// remaining leaders and then the removed ones masters, removes the followers of every removed
+// shard, moves its slots round-robin to the remaining leaders and removes it before the pods are
+// deleted. A scale up creates the leaders and adds them to the cluster. Both end with a rebalance
+// for the even and weighted slot distributions.
+func planScaling(cr *rcvb2.RedisCluster, nodes []clusterNodesResponse, podsByIP map[string]string, currentLeaders, currentFollowers int32) *rcvb2.ScalingPlan {
+	desiredLeaders := cr.Spec.GetReplicaCounts("leader")
+	desiredFollowers := cr.Spec.GetReplicaCounts("follower")
+	plan := &rcvb2.ScalingPlan{
+		CurrentLeaders:   currentLeaders,
+		DesiredLeaders:   desiredLeaders,
+		CurrentFollowers: currentFollowers,
+		DesiredFollowers: desiredFollowers,
+	}
+	leaderPod := func(i int32) string { return cr.Name + "-leader-" + strconv.Itoa(int(i)) }
+	followerPod := func(i int32) string { return cr.Name + "-follower-" + strconv.Itoa(int(i)) }
+
+	podNames := map[string]string{}
+	nodesByPod := map[string]clusterNodesResponse{}
+	for _, node := range nodes {
+		if len(node) < 8 || nodeFailedOrDisconnected(node) {
+			continue
+		}
+		podName := clusterNodePodName(node, podsByIP)
+		podNames[node[0]] = podName
+		nodesByPod[podName] = node
+	}
+	// shardMaster returns the node ID of the master of the shard of a leader pod
+	shardMaster := func(podName string) (string, bool) {
+		node, found := nodesByPod[podName]
+		if !found {
+			return "", false
+		}
+		if nodeIsOfType(node, "master") {
+			return node[0], false
+		}
+		return node[3], true
+	}
+	addStep := func(step rcvb2.ScalingPlanStep) {
+		plan.Steps = append(plan.Steps, step)
+		plan.SlotsToMove += step.Slots
+	}
+
+	slots := make([]int, max(currentLeaders, desiredLeaders))
+	for i := int32(0); i < currentLeaders; i++ {
+		if masterID, _ := shardMaster(leaderPod(i)); masterID != "" {
+			slots[i] = len(sourceSlots(nodes, masterID))
+		}
+	}
+
+	if desiredLeaders < currentLeaders {
+		// the leaders the slots are moved to are made masters first
+		for i := int32(0); i < desiredLeaders; i++ {
+			if _, failover := shardMaster(leaderPod(i)); failover {
+				addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanFailover, Pod: leaderPod(i)})
+			}
+		}
+		for shardIdx := currentLeaders - 1; shardIdx >= desiredLeaders; shardIdx-- {
+			pod := leaderPod(shardIdx)
+			masterID, failover := shardMaster(pod)
+			if failover {
+				addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanFailover, Pod: pod})
+			}
+			// after the failover the previous master replicates the leader pod as well
+			var followers []string
+			for id, podName := range podNames {
+				node := nodesByPod[podName]
+				if podName != pod && masterID != "" && (node[3] == masterID || (failover && id == masterID)) {
+					followers = append(followers, podName)
+				}
+			}
+			sort.Strings(followers)
+			for _, follower := range followers {
+				addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanRemoveNode, Pod: follower})
+			}
+			target := shardIdx % desiredLeaders
+			addStep(rcvb2.ScalingPlanStep{
+				Action:    rcvb2.ScalingPlanMoveSlots,
+				Pod:       pod,
+				TargetPod: leaderPod(target),
+				Slots:     int32(slots[shardIdx]),
+			})
+			slots[target] += slots[shardIdx]
+			slots[shardIdx] = 0
+			addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanRemoveNode, Pod: pod})
+		}
+		for i := currentLeaders - 1; i >= desiredLeaders; i-- {
+			addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanDeletePod, Pod: leaderPod(i)})
+		}
+	}
+	if desiredLeaders > currentLeaders {
+		for i := currentLeaders; i < desiredLeaders; i++ {
+			addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanCreatePod, Pod: leaderPod(i)})
+			addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanAddNode, Pod: leaderPod(i)})
+		}
+	}
+	// a single remaining leader serves every slot already
+	if desiredLeaders != currentLeaders && desiredLeaders > 1 && cr.Spec.GetSlotDistributionStrategy() != rcvb2.SlotDistributionPinned {
+		shards := make([]weightedShard, desiredLeaders)
+		for i := range shards {
+			weight := int32(1)
+			if cr.Spec.GetSlotDistributionStrategy() == rcvb2.SlotDistributionWeighted {
+				weight = cr.Spec.GetShardWeight(int32(i))
+			}
+			shards[i] = weightedShard{Shard: i, Weight: weight, Slots: make([]int, slots[i])}
+		}
+		var moved int
+		for _, move := range planSlotMoves(shards) {
+			moved += move.Slots
+		}
+		if moved > 0 {
+			addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanRebalance, Slots: int32(moved)})
+		}
+	}
+
+	for i := currentFollowers - 1; i >= desiredFollowers; i-- {
+		addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanDeletePod, Pod: followerPod(i)})
+	}
+	for i := currentFollowers; i < desiredFollowers; i++ {
+		addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanCreatePod, Pod: followerPod(i)})
+		addStep(rcvb2.ScalingPlanStep{Action: rcvb2.ScalingPlanAddNode, Pod: followerPod(i)})
+	}
+	return plan
+}
@@ -0,0 +1,74 @@
+package k8sutils
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestPlanScaling(t *testing.T) {
+	nodes := []clusterNodesResponse{
+		{"l0", "10.0.0.1:6379@16379,redis-cluster-leader-0.redis-cluster-leader-headless", "myself,master", "-", "0", "0", "1", "connected", "0-5460"},
+		{"l1", "10.0.0.2:6379@16379,redis-cluster-leader-1.redis-cluster-leader-headless", "master", "-", "0", "0", "2", "connected", "5461-10922"},
+		// the follower took over the shard 2 after a failover
+		{"f2", "10.0.0.6:6379@16379,redis-cluster-follower-2.redis-cluster-follower-headless", "master", "-", "0", "0", "5", "connected", "10923-16383"},
+		{"l2", "10.0.0.3:6379@16379,redis-cluster-leader-2.redis-cluster-leader-headless", "slave", "f2", "0", "0", "5", "connected"},
+		{"f0", "10.0.0.4:6379@16379,redis-cluster-follower-0.redis-cluster-follower-headless", "slave", "l0", "0", "0", "1", "connected"},
+		{"f1", "10.0.0.5:6379@16379,redis-cluster-follower-1.redis-cluster-follower-headless", "slave", "l1", "0", "0", "2", "connected"},
+	}
+
+	t.Run("scale down", func(t *testing.T) {
+		cr := &rcvb2.RedisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"},
+			Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(2))},
+		}
+		plan := planScaling(cr, nodes, nil, 3, 3)
+		assert.Equal(t, []rcvb2.ScalingPlanStep{
+			{Action: rcvb2.ScalingPlanFailover, Pod: "redis-cluster-leader-2"},
+			{Action: rcvb2.ScalingPlanRemoveNode, Pod: "redis-cluster-follower-2"},
+			{Action: rcvb2.ScalingPlanMoveSlots, Pod: "redis-cluster-leader-2", TargetPod: "redis-cluster-leader-0", Slots: 5461},
+			{Action: rcvb2.ScalingPlanRemoveNode, Pod: "redis-cluster-leader-2"},
+			{Action: rcvb2.ScalingPlanDeletePod, Pod: "redis-cluster-leader-2"},
+			{Action: rcvb2.ScalingPlanRebalance, Slots: 2730},
+			{Action: rcvb2.ScalingPlanDeletePod, Pod: "redis-cluster-follower-2"},
+		}, plan.Steps)
+		assert.Equal(t, int32(5461+2730), plan.SlotsToMove)
+		assert.Equal(t, int32(3), plan.CurrentLeaders)
+		assert.Equal(t, int32(2), plan.DesiredLeaders)
+	})
+
+	t.Run("scale up", func(t *testing.T) {
+		cr := &rcvb2.RedisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"},
+			Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(4))},
+		}
+		plan := planScaling(cr, nodes, nil, 3, 3)
+		assert.Equal(t, []rcvb2.ScalingPlanStep{
+			{Action: rcvb2.ScalingPlanCreatePod, Pod: "redis-cluster-leader-3"},
+			{Action: rcvb2.ScalingPlanAddNode, Pod: "redis-cluster-leader-3"},
+			{Action: rcvb2.ScalingPlanRebalance, Slots: 4096},
+			{Action: rcvb2.ScalingPlanCreatePod, Pod: "redis-cluster-follower-3"},
+			{Action: rcvb2.ScalingPlanAddNode, Pod: "redis-cluster-follower-3"},
+		}, plan.Steps)
+		assert.Equal(t, int32(4096), plan.SlotsToMove)
+	})
+
+	t.Run("pinned slots are not rebalanced", func(t *testing.T) {
+		cr := &rcvb2.RedisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"},
+			Spec: rcvb2.RedisClusterSpec{
+				ClusterSize:      ptr.To(int32(4)),
+				SlotDistribution: &rcvb2.SlotDistribution{Strategy: rcvb2.SlotDistributionPinned},
+			},
+		}
+		plan := planScaling(cr, nodes, nil, 3, 4)
+		assert.Equal(t, []rcvb2.ScalingPlanStep{
+			{Action: rcvb2.ScalingPlanCreatePod, Pod: "redis-cluster-leader-3"},
+			{Action: rcvb2.ScalingPlanAddNode, Pod: "redis-cluster-leader-3"},
+		}, plan.Steps)
+		assert.Zero(t, plan.SlotsToMove)
+	})
+}
@@ -227,72 +227,6 @@ func waitForClusterNoOpenSlots(ctx context.Context, client kubernetes.Interface,
 	return fmt.Errorf("cluster still has open slots or is not converged after %s", timeout)
 }
 
-// ReshardRedisCluster transfer the slots from the last node to the provided transfer node.
-//
-// NOTE: when all slot been transferred, the node become slave of the transfer node.
-func ReshardRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32, transferNodeIdx int32, remove bool) {
-	transferNodeName := fmt.Sprintf("%s-leader-%d", cr.Name, transferNodeIdx)
-	redisClient := configureRedisClient(ctx, client, cr, transferNodeName)
-	defer redisClient.Close()
-
-	var cmd []string
-
-	// Transfer Pod details
-	transferPOD := RedisDetails{
-		PodName:   transferNodeName,
-		Namespace: cr.Namespace,
-	}
-	// Remove POD details
-	removePOD := RedisDetails{
-		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(shardIdx)),
-		Namespace: cr.Namespace,
-	}
-	cmd = []string{"redis-cli", "--cluster", "reshard"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, transferPOD))
-	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
-		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
-		if err != nil {
-			log.FromContext(ctx).Error(err, "error in getting redis password")
-			return
-		}
-		cmd = append(cmd, "-a")
-		cmd = append(cmd, pass)
-	}
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, transferNodeName)...)
-
-	//--cluster-from <node-id> --cluster-to <node-id> --cluster-slots <number of slots> --cluster-yes
-
-	// Remove Node
-	removeNodeID := getRedisNodeID(ctx, client, cr, removePOD)
-	cmd = append(cmd, "--cluster-from")
-	cmd = append(cmd, removeNodeID)
-
-	// Transfer Node
-	transferNodeID := getRedisNodeID(ctx, client, cr, transferPOD)
-	cmd = append(cmd, "--cluster-to")
-	cmd = append(cmd, transferNodeID)
-
-	// Cluster Slots
-	slots := getRedisClusterSlots(ctx, redisClient, removeNodeID)
-	if slots == "0" || slots == "" {
-		log.FromContext(ctx).Info("skipping the execution cmd because no slots found", "Cmd", cmd)
-		return
-	}
-	cmd = append(cmd, "--cluster-slots")
-	cmd = append(cmd, slots)
-
-	cmd = append(cmd, "--cluster-yes")
-
-	log.FromContext(ctx).Info(fmt.Sprintf("transferring %s slots from shard %d to shard %d", slots, shardIdx, transferNodeIdx))
-	executeCommand(ctx, client, cr, cmd, transferNodeName)
-	log.FromContext(ctx).Info(fmt.Sprintf("transferring %s slots from shard %d to shard %d completed", slots, shardIdx, transferNodeIdx))
-
-	if remove {
-		RemoveRedisNodeFromCluster(ctx, client, cr, removePOD)
-	}
-}
-
 func getRedisClusterSlots(ctx context.Context, redisClient *redis.Client, nodeID string) string {
 	totalSlots := 0
 
@@ -349,7 +283,7 @@ func FixRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2
 		PodName:   cr.Name + "-leader-0",
 		Namespace: cr.Namespace,
 	}
-	cmd := []string{"redis-cli", "--cluster", "fix"}
+	cmd := []string{cr.Spec.Engine.CLI(), "--cluster", "fix"}
 	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
 		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
@@ -379,7 +313,7 @@ func RebalanceRedisClusterEmptyMasters(ctx context.Context, client kubernetes.In
 		PodName:   cr.Name + "-leader-1",
 		Namespace: cr.Namespace,
 	}
-	cmd = []string{"redis-cli", "--cluster", "rebalance"}
+	cmd = []string{cr.Spec.Engine.CLI(), "--cluster", "rebalance"}
 	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
 	cmd = append(cmd, "--cluster-use-empty-masters")
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
@@ -425,7 +359,7 @@ func RebalanceRedisCluster(ctx context.Context, client kubernetes.Interface, cr
 		PodName:   cr.Name + "-leader-1",
 		Namespace: cr.Namespace,
 	}
-	cmd = []string{"redis-cli", "--cluster", "rebalance"}
+	cmd = []string{cr.Spec.Engine.CLI(), "--cluster", "rebalance"}
 	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
 		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
@@ -458,7 +392,7 @@ func waitForNodePresence(ctx context.Context, client kubernetes.Interface, cr *r
 
 // Add redis cluster node would add a node to the existing redis cluster using redis-cli
 func AddRedisNodeToCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	cmd := []string{"redis-cli", "--cluster", "add-node"}
+	cmd := []string{cr.Spec.Engine.CLI(), "--cluster", "add-node"}
 	activeRedisNode := CheckRedisNodeCount(ctx, client, cr, "leader")
 	newPod := RedisDetails{
 		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(activeRedisNode)),
@@ -520,7 +454,7 @@ func RemoveRedisFollowerNodesFromCluster(ctx context.Context, client kubernetes.
 		Namespace: cr.Namespace,
 	}
 
-	cmd = []string{"redis-cli"}
+	cmd = []string{cr.Spec.Engine.CLI()}
 
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
 		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
@@ -552,7 +486,7 @@ func RemoveRedisNodeFromCluster(ctx context.Context, client kubernetes.Interface
 		PodName:   cr.Name + "-leader-0",
 		Namespace: cr.Namespace,
 	}
-	cmd := []string{"redis-cli", "--cluster", "del-node"}
+	cmd := []string{cr.Spec.Engine.CLI(), "--cluster", "del-node"}
 	cmd = append(cmd, getEndpoint(ctx, client, cr, existingPod))
 	cmd = append(cmd, getRedisNodeID(ctx, client, cr, removePod))
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
@@ -608,7 +542,7 @@ func ClusterFailover(ctx context.Context, client kubernetes.Interface, cr *rcvb2
 		return fmt.Errorf("invalid endpoint format: %s", endpoint)
 	}
 	host, port := endpoint[:lastColon], endpoint[lastColon+1:]
-	cmd = []string{"redis-cli", "-h", host, "-p", port}
+	cmd = []string{cr.Spec.Engine.CLI(), "-h", host, "-p", port}
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
 		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
 		if err != nil {
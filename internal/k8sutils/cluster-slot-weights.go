@@ -0,0 +1,258 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrClusterSlotsOpen is returned while a slot of the cluster is migrating or importing, no slot
+// is moved until it is finished
+var ErrClusterSlotsOpen = errors.New("the cluster has open slots")
+
+// SlotMove is a move of slots from a master to another
+type SlotMove struct {
+	SourcePod string
+	TargetPod string
+	Slots     int
+	// ReplacedKeys is the number of keys moved with REPLACE, overwriting the keys of the same name
+	// on the target
+	ReplacedKeys int
+}
+
+// weightedShard is a master of the cluster with the weight of its shard and the slots it serves
+type weightedShard struct {
+	Shard   int
+	PodName string
+	NodeID  string
+	Weight  int32
+	Slots   []int
+}
+
+// slotMove moves Slots slots from the shard at index From to the shard at index To
+type slotMove struct {
+	From  int
+	To    int
+	Slots int
+}
+
+// weightedShards returns the healthy masters ordered by shard. A master running on a follower pod
+// after a failover belongs to the shard of the leader pod replicating it, a master whose shard
+// cannot be told fails the call.
+func weightedShards(cr *rcvb2.RedisCluster, nodes []clusterNodesResponse, podsByIP map[string]string) ([]weightedShard, error) {
+	leaderPrefix := cr.Name + "-leader-"
+	podNames := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if len(node) >= 8 {
+			podNames[node[0]] = clusterNodePodName(node, podsByIP)
+		}
+	}
+	var shards []weightedShard
+	for _, node := range nodes {
+		if len(node) < 8 || !nodeIsOfType(node, "master") || nodeFailedOrDisconnected(node) {
+			continue
+		}
+		shardPod := podNames[node[0]]
+		if !strings.HasPrefix(shardPod, leaderPrefix) {
+			for _, replica := range nodes {
+				if len(replica) >= 8 && nodeIsOfType(replica, "slave") && replica[3] == node[0] && strings.HasPrefix(podNames[replica[0]], leaderPrefix) {
+					shardPod = podNames[replica[0]]
+					break
+				}
+			}
+		}
+		shard, err := strconv.Atoi(strings.TrimPrefix(shardPod, leaderPrefix))
+		if err != nil || !strings.HasPrefix(shardPod, leaderPrefix) {
+			return nil, fmt.Errorf("failed to tell the shard of the master %s", node[0])
+		}
+		shards = append(shards, weightedShard{
+			Shard:   shard,
+			PodName: podNames[node[0]],
+			NodeID:  node[0],
+			Weight:  cr.Spec.GetShardWeight(int32(shard)),
+			Slots:   sourceSlots(nodes, node[0]),
+		})
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i].Shard < shards[j].Shard })
+	return shards, nil
+}
+
+// slotTargets returns the number of slots every shard should serve, in proportion to its weight.
+// The slots left over by the rounding go to the shards with the largest remainders.
+func slotTargets(shards []weightedShard) []int {
+	var total, weights int64
+	for _, shard := range shards {
+		total += int64(len(shard.Slots))
+		weights += int64(shard.Weight)
+	}
+	if weights == 0 {
+		return nil
+	}
+	targets := make([]int, len(shards))
+	remainders := make([]int64, len(shards))
+	order := make([]int, len(shards))
+	var assigned int64
+	for i, shard := range shards {
+		share := total * int64(shard.Weight)
+		targets[i] = int(share / weights)
+		remainders[i] = share % weights
+		order[i] = i
+		assigned += int64(targets[i])
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < total; i++ {
+		targets[order[i]]++
+		assigned++
+	}
+	return targets
+}
+
+// planSlotMoves returns the moves bringing the shards to the number of slots of their weight, from
+// the shards serving too many slots to the ones serving too few
+func planSlotMoves(shards []weightedShard) []slotMove {
+	targets := slotTargets(shards)
+	if targets == nil {
+		return nil
+	}
+	have := make([]int, len(shards))
+	for i, shard := range shards {
+		have[i] = len(shard.Slots)
+	}
+	var moves []slotMove
+	from, to := 0, 0
+	for {
+		for from < len(shards) && have[from] <= targets[from] {
+			from++
+		}
+		for to < len(shards) && have[to] >= targets[to] {
+			to++
+		}
+		if from == len(shards) || to == len(shards) {
+			return moves
+		}
+		count := min(have[from]-targets[from], targets[to]-have[to])
+		moves = append(moves, slotMove{From: from, To: to, Slots: count})
+		have[from] -= count
+		have[to] += count
+	}
+}
+
+// planPinnedSlotMove returns the slots pinned to another shard than the one serving them, the
+// ones of the first source and target shards found, by the index of the shards. Pins of shards
+// without a master are left out.
+func planPinnedSlotMove(shards []weightedShard, pinned map[int]int32) (int, int, []int) {
+	index := make(map[int32]int, len(shards))
+	for i, shard := range shards {
+		index[int32(shard.Shard)] = i
+	}
+	from, to := -1, -1
+	var slots []int
+	for i, shard := range shards {
+		for _, slot := range shard.Slots {
+			pin, found := pinned[slot]
+			j, hasMaster := index[pin]
+			if !found || !hasMaster || j == i || (from != -1 && (from != i || to != j)) {
+				continue
+			}
+			from, to = i, j
+			slots = append(slots, slot)
+		}
+		if from != -1 {
+			return from, to, slots
+		}
+	}
+	return from, to, nil
+}
+
+// BalanceRedisClusterSlotWeights moves slots between the masters until every shard serves a
+// number of slots in proportion to its weight. It moves at most reshardSlotBatch slots per call
+// and returns the move it performed, nil when the slots already match the weights, or
+// ErrClusterSlotsOpen while a slot is open.
+func BalanceRedisClusterSlotWeights(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (*SlotMove, error) {
+	return moveRedisClusterSlots(ctx, client, cr, func(shards []weightedShard) (int, int, []int) {
+		moves := planSlotMoves(shards)
+		if len(moves) == 0 {
+			return 0, 0, nil
+		}
+		// the highest slots are moved so that the ranges of the source stay contiguous
+		source := shards[moves[0].From]
+		return moves[0].From, moves[0].To, source.Slots[len(source.Slots)-min(moves[0].Slots, reshardSlotBatch):]
+	})
+}
+
+// PinRedisClusterSlots moves the slots of spec.slotDistribution.pins onto their shard, the pins of
+// the shards removed by a scale down are left out. It moves at most reshardSlotBatch slots per
+// call and returns the move it performed, nil when every pinned slot is served by its shard, or
+// ErrClusterSlotsOpen while a slot is open.
+func PinRedisClusterSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (*SlotMove, error) {
+	pinned := cr.Spec.GetPinnedSlots()
+	maps.DeleteFunc(pinned, func(_ int, shard int32) bool {
+		return shard >= cr.Spec.GetReplicaCounts("leader")
+	})
+	if len(pinned) == 0 {
+		return nil, nil
+	}
+	return moveRedisClusterSlots(ctx, client, cr, func(shards []weightedShard) (int, int, []int) {
+		return planPinnedSlotMove(shards, pinned)
+	})
+}
+
+// moveRedisClusterSlots moves the slots planned from the masters of the cluster from a shard to
+// another, at most reshardSlotBatch of them
+func moveRedisClusterSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, plan func([]weightedShard) (int, int, []int)) (*SlotMove, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	source, target, slots, err := planClusterSlotMove(cr, nodes, podNamesByIP(getClusterPodPlacements(ctx, client, cr)), plan)
+	if err != nil || len(slots) == 0 {
+		return nil, err
+	}
+	m, err := newSlotMigration(ctx, client, cr,
+		RedisDetails{PodName: source.PodName, Namespace: cr.Namespace},
+		RedisDetails{PodName: target.PodName, Namespace: cr.Namespace},
+		source.NodeID, target.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer m.close()
+
+	move := &SlotMove{SourcePod: source.PodName, TargetPod: target.PodName}
+	for _, slot := range slots {
+		replaced, err := m.migrateSlot(ctx, slot)
+		move.ReplacedKeys += replaced
+		if err != nil {
+			return move, err
+		}
+		move.Slots++
+	}
+	log.FromContext(ctx).Info("Moved slots to match the slot distribution", "Source", move.SourcePod, "Target", move.TargetPod, "Slots", move.Slots)
+	return move, nil
+}
+
+// planClusterSlotMove returns the source and the target shards of the slots planned from the
+// masters of the cluster, at most reshardSlotBatch of them
+func planClusterSlotMove(cr *rcvb2.RedisCluster, nodes []clusterNodesResponse, podsByIP map[string]string, plan func([]weightedShard) (int, int, []int)) (weightedShard, weightedShard, []int, error) {
+	if clusterHasOpenSlots(nodes) {
+		return weightedShard{}, weightedShard{}, nil, ErrClusterSlotsOpen
+	}
+	shards, err := weightedShards(cr, nodes, podsByIP)
+	if err != nil {
+		return weightedShard{}, weightedShard{}, nil, err
+	}
+	from, to, slots := plan(shards)
+	if len(slots) == 0 {
+		return weightedShard{}, weightedShard{}, nil, nil
+	}
+	return shards[from], shards[to], slots[:min(len(slots), reshardSlotBatch)], nil
+}
@@ -0,0 +1,153 @@
+package k8sutils
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func slotRange(start, end int) []int {
+	slots := make([]int, 0, end-start+1)
+	for slot := start; slot <= end; slot++ {
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func TestWeightedShards(t *testing.T) {
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"},
+		Spec: rcvb2.RedisClusterSpec{
+			SlotDistribution: &rcvb2.SlotDistribution{
+				Strategy: rcvb2.SlotDistributionWeighted,
+				Weights:  []rcvb2.ShardWeight{{Shard: 1, Weight: 3}},
+			},
+		},
+	}
+	nodes := []clusterNodesResponse{
+		{"m1", "10.0.0.2:6379@16379,redis-cluster-leader-1.redis-cluster-leader-headless", "master", "-", "0", "0", "2", "connected", "100-199"},
+		{"m0", "10.0.0.1:6379@16379,redis-cluster-leader-0.redis-cluster-leader-headless", "myself,master", "-", "0", "0", "1", "connected", "0-99"},
+		// the follower took over the shard 2 after a failover
+		{"m2", "10.0.0.6:6379@16379,redis-cluster-follower-2.redis-cluster-follower-headless", "master", "-", "0", "0", "5", "connected", "200-299"},
+		{"r2", "10.0.0.3:6379@16379,redis-cluster-leader-2.redis-cluster-leader-headless", "slave", "m2", "0", "0", "5", "connected"},
+		{"f", "10.0.0.9:6379@16379,redis-cluster-leader-9.redis-cluster-leader-headless", "master,fail", "-", "0", "0", "9", "disconnected"},
+	}
+
+	shards, err := weightedShards(cr, nodes, nil)
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+	assert.Equal(t, weightedShard{Shard: 0, PodName: "redis-cluster-leader-0", NodeID: "m0", Weight: 1, Slots: slotRange(0, 99)}, shards[0])
+	assert.Equal(t, weightedShard{Shard: 1, PodName: "redis-cluster-leader-1", NodeID: "m1", Weight: 3, Slots: slotRange(100, 199)}, shards[1])
+	assert.Equal(t, weightedShard{Shard: 2, PodName: "redis-cluster-follower-2", NodeID: "m2", Weight: 1, Slots: slotRange(200, 299)}, shards[2])
+
+	// a master no leader pod belongs to
+	_, err = weightedShards(cr, nodes[:3], nil)
+	assert.Error(t, err)
+}
+
+func TestSlotTargets(t *testing.T) {
+	shards := []weightedShard{
+		{Weight: 1, Slots: make([]int, 5462)},
+		{Weight: 1, Slots: make([]int, 5461)},
+		{Weight: 2, Slots: make([]int, 5461)},
+	}
+	assert.Equal(t, []int{4096, 4096, 8192}, slotTargets(shards))
+
+	shards = []weightedShard{
+		{Weight: 1, Slots: make([]int, 10)},
+		{Weight: 1, Slots: make([]int, 0)},
+		{Weight: 1, Slots: make([]int, 0)},
+	}
+	assert.Equal(t, []int{4, 3, 3}, slotTargets(shards))
+
+	assert.Nil(t, slotTargets([]weightedShard{{Weight: 0, Slots: make([]int, 10)}}))
+}
+
+func TestPlanSlotMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		shards   []weightedShard
+		expected []slotMove
+	}{
+		{
+			name: "balanced",
+			shards: []weightedShard{
+				{Weight: 1, Slots: make([]int, 8192)},
+				{Weight: 1, Slots: make([]int, 8192)},
+			},
+		},
+		{
+			name: "heavier shard",
+			shards: []weightedShard{
+				{Weight: 1, Slots: make([]int, 5462)},
+				{Weight: 1, Slots: make([]int, 5461)},
+				{Weight: 2, Slots: make([]int, 5461)},
+			},
+			expected: []slotMove{{From: 0, To: 2, Slots: 1366}, {From: 1, To: 2, Slots: 1365}},
+		},
+		{
+			name: "drained shard",
+			shards: []weightedShard{
+				{Weight: 1, Slots: make([]int, 4)},
+				{Weight: 0, Slots: make([]int, 4)},
+				{Weight: 1, Slots: make([]int, 4)},
+			},
+			expected: []slotMove{{From: 1, To: 0, Slots: 2}, {From: 1, To: 2, Slots: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, planSlotMoves(tt.shards))
+		})
+	}
+}
+
+func TestPlanPinnedSlotMove(t *testing.T) {
+	shards := []weightedShard{
+		{Shard: 0, Slots: slotRange(0, 99)},
+		{Shard: 1, Slots: slotRange(100, 199)},
+		{Shard: 2, Slots: slotRange(200, 299)},
+	}
+
+	// the slots pinned to one shard are moved from one source at a time
+	pinned := map[int]int32{5: 2, 6: 2, 7: 1, 150: 0, 250: 2}
+	from, to, slots := planPinnedSlotMove(shards, pinned)
+	assert.Equal(t, 0, from)
+	assert.Equal(t, 2, to)
+	assert.Equal(t, []int{5, 6}, slots)
+
+	// the slots served by their shard and the pins of shards without a master stay
+	_, _, slots = planPinnedSlotMove(shards, map[int]int32{5: 0, 150: 1, 250: 7})
+	assert.Empty(t, slots)
+
+	from, to, slots = planPinnedSlotMove(shards, map[int]int32{5: 0, 150: 2})
+	assert.Equal(t, 1, from)
+	assert.Equal(t, 2, to)
+	assert.Equal(t, []int{150}, slots)
+}
+
+func TestPlanClusterSlotMove(t *testing.T) {
+	cr := &rcvb2.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"}}
+	nodes := []clusterNodesResponse{
+		{"m0", "10.0.0.1:6379@16379,redis-cluster-leader-0.redis-cluster-leader-headless", "myself,master", "-", "0", "0", "1", "connected", "0-99"},
+		{"m1", "10.0.0.2:6379@16379,redis-cluster-leader-1.redis-cluster-leader-headless", "master", "-", "0", "0", "2", "connected", "100-199"},
+	}
+	pin := func(shards []weightedShard) (int, int, []int) {
+		return planPinnedSlotMove(shards, map[int]int32{5: 1})
+	}
+
+	source, target, slots, err := planClusterSlotMove(cr, nodes, nil, pin)
+	require.NoError(t, err)
+	assert.Equal(t, "redis-cluster-leader-0", source.PodName)
+	assert.Equal(t, "redis-cluster-leader-1", target.PodName)
+	assert.Equal(t, []int{5}, slots)
+
+	// a slot left open is waited for instead of being reported as nothing to move
+	nodes[0] = append(nodes[0], "[7->-m1]")
+	_, _, slots, err = planClusterSlotMove(cr, nodes, nil, pin)
+	assert.ErrorIs(t, err, ErrClusterSlotsOpen)
+	assert.Empty(t, slots)
+}
@@ -0,0 +1,94 @@
+package k8sutils
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterFailureFlags are the CLUSTER NODES flags reported as failures of a node
+var clusterFailureFlags = []string{"fail", "fail?", "handshake", "noaddr"}
+
+// GetRedisClusterTopology returns the nodes of the cluster as seen by leader-0 and the number of
+// hash slots assigned to its masters
+func GetRedisClusterTopology(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]rcvb2.RedisClusterNode, int32, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, 0, err
+	}
+	return clusterTopology(nodes)
+}
+
+func clusterTopology(nodes []clusterNodesResponse) ([]rcvb2.RedisClusterNode, int32, error) {
+	topology := make([]rcvb2.RedisClusterNode, 0, len(nodes))
+	var slotsAssigned int32
+	for _, node := range nodes {
+		if len(node) < 8 {
+			continue
+		}
+		status := rcvb2.RedisClusterNode{
+			NodeID:    node[0],
+			Role:      rcvb2.RedisClusterNodeReplica,
+			LinkState: node[7],
+		}
+		if host, err := getHostFromClusterNode(node); err == nil {
+			status.PodName = strings.Split(host, ".")[0]
+		}
+		if node[3] != "-" {
+			status.MasterID = node[3]
+		}
+		for _, flag := range strings.Split(node[2], ",") {
+			if slices.Contains(clusterFailureFlags, flag) {
+				status.FailureFlags = append(status.FailureFlags, flag)
+			}
+		}
+		if nodeIsOfType(node, "master") {
+			status.Role = rcvb2.RedisClusterNodeMaster
+			for _, slots := range node[8:] {
+				// importing and migrating slots are listed as [slot-<-id] and [slot->-id]
+				if strings.HasPrefix(slots, "[") {
+					continue
+				}
+				count, err := slotRangeSize(slots)
+				if err != nil {
+					return nil, 0, err
+				}
+				status.Slots = append(status.Slots, slots)
+				slotsAssigned += count
+			}
+		}
+		topology = append(topology, status)
+	}
+	// CLUSTER NODES lists the nodes in no particular order
+	sort.Slice(topology, func(i, j int) bool {
+		if topology[i].PodName != topology[j].PodName {
+			return topology[i].PodName < topology[j].PodName
+		}
+		return topology[i].NodeID < topology[j].NodeID
+	})
+	return topology, slotsAssigned, nil
+}
+
+// slotRangeSize returns the number of slots of a range like 0-5460 or of a single slot
+func slotRangeSize(slots string) (int32, error) {
+	first, last, isRange := strings.Cut(slots, "-")
+	start, err := strconv.ParseInt(first, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if !isRange {
+		return 1, nil
+	}
+	end, err := strconv.ParseInt(last, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(end - start + 1), nil
+}
@@ -0,0 +1,33 @@
+package k8sutils
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterTopology(t *testing.T) {
+	nodes := []clusterNodesResponse{
+		{"id-follower-0", "10.0.0.3:6379@16379,cluster-follower-0", "slave,fail?", "id-leader-0", "0", "0", "1", "disconnected"},
+		{"id-leader-1", "10.0.0.2:6379@16379,cluster-leader-1", "master", "-", "0", "0", "2", "connected", "5461-10922", "[10923->-id-leader-0]"},
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "myself,master", "-", "0", "0", "1", "connected", "0-5460", "10923-16383"},
+		{"id-unknown", "10.0.0.9:6379@16379", "master,noaddr", "-", "0", "0", "0", "connected", "16000"},
+	}
+
+	topology, slotsAssigned, err := clusterTopology(nodes)
+	require.NoError(t, err)
+	assert.Equal(t, int32(16385), slotsAssigned)
+	assert.Equal(t, []rcvb2.RedisClusterNode{
+		{NodeID: "id-unknown", Role: "master", LinkState: "connected", Slots: []string{"16000"}, FailureFlags: []string{"noaddr"}},
+		{PodName: "cluster-follower-0", NodeID: "id-follower-0", Role: "replica", MasterID: "id-leader-0", LinkState: "disconnected", FailureFlags: []string{"fail?"}},
+		{PodName: "cluster-leader-0", NodeID: "id-leader-0", Role: "master", LinkState: "connected", Slots: []string{"0-5460", "10923-16383"}},
+		{PodName: "cluster-leader-1", NodeID: "id-leader-1", Role: "master", LinkState: "connected", Slots: []string{"5461-10922"}},
+	}, topology)
+
+	_, _, err = clusterTopology([]clusterNodesResponse{
+		{"id-leader-0", "10.0.0.1:6379@16379,cluster-leader-0", "master", "-", "0", "0", "1", "connected", "0-x"},
+	})
+	assert.Error(t, err)
+}
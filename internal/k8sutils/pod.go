@@ -1,11 +1,16 @@
 package k8sutils
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -22,3 +27,37 @@ func IsPodRunning(ctx context.Context, client kubernetes.Interface, namespace, p
 	}
 	return pod.Status.Phase == corev1.PodRunning
 }
+
+// ExecPodCommand runs cmd in the given container of a pod, streaming stdin into the command and
+// its stdout into stdout. Either stream may be nil. The call is bounded by ctx only, callers are
+// expected to set a deadline suited to the amount of data they move.
+func ExecPodCommand(ctx context.Context, client kubernetes.Interface, namespace, podName, container string, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	config, err := GenerateK8sConfig()()
+	if err != nil {
+		return err
+	}
+	req := client.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(namespace).SubResource("exec")
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: container,
+		Command:   cmd,
+		Stdin:     stdin != nil,
+		Stdout:    stdout != nil,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to init executor: %w", err)
+	}
+
+	var execErr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &execErr,
+		Tty:    false,
+	})
+	if err != nil {
+		return fmt.Errorf("execute command with error: %w, stderr: %s", err, execErr.String())
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	redis "github.com/redis/go-redis/v9"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrRedisPodNotFound is returned when an ACL command targets a pod that does not exist
+var ErrRedisPodNotFound = errors.New("redis pod not found")
+
+// GetRedisACLNodes returns every pod of the target an ACL user has to be applied on. ACL users
+// are not replicated, so followers and replicas are included next to the leaders and masters.
+func GetRedisACLNodes(target metav1.Object) ([]string, error) {
+	var pods []string
+	switch cr := target.(type) {
+	case *rcvb2.RedisCluster:
+		for _, role := range []string{"leader", "follower"} {
+			for i := int32(0); i < cr.Spec.GetReplicaCounts(role); i++ {
+				pods = append(pods, fmt.Sprintf("%s-%s-%d", cr.Name, role, i))
+			}
+		}
+	case *rrvb2.RedisReplication:
+		for i := int32(0); i < cr.Spec.GetReplicationCounts("replication"); i++ {
+			pods = append(pods, fmt.Sprintf("%s-%d", cr.Name, i))
+		}
+	case *rvb2.Redis:
+		pods = append(pods, cr.Name+"-0")
+	default:
+		return nil, fmt.Errorf("unsupported redis target %T", target)
+	}
+	return pods, nil
+}
+
+// SetRedisACLUser creates or replaces the ACL user on the pod
+func SetRedisACLUser(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName, username string, rules []string) error {
+	redisClient, err := configureRedisACLClient(ctx, client, target, podName)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	return setACLUser(ctx, redisClient, username, rules)
+}
+
+// DeleteRedisACLUser removes the ACL user from the pod, deleting a user that does not exist is
+// not an error
+func DeleteRedisACLUser(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName, username string) error {
+	redisClient, err := configureRedisACLClient(ctx, client, target, podName)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	if err := redisClient.Do(ctx, "ACL", "DELUSER", username).Err(); err != nil {
+		return fmt.Errorf("failed to delete ACL user %s: %w", username, err)
+	}
+	return nil
+}
+
+// SaveRedisACL writes the ACL users of the pod to its ACL file. It returns false without an
+// error when redis is not configured with an ACL file, users then only live in memory.
+func SaveRedisACL(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string) (bool, error) {
+	redisClient, err := configureRedisACLClient(ctx, client, target, podName)
+	if err != nil {
+		return false, err
+	}
+	defer redisClient.Close()
+	return saveACL(ctx, redisClient)
+}
+
+// configureRedisACLClient returns a client for the pod, or ErrRedisPodNotFound when the pod does
+// not exist, so callers can tell a missing pod apart from an unreachable one
+func configureRedisACLClient(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string) (*redis.Client, error) {
+	pod, err := client.CoreV1().Pods(target.GetNamespace()).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrRedisPodNotFound, podName)
+		}
+		return nil, err
+	}
+	if !IsRedisPodProbeable(pod) {
+		return nil, fmt.Errorf("redis pod %s is not ready", podName)
+	}
+	return configureRedisTargetClient(ctx, client, target, podName)
+}
+
+// setACLUser resets the user before applying the rules, so the user ends up with exactly the
+// given rules whatever it was granted before. ACL SETUSER applies all rules atomically.
+func setACLUser(ctx context.Context, redisClient *redis.Client, username string, rules []string) error {
+	args := make([]interface{}, 0, len(rules)+4)
+	args = append(args, "ACL", "SETUSER", username, "reset")
+	for _, rule := range rules {
+		args = append(args, rule)
+	}
+	if err := redisClient.Do(ctx, args...).Err(); err != nil {
+		return fmt.Errorf("failed to set ACL user %s: %w", username, err)
+	}
+	return nil
+}
+
+func saveACL(ctx context.Context, redisClient *redis.Client) (bool, error) {
+	config, err := redisClient.ConfigGet(ctx, "aclfile").Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get aclfile config: %w", err)
+	}
+	if config["aclfile"] == "" {
+		return false, nil
+	}
+	if err := redisClient.Do(ctx, "ACL", "SAVE").Err(); err != nil {
+		return false, fmt.Errorf("failed to save ACL file: %w", err)
+	}
+	return true, nil
+}
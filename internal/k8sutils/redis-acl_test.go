@@ -0,0 +1,82 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetRedisACLNodes(t *testing.T) {
+	cluster := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize:   ptr.To(int32(2)),
+			RedisFollower: rcvb2.RedisFollower{RedisFollower: commonapi.RedisFollower{Replicas: ptr.To(int32(1))}},
+		},
+	}
+	nodes, err := GetRedisACLNodes(cluster)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-0", "cluster-leader-1", "cluster-follower-0"}, nodes)
+
+	replication := &rrvb2.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "replication"},
+		Spec:       rrvb2.RedisReplicationSpec{Size: ptr.To(int32(2))},
+	}
+	nodes, err = GetRedisACLNodes(replication)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"replication-0", "replication-1"}, nodes)
+
+	nodes, err = GetRedisACLNodes(&rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"standalone-0"}, nodes)
+}
+
+func TestSetACLUser(t *testing.T) {
+	redisClient, mock := redismock.NewClientMock()
+	mock.ExpectDo("ACL", "SETUSER", "app", "reset", "on", "~app:*", "+@read").SetVal("OK")
+	require.NoError(t, setACLUser(context.Background(), redisClient, "app", []string{"on", "~app:*", "+@read"}))
+
+	mock.ExpectDo("ACL", "SETUSER", "app", "reset", "+unknown").SetErr(errors.New("ERR Error in ACL SETUSER modifier '+unknown'"))
+	assert.Error(t, setACLUser(context.Background(), redisClient, "app", []string{"+unknown"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveACL(t *testing.T) {
+	redisClient, mock := redismock.NewClientMock()
+
+	mock.ExpectConfigGet("aclfile").SetVal(map[string]string{"aclfile": ""})
+	persisted, err := saveACL(context.Background(), redisClient)
+	require.NoError(t, err)
+	assert.False(t, persisted)
+
+	mock.ExpectConfigGet("aclfile").SetVal(map[string]string{"aclfile": "/data/redis/user.acl"})
+	mock.ExpectDo("ACL", "SAVE").SetVal("OK")
+	persisted, err = saveACL(context.Background(), redisClient)
+	require.NoError(t, err)
+	assert.True(t, persisted)
+
+	// an ACL file mounted from a secret is read-only
+	mock.ExpectConfigGet("aclfile").SetVal(map[string]string{"aclfile": "/etc/redis/user.acl"})
+	mock.ExpectDo("ACL", "SAVE").SetErr(errors.New("ERR There was an error trying to save the ACLs"))
+	persisted, err = saveACL(context.Background(), redisClient)
+	assert.Error(t, err)
+	assert.False(t, persisted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteRedisACLUserPodNotFound(t *testing.T) {
+	err := DeleteRedisACLUser(context.Background(), k8sClientFake.NewSimpleClientset(),
+		&rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}}, "standalone-0", "app")
+	assert.ErrorIs(t, err, ErrRedisPodNotFound)
+}
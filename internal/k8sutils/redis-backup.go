@@ -0,0 +1,327 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	redis "github.com/redis/go-redis/v9"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultRDBStreamTimeout bounds streaming a single RDB file out of a redis pod unless
+// RDB_STREAM_TIMEOUT sets another one. Snapshots can be large, so this is much longer than the
+// exec timeout used for redis-cli commands.
+const defaultRDBStreamTimeout = time.Hour
+
+// RedisBackupNode is a redis pod an RDB snapshot is taken on
+type RedisBackupNode struct {
+	PodName string
+	// NodeID and Slots are only set for RedisCluster shards
+	NodeID string
+	Slots  []string
+}
+
+// ErrBGSaveInProgress is returned when a background save is already running on the pod, it
+// started before the backup and is not taken as its snapshot
+var ErrBGSaveInProgress = errors.New("a background save is already in progress")
+
+// BGSaveStatus is the persistence state reported by INFO persistence
+type BGSaveStatus struct {
+	InProgress bool
+	// LastStatus is the result of the last background save, "ok" or "err"
+	LastStatus string
+	// LastSaveTime is the unix time of the last successful save
+	LastSaveTime int64
+	// Saves is the number of RDB saves started since the server started, nil before redis 7.0
+	Saves *int64
+}
+
+// BGSaveRequest is a background save requested on a pod with the persistence state reported
+// right before the request
+type BGSaveRequest struct {
+	// Time is the redis server time the save was requested at
+	Time   time.Time
+	Before BGSaveStatus
+}
+
+// Done reports whether the requested save, or a later one, ended. LastStatus of the status tells
+// whether it succeeded.
+func (r BGSaveRequest) Done(status BGSaveStatus) bool {
+	if status.InProgress {
+		return false
+	}
+	if r.Before.Saves != nil && status.Saves != nil {
+		return *status.Saves > *r.Before.Saves
+	}
+	// without rdb_saves a successful save is told by the time it ended at, a failed one by the
+	// status turning to err. A save ending in the second the previous one ended in, or failing
+	// after a failed one, is not told apart from it.
+	return status.LastSaveTime > r.Before.LastSaveTime || (status.LastStatus != "ok" && r.Before.LastStatus == "ok")
+}
+
+// GetRedisClusterBackupNodes returns one node per cluster shard, the current master of each
+// slot range, as reported by CLUSTER NODES.
+func GetRedisClusterBackupNodes(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]RedisBackupNode, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+
+	podsByIP := make(map[string]string)
+	for _, role := range []string{"leader", "follower"} {
+		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+			podName := fmt.Sprintf("%s-%s-%d", cr.Name, role, i)
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if pod.Status.PodIP != "" {
+				podsByIP[pod.Status.PodIP] = pod.Name
+			}
+		}
+	}
+
+	var backupNodes []RedisBackupNode
+	for _, node := range nodes {
+		if len(node) < 9 || !hasFlag(node[2], "master") || hasAnyFlag(node[2], "fail", "fail?", "noaddr") {
+			continue
+		}
+		podName, ok := podsByIP[clusterNodeIP(node)]
+		if !ok {
+			if host, err := getHostFromClusterNode(node); err == nil {
+				podName = host
+			}
+		}
+		if podName == "" {
+			return nil, fmt.Errorf("failed to find pod of cluster master %s at %s", node[0], node[1])
+		}
+		backupNodes = append(backupNodes, RedisBackupNode{
+			PodName: podName,
+			NodeID:  node[0],
+			Slots:   append([]string(nil), node[8:]...),
+		})
+	}
+	if len(backupNodes) == 0 {
+		return nil, fmt.Errorf("no master with assigned slots found in redis cluster %s", cr.Name)
+	}
+	sort.Slice(backupNodes, func(i, j int) bool { return backupNodes[i].PodName < backupNodes[j].PodName })
+	return backupNodes, nil
+}
+
+// clusterNodeIP returns the IP of a CLUSTER NODES entry whose address looks like ip:port@cport[,hostname]
+func clusterNodeIP(node clusterNodesResponse) string {
+	addr := strings.Split(strings.Split(node[1], ",")[0], "@")[0]
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		addr = addr[:i]
+	}
+	return strings.Trim(addr, "[]")
+}
+
+// GetRedisReplicationBackupNodes returns a single replica of the replication to take the
+// snapshot on, so the master is not loaded by the fork. The master is used when no replica
+// is available.
+func GetRedisReplicationBackupNodes(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) ([]RedisBackupNode, error) {
+	for _, role := range []string{"slave", "master"} {
+		pods, err := GetRedisNodesByRole(ctx, client, cr, role)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods) > 0 {
+			return []RedisBackupNode{{PodName: pods[0]}}, nil
+		}
+	}
+	return nil, fmt.Errorf("no ready redis pod found in redis replication %s", cr.Name)
+}
+
+// GetRedisStandaloneBackupNodes returns the single pod of a standalone redis
+func GetRedisStandaloneBackupNodes(ctx context.Context, client kubernetes.Interface, cr *rvb2.Redis) ([]RedisBackupNode, error) {
+	podName := cr.Name + "-0"
+	pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !IsRedisPodProbeable(pod) {
+		return nil, fmt.Errorf("redis pod %s is not ready", podName)
+	}
+	return []RedisBackupNode{{PodName: podName}}, nil
+}
+
+// configureRedisTargetClient returns a redis client for a pod of the given Redis, RedisReplication or RedisCluster
+func configureRedisTargetClient(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string) (*redis.Client, error) {
+	switch cr := target.(type) {
+	case *rcvb2.RedisCluster:
+		return configureRedisClient(ctx, client, cr, podName), nil
+	case *rrvb2.RedisReplication:
+		return configureRedisReplicationClient(ctx, client, cr, podName), nil
+	case *rvb2.Redis:
+		return configureRedisStandaloneClient(ctx, client, cr, podName), nil
+	default:
+		return nil, fmt.Errorf("unsupported redis target %T", target)
+	}
+}
+
+// TriggerRedisBGSave requests a background save on the pod and returns the request with the
+// persistence state before it, which tells when the save ended. It returns ErrBGSaveInProgress
+// while a save that started earlier is running, the snapshot it writes predates the request.
+func TriggerRedisBGSave(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string) (BGSaveRequest, error) {
+	redisClient, err := configureRedisTargetClient(ctx, client, target, podName)
+	if err != nil {
+		return BGSaveRequest{}, err
+	}
+	defer redisClient.Close()
+
+	info, err := redisClient.Info(ctx, "persistence").Result()
+	if err != nil {
+		return BGSaveRequest{}, fmt.Errorf("failed to get persistence info: %w", err)
+	}
+	before, err := parseBGSaveStatus(info)
+	if err != nil {
+		return BGSaveRequest{}, err
+	}
+	if before.InProgress {
+		return BGSaveRequest{}, ErrBGSaveInProgress
+	}
+	now, err := redisClient.Time(ctx).Result()
+	if err != nil {
+		return BGSaveRequest{}, fmt.Errorf("failed to get redis server time: %w", err)
+	}
+	// SCHEDULE postpones the save instead of failing when an AOF rewrite is in progress
+	if err := redisClient.Do(ctx, "BGSAVE", "SCHEDULE").Err(); err != nil {
+		if strings.Contains(err.Error(), "already in progress") {
+			return BGSaveRequest{}, ErrBGSaveInProgress
+		}
+		return BGSaveRequest{}, fmt.Errorf("failed to trigger BGSAVE: %w", err)
+	}
+	log.FromContext(ctx).V(1).Info("Triggered background save", "pod", podName)
+	return BGSaveRequest{Time: now, Before: before}, nil
+}
+
+// GetRedisBGSaveStatus returns the background save state of the pod
+func GetRedisBGSaveStatus(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string) (BGSaveStatus, error) {
+	redisClient, err := configureRedisTargetClient(ctx, client, target, podName)
+	if err != nil {
+		return BGSaveStatus{}, err
+	}
+	defer redisClient.Close()
+
+	info, err := redisClient.Info(ctx, "persistence").Result()
+	if err != nil {
+		return BGSaveStatus{}, fmt.Errorf("failed to get persistence info: %w", err)
+	}
+	return parseBGSaveStatus(info)
+}
+
+func parseBGSaveStatus(info string) (BGSaveStatus, error) {
+	var (
+		status                           BGSaveStatus
+		foundInProgress, foundLastSaveTs bool
+	)
+	for _, line := range strings.Split(info, "\r\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "rdb_bgsave_in_progress":
+			status.InProgress = value == "1"
+			foundInProgress = true
+		case "rdb_last_bgsave_status":
+			status.LastStatus = value
+		case "rdb_saves":
+			saves, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return BGSaveStatus{}, fmt.Errorf("failed to parse rdb_saves %q: %w", value, err)
+			}
+			status.Saves = &saves
+		case "rdb_last_save_time":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return BGSaveStatus{}, fmt.Errorf("failed to parse rdb_last_save_time %q: %w", value, err)
+			}
+			status.LastSaveTime = ts
+			foundLastSaveTs = true
+		}
+	}
+	if !foundInProgress || !foundLastSaveTs {
+		return BGSaveStatus{}, fmt.Errorf("rdb_bgsave_in_progress or rdb_last_save_time not found in persistence info")
+	}
+	return status, nil
+}
+
+// StreamRedisRDB copies the RDB file of the pod into w and returns the number of bytes written.
+// The file location is read from the dir and dbfilename config of the running server.
+func StreamRedisRDB(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string, w io.Writer) (int64, error) {
+	redisClient, err := configureRedisTargetClient(ctx, client, target, podName)
+	if err != nil {
+		return 0, err
+	}
+	defer redisClient.Close()
+
+	rdbPath, err := getRedisRDBPath(ctx, redisClient)
+	if err != nil {
+		return 0, err
+	}
+
+	counter := &countingWriter{w: w}
+	streamCtx, cancel := context.WithTimeout(ctx, envs.GetRDBStreamTimeout(defaultRDBStreamTimeout))
+	defer cancel()
+	if err := ExecPodCommand(streamCtx, client, target.GetNamespace(), podName, statefulSetNameFromPodName(podName), []string{"cat", rdbPath}, nil, counter); err != nil {
+		return counter.n, fmt.Errorf("failed to stream %s from pod %s: %w", rdbPath, podName, err)
+	}
+	return counter.n, nil
+}
+
+func getRedisRDBPath(ctx context.Context, redisClient *redis.Client) (string, error) {
+	values := make(map[string]string, 2)
+	for _, key := range []string{"dir", "dbfilename"} {
+		result, err := redisClient.ConfigGet(ctx, key).Result()
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s config: %w", key, err)
+		}
+		values[key] = result[key]
+	}
+	if values["dbfilename"] == "" {
+		return "", fmt.Errorf("redis dbfilename is not configured")
+	}
+	return path.Join(values["dir"], values["dbfilename"]), nil
+}
+
+// statefulSetNameFromPodName returns the name of the statefulset owning the pod, which is
+// also the name of the redis container in it.
+func statefulSetNameFromPodName(podName string) string {
+	if i := strings.LastIndex(podName, "-"); i > 0 {
+		return podName[:i]
+	}
+	return podName
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,94 @@
+package k8sutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func TestParseBGSaveStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    string
+		want    BGSaveStatus
+		wantErr bool
+	}{
+		{
+			name: "save in progress",
+			info: "# Persistence\r\nloading:0\r\nrdb_bgsave_in_progress:1\r\nrdb_last_save_time:1700000000\r\nrdb_last_bgsave_status:ok\r\n",
+			want: BGSaveStatus{InProgress: true, LastStatus: "ok", LastSaveTime: 1700000000},
+		},
+		{
+			name: "last save failed",
+			info: "# Persistence\r\nrdb_bgsave_in_progress:0\r\nrdb_last_save_time:1700000000\r\nrdb_last_bgsave_status:err\r\n",
+			want: BGSaveStatus{LastStatus: "err", LastSaveTime: 1700000000},
+		},
+		{
+			name: "save count of redis 7",
+			info: "# Persistence\r\nrdb_bgsave_in_progress:0\r\nrdb_saves:12\r\nrdb_last_save_time:1700000000\r\nrdb_last_bgsave_status:ok\r\n",
+			want: BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000000, Saves: ptr.To(int64(12))},
+		},
+		{
+			name:    "missing fields",
+			info:    "# Persistence\r\nloading:0\r\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed save time",
+			info:    "rdb_bgsave_in_progress:0\r\nrdb_last_save_time:soon\r\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBGSaveStatus(tt.info)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBGSaveRequestDone(t *testing.T) {
+	request := BGSaveRequest{Before: BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000000, Saves: ptr.To(int64(12))}}
+	assert.False(t, request.Done(BGSaveStatus{InProgress: true, LastStatus: "ok", LastSaveTime: 1700000000, Saves: ptr.To(int64(13))}))
+	// a save ending in the second of the request is told apart by the save count
+	assert.True(t, request.Done(BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000000, Saves: ptr.To(int64(13))}))
+	// the save was scheduled behind an AOF rewrite and has not started yet
+	assert.False(t, request.Done(BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000000, Saves: ptr.To(int64(12))}))
+	assert.True(t, request.Done(BGSaveStatus{LastStatus: "err", LastSaveTime: 1700000000, Saves: ptr.To(int64(13))}))
+
+	// before redis 7.0 the time of the save tells it apart
+	request.Before.Saves = nil
+	assert.False(t, request.Done(BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000000}))
+	assert.True(t, request.Done(BGSaveStatus{LastStatus: "ok", LastSaveTime: 1700000001}))
+	assert.True(t, request.Done(BGSaveStatus{LastStatus: "err", LastSaveTime: 1700000000}))
+}
+
+func TestClusterNodeIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: "10.0.0.1:6379@16379", want: "10.0.0.1"},
+		{addr: "10.0.0.1:6379@16379,redis-cluster-leader-0", want: "10.0.0.1"},
+		{addr: "[fd00::1]:6379@16379", want: "fd00::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			node := clusterNodesResponse{"id", tt.addr, "master", "-", "0", "0", "1", "connected", "0-16383"}
+			assert.Equal(t, tt.want, clusterNodeIP(node))
+		})
+	}
+}
+
+func TestStatefulSetNameFromPodName(t *testing.T) {
+	assert.Equal(t, "redis-cluster-leader", statefulSetNameFromPodName("redis-cluster-leader-2"))
+	assert.Equal(t, "redis-replication", statefulSetNameFromPodName("redis-replication-0"))
+	assert.Equal(t, "redis", statefulSetNameFromPodName("redis"))
+}
@@ -8,6 +8,8 @@ import (
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/maps"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
@@ -28,6 +30,7 @@ type RedisClusterSTS struct {
 	NodeSelector                  map[string]string
 	TopologySpreadConstraints     []corev1.TopologySpreadConstraint
 	Tolerations                   *[]corev1.Toleration
+	Restore                       *RestoreSource
 }
 
 // RedisClusterService is a interface to call Redis Service function
@@ -44,7 +47,7 @@ func generateRedisClusterParams(ctx context.Context, cr *rcvb2.RedisCluster, rep
 	res := statefulSetParameters{
 		Replicas:                             &replicas,
 		ClusterMode:                          true,
-		ClusterVersion:                       cr.Spec.ClusterVersion,
+		ClusterVersion:                       cr.Spec.GetMajorVersion(),
 		NodeSelector:                         params.NodeSelector,
 		TopologySpreadConstraints:            params.TopologySpreadConstraints,
 		PodSecurityContext:                   cr.Spec.PodSecurityContext,
@@ -60,9 +63,20 @@ func generateRedisClusterParams(ctx context.Context, cr *rcvb2.RedisCluster, rep
 		MinReadySeconds:                      minreadyseconds,
 	}
 
+	if cr.Spec.KubernetesConfig.UsesManagedFailover() {
+		// the operator restarts the leaders itself, the followers roll out as usual
+		res.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{}
+		if params.RedisStateFulType == "leader" {
+			res.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+		}
+	}
+
 	if cr.Spec.PodManagementPolicy != nil {
 		res.PodManagementPolicy = cr.Spec.PodManagementPolicy
 	}
+	if cr.Spec.ReadinessGate {
+		res.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: rcvb2.NodeReadyCondition}}
+	}
 
 	if cr.Spec.RedisExporter != nil {
 		res.EnableMetrics = cr.Spec.RedisExporter.Enabled
@@ -128,6 +142,7 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 		SecurityContext: securityContext,
 		Port:            cr.Spec.Port,
 		HostPort:        cr.Spec.HostPort,
+		Engine:          cr.Spec.Engine,
 	}
 	if cr.Spec.RedisConfig != nil {
 		containerProp.MaxMemoryPercentOfLimit = cr.Spec.RedisConfig.MaxMemoryPercentOfLimit
@@ -135,7 +150,8 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 	if cr.Spec.EnvVars != nil {
 		containerProp.EnvVars = cr.Spec.EnvVars
 	}
-	if cr.Spec.KubernetesConfig.GetServiceType() == "NodePort" {
+	switch cr.Spec.GetExternalServiceType() {
+	case corev1.ServiceTypeNodePort:
 		envVars := util.Coalesce(containerProp.EnvVars, &[]corev1.EnvVar{})
 		*envVars = append(*envVars, corev1.EnvVar{
 			Name:  "NODEPORT",
@@ -178,6 +194,14 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 			})
 		}
 		containerProp.EnvVars = envVars
+	case corev1.ServiceTypeLoadBalancer:
+		envVars := util.Coalesce(containerProp.EnvVars, &[]corev1.EnvVar{})
+		*envVars = append(*envVars, corev1.EnvVar{
+			Name:  "EXTERNAL_ACCESS",
+			Value: "true",
+		})
+		*envVars = append(*envVars, loadBalancerAnnounceEnvVars(ctx, cr, cl, role)...)
+		containerProp.EnvVars = envVars
 	}
 	if cr.Spec.Storage != nil {
 		containerProp.AdditionalVolume = cr.Spec.Storage.VolumeMount.Volume
@@ -226,8 +250,8 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 	return containerProp
 }
 
-// CreateRedisLeader will create a leader redis setup
-func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface) error {
+// CreateRedisLeader will create a leader redis setup, the leaders are seeded from the restore when it is set
+func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface, restore *RestoreSource) error {
 	prop := RedisClusterSTS{
 		RedisStateFulType:             "leader",
 		Resources:                     cr.Spec.GetRedisLeaderResources(),
@@ -240,6 +264,7 @@ func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernete
 		Tolerations:    cr.Spec.RedisLeader.Tolerations,
 		ReadinessProbe: cr.Spec.RedisLeader.ReadinessProbe,
 		LivenessProbe:  cr.Spec.RedisLeader.LivenessProbe,
+		Restore:        restore,
 	}
 	if cr.Spec.RedisLeader.RedisConfig != nil {
 		prop.ExternalConfig = cr.Spec.RedisLeader.RedisConfig.AdditionalRedisConfig
@@ -295,6 +320,8 @@ func (service RedisClusterSTS) CreateRedisClusterSetup(ctx context.Context, cr *
 	labels["cluster"] = cr.Name
 	annotations := generateStatefulSetsAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.IgnoreAnnotations)
 	objectMetaInfo := generateObjectMetaInformation(stateFulName, cr.Namespace, labels, annotations)
+	initcontainerParams := generateRedisClusterInitContainerParams(cr)
+	initcontainerParams.Restore = service.Restore
 	err := CreateOrUpdateStateFul(
 		ctx,
 		cl,
@@ -302,7 +329,7 @@ func (service RedisClusterSTS) CreateRedisClusterSetup(ctx context.Context, cr *
 		objectMetaInfo,
 		generateRedisClusterParams(ctx, cr, service.getReplicaCount(cr), service.ExternalConfig, service),
 		redisClusterAsOwner(cr),
-		generateRedisClusterInitContainerParams(cr),
+		initcontainerParams,
 		generateRedisClusterContainerParams(ctx, cl, cr, service.SecurityContext, service.ReadinessProbe, service.LivenessProbe, service.RedisStateFulType, service.Resources),
 		cr.Spec.Sidecars,
 	)
@@ -359,7 +386,10 @@ func (service RedisClusterService) CreateRedisClusterService(ctx context.Context
 	if cr.Spec.KubernetesConfig.ShouldIncludeBusPortForHeadless() {
 		headlessExtraPorts = append(headlessExtraPorts, busPort)
 	}
-	err := CreateOrUpdateService(ctx, cr.Namespace, headlessObjectMetaInfo, redisClusterAsOwner(cr), disableMetrics, true, "ClusterIP", *cr.Spec.Port, cl, headlessExtraPorts...)
+	headlessService := generateServiceDef(headlessObjectMetaInfo, disableMetrics, redisClusterAsOwner(cr), true, "ClusterIP", *cr.Spec.Port, headlessExtraPorts...)
+	// the pods gated on their node serving the cluster are resolved before they join it
+	headlessService.Spec.PublishNotReadyAddresses = cr.Spec.ReadinessGate
+	err := createOrUpdateServiceDef(ctx, cr.Namespace, headlessService, cl)
 	if err != nil {
 		log.FromContext(ctx).Error(err, "Cannot create headless service for Redis", "Setup.Type", service.RedisServiceRole)
 		return err
@@ -373,10 +403,9 @@ func (service RedisClusterService) CreateRedisClusterService(ctx context.Context
 		log.FromContext(ctx).Error(err, "Cannot create service for Redis", "Setup.Type", service.RedisServiceRole)
 		return err
 	}
-	if cr.Spec.KubernetesConfig.GetServiceType() == "NodePort" {
-		err = service.createOrUpdateClusterNodePortService(ctx, cr, cl)
+	if cr.Spec.GetExternalServiceType() != "" {
+		err = service.createOrUpdateClusterPodServices(ctx, cr, cl)
 		if err != nil {
-			log.FromContext(ctx).Error(err, "Cannot create nodeport service for Redis", "Setup.Type", service.RedisServiceRole)
 			return err
 		}
 	}
@@ -408,6 +437,21 @@ func (service RedisClusterService) CreateRedisClusterService(ctx context.Context
 		return err
 	}
 
+	// the followers replicating a master serve the reads of the clients sending READONLY
+	if service.RedisServiceRole == "follower" && cr.Spec.KubernetesConfig.ShouldCreateReplicaService(false) {
+		replicaObjectMetaInfo := generateObjectMetaInformation(
+			cr.Name+"-replica",
+			cr.Namespace,
+			maps.Merge(labels, map[string]string{common.RedisRoleLabelKey: common.RedisRoleLabelSlave}),
+			generateServiceAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.GetReplicaServiceAnnotations(), epp),
+		)
+		err = CreateOrUpdateService(ctx, cr.Namespace, replicaObjectMetaInfo, redisClusterAsOwner(cr), disableMetrics, false, cr.Spec.KubernetesConfig.GetReplicaServiceType(), *cr.Spec.Port, cl)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Cannot create replica service for Redis", "Setup.Type", service.RedisServiceRole)
+			return err
+		}
+	}
+
 	if cr.Spec.RedisExporter != nil && cr.Spec.RedisExporter.Enabled {
 		defaultP := ptr.To(common.RedisExporterPort)
 		exporterPort := *util.Coalesce(cr.Spec.RedisExporter.Port, defaultP)
@@ -421,15 +465,90 @@ func (service RedisClusterService) CreateRedisClusterService(ctx context.Context
 	return nil
 }
 
-func (service RedisClusterService) createOrUpdateClusterNodePortService(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface) error {
+// ReconcileRedisClusterExternalServices creates the LoadBalancer services of the pods of the role
+// and returns the names of the ones whose load balancer has no address yet. The pods announce
+// the addresses of their load balancers, so they are only created once every one is assigned.
+func ReconcileRedisClusterExternalServices(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface, role string) ([]string, error) {
+	if cr.Spec.GetExternalServiceType() != corev1.ServiceTypeLoadBalancer {
+		return nil, nil
+	}
+	service := RedisClusterService{RedisServiceRole: role}
+	if err := service.createOrUpdateClusterPodServices(ctx, cr, cl); err != nil {
+		return nil, err
+	}
+	var pending []string
+	for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+		serviceName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+		svc, err := getService(ctx, cl, cr.Namespace, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		if ip, hostname := loadBalancerAddress(svc); ip == "" && hostname == "" {
+			pending = append(pending, serviceName)
+		}
+	}
+	return pending, nil
+}
+
+// loadBalancerAnnounceEnvVars returns the addresses and ports the pods of the role announce,
+// those of the load balancers of their services
+func loadBalancerAnnounceEnvVars(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface, role string) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+		podName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+		svc, err := getService(ctx, cl, cr.Namespace, podName)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Cannot get service for Redis", "Setup.Type", role)
+			continue
+		}
+		suffix := "_" + strings.ReplaceAll(podName, "-", "_")
+		ip, hostname := loadBalancerAddress(svc)
+		switch {
+		case ip != "":
+			envVars = append(envVars, corev1.EnvVar{Name: "announce_ip" + suffix, Value: ip})
+		case hostname != "":
+			envVars = append(envVars, corev1.EnvVar{Name: "announce_hostname" + suffix, Value: hostname})
+		default:
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			switch port.Name {
+			case "redis-client":
+				envVars = append(envVars, corev1.EnvVar{Name: "announce_port" + suffix, Value: strconv.Itoa(int(port.Port))})
+			case "redis-bus":
+				envVars = append(envVars, corev1.EnvVar{Name: "announce_bus_port" + suffix, Value: strconv.Itoa(int(port.Port))})
+			}
+		}
+	}
+	return envVars
+}
+
+// loadBalancerAddress returns the IP or else the hostname of the load balancer of the service
+func loadBalancerAddress(svc *corev1.Service) (string, string) {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" || ingress.Hostname != "" {
+			return ingress.IP, ingress.Hostname
+		}
+	}
+	return "", ""
+}
+
+// createOrUpdateClusterPodServices creates a service of the external access type for every pod
+// of the role, named after the pod
+func (service RedisClusterService) createOrUpdateClusterPodServices(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface) error {
 	replicas := cr.Spec.GetReplicaCounts(service.RedisServiceRole)
+	serviceType := cr.Spec.GetExternalServiceType()
+	var serviceAnnotations map[string]string
+	if cr.Spec.ExternalAccess != nil {
+		serviceAnnotations = cr.Spec.ExternalAccess.Annotations
+	}
 
 	for i := 0; i < int(replicas); i++ {
 		serviceName := cr.Name + "-" + service.RedisServiceRole + "-" + strconv.Itoa(i)
 		labels := getRedisLabels(cr.Name+"-"+service.RedisServiceRole, cluster, service.RedisServiceRole, map[string]string{
 			"statefulset.kubernetes.io/pod-name": serviceName,
 		})
-		annotations := generateServiceAnots(cr.ObjectMeta, nil, disableMetrics)
+		annotations := generateServiceAnots(cr.ObjectMeta, serviceAnnotations, disableMetrics)
 		objectMetaInfo := generateObjectMetaInformation(serviceName, cr.Namespace, labels, annotations)
 		busPort := corev1.ServicePort{
 			Name:     "redis-bus",
@@ -440,9 +559,9 @@ func (service RedisClusterService) createOrUpdateClusterNodePortService(ctx cont
 				IntVal: int32(*cr.Spec.Port + 10000),
 			},
 		}
-		err := CreateOrUpdateService(ctx, cr.Namespace, objectMetaInfo, redisClusterAsOwner(cr), disableMetrics, false, "NodePort", *cr.Spec.Port, cl, busPort)
+		err := CreateOrUpdateService(ctx, cr.Namespace, objectMetaInfo, redisClusterAsOwner(cr), disableMetrics, false, string(serviceType), *cr.Spec.Port, cl, busPort)
 		if err != nil {
-			log.FromContext(ctx).Error(err, "Cannot create nodeport service for Redis", "Setup.Type", service.RedisServiceRole)
+			log.FromContext(ctx).Error(err, "Cannot create the service of the pod for Redis", "Setup.Type", service.RedisServiceRole, "Service.Type", serviceType)
 			return err
 		}
 	}
@@ -9,8 +9,12 @@ import (
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
@@ -194,6 +198,100 @@ func Test_generateRedisClusterParams(t *testing.T) {
 	assert.EqualValues(t, expectedFollowerSTS, actualFollowerSTS, "Expected %+v, got %+v", expectedFollowerSTS, actualFollowerSTS)
 }
 
+func Test_generateRedisClusterParamsManagedFailover(t *testing.T) {
+	cr := &rcvb2.RedisCluster{}
+	cr.Spec.KubernetesConfig.UpdateStrategy.Type = common.ManagedFailoverUpdateStrategyType
+
+	leader := generateRedisClusterParams(context.TODO(), cr, 3, nil, RedisClusterSTS{RedisStateFulType: "leader"})
+	assert.Equal(t, appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, leader.UpdateStrategy)
+
+	follower := generateRedisClusterParams(context.TODO(), cr, 3, nil, RedisClusterSTS{RedisStateFulType: "follower"})
+	assert.Equal(t, appsv1.StatefulSetUpdateStrategy{}, follower.UpdateStrategy)
+}
+
+func Test_generateRedisClusterParamsReadinessGate(t *testing.T) {
+	cr := &rcvb2.RedisCluster{}
+	params := generateRedisClusterParams(context.TODO(), cr, 3, nil, RedisClusterSTS{RedisStateFulType: "leader"})
+	assert.Empty(t, params.ReadinessGates)
+
+	cr.Spec.ReadinessGate = true
+	params = generateRedisClusterParams(context.TODO(), cr, 3, nil, RedisClusterSTS{RedisStateFulType: "follower"})
+	assert.Equal(t, []corev1.PodReadinessGate{{ConditionType: rcvb2.NodeReadyCondition}}, params.ReadinessGates)
+}
+
+func TestCreateRedisFollowerServiceReplicas(t *testing.T) {
+	cr := &rcvb2.RedisCluster{}
+	cr.Name = "cluster"
+	cr.Namespace = "redis"
+	cr.UID = "uid"
+	cr.Spec.Port = ptr.To(6379)
+	client := fake.NewSimpleClientset()
+
+	assert.NoError(t, CreateRedisFollowerService(context.TODO(), cr, client))
+	_, err := client.CoreV1().Services("redis").Get(context.TODO(), "cluster-replica", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the replica service is only created when it is set")
+
+	cr.Spec.KubernetesConfig.Service = &common.ServiceConfig{Replicas: &common.Service{Type: "LoadBalancer"}}
+	assert.NoError(t, CreateRedisFollowerService(context.TODO(), cr, client))
+	service, err := client.CoreV1().Services("redis").Get(context.TODO(), "cluster-replica", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Equal(t, "cluster-follower", service.Spec.Selector["app"])
+	assert.Equal(t, "follower", service.Spec.Selector["role"])
+	assert.Equal(t, "slave", service.Spec.Selector["redis-role"])
+}
+
+func TestReconcileRedisClusterExternalServices(t *testing.T) {
+	ctx := context.TODO()
+	cr := &rcvb2.RedisCluster{}
+	cr.Name = "cluster"
+	cr.Namespace = "redis"
+	cr.UID = "uid"
+	cr.Spec.Port = ptr.To(6379)
+	cr.Spec.ClusterSize = ptr.To(int32(2))
+	client := fake.NewSimpleClientset()
+
+	pending, err := ReconcileRedisClusterExternalServices(ctx, cr, client, "leader")
+	require.NoError(t, err)
+	assert.Empty(t, pending, "the pods have no external services by default")
+
+	cr.Spec.ExternalAccess = &rcvb2.ExternalAccess{Annotations: map[string]string{"lb": "internet-facing"}}
+	pending, err = ReconcileRedisClusterExternalServices(ctx, cr, client, "leader")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-0", "cluster-leader-1"}, pending)
+
+	service, err := client.CoreV1().Services("redis").Get(ctx, "cluster-leader-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Equal(t, "internet-facing", service.Annotations["lb"])
+	assert.Equal(t, "cluster-leader-0", service.Spec.Selector["statefulset.kubernetes.io/pod-name"])
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+	_, err = client.CoreV1().Services("redis").UpdateStatus(ctx, service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	pending, err = ReconcileRedisClusterExternalServices(ctx, cr, client, "leader")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-1"}, pending)
+
+	service, err = client.CoreV1().Services("redis").Get(ctx, "cluster-leader-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb-1.example.com"}}
+	_, err = client.CoreV1().Services("redis").UpdateStatus(ctx, service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	pending, err = ReconcileRedisClusterExternalServices(ctx, cr, client, "leader")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "announce_ip_cluster_leader_0", Value: "203.0.113.10"},
+		{Name: "announce_port_cluster_leader_0", Value: "6379"},
+		{Name: "announce_bus_port_cluster_leader_0", Value: "16379"},
+		{Name: "announce_hostname_cluster_leader_1", Value: "lb-1.example.com"},
+		{Name: "announce_port_cluster_leader_1", Value: "6379"},
+		{Name: "announce_bus_port_cluster_leader_1", Value: "16379"},
+	}, loadBalancerAnnounceEnvVars(ctx, cr, client, "leader"))
+}
+
 func Test_generateRedisClusterContainerParams(t *testing.T) {
 	path := filepath.Join("..", "..", "tests", "testdata", "redis-cluster.yaml")
 	expectedLeaderContainer := containerParameters{
@@ -0,0 +1,94 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	redis "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AddRedisPassword makes the default user of the pod accept password next to the previous
+// passwords and authenticates replication to the master with password. The pod is logged in
+// with the first of password and previous it accepts, pods started during a rotation only
+// know password.
+func AddRedisPassword(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName, password string, previous []string) error {
+	redisClient, err := configureRedisPasswordClient(ctx, client, target, podName, append([]string{password}, previous...))
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	return setDefaultUserPasswords(ctx, redisClient, password, previous, false)
+}
+
+// ResetRedisPassword makes the default user of the pod accept password only
+func ResetRedisPassword(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName, password string, previous []string) error {
+	redisClient, err := configureRedisPasswordClient(ctx, client, target, podName, append([]string{password}, previous...))
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	return setDefaultUserPasswords(ctx, redisClient, password, nil, true)
+}
+
+// configureRedisPasswordClient returns a client for the pod logged in with the first of the
+// passwords the pod accepts
+func configureRedisPasswordClient(ctx context.Context, client kubernetes.Interface, target metav1.Object, podName string, passwords []string) (*redis.Client, error) {
+	redisClient, err := configureRedisACLClient(ctx, client, target, podName)
+	if err != nil {
+		return nil, err
+	}
+	opts := *redisClient.Options()
+	redisClient.Close()
+
+	for _, password := range passwords {
+		attempt := opts
+		attempt.Password = password
+		redisClient = redis.NewClient(&attempt)
+		err = redisClient.Ping(ctx).Err()
+		if err == nil {
+			return redisClient, nil
+		}
+		redisClient.Close()
+		if !isRedisAuthError(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("redis pod %s accepts none of the known passwords: %w", podName, err)
+}
+
+func isRedisAuthError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "WRONGPASS") || strings.Contains(msg, "NOAUTH") || strings.Contains(msg, "invalid password")
+}
+
+// setDefaultUserPasswords adds the passwords to the default user, reset drops every other
+// password first. masterauth is switched in the same step so replicas keep authenticating to
+// masters that already accept password. The ACL file is saved when there is one, otherwise the
+// default user would come back with the passwords it was saved with after a restart.
+func setDefaultUserPasswords(ctx context.Context, redisClient *redis.Client, password string, previous []string, reset bool) error {
+	args := []interface{}{"ACL", "SETUSER", "default"}
+	if reset {
+		args = append(args, "resetpass")
+	}
+	args = append(args, ">"+password)
+	for _, p := range previous {
+		if p != password {
+			args = append(args, ">"+p)
+		}
+	}
+	if err := redisClient.Do(ctx, args...).Err(); err != nil {
+		return fmt.Errorf("failed to set the passwords of the default user: %w", err)
+	}
+	if err := redisClient.ConfigSet(ctx, "masterauth", password).Err(); err != nil {
+		return fmt.Errorf("failed to set masterauth: %w", err)
+	}
+	// a read-only ACL file, e.g. mounted from a Secret, is managed outside the operator
+	if _, err := saveACL(ctx, redisClient); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to save the default user to the ACL file")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefaultUserPasswords(t *testing.T) {
+	redisClient, mock := redismock.NewClientMock()
+
+	mock.ExpectDo("ACL", "SETUSER", "default", ">new", ">old").SetVal("OK")
+	mock.ExpectConfigSet("masterauth", "new").SetVal("OK")
+	mock.ExpectConfigGet("aclfile").SetVal(map[string]string{"aclfile": ""})
+	require.NoError(t, setDefaultUserPasswords(context.Background(), redisClient, "new", []string{"old", "new"}, false))
+
+	mock.ExpectDo("ACL", "SETUSER", "default", "resetpass", ">new").SetVal("OK")
+	mock.ExpectConfigSet("masterauth", "new").SetVal("OK")
+	mock.ExpectConfigGet("aclfile").SetVal(map[string]string{"aclfile": "/etc/redis/user.acl"})
+	mock.ExpectDo("ACL", "SAVE").SetErr(errors.New("ERR There was an error trying to save the ACLs"))
+	require.NoError(t, setDefaultUserPasswords(context.Background(), redisClient, "new", nil, true))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetDefaultUserPasswordsFails(t *testing.T) {
+	redisClient, mock := redismock.NewClientMock()
+
+	mock.ExpectDo("ACL", "SETUSER", "default", ">new").SetVal("OK")
+	mock.ExpectConfigSet("masterauth", "new").SetErr(errors.New("ERR unsupported"))
+	err := setDefaultUserPasswords(context.Background(), redisClient, "new", nil, false)
+	assert.ErrorContains(t, err, "failed to set masterauth")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRedisAuthError(t *testing.T) {
+	assert.True(t, isRedisAuthError(errors.New("WRONGPASS invalid username-password pair or user is disabled.")))
+	assert.True(t, isRedisAuthError(errors.New("NOAUTH Authentication required.")))
+	assert.False(t, isRedisAuthError(errors.New("dial tcp 10.0.0.1:6379: connect: connection refused")))
+}
@@ -0,0 +1,134 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GetRedisReplicationReplicaPriorities returns the replica-priority of every pod of the replication
+// by pod name, nil without spec.replicaPriority. A pod that does not exist or is not scheduled is
+// left out while the labels of its node are needed, it fails when the pod or its node cannot be
+// read rather than returning the priorities of some pods only.
+func GetRedisReplicationReplicaPriorities(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) (map[string]int32, error) {
+	if len(cr.Spec.ReplicaPriority) == 0 {
+		return nil, nil
+	}
+	needsNodeLabels := cr.ReplicaPriorityNeedsNodeLabels()
+	nodeLabels := map[string]map[string]string{}
+	priorities := map[string]int32{}
+	for i := int32(0); i < cr.Spec.GetReplicationCounts(""); i++ {
+		podName := cr.Name + "-" + strconv.Itoa(int(i))
+		if !needsNodeLabels {
+			priorities[podName] = cr.ReplicaPriority(i, nil)
+			continue
+		}
+		pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get the pod %s: %w", podName, err)
+		}
+		if err != nil || pod.Spec.NodeName == "" {
+			log.FromContext(ctx).Info("Leaving the unscheduled pod out of the replica priorities, it may not be promoted", "pod", podName)
+			continue
+		}
+		labels, found := nodeLabels[pod.Spec.NodeName]
+		if !found {
+			node, err := client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the labels of the node %s of the pod %s: %w", pod.Spec.NodeName, podName, err)
+			}
+			labels = node.Labels
+			nodeLabels[pod.Spec.NodeName] = labels
+		}
+		priorities[podName] = cr.ReplicaPriority(i, labels)
+	}
+	return priorities, nil
+}
+
+// SetRedisReplicationReplicaPriority sets the replica-priority of every pod the sentinels read
+// when they pick the replica to promote, the pods reset to the default priority once
+// spec.replicaPriority is removed. It returns the priorities to record in the status.
+func SetRedisReplicationReplicaPriority(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) (map[string]int32, error) {
+	priorities, err := GetRedisReplicationReplicaPriorities(ctx, client, cr)
+	if err != nil {
+		return cr.Status.ReplicaPriorities, err
+	}
+	config := maps.Clone(priorities)
+	if config == nil {
+		config = map[string]int32{}
+	}
+	for podName := range cr.Status.ReplicaPriorities {
+		if _, found := config[podName]; !found {
+			config[podName] = rrvb2.DefaultReplicaPriority
+		}
+	}
+	if err := setRedisReplicationReplicaPriorities(ctx, client, cr, config); err != nil {
+		return cr.Status.ReplicaPriorities, err
+	}
+	return priorities, nil
+}
+
+func setRedisReplicationReplicaPriorities(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, priorities map[string]int32) error {
+	for _, podName := range slices.Sorted(maps.Keys(priorities)) {
+		redisClient := configureRedisReplicationClient(ctx, client, cr, podName)
+		_, err := applyDynamicConfig(ctx, redisClient, podName, []string{"replica-priority " + strconv.Itoa(int(priorities[podName]))})
+		redisClient.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PromotableReplicas returns the replicas which may be promoted, the ones with the lowest
+// replica-priority first. Without priorities every replica may be promoted in the given order,
+// with priorities a replica whose priority is unknown may not.
+func PromotableReplicas(replicas []string, priorities map[string]int32) []string {
+	if priorities == nil {
+		return replicas
+	}
+	promotable := slices.DeleteFunc(slices.Clone(replicas), func(podName string) bool {
+		priority, found := priorities[podName]
+		return !found || priority == 0
+	})
+	slices.SortStableFunc(promotable, func(a, b string) int {
+		return int(replicaPriority(priorities, a)) - int(replicaPriority(priorities, b))
+	})
+	return promotable
+}
+
+// pickBestMaster returns the pod the sentinels would promote: the lowest replica-priority other
+// than 0 first, the highest replication offset next. Pods whose offset or priority is unknown are
+// left out, "" when no pod may be promoted.
+func pickBestMaster(pods []string, offsets map[string]int64, priorities map[string]int32) string {
+	var bestMasterPod string
+	for _, podName := range PromotableReplicas(pods, priorities) {
+		offset, found := offsets[podName]
+		if !found {
+			continue
+		}
+		if bestMasterPod == "" {
+			bestMasterPod = podName
+			continue
+		}
+		if replicaPriority(priorities, podName) == replicaPriority(priorities, bestMasterPod) && offset > offsets[bestMasterPod] {
+			bestMasterPod = podName
+		}
+	}
+	return bestMasterPod
+}
+
+func replicaPriority(priorities map[string]int32, podName string) int32 {
+	if priority, found := priorities[podName]; found {
+		return priority
+	}
+	return rrvb2.DefaultReplicaPriority
+}
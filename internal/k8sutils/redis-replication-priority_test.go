@@ -0,0 +1,84 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetRedisReplicationReplicaPriorities(t *testing.T) {
+	cr := &rrvb2.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+		Spec:       rrvb2.RedisReplicationSpec{Size: ptr.To(int32(4))},
+	}
+	client := k8sClientFake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "dr", Labels: map[string]string{corev1.LabelTopologyZone: "dr"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "primary", Labels: map[string]string{corev1.LabelTopologyZone: "primary"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "primary"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-1", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "dr"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-2", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "primary"}},
+		// redis-3 is not scheduled
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-3", Namespace: "default"}},
+	)
+
+	priorities, err := GetRedisReplicationReplicaPriorities(context.TODO(), client, cr)
+	require.NoError(t, err)
+	assert.Nil(t, priorities)
+
+	cr.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{{Ordinals: []int32{2}, Priority: 10}}
+	priorities, err = GetRedisReplicationReplicaPriorities(context.TODO(), client, cr)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"redis-0": 100, "redis-1": 100, "redis-2": 10, "redis-3": 100}, priorities)
+
+	// the first rule selecting a pod applies
+	cr.Spec.ReplicaPriority = []rrvb2.ReplicaPriorityRule{
+		{NodeSelector: map[string]string{corev1.LabelTopologyZone: "dr"}, Priority: 0},
+		{Ordinals: []int32{1, 2}, Priority: 10},
+	}
+	priorities, err = GetRedisReplicationReplicaPriorities(context.TODO(), client, cr)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"redis-0": 100, "redis-1": 0, "redis-2": 10}, priorities)
+
+	// a node that cannot be read fails rather than leaving its pods out
+	client.PrependReactor("get", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	_, err = GetRedisReplicationReplicaPriorities(context.TODO(), client, cr)
+	assert.Error(t, err)
+}
+
+func TestPromotableReplicas(t *testing.T) {
+	replicas := []string{"redis-1", "redis-2", "redis-3"}
+	assert.Equal(t, replicas, PromotableReplicas(replicas, nil))
+	assert.Equal(t, []string{"redis-3", "redis-1"},
+		PromotableReplicas(replicas, map[string]int32{"redis-1": 100, "redis-2": 0, "redis-3": 10}))
+	// a pod whose priority is unknown is not promoted
+	assert.Equal(t, []string{"redis-2"}, PromotableReplicas(replicas, map[string]int32{"redis-2": 50}))
+	assert.Empty(t, PromotableReplicas(replicas, map[string]int32{}))
+	assert.Equal(t, replicas, []string{"redis-1", "redis-2", "redis-3"}, "the replicas are not reordered in place")
+}
+
+func TestPickBestMaster(t *testing.T) {
+	pods := []string{"redis-0", "redis-1", "redis-2"}
+	offsets := map[string]int64{"redis-0": 100, "redis-1": 300, "redis-2": 200}
+
+	assert.Equal(t, "redis-1", pickBestMaster(pods, offsets, nil))
+	// the priority comes before the offset
+	assert.Equal(t, "redis-2", pickBestMaster(pods, offsets, map[string]int32{"redis-0": 100, "redis-1": 100, "redis-2": 10}))
+	// a pod with priority 0 is never picked
+	assert.Equal(t, "redis-2", pickBestMaster(pods, offsets, map[string]int32{"redis-0": 100, "redis-1": 0, "redis-2": 100}))
+	assert.Empty(t, pickBestMaster(pods, offsets, map[string]int32{"redis-0": 0, "redis-1": 0, "redis-2": 0}))
+	// a pod whose offset or priority is unknown is left out
+	assert.Equal(t, "redis-0", pickBestMaster(pods, map[string]int64{"redis-0": 100}, map[string]int32{"redis-0": 100, "redis-1": 10}))
+	assert.Empty(t, pickBestMaster(pods, offsets, map[string]int32{"redis-1": 0}))
+}
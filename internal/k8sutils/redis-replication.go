@@ -8,6 +8,7 @@ import (
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/maps"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,7 +43,7 @@ func CreateReplicationService(ctx context.Context, cr *rrvb2.RedisReplication, c
 		labels, map[string]string{common.RedisRoleLabelKey: common.RedisRoleLabelSlave},
 	)
 	masterObjectMetaInfo := generateObjectMetaInformation(cr.MasterService(), cr.Namespace, masterLabels, annotations)
-	replicaObjectMetaInfo := generateObjectMetaInformation(cr.Name+"-replica", cr.Namespace, replicaLabels, annotations)
+	replicaObjectMetaInfo := generateObjectMetaInformation(cr.Name+"-replica", cr.Namespace, replicaLabels, generateServiceAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.GetReplicaServiceAnnotations(), epp))
 
 	if err := CreateOrUpdateService(ctx, cr.Namespace, headlessObjectMetaInfo, redisReplicationAsOwner(cr), disableMetrics, true, "ClusterIP", common.RedisPort, cl); err != nil {
 		log.FromContext(ctx).Error(err, "Cannot create replication headless service for Redis")
@@ -60,9 +61,11 @@ func CreateReplicationService(ctx context.Context, cr *rrvb2.RedisReplication, c
 		log.FromContext(ctx).Error(err, "Cannot create master service for Redis")
 		return err
 	}
-	if err := CreateOrUpdateService(ctx, cr.Namespace, replicaObjectMetaInfo, redisReplicationAsOwner(cr), disableMetrics, false, "ClusterIP", common.RedisPort, cl); err != nil {
-		log.FromContext(ctx).Error(err, "Cannot create replica service for Redis")
-		return err
+	if cr.Spec.KubernetesConfig.ShouldCreateReplicaService(true) {
+		if err := CreateOrUpdateService(ctx, cr.Namespace, replicaObjectMetaInfo, redisReplicationAsOwner(cr), disableMetrics, false, cr.Spec.KubernetesConfig.GetReplicaServiceType(), common.RedisPort, cl); err != nil {
+			log.FromContext(ctx).Error(err, "Cannot create replica service for Redis")
+			return err
+		}
 	}
 	if cr.Spec.RedisExporter != nil && cr.Spec.RedisExporter.Enabled {
 		exporterPort := *util.Coalesce(cr.Spec.RedisExporter.Port, ptr.To(common.RedisExporterPort))
@@ -76,12 +79,14 @@ func CreateReplicationService(ctx context.Context, cr *rrvb2.RedisReplication, c
 	return nil
 }
 
-// CreateReplicationRedis will create a replication redis setup
-func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl kubernetes.Interface) error {
+// CreateReplicationRedis will create a replication redis setup, the pods are seeded from the restore when it is set
+func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl kubernetes.Interface, restore *RestoreSource) error {
 	stateFulName := cr.Name
 	labels := getRedisLabels(cr.Name, replication, "replication", cr.Labels)
 	annotations := generateStatefulSetsAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.IgnoreAnnotations)
 	objectMetaInfo := generateObjectMetaInformation(stateFulName, cr.Namespace, labels, annotations)
+	initcontainerParams := generateRedisReplicationInitContainerParams(cr)
+	initcontainerParams.Restore = restore
 
 	err := CreateOrUpdateStateFul(
 		ctx,
@@ -90,7 +95,7 @@ func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl
 		objectMetaInfo,
 		generateRedisReplicationParams(cr),
 		redisReplicationAsOwner(cr),
-		generateRedisReplicationInitContainerParams(cr),
+		initcontainerParams,
 		generateRedisReplicationContainerParams(cr),
 		cr.Spec.Sidecars,
 	)
@@ -122,6 +127,12 @@ func generateRedisReplicationParams(cr *rrvb2.RedisReplication) statefulSetParam
 		PersistentVolumeClaimRetentionPolicy: cr.Spec.KubernetesConfig.PersistentVolumeClaimRetentionPolicy,
 		IgnoreAnnotations:                    cr.Spec.KubernetesConfig.IgnoreAnnotations,
 		MinReadySeconds:                      minreadyseconds,
+		ClusterVersion:                       cr.Spec.Engine.MajorVersion(cr.Spec.EngineVersion),
+	}
+
+	if cr.Spec.KubernetesConfig.UsesManagedFailover() {
+		// the operator restarts the pods itself, the master last
+		res.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
 	}
 
 	if cr.Spec.PodManagementPolicy != nil {
@@ -162,6 +173,7 @@ func generateRedisReplicationContainerParams(cr *rrvb2.RedisReplication) contain
 		SecurityContext: cr.Spec.SecurityContext,
 		Port:            ptr.To(common.RedisPort),
 		HostPort:        cr.Spec.HostPort,
+		Engine:          cr.Spec.Engine,
 	}
 	if cr.Spec.RedisConfig != nil {
 		containerProp.MaxMemoryPercentOfLimit = cr.Spec.RedisConfig.MaxMemoryPercentOfLimit
@@ -252,9 +264,10 @@ func generateRedisReplicationInitContainerParams(cr *rrvb2.RedisReplication) ini
 	return initcontainerProp
 }
 
-func IsRedisReplicationReady(ctx context.Context, client kubernetes.Interface, ctrlClient client.Client, rs *rsvb2.RedisSentinel) bool {
+// IsRedisReplicationReady reports whether the replication monitored by the sentinels runs a master
+func IsRedisReplicationReady(ctx context.Context, client kubernetes.Interface, ctrlClient client.Client, rs *rsvb2.RedisSentinel, replicationName string) bool {
 	// statefulset name the same as the redis replication name
-	sts, err := GetStatefulSet(ctx, client, rs.GetNamespace(), rs.Spec.RedisSentinelConfig.RedisReplicationName)
+	sts, err := GetStatefulSet(ctx, client, rs.GetNamespace(), replicationName)
 	if err != nil {
 		return false
 	}
@@ -270,7 +283,7 @@ func IsRedisReplicationReady(ctx context.Context, client kubernetes.Interface, c
 	// Enhanced check: When the pod is ready, it may not have been
 	// created as part of a replication cluster, so we should verify
 	// whether there is an actual master node.
-	if master := getRedisReplicationMasterIP(ctx, client, rs, ctrlClient); master == "" {
+	if master := getRedisReplicationMasterIP(ctx, client, rs, replicationName, ctrlClient); master == "" {
 		return false
 	}
 	return true
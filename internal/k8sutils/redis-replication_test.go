@@ -8,6 +8,7 @@ import (
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -105,6 +106,14 @@ func Test_generateRedisReplicationParams(t *testing.T) {
 	assert.EqualValues(t, expected, actual, "Expected %+v, got %+v", expected, actual)
 }
 
+func Test_generateRedisReplicationParamsManagedFailover(t *testing.T) {
+	cr := &rrvb2.RedisReplication{Spec: rrvb2.RedisReplicationSpec{Size: ptr.To(int32(3))}}
+	cr.Spec.KubernetesConfig.UpdateStrategy.Type = common.ManagedFailoverUpdateStrategyType
+
+	params := generateRedisReplicationParams(cr)
+	assert.Equal(t, appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}, params.UpdateStrategy)
+}
+
 func Test_generateRedisReplicationContainerParams(t *testing.T) {
 	path := filepath.Join("..", "..", "tests", "testdata", "redis-replication.yaml")
 	expected := containerParameters{
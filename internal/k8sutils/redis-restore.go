@@ -0,0 +1,290 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	common "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	restoreVolumeName = "restore-source"
+	restoreMountPath  = "/restore"
+	// restoreMarker is written to the data volume once a snapshot was loaded into it, so a
+	// restarted pod never overwrites data it accepted writes on since
+	restoreMarker = "/data/.restored"
+)
+
+// restoreScript copies the snapshot assigned to the pod into its data volume before redis
+// starts. The snapshot is also placed as the append only file when AOF is enabled, since
+// redis then ignores dump.rdb; an RDB preamble is a valid AOF and is upgraded to the base
+// file of the AOF directory by redis 7.
+const restoreScript = `set -e
+if [ -f "` + restoreMarker + `" ]; then
+    echo "data volume was already restored"
+    exit 0
+fi
+SNAPSHOT=""
+for entry in $RESTORE_SNAPSHOTS; do
+    if [ "${entry%%=*}" = "$POD_NAME" ]; then
+        SNAPSHOT="${entry#*=}"
+    fi
+done
+if [ -z "$SNAPSHOT" ]; then
+    echo "no snapshot to restore for $POD_NAME"
+    exit 0
+fi
+echo "restoring $SNAPSHOT"
+cp "$SNAPSHOT" /data/dump.rdb.tmp
+mv /data/dump.rdb.tmp /data/dump.rdb
+if [ ! -d /data/appendonlydir ]; then
+    cp /data/dump.rdb "/data/$RESTORE_AOF_FILENAME"
+fi
+touch "` + restoreMarker + `"
+`
+
+// RestoreSource describes the snapshots the pods of a statefulset are seeded with
+type RestoreSource struct {
+	// ClaimName is the claim the snapshots are stored on
+	ClaimName string
+	// Path is the directory inside the claim the snapshot locations are relative to
+	Path string
+	// Snapshots maps the pod names to the location of the snapshot they load
+	Snapshots map[string]string
+}
+
+// NewRestoreSource returns the restore source of a RedisRestore, or nil when the restore has
+// not resolved its snapshots yet
+func NewRestoreSource(restore *rbvb2.RedisRestore) *RestoreSource {
+	if restore == nil || restore.Status.Source == nil || len(restore.Status.Shards) == 0 {
+		return nil
+	}
+	source := &RestoreSource{
+		ClaimName: restore.Status.Source.ClaimName,
+		Path:      restore.Status.Source.Path,
+		Snapshots: make(map[string]string, len(restore.Status.Shards)),
+	}
+	for _, shard := range restore.Status.Shards {
+		source.Snapshots[shard.PodName] = shard.Location
+	}
+	return source
+}
+
+func (rs *RestoreSource) snapshotsEnv() string {
+	pods := make([]string, 0, len(rs.Snapshots))
+	for pod := range rs.Snapshots {
+		pods = append(pods, pod)
+	}
+	sort.Strings(pods)
+	entries := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		entries = append(entries, fmt.Sprintf("%s=%s", pod, path.Join(restoreMountPath, rs.Path, rs.Snapshots[pod])))
+	}
+	return strings.Join(entries, " ")
+}
+
+// HasVolumeClaimTemplate reports whether the storage provisions a data volume for every pod,
+// which is where restored snapshots are placed
+func HasVolumeClaimTemplate(storage *commonapi.Storage) bool {
+	return storageHasVolumeClaimTemplate(storage)
+}
+
+// generateRestoreInitContainer returns the init container that seeds the data volume of a pod
+func generateRestoreInitContainer(name string, restore *RestoreSource, containerParams containerParameters) corev1.Container {
+	aofFilename := "appendonly.aof"
+	if generatesConfigInInitContainer(containerParams.Engine) {
+		aofFilename = "Appendonly.aof"
+	}
+	return corev1.Container{
+		Name:            "restore-rdb",
+		Image:           containerParams.Image,
+		ImagePullPolicy: containerParams.ImagePullPolicy,
+		Command:         []string{"sh", "-c", restoreScript},
+		SecurityContext: containerParams.SecurityContext,
+		Env: []corev1.EnvVar{
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				},
+			},
+			{Name: "RESTORE_SNAPSHOTS", Value: restore.snapshotsEnv()},
+			{Name: "RESTORE_AOF_FILENAME", Value: aofFilename},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      util.CoalesceEnv1(common.EnvOperatorSTSPVCTemplateName, name),
+				MountPath: "/data",
+			},
+			{
+				Name:      restoreVolumeName,
+				MountPath: restoreMountPath,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
+
+func generateRestoreVolume(restore *RestoreSource) corev1.Volume {
+	return corev1.Volume{
+		Name: restoreVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: restore.ClaimName,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
+
+// RestoreRedisClusterSlots assembles a cluster from leaders seeded with the shards of a
+// backup. Every leader is assigned the slots its snapshot was taken from, which redis-cli
+// --cluster create cannot do as it refuses nodes holding data and splits the slots evenly,
+// and the leaders are then introduced to each other.
+func RestoreRedisClusterSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, slots map[string][]string) error {
+	return restoreRedisClusterSlots(ctx, cr, slots,
+		func(podName string) *redis.Client {
+			return configureRedisClient(ctx, client, cr, podName)
+		},
+		func(podName string) string {
+			return getRedisServerIP(ctx, client, RedisDetails{PodName: podName, Namespace: cr.Namespace})
+		},
+	)
+}
+
+func restoreRedisClusterSlots(ctx context.Context, cr *rcvb2.RedisCluster, slots map[string][]string, makeClient func(podName string) *redis.Client, podIP func(podName string) string) error {
+	logger := log.FromContext(ctx)
+	leaders := cr.Spec.GetReplicaCounts("leader")
+
+	clients := make([]*redis.Client, 0, leaders)
+	defer func() {
+		for _, redisClient := range clients {
+			redisClient.Close()
+		}
+	}()
+	for i := int32(0); i < leaders; i++ {
+		podName := fmt.Sprintf("%s-leader-%d", cr.Name, i)
+		wanted, err := parseSlotRanges(slots[podName])
+		if err != nil {
+			return fmt.Errorf("invalid slots of %s: %w", podName, err)
+		}
+		redisClient := makeClient(podName)
+		clients = append(clients, redisClient)
+		if err := assignSlots(ctx, redisClient, wanted, int64(i+1)); err != nil {
+			return fmt.Errorf("failed to assign slots to %s: %w", podName, err)
+		}
+		logger.V(1).Info("Assigned restored slots", "Pod", podName, "Slots", len(wanted))
+	}
+
+	for i := int32(1); i < leaders; i++ {
+		podName := fmt.Sprintf("%s-leader-%d", cr.Name, i)
+		ip := podIP(podName)
+		if ip == "" {
+			return fmt.Errorf("failed to get IP for pod %s", podName)
+		}
+		if err := clients[0].ClusterMeet(ctx, ip, strconv.Itoa(*cr.Spec.Port)).Err(); err != nil {
+			return fmt.Errorf("failed to meet %s: %w", podName, err)
+		}
+	}
+	return nil
+}
+
+// assignSlots adds the wanted slots the node does not own yet. A node that loaded keys claims
+// the slots of those keys by itself, adding them again would be rejected.
+func assignSlots(ctx context.Context, redisClient *redis.Client, wanted []int, configEpoch int64) error {
+	nodes, err := clusterNodes(ctx, redisClient)
+	if err != nil {
+		return err
+	}
+	owned := map[int]bool{}
+	for _, node := range nodes {
+		if len(node) < 3 || !hasFlag(node[2], "myself") {
+			continue
+		}
+		if len(node) > 8 {
+			ranges, err := parseSlotRanges(node[8:])
+			if err != nil {
+				return err
+			}
+			for _, slot := range ranges {
+				owned[slot] = true
+			}
+		}
+	}
+	missing := make([]int, 0, len(wanted))
+	for _, slot := range wanted {
+		if !owned[slot] {
+			missing = append(missing, slot)
+		}
+	}
+	if len(missing) > 0 {
+		if err := redisClient.ClusterAddSlots(ctx, missing...).Err(); err != nil {
+			return err
+		}
+	}
+	// distinct epochs let the leaders agree on slot ownership without a collision round, the
+	// command is refused once the node knows other nodes, which is fine on a retry
+	if err := redisClient.Do(ctx, "CLUSTER", "SET-CONFIG-EPOCH", configEpoch).Err(); err != nil {
+		log.FromContext(ctx).V(1).Info("Config epoch was not set", "Error", err.Error())
+	}
+	return nil
+}
+
+// parseSlotRanges expands slot ranges as reported by CLUSTER NODES, e.g. "0-5460" or "5461",
+// into the slots. Importing and migrating entries in brackets are skipped.
+func parseSlotRanges(ranges []string) ([]int, error) {
+	var slots []int
+	for _, r := range ranges {
+		if r == "" || strings.HasPrefix(r, "[") {
+			continue
+		}
+		first, last, isRange := strings.Cut(r, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot range %q", r)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid slot range %q", r)
+			}
+		}
+		if start < 0 || end >= 16384 || start > end {
+			return nil, fmt.Errorf("invalid slot range %q", r)
+		}
+		for slot := start; slot <= end; slot++ {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// WaitForRestore reports whether deploying a statefulset has to wait for the restore seeding it
+// to resolve its snapshots. A statefulset that is already deployed never waits, its pods can no
+// longer be seeded and the restore is rejected.
+func WaitForRestore(ctx context.Context, cl kubernetes.Interface, restore *rbvb2.RedisRestore, namespace, statefulSet string) (bool, error) {
+	if restore == nil || restore.Status.State == rbvb2.RedisRestoreRestoring || restore.Status.State == rbvb2.RedisRestoreCompleted {
+		return false, nil
+	}
+	_, err := GetStatefulSet(ctx, cl, namespace, statefulSet)
+	if err == nil {
+		return false, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
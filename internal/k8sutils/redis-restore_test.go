@@ -0,0 +1,141 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestParseSlotRanges(t *testing.T) {
+	slots, err := parseSlotRanges([]string{"0-2", "7", "[8->-abc]", "10-11"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 7, 10, 11}, slots)
+
+	for _, invalid := range []string{"a-3", "5-2", "16384", "0-x"} {
+		_, err := parseSlotRanges([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestNewRestoreSource(t *testing.T) {
+	assert.Nil(t, NewRestoreSource(nil))
+	assert.Nil(t, NewRestoreSource(&rbvb2.RedisRestore{}))
+
+	source := NewRestoreSource(&rbvb2.RedisRestore{
+		Status: rbvb2.RedisRestoreStatus{
+			Source: &rbvb2.PVCBackupStorage{ClaimName: "backups", Path: "/snapshots"},
+			Shards: []rbvb2.RedisRestoreShard{
+				{PodName: "cluster-leader-1", Location: "default/nightly/old-leader-2.rdb"},
+				{PodName: "cluster-leader-0", Location: "default/nightly/old-leader-0.rdb"},
+			},
+		},
+	})
+	require.NotNil(t, source)
+	assert.Equal(t, "backups", source.ClaimName)
+	assert.Equal(t,
+		"cluster-leader-0=/restore/snapshots/default/nightly/old-leader-0.rdb cluster-leader-1=/restore/snapshots/default/nightly/old-leader-2.rdb",
+		source.snapshotsEnv())
+}
+
+func TestGenerateStatefulSetsDefWithRestore(t *testing.T) {
+	restore := &RestoreSource{ClaimName: "backups", Snapshots: map[string]string{"cluster-leader-0": "a.rdb"}}
+
+	stsDef := generateStatefulSetsDef(
+		metav1.ObjectMeta{Name: "cluster-leader", Namespace: "default"},
+		statefulSetParameters{Replicas: ptr.To(int32(3))},
+		metav1.OwnerReference{},
+		initContainerParameters{Restore: restore},
+		containerParameters{Image: "redis:latest", PersistenceEnabled: ptr.To(true)},
+		nil,
+	)
+	initContainers := stsDef.Spec.Template.Spec.InitContainers
+	require.Len(t, initContainers, 1)
+	assert.Equal(t, "restore-rdb", initContainers[0].Name)
+	assert.Equal(t, "redis:latest", initContainers[0].Image)
+	assert.Equal(t, "cluster-leader", initContainers[0].VolumeMounts[0].Name)
+	assert.Equal(t, "/data", initContainers[0].VolumeMounts[0].MountPath)
+	assert.True(t, initContainers[0].VolumeMounts[1].ReadOnly)
+
+	var volume *string
+	for _, v := range stsDef.Spec.Template.Spec.Volumes {
+		if v.Name == restoreVolumeName {
+			volume = &v.PersistentVolumeClaim.ClaimName
+		}
+	}
+	require.NotNil(t, volume)
+	assert.Equal(t, "backups", *volume)
+
+	// pods without persistence have no data volume to seed
+	stsDef = generateStatefulSetsDef(
+		metav1.ObjectMeta{Name: "cluster-leader", Namespace: "default"},
+		statefulSetParameters{Replicas: ptr.To(int32(3))},
+		metav1.OwnerReference{},
+		initContainerParameters{Restore: restore},
+		containerParameters{Image: "redis:latest", PersistenceEnabled: ptr.To(false)},
+		nil,
+	)
+	assert.Empty(t, stsDef.Spec.Template.Spec.InitContainers)
+}
+
+func TestRestoreRedisClusterSlots(t *testing.T) {
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(2)), Port: ptr.To(6379)},
+	}
+	leader0, mock0 := redismock.NewClientMock()
+	leader1, mock1 := redismock.NewClientMock()
+
+	// leader-0 claimed the slots it loaded keys for, only the empty ones are added
+	mock0.ExpectClusterNodes().SetVal("id0 10.0.0.1:6379@16379 myself,master - 0 0 0 connected 0-1\n")
+	mock0.ExpectClusterAddSlots(2, 3).SetVal("OK")
+	mock0.ExpectDo("CLUSTER", "SET-CONFIG-EPOCH", int64(1)).SetVal("OK")
+	mock1.ExpectClusterNodes().SetVal("id1 10.0.0.2:6379@16379 myself,master - 0 0 0 connected 4\n")
+	mock1.ExpectClusterAddSlots(5).SetVal("OK")
+	mock1.ExpectDo("CLUSTER", "SET-CONFIG-EPOCH", int64(2)).SetVal("OK")
+	mock0.ExpectClusterMeet("10.0.0.2", "6379").SetVal("OK")
+
+	clients := map[string]*redis.Client{"cluster-leader-0": leader0, "cluster-leader-1": leader1}
+	err := restoreRedisClusterSlots(context.Background(), cr,
+		map[string][]string{"cluster-leader-0": {"0-3"}, "cluster-leader-1": {"4-5"}},
+		func(podName string) *redis.Client { return clients[podName] },
+		func(podName string) string { return "10.0.0.2" },
+	)
+	require.NoError(t, err)
+	assert.NoError(t, mock0.ExpectationsWereMet())
+	assert.NoError(t, mock1.ExpectationsWereMet())
+}
+
+func TestWaitForRestore(t *testing.T) {
+	ctx := context.Background()
+	pending := &rbvb2.RedisRestore{Status: rbvb2.RedisRestoreStatus{State: rbvb2.RedisRestorePending}}
+	restoring := &rbvb2.RedisRestore{Status: rbvb2.RedisRestoreStatus{State: rbvb2.RedisRestoreRestoring}}
+
+	cl := k8sClientFake.NewSimpleClientset()
+	wait, err := WaitForRestore(ctx, cl, nil, "default", "cluster-leader")
+	require.NoError(t, err)
+	assert.False(t, wait)
+
+	wait, err = WaitForRestore(ctx, cl, pending, "default", "cluster-leader")
+	require.NoError(t, err)
+	assert.True(t, wait)
+
+	wait, err = WaitForRestore(ctx, cl, restoring, "default", "cluster-leader")
+	require.NoError(t, err)
+	assert.False(t, wait)
+
+	// a deployed statefulset does not wait for a restore that can no longer seed it
+	cl = k8sClientFake.NewSimpleClientset(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader", Namespace: "default"}})
+	wait, err = WaitForRestore(ctx, cl, pending, "default", "cluster-leader")
+	require.NoError(t, err)
+	assert.False(t, wait)
+}
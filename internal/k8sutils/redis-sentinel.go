@@ -107,6 +107,7 @@ func generateRedisSentinelParams(ctx context.Context, cr *rsvb2.RedisSentinel, r
 		PersistentVolumeClaimRetentionPolicy: cr.Spec.KubernetesConfig.PersistentVolumeClaimRetentionPolicy,
 		IgnoreAnnotations:                    cr.Spec.KubernetesConfig.IgnoreAnnotations,
 		MinReadySeconds:                      minreadyseconds,
+		ClusterVersion:                       cr.Spec.Engine.MajorVersion(cr.Spec.EngineVersion),
 	}
 
 	if cr.Spec.PodManagementPolicy != nil {
@@ -168,6 +169,7 @@ func generateRedisSentinelContainerParams(ctx context.Context, client kubernetes
 		Port:                  ptr.To(common.SentinelPort),
 		HostPort:              cr.Spec.HostPort,
 		AdditionalEnvVariable: getSentinelEnvVariable(cr),
+		Engine:                cr.Spec.Engine,
 	}
 	if cr.Spec.EnvVars != nil {
 		containerProp.EnvVars = cr.Spec.EnvVars
@@ -319,8 +321,7 @@ func getSentinelEnvVariable(cr *rsvb2.RedisSentinel) *[]corev1.EnvVar {
 	return envVar
 }
 
-func getRedisReplicationMasterPod(ctx context.Context, client kubernetes.Interface, cr *rsvb2.RedisSentinel, ctrlClient client.Client) RedisDetails {
-	replicationName := cr.Spec.RedisSentinelConfig.RedisReplicationName
+func getRedisReplicationMasterPod(ctx context.Context, client kubernetes.Interface, cr *rsvb2.RedisSentinel, replicationName string, ctrlClient client.Client) RedisDetails {
 	replicationNamespace := cr.Namespace
 
 	var replicationInstance rrvb2.RedisReplication
@@ -380,15 +381,23 @@ func getRedisReplicationMasterPod(ctx context.Context, client kubernetes.Interfa
 		}
 		// Fallback 2: use master based on Redis offset (best-effort)
 		if realMasterPod == "" {
-			bestMaster := GetRedisReplicationBestMaster(ctx, client, &replicationInstance, masterPods)
+			bestMaster, err := GetRedisReplicationBestMaster(ctx, client, &replicationInstance, masterPods)
+			if err != nil {
+				// no master is elected from the priorities of some pods only
+				log.FromContext(ctx).Error(err, "Failed to read the replica priorities of the RedisReplication", "replication name", replicationName)
+				return emptyRedisInfo
+			}
 			if bestMaster != "" {
 				log.FromContext(ctx).Info("No valid Status.MasterNode, falling back to best master based on Redis offset",
 					"bestMaster", bestMaster)
 				realMasterPod = bestMaster
 			}
 		}
-		// Fallback 3: use first master pod as last resort
-		if realMasterPod == "" && len(masterPods) > 0 {
+		// Fallback 3: use first master pod as last resort, unless it may never be promoted
+		if realMasterPod == "" && len(replicationInstance.Spec.ReplicaPriority) > 0 {
+			log.FromContext(ctx).Info("No master pod may be promoted with the replica priorities of the RedisReplication",
+				"replication name", replicationName)
+		} else if realMasterPod == "" && len(masterPods) > 0 {
 			log.FromContext(ctx).Info("No valid Status.MasterNode, falling back to first master pod",
 				"masterPod", masterPods[0])
 			realMasterPod = masterPods[0]
@@ -406,8 +415,8 @@ func getRedisReplicationMasterPod(ctx context.Context, client kubernetes.Interfa
 	}
 }
 
-func getRedisReplicationMasterIP(ctx context.Context, client kubernetes.Interface, cr *rsvb2.RedisSentinel, ctrlClient client.Client) string {
-	RedisDetails := getRedisReplicationMasterPod(ctx, client, cr, ctrlClient)
+func getRedisReplicationMasterIP(ctx context.Context, client kubernetes.Interface, cr *rsvb2.RedisSentinel, replicationName string, ctrlClient client.Client) string {
+	RedisDetails := getRedisReplicationMasterPod(ctx, client, cr, replicationName, ctrlClient)
 	if RedisDetails.PodName == "" || RedisDetails.Namespace == "" {
 		return ""
 	} else {
@@ -124,6 +124,7 @@ func generateRedisStandaloneParams(cr *rvb2.Redis) statefulSetParameters {
 		PersistentVolumeClaimRetentionPolicy: cr.Spec.KubernetesConfig.PersistentVolumeClaimRetentionPolicy,
 		IgnoreAnnotations:                    cr.Spec.KubernetesConfig.IgnoreAnnotations,
 		MinReadySeconds:                      minreadyseconds,
+		ClusterVersion:                       cr.Spec.Engine.MajorVersion(cr.Spec.EngineVersion),
 	}
 	if cr.Spec.KubernetesConfig.ImagePullSecrets != nil {
 		res.ImagePullSecrets = cr.Spec.KubernetesConfig.ImagePullSecrets
@@ -159,6 +160,7 @@ func generateRedisStandaloneContainerParams(cr *rvb2.Redis) containerParameters
 		SecurityContext: cr.Spec.SecurityContext,
 		Port:            ptr.To(common.RedisPort),
 		HostPort:        cr.Spec.HostPort,
+		Engine:          cr.Spec.Engine,
 	}
 	if cr.Spec.RedisConfig != nil {
 		containerProp.MaxMemoryPercentOfLimit = cr.Spec.RedisConfig.MaxMemoryPercentOfLimit
@@ -0,0 +1,291 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	redis "github.com/redis/go-redis/v9"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// switchoverTimeout bounds how long writes are paused on the master for the target to catch up
+	switchoverTimeout      = 10 * time.Second
+	switchoverPollInterval = 100 * time.Millisecond
+	// sentinelSwitchoverTimeout bounds how long the sentinel takes to read the replica priorities,
+	// it reads the INFO of the replicas every 10 seconds, and to promote the target
+	sentinelSwitchoverTimeout = 30 * time.Second
+	sentinelPollInterval      = 500 * time.Millisecond
+)
+
+// Sentinel is a sentinel monitoring the master group of a replication
+type Sentinel interface {
+	SentinelFailover(ctx context.Context, masterGroupName string) error
+	SentinelGetMasterAddrByName(ctx context.Context, masterGroupName string) (string, string, error)
+	SentinelReplicaPriorities(ctx context.Context, masterGroupName string) (map[string]int, error)
+}
+
+// SwitchoverRedisReplicationMaster hands the master role to the replica target without losing
+// writes: writes are paused on the master until the target reached its replication offset, the
+// target is promoted and the other pods, the previous master first, replicate from it.
+func SwitchoverRedisReplicationMaster(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, master, target string, replicas []string) error {
+	masterClient := configureRedisReplicationClient(ctx, client, cr, master)
+	defer masterClient.Close()
+	targetClient := configureRedisReplicationClient(ctx, client, cr, target)
+	defer targetClient.Close()
+
+	if err := promoteRedisReplica(ctx, masterClient, targetClient, master, target, switchoverTimeout); err != nil {
+		return err
+	}
+	// writes still paused on the previous master fail with READONLY once it replicates from the target
+	defer unpauseRedisWrites(ctx, masterClient, master)
+
+	pods := []string{master}
+	for _, pod := range replicas {
+		if pod != master && pod != target {
+			pods = append(pods, pod)
+		}
+	}
+	return CreateMasterSlaveReplication(ctx, client, cr, pods, target)
+}
+
+// SwitchoverRedisReplicationMasterWithSentinel hands the master role to the replica target through
+// the sentinels, which would fail a master promoted behind their back over again. The other
+// replicas are given replica-priority 0 so the target is the only replica the sentinel may elect,
+// writes are paused on the master until the target reached its replication offset and SENTINEL
+// FAILOVER promotes it. The replica priorities are restored once the sentinel reports the target
+// as the master.
+func SwitchoverRedisReplicationMasterWithSentinel(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, sentinel Sentinel, masterGroupName, master, target string, replicas []string) error {
+	hosts := sentinelHosts{}
+	for _, podName := range replicas {
+		pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get the pod %s: %w", podName, err)
+		}
+		hosts[podName] = pod.Status.PodIP
+	}
+
+	configured, err := GetRedisReplicationReplicaPriorities(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	failoverPriorities := map[string]int32{}
+	for podName := range hosts {
+		if podName != master {
+			failoverPriorities[podName] = 0
+		}
+	}
+	failoverPriorities[target] = replicaPriority(configured, target)
+	if failoverPriorities[target] == 0 {
+		failoverPriorities[target] = rrvb2.DefaultReplicaPriority
+	}
+	if err := setRedisReplicationReplicaPriorities(ctx, client, cr, failoverPriorities); err != nil {
+		return err
+	}
+	defer func() {
+		// a pod whose priority is unknown is left out of the elections until the next reconcile sets it
+		restored := map[string]int32{}
+		for podName := range hosts {
+			if _, found := configured[podName]; found || configured == nil {
+				restored[podName] = replicaPriority(configured, podName)
+			}
+		}
+		if err := setRedisReplicationReplicaPriorities(ctx, client, cr, restored); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to restore the replica priorities after the switchover")
+		}
+	}()
+	if err := waitForSentinelReplicaPriorities(ctx, sentinel, masterGroupName, hosts, failoverPriorities, sentinelSwitchoverTimeout); err != nil {
+		return err
+	}
+
+	masterClient := configureRedisReplicationClient(ctx, client, cr, master)
+	defer masterClient.Close()
+	targetClient := configureRedisReplicationClient(ctx, client, cr, target)
+	defer targetClient.Close()
+	return failoverRedisReplicaWithSentinel(ctx, masterClient, targetClient, sentinel, masterGroupName, hosts, master, target, switchoverTimeout, sentinelSwitchoverTimeout)
+}
+
+// sentinelHosts are the IPs of the pods by pod name, the sentinels report a pod by its IP or by
+// its hostname when they resolve hostnames
+type sentinelHosts map[string]string
+
+func (h sentinelHosts) pod(host string) string {
+	for podName, ip := range h {
+		if host == ip || host == podName || strings.HasPrefix(host, podName+".") {
+			return podName
+		}
+	}
+	return ""
+}
+
+// waitForSentinelReplicaPriorities waits for the sentinel to read the replica-priority of every
+// replica, it may elect a replica it still believes to be promotable otherwise
+func waitForSentinelReplicaPriorities(ctx context.Context, sentinel Sentinel, masterGroupName string, hosts sentinelHosts, priorities map[string]int32, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		known, err := sentinel.SentinelReplicaPriorities(ctx, masterGroupName)
+		if err != nil {
+			return fmt.Errorf("failed to get the replicas of %s from the sentinel: %w", masterGroupName, err)
+		}
+		var pending []string
+		for podName, priority := range priorities {
+			found := false
+			for host, knownPriority := range known {
+				if hosts.pod(host) == podName {
+					found = (knownPriority == 0) == (priority == 0)
+					break
+				}
+			}
+			if !found {
+				pending = append(pending, podName)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			slices.Sort(pending)
+			return fmt.Errorf("the sentinel did not read the replica priorities of %s within %s", strings.Join(pending, ", "), timeout)
+		}
+		time.Sleep(sentinelPollInterval)
+	}
+}
+
+// failoverRedisReplicaWithSentinel pauses the writes on the master until the target caught up and
+// has the sentinel fail the master over, the writes are resumed once the sentinel reports the
+// target as the master or the failover failed
+func failoverRedisReplicaWithSentinel(ctx context.Context, masterClient, targetClient *redis.Client, sentinel Sentinel, masterGroupName string, hosts sentinelHosts, master, target string, timeout, failoverTimeout time.Duration) error {
+	logger := log.FromContext(ctx)
+	if err := pauseUntilCaughtUp(ctx, masterClient, targetClient, master, target, timeout+failoverTimeout, timeout); err != nil {
+		return err
+	}
+	// writes still paused on the previous master fail with READONLY once it replicates from the target
+	defer unpauseRedisWrites(ctx, masterClient, master)
+
+	if err := sentinel.SentinelFailover(ctx, masterGroupName); err != nil {
+		return fmt.Errorf("failed to fail %s over to %s: %w", masterGroupName, target, err)
+	}
+	deadline := time.Now().Add(failoverTimeout)
+	for {
+		host, _, err := sentinel.SentinelGetMasterAddrByName(ctx, masterGroupName)
+		if err != nil {
+			return fmt.Errorf("failed to get the master of %s from the sentinel: %w", masterGroupName, err)
+		}
+		switch hosts.pod(host) {
+		case target:
+			logger.Info("Sentinel promoted the switchover target to master", "target", target)
+			return nil
+		case master:
+		default:
+			return fmt.Errorf("the sentinel promoted %s instead of %s", host, target)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the sentinel did not promote %s within %s", target, failoverTimeout)
+		}
+		time.Sleep(sentinelPollInterval)
+	}
+}
+
+// promoteRedisReplica pauses the writes on the master and promotes the target once it caught up,
+// the writes are resumed when the target cannot be promoted
+func promoteRedisReplica(ctx context.Context, masterClient, targetClient *redis.Client, master, target string, timeout time.Duration) error {
+	if err := pauseUntilCaughtUp(ctx, masterClient, targetClient, master, target, timeout, timeout); err != nil {
+		return err
+	}
+	if err := targetClient.SlaveOf(ctx, "NO", "ONE").Err(); err != nil {
+		unpauseRedisWrites(ctx, masterClient, master)
+		return fmt.Errorf("failed to promote %s: %w", target, err)
+	}
+	log.FromContext(ctx).Info("Promoted switchover target to master", "target", target)
+	return nil
+}
+
+// pauseUntilCaughtUp pauses the writes on the master for pause and waits up to timeout for the
+// target to reach its replication offset, the writes are resumed when it does not
+func pauseUntilCaughtUp(ctx context.Context, masterClient, targetClient *redis.Client, master, target string, pause, timeout time.Duration) error {
+	if err := masterClient.Do(ctx, "CLIENT", "PAUSE", pause.Milliseconds(), "WRITE").Err(); err != nil {
+		return fmt.Errorf("failed to pause writes on %s: %w", master, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		masterOffset, err := checkRedisOffset(ctx, masterClient, master)
+		if err != nil {
+			unpauseRedisWrites(ctx, masterClient, master)
+			return err
+		}
+		targetOffset, err := checkRedisOffset(ctx, targetClient, target)
+		if err != nil {
+			unpauseRedisWrites(ctx, masterClient, master)
+			return err
+		}
+		if targetOffset >= masterOffset {
+			log.FromContext(ctx).Info("Switchover target caught up with the master", "master", master, "target", target, "offset", masterOffset)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			unpauseRedisWrites(ctx, masterClient, master)
+			return fmt.Errorf("%s did not reach the replication offset %d of %s within %s, it is at %d", target, masterOffset, master, timeout, targetOffset)
+		}
+		time.Sleep(switchoverPollInterval)
+	}
+}
+
+func unpauseRedisWrites(ctx context.Context, redisClient *redis.Client, podName string) {
+	if err := redisClient.ClientUnpause(ctx).Err(); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to resume writes", "pod", podName)
+	}
+}
+
+// RedisReplicationReplicasInSync reports whether every replica has its link to the master up and
+// reached the replication offset the master had when the replicas were checked
+func RedisReplicationReplicasInSync(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, master string, replicas []string) (bool, error) {
+	masterClient := configureRedisReplicationClient(ctx, client, cr, master)
+	defer masterClient.Close()
+	return replicasInSync(ctx, masterClient, master, replicas, func(podName string) *redis.Client {
+		return configureRedisReplicationClient(ctx, client, cr, podName)
+	})
+}
+
+func replicasInSync(ctx context.Context, masterClient *redis.Client, master string, replicas []string, makeClient func(podName string) *redis.Client) (bool, error) {
+	// the master offset is read first, a replica in sync reaches it by the time it is read
+	masterOffset, err := checkRedisOffset(ctx, masterClient, master)
+	if err != nil {
+		return false, err
+	}
+	for _, replica := range replicas {
+		replicaClient := makeClient(replica)
+		info, err := replicaClient.Info(ctx, "Replication").Result()
+		replicaClient.Close()
+		if err != nil {
+			return false, fmt.Errorf("failed to get the replication info of %s: %w", replica, err)
+		}
+		offset, found := replicaOffset(info)
+		if !found || offset < masterOffset {
+			log.FromContext(ctx).Info("Replica is not in sync with the master", "replica", replica, "master", master, "offset", offset, "masterOffset", masterOffset)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// replicaOffset returns the replication offset of an INFO Replication output, found only for a
+// replica whose link to its master is up
+func replicaOffset(info string) (int64, bool) {
+	if !strings.Contains(info, "role:slave") || !strings.Contains(info, "master_link_status:up") {
+		return 0, false
+	}
+	for _, line := range strings.Split(info, "\r\n") {
+		if value, found := strings.CutPrefix(line, "master_repl_offset:"); found {
+			offset, err := strconv.ParseInt(value, 10, 64)
+			return offset, err == nil
+		}
+	}
+	return 0, false
+}
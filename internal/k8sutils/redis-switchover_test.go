@@ -0,0 +1,207 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoteRedisReplica(t *testing.T) {
+	ctx := context.Background()
+	timeout := 10 * time.Second
+	masterClient, masterMock := redismock.NewClientMock()
+	targetClient, targetMock := redismock.NewClientMock()
+
+	// the target is promoted once it caught up with the paused master
+	masterMock.ExpectDo("CLIENT", "PAUSE", timeout.Milliseconds(), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:150\r\n")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectSlaveOf("NO", "ONE").SetVal("OK")
+
+	assert.NoError(t, promoteRedisReplica(ctx, masterClient, targetClient, "redis-0", "redis-1", timeout))
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+}
+
+func TestPromoteRedisReplicaResumesWritesOnFailure(t *testing.T) {
+	ctx := context.Background()
+	masterClient, masterMock := redismock.NewClientMock()
+	targetClient, targetMock := redismock.NewClientMock()
+
+	// a target that does not catch up is not promoted
+	masterMock.ExpectDo("CLIENT", "PAUSE", int64(0), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:150\r\n")
+	masterMock.ExpectClientUnpause().SetVal(true)
+
+	assert.Error(t, promoteRedisReplica(ctx, masterClient, targetClient, "redis-0", "redis-1", 0))
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+
+	masterMock.ExpectDo("CLIENT", "PAUSE", int64(0), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectSlaveOf("NO", "ONE").SetErr(errors.New("READONLY"))
+	masterMock.ExpectClientUnpause().SetVal(true)
+
+	assert.Error(t, promoteRedisReplica(ctx, masterClient, targetClient, "redis-0", "redis-1", 0))
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+}
+
+func TestReplicasInSync(t *testing.T) {
+	ctx := context.Background()
+	masterInfo := "# Replication\r\nrole:master\r\nconnected_slaves:2\r\nmaster_repl_offset:200\r\n"
+	tests := []struct {
+		name     string
+		replicas map[string]string
+		expected bool
+	}{
+		{
+			name: "caught up",
+			replicas: map[string]string{
+				"redis-1": "# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_repl_offset:200\r\n",
+				"redis-2": "# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_repl_offset:210\r\n",
+			},
+			expected: true,
+		},
+		{
+			name: "lagging replica",
+			replicas: map[string]string{
+				"redis-1": "# Replication\r\nrole:slave\r\nmaster_link_status:up\r\nmaster_repl_offset:150\r\n",
+			},
+		},
+		{
+			name: "link down",
+			replicas: map[string]string{
+				"redis-1": "# Replication\r\nrole:slave\r\nmaster_link_status:down\r\nmaster_repl_offset:200\r\n",
+			},
+		},
+		{
+			name: "restarted replica not replicating yet",
+			replicas: map[string]string{
+				"redis-1": "# Replication\r\nrole:master\r\nconnected_slaves:0\r\nmaster_repl_offset:0\r\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masterClient, masterMock := redismock.NewClientMock()
+			masterMock.ExpectInfo("Replication").SetVal(masterInfo)
+			var replicas []string
+			for replica := range tt.replicas {
+				replicas = append(replicas, replica)
+			}
+			sort.Strings(replicas)
+
+			inSync, err := replicasInSync(ctx, masterClient, "redis-0", replicas, func(podName string) *redis.Client {
+				replicaClient, replicaMock := redismock.NewClientMock()
+				replicaMock.ExpectInfo("Replication").SetVal(tt.replicas[podName])
+				return replicaClient
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, inSync)
+			assert.NoError(t, masterMock.ExpectationsWereMet())
+		})
+	}
+}
+
+type fakeSentinel struct {
+	// priorities and masters are the successive replies of the sentinel, the last one repeats
+	priorities  []map[string]int
+	masters     []string
+	failoverErr error
+	failovers   int
+}
+
+func (f *fakeSentinel) SentinelFailover(context.Context, string) error {
+	f.failovers++
+	return f.failoverErr
+}
+
+func (f *fakeSentinel) SentinelGetMasterAddrByName(context.Context, string) (string, string, error) {
+	master := f.masters[0]
+	if len(f.masters) > 1 {
+		f.masters = f.masters[1:]
+	}
+	return master, "6379", nil
+}
+
+func (f *fakeSentinel) SentinelReplicaPriorities(context.Context, string) (map[string]int, error) {
+	priorities := f.priorities[0]
+	if len(f.priorities) > 1 {
+		f.priorities = f.priorities[1:]
+	}
+	return priorities, nil
+}
+
+func TestSentinelHosts(t *testing.T) {
+	hosts := sentinelHosts{"redis-0": "10.0.0.1", "redis-1": "10.0.0.2"}
+	assert.Equal(t, "redis-1", hosts.pod("10.0.0.2"))
+	assert.Equal(t, "redis-0", hosts.pod("redis-0.redis-headless.default.svc.cluster.local"))
+	assert.Empty(t, hosts.pod("10.0.0.3"))
+}
+
+func TestWaitForSentinelReplicaPriorities(t *testing.T) {
+	ctx := context.Background()
+	hosts := sentinelHosts{"redis-0": "10.0.0.1", "redis-1": "10.0.0.2", "redis-2": "10.0.0.3"}
+	priorities := map[string]int32{"redis-1": 100, "redis-2": 0}
+
+	// the sentinel still believes redis-2 to be promotable on its first reply
+	sentinel := &fakeSentinel{priorities: []map[string]int{
+		{"10.0.0.2": 100, "10.0.0.3": 100},
+		{"10.0.0.2": 10, "10.0.0.3": 0},
+	}}
+	assert.NoError(t, waitForSentinelReplicaPriorities(ctx, sentinel, "mymaster", hosts, priorities, time.Second))
+
+	sentinel = &fakeSentinel{priorities: []map[string]int{{"10.0.0.2": 100}}}
+	assert.ErrorContains(t, waitForSentinelReplicaPriorities(ctx, sentinel, "mymaster", hosts, priorities, 0), "redis-2")
+}
+
+func TestFailoverRedisReplicaWithSentinel(t *testing.T) {
+	ctx := context.Background()
+	timeout := 10 * time.Second
+	hosts := sentinelHosts{"redis-0": "10.0.0.1", "redis-1": "10.0.0.2", "redis-2": "10.0.0.3"}
+	masterClient, masterMock := redismock.NewClientMock()
+	targetClient, targetMock := redismock.NewClientMock()
+
+	// the writes are paused until the sentinel reports the target as the master
+	masterMock.ExpectDo("CLIENT", "PAUSE", (2 * timeout).Milliseconds(), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:200\r\n")
+	masterMock.ExpectClientUnpause().SetVal(true)
+	sentinel := &fakeSentinel{masters: []string{"10.0.0.1", "10.0.0.2"}}
+
+	assert.NoError(t, failoverRedisReplicaWithSentinel(ctx, masterClient, targetClient, sentinel, "mymaster", hosts, "redis-0", "redis-1", timeout, timeout))
+	assert.Equal(t, 1, sentinel.failovers)
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+	assert.NoError(t, targetMock.ExpectationsWereMet())
+
+	// the sentinel electing another replica fails the switchover
+	masterMock.ExpectDo("CLIENT", "PAUSE", (2 * timeout).Milliseconds(), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:200\r\n")
+	masterMock.ExpectClientUnpause().SetVal(true)
+	sentinel = &fakeSentinel{masters: []string{"10.0.0.3"}}
+
+	assert.ErrorContains(t, failoverRedisReplicaWithSentinel(ctx, masterClient, targetClient, sentinel, "mymaster", hosts, "redis-0", "redis-1", timeout, timeout), "instead of redis-1")
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+
+	// the sentinel refusing the failover resumes the writes
+	masterMock.ExpectDo("CLIENT", "PAUSE", (2 * timeout).Milliseconds(), "WRITE").SetVal("OK")
+	masterMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:master\r\nmaster_repl_offset:200\r\n")
+	targetMock.ExpectInfo("Replication").SetVal("# Replication\r\nrole:slave\r\nmaster_repl_offset:200\r\n")
+	masterMock.ExpectClientUnpause().SetVal(true)
+	sentinel = &fakeSentinel{failoverErr: errors.New("NOGOODSLAVE No suitable replica to promote")}
+
+	assert.ErrorContains(t, failoverRedisReplicaWithSentinel(ctx, masterClient, targetClient, sentinel, "mymaster", hosts, "redis-0", "redis-1", timeout, timeout), "NOGOODSLAVE")
+	assert.NoError(t, masterMock.ExpectationsWereMet())
+}
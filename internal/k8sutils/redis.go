@@ -81,7 +81,7 @@ func getEndpoint(ctx context.Context, client kubernetes.Interface, cr *rcvb2.Red
 		port int
 	)
 	port = *cr.Spec.Port
-	if cr.Spec.ClusterVersion != nil && *cr.Spec.ClusterVersion == "v7" {
+	if cr.Spec.AnnouncesHostnames() {
 		host = rd.FQDN()
 	} else {
 		host = getRedisServerIP(ctx, client, rd)
@@ -89,7 +89,7 @@ func getEndpoint(ctx context.Context, client kubernetes.Interface, cr *rcvb2.Red
 			return ""
 		}
 	}
-	if cr.Spec.KubernetesConfig.GetServiceType() == "NodePort" {
+	if cr.Spec.GetExternalServiceType() == corev1.ServiceTypeNodePort {
 		svc, err := getService(ctx, client, cr.Namespace, rd.PodName)
 		if err != nil {
 			log.FromContext(ctx).Error(err, "Failed to get service for redis pod", "Pod", rd.PodName)
@@ -142,8 +142,8 @@ func executeSingleLeaderAddSlots(ctx context.Context, client kubernetes.Interfac
 
 	// Redis 7+ supports ADDSLOTSRANGE which takes a start-end pair instead
 	// of listing every slot number individually — avoids the URL length issue entirely.
-	if cr.Spec.ClusterVersion != nil && *cr.Spec.ClusterVersion == "v7" {
-		cmd := []string{"redis-cli"}
+	if cr.Spec.AnnouncesHostnames() {
+		cmd := []string{cr.Spec.Engine.CLI()}
 		cmd = append(cmd, flags...)
 		cmd = append(cmd, "CLUSTER", "ADDSLOTSRANGE", "0", "16383")
 		logger.V(1).Info("Executing CLUSTER ADDSLOTSRANGE 0 16383")
@@ -158,7 +158,7 @@ func executeSingleLeaderAddSlots(ctx context.Context, client kubernetes.Interfac
 	const batchSize = 1000
 	for start := 0; start < totalSlots; start += batchSize {
 		end := min(start+batchSize, totalSlots)
-		cmd := []string{"redis-cli"}
+		cmd := []string{cr.Spec.Engine.CLI()}
 		cmd = append(cmd, flags...)
 		cmd = append(cmd, "CLUSTER", "ADDSLOTS")
 		for i := start; i < end; i++ {
@@ -323,7 +323,7 @@ func getHostFromClusterNode(node clusterNodesResponse) (string, error) {
 // CreateMultipleLeaderRedisCommand will create command for single leader cluster creation
 func CreateMultipleLeaderRedisCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) RedisInvocation {
 	cmd := RedisInvocation{
-		Command: []string{"redis-cli", "--cluster", "create"},
+		Command: []string{cr.Spec.Engine.CLI(), "--cluster", "create"},
 	}
 	replicas := cr.Spec.GetReplicaCounts("leader")
 	for podCount := 0; podCount < int(replicas); podCount++ {
@@ -398,7 +398,7 @@ func getRedisTLSArgs(tlsConfig *commonapi.TLSConfig, clientHost string) []string
 
 // createRedisReplicationCommand will create redis replication creation command
 func createRedisReplicationCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, leaderPod RedisDetails, followerPod RedisDetails) []string {
-	cmd := []string{"redis-cli", "--cluster", "add-node"}
+	cmd := []string{cr.Spec.Engine.CLI(), "--cluster", "add-node"}
 	cmd = append(cmd, getEndpoint(ctx, client, cr, followerPod))
 	cmd = append(cmd, getEndpoint(ctx, client, cr, leaderPod))
 	cmd = append(cmd, "--cluster-slave")
@@ -414,13 +414,9 @@ func createRedisReplicationCommand(ctx context.Context, client kubernetes.Interf
 	return cmd
 }
 
-// ExecuteRedisReplicationCommand will execute the replication command
+// ExecuteRedisReplicationCommand will execute the replication command. The followers that did not
+// join the cluster are added as replicas of the masters planned by planFollowerAttachments.
 func ExecuteRedisReplicationCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	var podIP string
-	followerCounts := cr.Spec.GetReplicaCounts("follower")
-	leaderCounts := cr.Spec.GetReplicaCounts("leader")
-	followerPerLeader := followerCounts / leaderCounts
-
 	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
 	defer redisClient.Close()
 
@@ -428,37 +424,42 @@ func ExecuteRedisReplicationCommand(ctx context.Context, client kubernetes.Inter
 	if err != nil {
 		log.FromContext(ctx).Error(err, "failed to get cluster nodes")
 	}
-	for followerIdx := 0; followerIdx <= int(followerCounts)-1; {
-		for i := 0; i < int(followerPerLeader) && followerIdx <= int(followerCounts)-1; i++ {
-			followerPod := RedisDetails{
-				PodName:   cr.Name + "-follower-" + strconv.Itoa(followerIdx),
-				Namespace: cr.Namespace,
-			}
-			leaderPod := RedisDetails{
-				PodName:   cr.Name + "-leader-" + strconv.Itoa((followerIdx)%int(leaderCounts)),
-				Namespace: cr.Namespace,
-			}
-			podIP = getRedisServerIP(ctx, client, followerPod)
-			if !checkRedisNodePresence(ctx, nodes, podIP) {
-				log.FromContext(ctx).V(1).Info("Adding node to cluster.", "Node.IP", podIP, "Follower.Pod", followerPod)
-				cmd := createRedisReplicationCommand(ctx, client, cr, leaderPod, followerPod)
-				redisClient := configureRedisClient(ctx, client, cr, followerPod.PodName)
-				pong, err := redisClient.Ping(ctx).Result()
-				redisClient.Close()
-				if err != nil {
-					log.FromContext(ctx).Error(err, "Failed to ping Redis server", "Follower.Pod", followerPod)
-					continue
-				}
-				if pong == "PONG" {
-					executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
-				} else {
-					log.FromContext(ctx).V(1).Info("Skipping execution of command due to failed Redis ping", "Follower.Pod", followerPod)
-				}
-			} else {
-				log.FromContext(ctx).V(1).Info("Skipping Adding node to cluster, already present.", "Follower.Pod", followerPod)
+	masters, replicas := clusterShards(cr, nodes, getClusterPodPlacements(ctx, client, cr))
+	plan := planFollowerAttachments(masters, replicas)
+	for _, replica := range replicas {
+		followerPod := RedisDetails{
+			PodName:   replica.PodName,
+			Namespace: cr.Namespace,
+		}
+		if replica.NodeID != "" {
+			log.FromContext(ctx).V(1).Info("Skipping Adding node to cluster, already present.", "Follower.Pod", followerPod)
+			continue
+		}
+		masterID, planned := plan[replica.PodName]
+		if !planned {
+			log.FromContext(ctx).V(1).Info("Skipping Adding node to cluster, no master to replicate.", "Follower.Pod", followerPod)
+			continue
+		}
+		var leaderPod RedisDetails
+		for _, master := range masters {
+			if master.NodeID == masterID {
+				leaderPod = RedisDetails{PodName: master.PodName, Namespace: cr.Namespace}
 			}
-
-			followerIdx++
+		}
+		log.FromContext(ctx).V(1).Info("Adding node to cluster.", "Follower.Pod", followerPod, "Master.Pod", leaderPod)
+		cmd := createRedisReplicationCommand(ctx, client, cr, leaderPod, followerPod)
+		cmd = append(cmd, "--cluster-master-id", masterID)
+		redisClient := configureRedisClient(ctx, client, cr, followerPod.PodName)
+		pong, err := redisClient.Ping(ctx).Result()
+		redisClient.Close()
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to ping Redis server", "Follower.Pod", followerPod)
+			continue
+		}
+		if pong == "PONG" {
+			executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
+		} else {
+			log.FromContext(ctx).V(1).Info("Skipping execution of command due to failed Redis ping", "Follower.Pod", followerPod)
 		}
 	}
 }
@@ -613,7 +614,7 @@ func RedisClusterStatusHealth(ctx context.Context, client kubernetes.Interface,
 func checkClusterHealth(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) error {
 	logger := log.FromContext(ctx)
 
-	cmd := []string{"redis-cli", "--cluster", "check", fmt.Sprintf("127.0.0.1:%d", *cr.Spec.Port)}
+	cmd := []string{cr.Spec.Engine.CLI(), "--cluster", "check", fmt.Sprintf("127.0.0.1:%d", *cr.Spec.Port)}
 	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
 		pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
 		if err != nil {
@@ -1074,10 +1075,16 @@ func GetRedisReplicationRealMaster(ctx context.Context, client kubernetes.Interf
 	return ""
 }
 
-func GetRedisReplicationBestMaster(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, masterPods []string) string {
-	var bestMasterPod string
-	var bestOffset int64 = -1
-
+// GetRedisReplicationBestMaster returns the pod the sentinels would promote, the replica-priority
+// of spec.replicaPriority first and the replication offset next. A pod with priority 0 or an
+// unknown one is never returned, "" when no pod may be promoted. It fails while the priorities
+// cannot be read.
+func GetRedisReplicationBestMaster(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, masterPods []string) (string, error) {
+	priorities, err := GetRedisReplicationReplicaPriorities(ctx, client, cr)
+	if err != nil {
+		return "", err
+	}
+	offsets := map[string]int64{}
 	for _, podName := range masterPods {
 		redisClient := configureRedisReplicationClient(ctx, client, cr, podName)
 		defer redisClient.Close()
@@ -1087,14 +1094,10 @@ func GetRedisReplicationBestMaster(ctx context.Context, client kubernetes.Interf
 			log.FromContext(ctx).Error(err, "Failed to get replication offset for", "pod", podName)
 			continue
 		}
-
-		if offset > bestOffset {
-			bestOffset = offset
-			bestMasterPod = podName
-		}
+		offsets[podName] = offset
 	}
 
-	return bestMasterPod
+	return pickBestMaster(masterPods, offsets, priorities), nil
 }
 
 func applyDynamicConfig(ctx context.Context, redisClient *redis.Client, podName string, dynamicConfig []string) (bool, error) {
@@ -1134,9 +1137,12 @@ func applyDynamicConfig(ctx context.Context, redisClient *redis.Client, podName
 
 // SetRedisClusterDynamicConfig applies dynamic configuration to each Redis instance in the cluster
 func SetRedisClusterDynamicConfig(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
-	// Get dynamic configuration
-	dynamicConfig := cr.Spec.GetRedisDynamicConfig()
-	if len(dynamicConfig) == 0 {
+	return SetRedisClusterConfig(ctx, client, cr, cr.Spec.GetRedisDynamicConfig())
+}
+
+// SetRedisClusterConfig applies the config to each Redis instance in the cluster
+func SetRedisClusterConfig(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, config []string) error {
+	if len(config) == 0 {
 		return nil
 	}
 
@@ -1154,7 +1160,7 @@ func SetRedisClusterDynamicConfig(ctx context.Context, client kubernetes.Interfa
 		}
 
 		redisClient := configureRedisClient(ctx, client, cr, podName)
-		_, err := applyDynamicConfig(ctx, redisClient, podName, dynamicConfig)
+		_, err := applyDynamicConfig(ctx, redisClient, podName, config)
 		redisClient.Close()
 		if err != nil {
 			return err
@@ -1165,14 +1171,22 @@ func SetRedisClusterDynamicConfig(ctx context.Context, client kubernetes.Interfa
 }
 
 func SetRedisReplicationDynamicConfig(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) error {
-	return setRedisReplicationDynamicConfig(ctx, cr, func(podName string) *redis.Client {
+	return SetRedisReplicationConfig(ctx, client, cr, cr.Spec.GetRedisDynamicConfig())
+}
+
+// SetRedisReplicationConfig applies the config to each Redis instance of the replication
+func SetRedisReplicationConfig(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, config []string) error {
+	return applyRedisReplicationConfig(ctx, cr, config, func(podName string) *redis.Client {
 		return configureRedisReplicationClient(ctx, client, cr, podName)
 	})
 }
 
 func setRedisReplicationDynamicConfig(ctx context.Context, cr *rrvb2.RedisReplication, makeClient func(podName string) *redis.Client) error {
-	dynamicConfig := cr.Spec.GetRedisDynamicConfig()
-	if len(dynamicConfig) == 0 {
+	return applyRedisReplicationConfig(ctx, cr, cr.Spec.GetRedisDynamicConfig(), makeClient)
+}
+
+func applyRedisReplicationConfig(ctx context.Context, cr *rrvb2.RedisReplication, config []string, makeClient func(podName string) *redis.Client) error {
+	if len(config) == 0 {
 		return nil
 	}
 
@@ -1181,7 +1195,7 @@ func setRedisReplicationDynamicConfig(ctx context.Context, cr *rrvb2.RedisReplic
 		podName := cr.Name + "-" + strconv.Itoa(i)
 
 		redisClient := makeClient(podName)
-		_, err := applyDynamicConfig(ctx, redisClient, podName, dynamicConfig)
+		_, err := applyDynamicConfig(ctx, redisClient, podName, config)
 		redisClient.Close()
 		if err != nil {
 			return err
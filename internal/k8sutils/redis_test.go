@@ -484,6 +484,15 @@ func TestExecuteSingleLeaderAddSlots(t *testing.T) {
 			expectedFlags: []string{"-a", "password"},
 			rangeCommand:  true,
 		},
+		{
+			name: "valkey uses a single ADDSLOTSRANGE command run by valkey-cli",
+			redisCluster: func() *rcvb2.RedisCluster {
+				cr := newCluster(ptr.To("v6"), false, false)
+				cr.Spec.Engine = common.EngineValkey
+				return cr
+			}(),
+			rangeCommand: true,
+		},
 		{
 			name:         "redis v6 falls back to batched ADDSLOTS",
 			redisCluster: newCluster(nil, false, false),
@@ -508,7 +517,7 @@ func TestExecuteSingleLeaderAddSlots(t *testing.T) {
 				execs = append(execs, recordedExec{cmd: cmd, podName: podName})
 			})
 
-			expectedPrefix := append([]string{"redis-cli"}, tt.expectedFlags...)
+			expectedPrefix := append([]string{tt.redisCluster.Spec.Engine.CLI()}, tt.expectedFlags...)
 			if tt.rangeCommand {
 				assert.Len(t, execs, 1, "v7 should issue exactly one command")
 				assert.Equal(t, append(expectedPrefix, "CLUSTER", "ADDSLOTSRANGE", "0", "16383"), execs[0].cmd)
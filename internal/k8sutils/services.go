@@ -127,7 +127,12 @@ func getService(ctx context.Context, k8sClient kubernetes.Interface, namespace s
 // CreateOrUpdateService method will create or update Redis service
 func CreateOrUpdateService(ctx context.Context, namespace string, serviceMeta metav1.ObjectMeta, ownerDef metav1.OwnerReference, epp exporterPortProvider, headless bool, serviceType string, port int, cl kubernetes.Interface, extra ...corev1.ServicePort) error {
 	serviceDef := generateServiceDef(serviceMeta, epp, ownerDef, headless, serviceType, port, extra...)
-	storedService, err := getService(ctx, cl, namespace, serviceMeta.GetName())
+	return createOrUpdateServiceDef(ctx, namespace, serviceDef, cl)
+}
+
+// createOrUpdateServiceDef creates the service or patches the stored one to the definition
+func createOrUpdateServiceDef(ctx context.Context, namespace string, serviceDef *corev1.Service, cl kubernetes.Interface) error {
+	storedService, err := getService(ctx, cl, namespace, serviceDef.GetName())
 	if err != nil {
 		if errors.IsNotFound(err) {
 			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(serviceDef); err != nil { //nolint:gocritic
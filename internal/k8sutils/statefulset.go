@@ -59,11 +59,13 @@ func (s *StatefulSetService) IsStatefulSetReady(ctx context.Context, namespace,
 		replicas = int(*sts.Spec.Replicas)
 	}
 
-	if expectedUpdateReplicas := replicas - partition; sts.Status.UpdatedReplicas < int32(expectedUpdateReplicas) {
+	// with OnDelete the pods only move to the update revision when they are deleted
+	onDelete := sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
+	if expectedUpdateReplicas := replicas - partition; !onDelete && sts.Status.UpdatedReplicas < int32(expectedUpdateReplicas) {
 		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.UpdatedReplicas", sts.Status.UpdatedReplicas, "ExpectedUpdateReplicas", expectedUpdateReplicas)
 		return false
 	}
-	if partition == 0 && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+	if !onDelete && partition == 0 && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
 		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.CurrentRevision", sts.Status.CurrentRevision, "Status.UpdateRevision", sts.Status.UpdateRevision)
 		return false
 	}
@@ -71,13 +73,67 @@ func (s *StatefulSetService) IsStatefulSetReady(ctx context.Context, namespace,
 		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.ObservedGeneration", sts.Status.ObservedGeneration, "Generation", sts.Generation)
 		return false
 	}
-	if int(sts.Status.ReadyReplicas) != replicas {
-		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.ReadyReplicas", sts.Status.ReadyReplicas, "Replicas", replicas)
+	readyReplicas := sts.Status.ReadyReplicas
+	// the operator sets the readiness gates of the pods itself, it only waits for their containers
+	if len(sts.Spec.Template.Spec.ReadinessGates) > 0 {
+		if readyReplicas, err = s.containersReadyReplicas(ctx, sts); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list the pods of the statefulset")
+			return false
+		}
+	}
+	if int(readyReplicas) != replicas {
+		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.ReadyReplicas", readyReplicas, "Replicas", replicas)
 		return false
 	}
 	return true
 }
 
+// containersReadyReplicas returns the number of pods of the statefulset whose containers are ready
+func (s *StatefulSetService) containersReadyReplicas(ctx context.Context, sts *appsv1.StatefulSet) (int32, error) {
+	selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+	if err != nil {
+		return 0, err
+	}
+	pods, err := s.kubeClient.CoreV1().Pods(sts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, err
+	}
+	var ready int32
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && podConditionTrue(&pod, corev1.ContainersReady) {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+// GetStatefulSetOutdatedPods returns the pods of the statefulset not running its update revision,
+// highest ordinal first
+func GetStatefulSetOutdatedPods(ctx context.Context, client kubernetes.Interface, namespace, name string) ([]string, error) {
+	sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if sts.Status.UpdateRevision == "" || sts.Spec.Replicas == nil {
+		return nil, nil
+	}
+	var outdated []string
+	for i := int(*sts.Spec.Replicas) - 1; i >= 0; i-- {
+		podName := name + "-" + strconv.Itoa(i)
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if pod.Labels[appsv1.StatefulSetRevisionLabel] != sts.Status.UpdateRevision {
+			outdated = append(outdated, podName)
+		}
+	}
+	return outdated, nil
+}
+
 func (s *StatefulSetService) GetStatefulSetReplicas(ctx context.Context, namespace, name string) int32 {
 	sts, err := s.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -120,6 +176,7 @@ type statefulSetParameters struct {
 	HostNetwork                          bool
 	MinReadySeconds                      int32
 	PodManagementPolicy                  *string
+	ReadinessGates                       []corev1.PodReadinessGate
 }
 
 // containerParameters will define container input params
@@ -150,6 +207,7 @@ type containerParameters struct {
 	EnvVars                      *[]corev1.EnvVar
 	Port                         *int
 	HostPort                     *int
+	Engine                       commonapi.Engine
 	// Sentinel-driven preStop settings. These are only populated for the
 	// "replication" role when an embedded Sentinel is enabled. When
 	// SentinelService is empty the replication preStop hook is not installed.
@@ -172,6 +230,8 @@ type initContainerParameters struct {
 	AdditionalVolume      []corev1.Volume
 	AdditionalMountPath   []corev1.VolumeMount
 	SecurityContext       *corev1.SecurityContext
+	// Restore seeds the data volume of the pods with the snapshots of a backup
+	Restore *RestoreSource
 }
 
 // CreateOrUpdateStateFul method will create or update Redis service
@@ -335,6 +395,7 @@ func generateStatefulSetsDef(stsMeta metav1.ObjectMeta, params statefulSetParame
 					Affinity:                      params.Affinity,
 					TerminationGracePeriodSeconds: params.TerminationGracePeriodSeconds,
 					HostNetwork:                   params.HostNetwork,
+					ReadinessGates:                params.ReadinessGates,
 					Volumes:                       []corev1.Volume{generateConfigVolume(common.VolumeNameConfig)},
 				},
 			},
@@ -366,6 +427,9 @@ func generateStatefulSetsDef(stsMeta metav1.ObjectMeta, params statefulSetParame
 	if containerParams.AdditionalVolume != nil {
 		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, containerParams.AdditionalVolume...)
 	}
+	if initcontainerParams.Restore != nil && containerParams.PersistenceEnabled != nil && *containerParams.PersistenceEnabled {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, generateRestoreVolume(initcontainerParams.Restore))
+	}
 
 	if containerParams.TLSConfig != nil {
 		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes,
@@ -487,18 +551,19 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 				containerParams.Resources,
 				containerParams.MaxMemoryPercentOfLimit,
 			),
-			ReadinessProbe: getProbeInfo(containerParams.ReadinessProbe, sentinelCntr, enableTLS, enableAuth),
-			LivenessProbe:  getProbeInfo(containerParams.LivenessProbe, sentinelCntr, enableTLS, enableAuth),
+			ReadinessProbe: getProbeInfo(containerParams.ReadinessProbe, containerParams.Engine.CLI(), sentinelCntr, enableTLS, enableAuth),
+			LivenessProbe:  getProbeInfo(containerParams.LivenessProbe, containerParams.Engine.CLI(), sentinelCntr, enableTLS, enableAuth),
 			VolumeMounts:   getVolumeMount(name, containerParams.PersistenceEnabled, clusterMode, nodeConfVolume, externalConfig, mountpath, containerParams.TLSConfig, containerParams.ACLConfig),
 		},
 	}
 
-	if features.Enabled(features.GenerateConfigInInitContainer) {
+	configInInitContainer := generatesConfigInInitContainer(containerParams.Engine)
+	if configInInitContainer {
 		if sentinelCntr {
-			containerDefinition[0].Command = []string{"redis-sentinel"}
+			containerDefinition[0].Command = []string{containerParams.Engine.Sentinel()}
 			containerDefinition[0].Args = []string{"/etc/redis/sentinel.conf"}
 		} else {
-			containerDefinition[0].Command = []string{"redis-server"}
+			containerDefinition[0].Command = []string{containerParams.Engine.Server()}
 			containerDefinition[0].Args = []string{"/etc/redis/redis.conf"}
 		}
 	}
@@ -508,13 +573,15 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 	// sentinel.conf lives on the overlay filesystem and is lost on restart,
 	// causing sentinel to lose all runtime-discovered master topology.
 	// The config volume is already created on all StatefulSets but only
-	// mounted when GenerateConfigInInitContainer is enabled.
-	if sentinelCntr && !features.Enabled(features.GenerateConfigInInitContainer) {
+	// mounted when GenerateConfigInInitContainer is enabled, Valkey pods read
+	// the generated config from it as well.
+	if (sentinelCntr || configInInitContainer) && !features.Enabled(features.GenerateConfigInInitContainer) {
 		containerDefinition[0].VolumeMounts = append(containerDefinition[0].VolumeMounts, generateConfigVolumeMount(common.VolumeNameConfig))
 	}
 
 	preStopCfg := PreStopConfig{
 		Role:               containerParams.Role,
+		Engine:             containerParams.Engine,
 		EnableAuth:         enableAuth,
 		EnableTLS:          enableTLS,
 		SentinelService:    containerParams.SentinelService,
@@ -579,11 +646,19 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 	return containerDefinition
 }
 
+// generatesConfigInInitContainer reports whether the init container writes the config the server
+// is started with. Valkey images lack the entrypoint of the Redis images that writes it otherwise.
+func generatesConfigInInitContainer(engine commonapi.Engine) bool {
+	return features.Enabled(features.GenerateConfigInInitContainer) || engine == commonapi.EngineValkey
+}
+
 // PreStopConfig holds the inputs needed to render a container preStop hook.
 type PreStopConfig struct {
 	Role       string
 	EnableAuth bool
 	EnableTLS  bool
+	// Engine selects the CLI the hook runs, redis-cli or valkey-cli
+	Engine commonapi.Engine
 	// SentinelService, SentinelMasterName and SentinelPort describe the
 	// Sentinel that manages failover for the "replication" role. They must be
 	// sourced from the actual (embedded) Sentinel config rather than derived in
@@ -608,7 +683,7 @@ func GeneratePreStopCommand(cfg PreStopConfig) string {
 
 	switch cfg.Role {
 	case "cluster":
-		return generateClusterPreStop(authArgs, tlsArgs)
+		return generateClusterPreStop(cfg.Engine.CLI(), authArgs, tlsArgs)
 	case "replication":
 		// Without a Sentinel managing failover there is nothing to fail over
 		// to; installing the hook would make every master termination block on
@@ -654,12 +729,12 @@ func GenerateAuthAndTLSArgs(enableAuth, enableTLS bool) (string, string) {
 
 // generateClusterPreStop generates the preStop script for Redis cluster mode.
 // It identifies the master node and triggers a failover to the best available slave before shutdown.
-func generateClusterPreStop(authArgs, tlsArgs string) string {
+func generateClusterPreStop(cli, authArgs, tlsArgs string) string {
 	return fmt.Sprintf(`#!/bin/sh
-ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s %s info replication | awk -F: '/role:master/ {print "master"}')
+ROLE=$(%[1]s -h $(hostname) -p ${REDIS_PORT} %[2]s %[3]s info replication | awk -F: '/role:master/ {print "master"}')
 
 if [ "$ROLE" = "master" ]; then
-    BEST_SLAVE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s %s info replication | awk -F: '
+    BEST_SLAVE=$(%[1]s -h $(hostname) -p ${REDIS_PORT} %[2]s %[3]s info replication | awk -F: '
         BEGIN { maxOffset = -1; bestSlave = "" }
         /slave[0-9]+:ip/ {
             split($2, a, ",");
@@ -676,9 +751,9 @@ if [ "$ROLE" = "master" ]; then
     ')
 
     if [ -n "$BEST_SLAVE" ]; then
-        redis-cli -h "$BEST_SLAVE" -p ${REDIS_PORT} %s %s cluster failover
+        %[1]s -h "$BEST_SLAVE" -p ${REDIS_PORT} %[2]s %[3]s cluster failover
     fi
-fi`, authArgs, tlsArgs, authArgs, tlsArgs, authArgs, tlsArgs)
+fi`, cli, authArgs, tlsArgs)
 }
 
 // generateReplicationPreStop generates the preStop script for Redis replication mode.
@@ -696,25 +771,25 @@ func generateReplicationPreStop(authArgs, tlsArgs string, cfg PreStopConfig) str
 	}
 	waitSeconds := max(cfg.WaitSeconds, 1)
 	return fmt.Sprintf(`#!/bin/sh
-ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s %s info replication | awk -F: '/role:master/ {print "master"}')
+ROLE=$(%[1]s -h $(hostname) -p ${REDIS_PORT} %[2]s %[3]s info replication | awk -F: '/role:master/ {print "master"}')
 
 if [ "$ROLE" = "master" ]; then
-    redis-cli -h "%s" -p %d SENTINEL FAILOVER %s
+    %[1]s -h "%[4]s" -p %[5]d SENTINEL FAILOVER %[6]s
 
-    for i in $(seq 1 %d); do
-        NEW_ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s %s info replication | awk -F: '/role:slave/ {print "slave"}')
+    for i in $(seq 1 %[7]d); do
+        NEW_ROLE=$(%[1]s -h $(hostname) -p ${REDIS_PORT} %[2]s %[3]s info replication | awk -F: '/role:slave/ {print "slave"}')
         if [ "$NEW_ROLE" = "slave" ]; then
             break
         fi
         sleep 1
     done
-fi`, authArgs, tlsArgs, cfg.SentinelService, sentinelPort, cfg.SentinelMasterName, waitSeconds, authArgs, tlsArgs)
+fi`, cfg.Engine.CLI(), authArgs, tlsArgs, cfg.SentinelService, sentinelPort, cfg.SentinelMasterName, waitSeconds)
 }
 
 func generateInitContainerDef(role, name string, initcontainerParams initContainerParameters, externalConfig *string, mountpath []corev1.VolumeMount, containerParams containerParameters, clusterVersion *string) []corev1.Container {
 	containers := []corev1.Container{}
 
-	if features.Enabled(features.GenerateConfigInInitContainer) {
+	if generatesConfigInInitContainer(containerParams.Engine) {
 		// give all container env vars to init container
 		envVars := append(
 			ptr.Deref(containerParams.EnvVars, []corev1.EnvVar{}),
@@ -775,6 +850,10 @@ func generateInitContainerDef(role, name string, initcontainerParams initContain
 			Env:             ptr.Deref(initcontainerParams.AdditionalEnvVariable, []corev1.EnvVar{}),
 		})
 	}
+
+	if initcontainerParams.Restore != nil && containerParams.PersistenceEnabled != nil && *containerParams.PersistenceEnabled {
+		containers = append(containers, generateRestoreInitContainer(name, initcontainerParams.Restore, containerParams))
+	}
 	return containers
 }
 
@@ -950,13 +1029,13 @@ func getVolumeMount(name string, persistenceEnabled *bool, clusterMode bool, nod
 // getProbeInfo generate probe for Redis StatefulSet
 // The `ping` command will exit successfully even if the node is loading,
 // so we need to verify that the Redis `ping` command returns "PONG".
-func getProbeInfo(probe *corev1.Probe, sentinel, enableTLS, enableAuth bool) *corev1.Probe {
+func getProbeInfo(probe *corev1.Probe, cli string, sentinel, enableTLS, enableAuth bool) *corev1.Probe {
 	if probe == nil {
 		probe = &corev1.Probe{}
 	}
 	if probe.Exec == nil && probe.HTTPGet == nil && probe.TCPSocket == nil && probe.GRPC == nil {
 		redisHealthCheck := []string{
-			"redis-cli",
+			cli,
 			"-h", "$(hostname)",
 		}
 		if sentinel {
@@ -197,6 +197,52 @@ func TestGenerateReplicationPreStopContent(t *testing.T) {
 	assert.NotContains(t, script, "--no-auth-warning")
 }
 
+func TestGeneratePreStopCommandUsesEngineCLI(t *testing.T) {
+	cluster := GeneratePreStopCommand(PreStopConfig{Role: "cluster", Engine: common.EngineValkey})
+	assert.Contains(t, cluster, `valkey-cli -h "$BEST_SLAVE" -p ${REDIS_PORT}   cluster failover`)
+	assert.NotContains(t, cluster, "redis-cli")
+
+	replication := GeneratePreStopCommand(PreStopConfig{
+		Role:               "replication",
+		Engine:             common.EngineValkey,
+		SentinelService:    "my-replication-s-hl",
+		SentinelMasterName: "mymaster",
+		WaitSeconds:        20,
+	})
+	assert.Contains(t, replication, `valkey-cli -h "my-replication-s-hl" -p 26379 SENTINEL FAILOVER mymaster`)
+	assert.NotContains(t, replication, "redis-cli")
+}
+
+func TestGenerateContainerDefForValkey(t *testing.T) {
+	for _, role := range []string{"cluster", "sentinel"} {
+		containers := generateContainerDef(
+			"valkey",
+			containerParameters{Role: role, Image: "valkey/valkey:8.0", Engine: common.EngineValkey},
+			false,
+			false,
+			false,
+			nil,
+			ptr.To("v8"),
+			nil,
+			nil,
+		)
+		require.Len(t, containers, 1)
+		// the Valkey image is started with the config generated by the init container
+		if role == "sentinel" {
+			assert.Equal(t, []string{"valkey-sentinel"}, containers[0].Command)
+		} else {
+			assert.Equal(t, []string{"valkey-server"}, containers[0].Command)
+		}
+		assert.Contains(t, containers[0].VolumeMounts, generateConfigVolumeMount("config"))
+		assert.Contains(t, containers[0].ReadinessProbe.Exec.Command[2], "valkey-cli -h $(hostname)")
+		assert.Contains(t, containers[0].Env, corev1.EnvVar{Name: "REDIS_MAJOR_VERSION", Value: "v8"})
+	}
+
+	initContainers := generateInitContainerDef("cluster", "valkey", initContainerParameters{}, nil, nil, containerParameters{Role: "cluster", Engine: common.EngineValkey}, ptr.To("v8"))
+	require.Len(t, initContainers, 1)
+	assert.Equal(t, []string{"bootstrap"}, initContainers[0].Args)
+}
+
 func TestReplicationPreStopWaitSeconds(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -455,6 +501,83 @@ func Test_GetStatefulSet(t *testing.T) {
 	}
 }
 
+func TestIsStatefulSetReadyOnDelete(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sts", Namespace: "test-ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       ptr.To(int32(3)),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   3,
+			UpdatedReplicas: 1,
+			CurrentRevision: "rev-1",
+			UpdateRevision:  "rev-2",
+		},
+	}
+	service := NewStatefulSetService(k8sClientFake.NewSimpleClientset(sts))
+	assert.True(t, service.IsStatefulSetReady(context.TODO(), "test-ns", "test-sts"))
+
+	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{}
+	service = NewStatefulSetService(k8sClientFake.NewSimpleClientset(sts))
+	assert.False(t, service.IsStatefulSetReady(context.TODO(), "test-ns", "test-sts"))
+}
+
+func TestIsStatefulSetReadyReadinessGates(t *testing.T) {
+	labels := map[string]string{"app": "test-sts"}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sts", Namespace: "test-ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(2)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/ready"}},
+			}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdatedReplicas: 2},
+	}
+	pod := func(name string, containersReady corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: labels},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.ContainersReady, Status: containersReady},
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+			}},
+		}
+	}
+
+	// the pods are not ready until their gate is set, their containers are
+	service := NewStatefulSetService(k8sClientFake.NewSimpleClientset(sts, pod("test-sts-0", corev1.ConditionTrue), pod("test-sts-1", corev1.ConditionTrue)))
+	assert.True(t, service.IsStatefulSetReady(context.TODO(), "test-ns", "test-sts"))
+
+	service = NewStatefulSetService(k8sClientFake.NewSimpleClientset(sts, pod("test-sts-0", corev1.ConditionTrue), pod("test-sts-1", corev1.ConditionFalse)))
+	assert.False(t, service.IsStatefulSetReady(context.TODO(), "test-ns", "test-sts"))
+}
+
+func TestGetStatefulSetOutdatedPods(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader", Namespace: "test-ns"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(4))},
+		Status:     appsv1.StatefulSetStatus{UpdateRevision: "rev-2"},
+	}
+	pod := func(name, revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+			Labels:    map[string]string{appsv1.StatefulSetRevisionLabel: revision},
+		}}
+	}
+	client := k8sClientFake.NewSimpleClientset(sts,
+		pod("cluster-leader-0", "rev-1"),
+		pod("cluster-leader-1", "rev-2"),
+		pod("cluster-leader-2", "rev-1"),
+	)
+
+	pods, err := GetStatefulSetOutdatedPods(context.TODO(), client, "test-ns", "cluster-leader")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-leader-2", "cluster-leader-0"}, pods)
+}
+
 func Test_createStatefulSet(t *testing.T) {
 	tests := []struct {
 		name    string
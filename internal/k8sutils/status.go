@@ -16,6 +16,8 @@ func UpdateRedisClusterStatus(ctx context.Context, cr *rcvb2.RedisCluster, state
 		Reason:                reason,
 		ReadyLeaderReplicas:   readyLeaderReplicas,
 		ReadyFollowerReplicas: readyFollowerReplicas,
+		PasswordRotation:      cr.Status.PasswordRotation,
+		TLSRotation:           cr.Status.TLSRotation,
 	}
 	if reflect.DeepEqual(cr.Status, newStatus) {
 		return nil
@@ -34,3 +34,10 @@ func RegisterRedisClusterMetrics() {
 		RedisClusterReshardTotal,
 	)
 }
+
+func RegisterRedisBackupMetrics() {
+	metrics.Registry.MustRegister(
+		RedisBackupScheduleLastSuccessTimestamp,
+		RedisBackupScheduleConsecutiveFailures,
+	)
+}
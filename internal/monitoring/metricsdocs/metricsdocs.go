@@ -21,14 +21,21 @@ func main() {
 		return clusterMetrics[i].Name < clusterMetrics[j].Name
 	})
 
+	backupMetrics := monitoring.ListRedisBackupMetrics()
+	sort.Slice(backupMetrics, func(i, j int) bool {
+		return backupMetrics[i].Name < backupMetrics[j].Name
+	})
+
 	type MetricsData struct {
 		Replication []monitoring.MetricDescription
 		Cluster     []monitoring.MetricDescription
+		Backup      []monitoring.MetricDescription
 	}
 
 	data := MetricsData{
 		Replication: replicationMetrics,
 		Cluster:     clusterMetrics,
+		Backup:      backupMetrics,
 	}
 
 	tmpl, err := template.New("Redis Operator metrics").Parse("# Operator Metrics\n" +
@@ -52,6 +59,14 @@ func main() {
 		"Type: {{.Type}}.\n" +
 		"{{end}}" +
 		"\n" +
+		"## Redis Backup Metrics" +
+		"\n" +
+		"{{range .Backup}}\n" +
+		"### {{.Name}}\n" +
+		"{{.Help}} " +
+		"Type: {{.Type}}.\n" +
+		"{{end}}" +
+		"\n" +
 		"## Developing new metrics\n" +
 		"After developing new metrics or changing old ones, please run \"make generate-metricsdocs\" to regenerate this document.\n\n" +
 		"If you feel that the new metric doesn't follow these rules, please change \"monitoring/metricsdocs\" according to your needs.")
@@ -0,0 +1,49 @@
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RedisBackupDescription is a map of string keys (metrics) to MetricDescription values (Name, Help).
+var RedisBackupDescription = map[string]MetricDescription{
+	"RedisBackupScheduleLastSuccessTimestamp": {
+		Name:   "redisbackupschedule_last_success_timestamp_seconds",
+		Help:   "Unix time of the completion of the last successful backup of a RedisBackupSchedule, alert on it to catch stale backups.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisBackupScheduleConsecutiveFailures": {
+		Name:   "redisbackupschedule_consecutive_failures",
+		Help:   "Number of backups of a RedisBackupSchedule that failed since the last successful one.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+}
+
+var (
+	RedisBackupScheduleLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisBackupDescription["RedisBackupScheduleLastSuccessTimestamp"].Name,
+			Help: RedisBackupDescription["RedisBackupScheduleLastSuccessTimestamp"].Help,
+		},
+		RedisBackupDescription["RedisBackupScheduleLastSuccessTimestamp"].labels,
+	)
+	RedisBackupScheduleConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisBackupDescription["RedisBackupScheduleConsecutiveFailures"].Name,
+			Help: RedisBackupDescription["RedisBackupScheduleConsecutiveFailures"].Help,
+		},
+		RedisBackupDescription["RedisBackupScheduleConsecutiveFailures"].labels,
+	)
+)
+
+// ListRedisBackupMetrics will create a slice with the metrics available in RedisBackupDescription
+func ListRedisBackupMetrics() []MetricDescription {
+	v := make([]MetricDescription, 0, len(RedisBackupDescription))
+	// Insert value (Name, Help) for each metric
+	for _, value := range RedisBackupDescription {
+		v = append(v, value)
+	}
+
+	return v
+}
@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
 	"strconv"
 	"strings"
@@ -57,6 +59,10 @@ type Service interface {
 	SentinelSet(ctx context.Context, masterGroupName, key, value string) error
 	SentinelReset(ctx context.Context, masterGroupName string) error
 	GetInfoSentinel(ctx context.Context) (*InfoSentinelResult, error)
+	SentinelCKQuorum(ctx context.Context, masterGroupName string) (bool, string, error)
+	SentinelFailover(ctx context.Context, masterGroupName string) error
+	SentinelGetMasterAddrByName(ctx context.Context, masterGroupName string) (string, string, error)
+	SentinelReplicaPriorities(ctx context.Context, masterGroupName string) (map[string]int, error)
 	GetClusterInfo(ctx context.Context) (*ClusterStatus, error)
 }
 
@@ -152,6 +158,100 @@ func (c *service) GetInfoSentinel(ctx context.Context) (*InfoSentinelResult, err
 	return info, nil
 }
 
+// SentinelCKQuorum reports whether the sentinels monitoring the master group reach the quorum
+// and the majority needed to authorize a failover, with the message of SENTINEL CKQUORUM. The
+// NOQUORUM reply of the command is not an error.
+func (c *service) SentinelCKQuorum(ctx context.Context, masterGroupName string) (bool, string, error) {
+	client := c.createClient()
+	if client == nil {
+		return false, "", nil
+	}
+	defer client.Close()
+
+	message, err := client.Do(ctx, "SENTINEL", "CKQUORUM", masterGroupName).Text()
+	if err != nil {
+		var redisErr rediscli.Error
+		if errors.As(err, &redisErr) && err != rediscli.Nil {
+			return false, err.Error(), nil
+		}
+		return false, "", err
+	}
+	return true, message, nil
+}
+
+// SentinelFailover forces a failover of the master group without asking the other sentinels,
+// the replica to promote is elected among the replicas with a replica-priority other than 0
+func (c *service) SentinelFailover(ctx context.Context, masterGroupName string) error {
+	client := c.createClient()
+	if client == nil {
+		return nil
+	}
+	defer client.Close()
+
+	return client.Do(ctx, "SENTINEL", "FAILOVER", masterGroupName).Err()
+}
+
+// SentinelGetMasterAddrByName returns the host and port of the master the sentinel knows for the
+// master group
+func (c *service) SentinelGetMasterAddrByName(ctx context.Context, masterGroupName string) (string, string, error) {
+	client := c.createClient()
+	if client == nil {
+		return "", "", nil
+	}
+	defer client.Close()
+
+	addr, err := client.Do(ctx, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", masterGroupName).StringSlice()
+	if err != nil {
+		return "", "", err
+	}
+	if len(addr) != 2 {
+		return "", "", fmt.Errorf("unexpected master address %v of %s", addr, masterGroupName)
+	}
+	return addr[0], addr[1], nil
+}
+
+// SentinelReplicaPriorities returns the replica-priority the sentinel last read from every replica
+// of the master group, by the host of the replica
+func (c *service) SentinelReplicaPriorities(ctx context.Context, masterGroupName string) (map[string]int, error) {
+	client := c.createClient()
+	if client == nil {
+		return nil, nil
+	}
+	defer client.Close()
+
+	replicas, err := client.Do(ctx, "SENTINEL", "REPLICAS", masterGroupName).Slice()
+	if err != nil {
+		return nil, err
+	}
+	priorities := map[string]int{}
+	for _, replica := range replicas {
+		fields := sentinelFields(replica)
+		priority, err := strconv.Atoi(fields["slave-priority"])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected replica-priority of %s: %w", fields["ip"], err)
+		}
+		priorities[fields["ip"]] = priority
+	}
+	return priorities, nil
+}
+
+// sentinelFields reads the fields of a SENTINEL reply, a flat list of names and values on RESP2
+// and a map on RESP3
+func sentinelFields(reply interface{}) map[string]string {
+	fields := map[string]string{}
+	switch v := reply.(type) {
+	case []interface{}:
+		for i := 0; i+1 < len(v); i += 2 {
+			fields[fmt.Sprint(v[i])] = fmt.Sprint(v[i+1])
+		}
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			fields[fmt.Sprint(key)] = fmt.Sprint(value)
+		}
+	}
+	return fields
+}
+
 func (c *service) SentinelSet(ctx context.Context, masterGroupName, key, value string) error {
 	client := c.createClient()
 	if client == nil {